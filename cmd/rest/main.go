@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/VanLavr/Diploma-fin/internal/controllers/rest"
 	"github.com/VanLavr/Diploma-fin/internal/infrastructure/postgres"
@@ -10,6 +11,7 @@ import (
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/config"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
 )
 
 func main() {
@@ -28,6 +30,31 @@ func main() {
 	cfg, err := config.ReadConfig()
 	errors.FatalOnError(err)
 
+	// app migrate up|down|status|redo
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		command := postgres.MigrateUp
+		if len(os.Args) > 2 {
+			command = os.Args[2]
+		}
+		errors.FatalOnError(postgres.Migrate(context.Background(), cfg, command))
+		return
+	}
+	// app backup <file> | app restore <file>
+	if len(os.Args) > 1 && (os.Args[1] == "backup" || os.Args[1] == "restore") {
+		if len(os.Args) < 3 {
+			errors.FatalOnError(log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_APPLICATION, "usage: app "+os.Args[1]+" <file>"))
+		}
+		run := postgres.Backup
+		if os.Args[1] == "restore" {
+			run = postgres.Restore
+		}
+		errors.FatalOnError(run(context.Background(), cfg, os.Args[2]))
+		return
+	}
+	if cfg.MigrateOnStart {
+		errors.FatalOnError(postgres.Migrate(context.Background(), cfg, postgres.MigrateUp))
+	}
+
 	repository := postgres.NewRepository(cfg)
 	errors.FatalOnError(utils.CreateAdmin(cfg, repository))
 
@@ -36,6 +63,15 @@ func main() {
 	examApp := application.NewExamUsecase(repository)
 	groupApp := application.NewGroupUsecase(repository)
 	fileApp := application.NewFileUsecase(repository)
+	retakeSessionApp := application.NewRetakeSessionUsecase(repository)
+	roomApp := application.NewRoomUsecase(repository)
+	availabilityApp := application.NewTeacherAvailabilityUsecase(repository)
+	timetableApp := application.NewTimetableUsecase(repository)
+	resultApp := application.NewResultUsecase(repository)
+	documentApp := application.NewDocumentUsecase(repository)
+	searchApp := application.NewSearchUsecase(repository)
+	trashApp := application.NewTrashUsecase(repository)
+	personalDataApp := application.NewPersonalDataUsecase(repository)
 
 	server := rest.NewServer(
 		cfg,
@@ -45,7 +81,23 @@ func main() {
 		rest.NewExamHandler(examApp),
 		rest.NewGroupHandler(groupApp),
 		rest.NewFileHandler(fileApp),
+		rest.NewRetakeSessionHandler(retakeSessionApp),
+		rest.NewRoomHandler(roomApp),
+		rest.NewTeacherAvailabilityHandler(availabilityApp),
+		rest.NewTimetableHandler(timetableApp),
+		rest.NewResultHandler(resultApp),
+		rest.NewDocumentHandler(documentApp),
+		rest.NewSearchHandler(searchApp),
+		rest.NewTrashHandler(trashApp),
+		rest.NewPersonalDataHandler(personalDataApp),
 	)
 
-	errors.FatalOnError(server.Start(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go fileApp.RunImportJobs(ctx)
+	go trashApp.RunTrashPurge(ctx, cfg.TrashRetention)
+	go personalDataApp.RunPersonalDataRetention(ctx, cfg.PersonalDataRetention)
+
+	errors.FatalOnError(server.Start(ctx))
 }
@@ -5,9 +5,33 @@ type SetDebtDateDTO struct {
 	ExamID      int64  `json:"exam_id"`
 	ExamDate    string `json:"exam_date"`
 	Address     string `json:"address"`
+	RoomID      int64  `json:"room_id"`
+	// schedule despite conflicts with other retakes
+	Force bool `json:"force"`
+	// optional, narrow the debts of the exam down
+	GroupIDs     []int64  `json:"group_ids"`
+	StudentUUIDs []string `json:"student_uuids"`
 }
 
+type SetDateResult struct {
+	DebtID      int64  `json:"debt_id"`
+	StudentUUID string `json:"student_uuid"`
+	Scheduled   bool   `json:"scheduled"`
+	Notified    bool   `json:"notified"`
+	Error       string `json:"error,omitempty"`
+}
+
+type SetDateResponseDTO struct {
+	Err       error              `json:"error"`
+	Data      []SetDateResult    `json:"data"`
+	Conflicts []ScheduleConflict `json:"conflicts"`
+}
+
+// Exam, Group, Student and Teacher carry the version of their ETag, it is
+// omitted where they are joined into another entity.
 type Exam struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	AssessmentType string `json:"assessment_type"`
+	Version        int64  `json:"version,omitempty"`
 }
@@ -0,0 +1,275 @@
+package dto
+
+import (
+	"github.com/xuri/excelize/v2"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func ImportRecordDTOFromTypes(src types.ImportRecord) ImportRecord {
+	return ImportRecord{
+		Sheet:             src.Sheet,
+		Row:               src.Row,
+		Column:            src.Column,
+		GroupName:         src.GroupName,
+		StudentLastName:   src.StudentLastName,
+		StudentFirstName:  src.StudentFirstName,
+		StudentMiddleName: src.StudentMiddleName,
+		StudentEmail:      src.StudentEmail,
+		TeacherLastName:   src.TeacherLastName,
+		TeacherFirstName:  src.TeacherFirstName,
+		TeacherMiddleName: src.TeacherMiddleName,
+		TeacherEmail:      src.TeacherEmail,
+		ExamName:          src.ExamName,
+	}
+}
+
+func ImportIssueDTOFromTypes(src types.ImportIssue) ImportIssue {
+	var cell string
+	if src.Row != 0 && src.Column != 0 {
+		cell, _ = excelize.CoordinatesToCellName(int(src.Column), int(src.Row))
+	}
+
+	return ImportIssue{
+		Sheet:   src.Sheet,
+		Row:     src.Row,
+		Column:  src.Column,
+		Cell:    cell,
+		Value:   src.Value,
+		Message: src.Message,
+	}
+}
+
+func ImportReportDTOFromTypes(src types.ImportReport) ImportReport {
+	var confirmedAt *string
+	if src.ConfirmedAt != nil {
+		formatted := src.ConfirmedAt.Format(valueobjects.DateLayout)
+		confirmedAt = &formatted
+	}
+
+	newDebts := make([]ImportRecord, len(src.NewDebts))
+	for i, record := range src.NewDebts {
+		newDebts[i] = ImportRecordDTOFromTypes(record)
+	}
+	existingDebts := make([]ImportRecord, len(src.ExistingDebts))
+	for i, record := range src.ExistingDebts {
+		existingDebts[i] = ImportRecordDTOFromTypes(record)
+	}
+	issues := make([]ImportIssue, len(src.Issues))
+	for i, issue := range src.Issues {
+		issues[i] = ImportIssueDTOFromTypes(issue)
+	}
+
+	return ImportReport{
+		Token:         src.Token,
+		CreatedBy:     src.CreatedBy,
+		ProfileID:     src.ProfileID,
+		CreatedAt:     src.CreatedAt.Format(valueobjects.DateLayout),
+		ExpiresAt:     src.ExpiresAt.Format(valueobjects.DateLayout),
+		ConfirmedAt:   confirmedAt,
+		Groups:        ImportDiff(src.Groups),
+		Students:      ImportDiff(src.Students),
+		Teachers:      ImportDiff(src.Teachers),
+		Exams:         ImportDiff(src.Exams),
+		NewDebts:      newDebts,
+		ExistingDebts: existingDebts,
+		Issues:        issues,
+	}
+}
+
+func ImportJobDTOFromTypes(src types.ImportJob) ImportJob {
+	issues := make([]ImportIssue, len(src.Issues))
+	for i, issue := range src.Issues {
+		issues[i] = ImportIssueDTOFromTypes(issue)
+	}
+	jobErrors := make([]ImportIssue, len(src.Errors))
+	for i, issue := range src.Errors {
+		jobErrors[i] = ImportIssueDTOFromTypes(issue)
+	}
+
+	var startedAt, finishedAt, rolledBackAt *string
+	if src.StartedAt != nil {
+		formatted := src.StartedAt.Format(valueobjects.DateLayout)
+		startedAt = &formatted
+	}
+	if src.FinishedAt != nil {
+		formatted := src.FinishedAt.Format(valueobjects.DateLayout)
+		finishedAt = &formatted
+	}
+	if src.RolledBackAt != nil {
+		formatted := src.RolledBackAt.Format(valueobjects.DateLayout)
+		rolledBackAt = &formatted
+	}
+
+	return ImportJob{
+		ID:           src.ID,
+		Source:       src.Source,
+		Status:       src.Status,
+		Actor:        src.Actor,
+		FileName:     src.FileName,
+		Checksum:     src.Checksum,
+		ProfileID:    src.ProfileID,
+		PreviewToken: src.PreviewToken,
+		Total:        src.Total,
+		Processed:    src.Processed,
+		Created:      src.Created,
+		Existing:     src.Existing,
+		Failed:       src.Failed,
+		Attempts:     src.Attempts,
+		Issues:       issues,
+		Errors:       jobErrors,
+		CreatedAt:    src.CreatedAt.Format(valueobjects.DateLayout),
+		UpdatedAt:    src.UpdatedAt.Format(valueobjects.DateLayout),
+		StartedAt:    startedAt,
+		FinishedAt:   finishedAt,
+		RolledBackAt: rolledBackAt,
+	}
+}
+
+func ImportRollbackDTOFromTypes(src types.ImportRollback) ImportRollback {
+	kept := make([]ImportRollbackItem, len(src.Kept))
+	for i, item := range src.Kept {
+		references := item.References
+		if references == nil {
+			references = []string{}
+		}
+		kept[i] = ImportRollbackItem{
+			Entity:     item.Entity,
+			EntityID:   item.EntityID,
+			References: references,
+			Message:    item.Message,
+		}
+	}
+
+	return ImportRollback{
+		JobID:   src.JobID,
+		Deleted: src.Deleted,
+		Kept:    kept,
+	}
+}
+
+func ImportProfileDTOFromTypes(src types.ImportProfile) ImportProfile {
+	return ImportProfile{
+		ID:            src.ID,
+		Name:          src.Name,
+		Layout:        src.Layout,
+		Sheets:        src.Sheets,
+		HeaderRow:     src.HeaderRow,
+		Separator:     src.Separator,
+		StudentFields: src.StudentFields,
+		TeacherFields: src.TeacherFields,
+		Columns:       src.Columns,
+		CreatedAt:     src.CreatedAt.Format(valueobjects.DateLayout),
+	}
+}
+
+// TypesImportProfileFromCreateImportProfileDTO defaults the header row to
+// the first one.
+func TypesImportProfileFromCreateImportProfileDTO(src CreateImportProfileDTO) types.ImportProfile {
+	headerRow := src.HeaderRow
+	if headerRow == 0 {
+		headerRow = 1
+	}
+
+	return types.ImportProfile{
+		Name:          src.Name,
+		Layout:        src.Layout,
+		Sheets:        src.Sheets,
+		HeaderRow:     headerRow,
+		Separator:     src.Separator,
+		StudentFields: src.StudentFields,
+		TeacherFields: src.TeacherFields,
+		Columns:       src.Columns,
+	}
+}
+
+func TypesImportProfileFromUpdateImportProfileDTO(src UpdateImportProfileDTO) types.ImportProfile {
+	headerRow := src.HeaderRow
+	if headerRow == 0 {
+		headerRow = 1
+	}
+
+	return types.ImportProfile{
+		ID:            src.ID,
+		Name:          src.Name,
+		Layout:        src.Layout,
+		Sheets:        src.Sheets,
+		HeaderRow:     headerRow,
+		Separator:     src.Separator,
+		StudentFields: src.StudentFields,
+		TeacherFields: src.TeacherFields,
+		Columns:       src.Columns,
+	}
+}
+
+func TypesBulkImportFromDTO(src BulkImportDTO, actor string) types.BulkImport {
+	batch := types.BulkImport{
+		Actor:    actor,
+		Groups:   make([]types.Group, len(src.Groups)),
+		Teachers: make([]types.Teacher, len(src.Teachers)),
+		Students: make([]types.Student, len(src.Students)),
+		Debts:    make([]types.Debt, len(src.Debts)),
+	}
+	for i, group := range src.Groups {
+		batch.Groups[i] = types.Group{Name: group.Name}
+	}
+	for i, teacher := range src.Teachers {
+		batch.Teachers[i] = types.Teacher{
+			LastName:   teacher.LastName,
+			FirstName:  teacher.FirstName,
+			MiddleName: teacher.MiddleName,
+			Email:      teacher.Email,
+		}
+	}
+	for i, student := range src.Students {
+		batch.Students[i] = types.Student{
+			LastName:   student.LastName,
+			FirstName:  student.FirstName,
+			MiddleName: student.MiddleName,
+			Email:      student.Email,
+			Group:      &types.Group{Name: student.Group},
+		}
+	}
+	for i, debt := range src.Debts {
+		batch.Debts[i] = types.Debt{
+			Exam:    &types.Exam{Name: debt.Exam},
+			Student: &types.Student{Email: debt.StudentEmail},
+			Teacher: &types.Teacher{Email: debt.TeacherEmail},
+		}
+	}
+
+	return batch
+}
+
+func BulkImportResultDTOFromTypes(src types.BulkImportResult) BulkImportResult {
+	return BulkImportResult{
+		JobID:    src.JobID,
+		Created:  src.Created,
+		Existing: src.Existing,
+		Failed:   src.Failed,
+		Groups:   bulkRecordResultsDTOFromTypes(src.Groups),
+		Teachers: bulkRecordResultsDTOFromTypes(src.Teachers),
+		Students: bulkRecordResultsDTOFromTypes(src.Students),
+		Debts:    bulkRecordResultsDTOFromTypes(src.Debts),
+	}
+}
+
+func bulkRecordResultsDTOFromTypes(src []types.BulkRecordResult) []BulkRecordResult {
+	result := make([]BulkRecordResult, len(src))
+	for i, record := range src {
+		var err string
+		if record.Err != nil {
+			err = record.Err.Error()
+		}
+		result[i] = BulkRecordResult{
+			Index:   i,
+			ID:      record.ID,
+			UUID:    record.UUID,
+			Created: record.Created,
+			Error:   err,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,229 @@
+package dto
+
+// ImportRecord Row and Column point at the cell of the uploaded workbook,
+// both start at 1.
+type ImportRecord struct {
+	Sheet             string `json:"sheet"`
+	Row               int64  `json:"row"`
+	Column            int64  `json:"column"`
+	GroupName         string `json:"group_name"`
+	StudentLastName   string `json:"student_last_name"`
+	StudentFirstName  string `json:"student_first_name"`
+	StudentMiddleName string `json:"student_middle_name"`
+	StudentEmail      string `json:"student_email"`
+	TeacherLastName   string `json:"teacher_last_name"`
+	TeacherFirstName  string `json:"teacher_first_name"`
+	TeacherMiddleName string `json:"teacher_middle_name"`
+	TeacherEmail      string `json:"teacher_email"`
+	ExamName          string `json:"exam_name"`
+}
+
+// ImportIssue Row or Column is 0 when the issue concerns a whole column or
+// row, Cell is empty then.
+type ImportIssue struct {
+	Sheet   string `json:"sheet"`
+	Row     int64  `json:"row"`
+	Column  int64  `json:"column"`
+	Cell    string `json:"cell,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportDiff struct {
+	New      []string `json:"new"`
+	Existing []string `json:"existing"`
+}
+
+type ImportReport struct {
+	Token         string         `json:"token"`
+	CreatedBy     string         `json:"created_by"`
+	ProfileID     *int64         `json:"profile_id"`
+	CreatedAt     string         `json:"created_at"`
+	ExpiresAt     string         `json:"expires_at"`
+	ConfirmedAt   *string        `json:"confirmed_at"`
+	Groups        ImportDiff     `json:"groups"`
+	Students      ImportDiff     `json:"students"`
+	Teachers      ImportDiff     `json:"teachers"`
+	Exams         ImportDiff     `json:"exams"`
+	NewDebts      []ImportRecord `json:"new_debts"`
+	ExistingDebts []ImportRecord `json:"existing_debts"`
+	Issues        []ImportIssue  `json:"issues"`
+}
+
+// ImportJob Issues are the cells skipped when the file was read, Errors
+// are the records that failed in the last run.
+type ImportJob struct {
+	ID           int64         `json:"id"`
+	Source       string        `json:"source"`
+	Status       string        `json:"status"`
+	Actor        string        `json:"actor"`
+	FileName     string        `json:"file_name,omitempty"`
+	Checksum     string        `json:"checksum,omitempty"`
+	ProfileID    *int64        `json:"profile_id"`
+	PreviewToken *string       `json:"preview_token,omitempty"`
+	Total        int64         `json:"total"`
+	Processed    int64         `json:"processed"`
+	Created      int64         `json:"created"`
+	Existing     int64         `json:"existing"`
+	Failed       int64         `json:"failed"`
+	Attempts     int64         `json:"attempts"`
+	Issues       []ImportIssue `json:"issues"`
+	Errors       []ImportIssue `json:"errors"`
+	CreatedAt    string        `json:"created_at"`
+	UpdatedAt    string        `json:"updated_at"`
+	StartedAt    *string       `json:"started_at"`
+	FinishedAt   *string       `json:"finished_at"`
+	RolledBackAt *string       `json:"rolled_back_at"`
+}
+
+type ImportReportDTO struct {
+	Err  error        `json:"error"`
+	Data ImportReport `json:"data"`
+}
+
+type ImportJobDTO struct {
+	Err  error     `json:"error"`
+	Data ImportJob `json:"data"`
+}
+
+// ImportRollback Kept are the entities of the job still in place, see
+// types.ImportRollback.
+type ImportRollback struct {
+	JobID   int64                `json:"job_id"`
+	Deleted int64                `json:"deleted"`
+	Kept    []ImportRollbackItem `json:"kept"`
+}
+
+type ImportRollbackItem struct {
+	Entity     string   `json:"entity"`
+	EntityID   string   `json:"entity_id"`
+	References []string `json:"references"`
+	Message    string   `json:"message"`
+}
+
+type ImportRollbackDTO struct {
+	Err  error          `json:"error"`
+	Data ImportRollback `json:"data"`
+}
+
+type GetAllImportJobsDTO struct {
+	Err  error       `json:"error"`
+	Data []ImportJob `json:"data"`
+}
+
+// ImportProfile Layout is "matrix" or "list". The matrix splits person
+// cells by Separator into StudentFields and TeacherFields (last_name,
+// first_name, middle_name, full_name, group, email), the list maps Columns
+// like "student_email", "teacher_full_name", "group" and "exam" to column
+// letters. HeaderRow starts at 1, empty Sheets means every sheet.
+type ImportProfile struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Layout        string            `json:"layout"`
+	Sheets        []string          `json:"sheets"`
+	HeaderRow     int64             `json:"header_row"`
+	Separator     string            `json:"separator"`
+	StudentFields []string          `json:"student_fields"`
+	TeacherFields []string          `json:"teacher_fields"`
+	Columns       map[string]string `json:"columns"`
+	CreatedAt     string            `json:"created_at"`
+}
+
+type CreateImportProfileDTO struct {
+	Name          string            `json:"name"`
+	Layout        string            `json:"layout"`
+	Sheets        []string          `json:"sheets"`
+	HeaderRow     int64             `json:"header_row"`
+	Separator     string            `json:"separator"`
+	StudentFields []string          `json:"student_fields"`
+	TeacherFields []string          `json:"teacher_fields"`
+	Columns       map[string]string `json:"columns"`
+}
+
+type UpdateImportProfileDTO struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Layout        string            `json:"layout"`
+	Sheets        []string          `json:"sheets"`
+	HeaderRow     int64             `json:"header_row"`
+	Separator     string            `json:"separator"`
+	StudentFields []string          `json:"student_fields"`
+	TeacherFields []string          `json:"teacher_fields"`
+	Columns       map[string]string `json:"columns"`
+}
+
+type GetImportProfileDTO struct {
+	Err  error         `json:"error"`
+	Data ImportProfile `json:"data"`
+}
+
+type GetAllImportProfilesDTO struct {
+	Err  error           `json:"error"`
+	Data []ImportProfile `json:"data"`
+}
+
+type CreateImportProfileResponseDTO struct {
+	Err  error `json:"error"`
+	Data int64 `json:"id"`
+}
+
+// BulkImportDTO is the body of /import/json. The CSV import takes a file of
+// one of the lists with the json names of the fields as the header.
+type BulkImportDTO struct {
+	Groups   []BulkGroupDTO   `json:"groups"`
+	Teachers []BulkTeacherDTO `json:"teachers"`
+	Students []BulkStudentDTO `json:"students"`
+	Debts    []BulkDebtDTO    `json:"debts"`
+}
+
+type BulkGroupDTO struct {
+	Name string `json:"name"`
+}
+
+type BulkTeacherDTO struct {
+	LastName   string `json:"last_name"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name"`
+	Email      string `json:"email"`
+}
+
+type BulkStudentDTO struct {
+	LastName   string `json:"last_name"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name"`
+	Email      string `json:"email"`
+	Group      string `json:"group"`
+}
+
+// BulkDebtDTO refers to stored people or to people of the same batch.
+type BulkDebtDTO struct {
+	Exam         string `json:"exam"`
+	StudentEmail string `json:"student_email"`
+	TeacherEmail string `json:"teacher_email"`
+}
+
+// BulkRecordResult Index is the position of the record in its list, the
+// line of a CSV record is Index + 2.
+type BulkRecordResult struct {
+	Index   int    `json:"index"`
+	ID      int64  `json:"id,omitempty"`
+	UUID    string `json:"uuid,omitempty"`
+	Created bool   `json:"created"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BulkImportResult struct {
+	JobID    int64              `json:"job_id"`
+	Created  int                `json:"created"`
+	Existing int                `json:"existing"`
+	Failed   int                `json:"failed"`
+	Groups   []BulkRecordResult `json:"groups"`
+	Teachers []BulkRecordResult `json:"teachers"`
+	Students []BulkRecordResult `json:"students"`
+	Debts    []BulkRecordResult `json:"debts"`
+}
+
+type BulkImportResultDTO struct {
+	Err  error            `json:"error"`
+	Data BulkImportResult `json:"data"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "github.com/VanLavr/Diploma-fin/internal/services/types"
+
+// PageDTO is a page of a listing, NextCursor is passed back as ?cursor= for
+// the next page and is empty on the last one.
+type PageDTO[T any] struct {
+	Err        error  `json:"error"`
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+	Total      int64  `json:"total"`
+}
+
+func PageDTOFromTypes[S, T any](src *types.Page[S], convert func(S) T) PageDTO[T] {
+	result := PageDTO[T]{
+		Data:       make([]T, 0, len(src.Items)),
+		NextCursor: src.NextCursor,
+		Total:      src.Total,
+	}
+	for _, item := range src.Items {
+		result.Data = append(result.Data, convert(item))
+	}
+	return result
+}
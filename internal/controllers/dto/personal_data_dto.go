@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+// StudentStatus is read and written by admins, LeftAt is empty for an
+// active student. DeletedAt is set for a student in the trash and is not
+// written.
+type StudentStatus struct {
+	Status       string `json:"status"`
+	LeftAt       string `json:"left_at,omitempty"`
+	AnonymizedAt string `json:"anonymized_at,omitempty"`
+	DeletedAt    string `json:"deleted_at,omitempty"`
+}
+
+type PersonalDataAudit struct {
+	ID        int64  `json:"id"`
+	Action    string `json:"action"`
+	ActorUUID string `json:"actor_uuid,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// PersonalData is the export of a student, each field is a file of the
+// zip export.
+type PersonalData struct {
+	Student        Student             `json:"student"`
+	Status         StudentStatus       `json:"status"`
+	Debts          []Debt              `json:"debts"`
+	Results        []DebtResult        `json:"results"`
+	RetakeRequests []RetakeRequest     `json:"retake_requests"`
+	RetakeBookings []RetakeBooking     `json:"retake_bookings"`
+	Audit          []PersonalDataAudit `json:"audit"`
+}
+
+type GetPersonalDataResponseDTO struct {
+	Err  error         `json:"error"`
+	Data *PersonalData `json:"data"`
+}
+
+// LeftAtFromDTO parses the left at of a status, empty is zero.
+func LeftAtFromDTO(src StudentStatus) (time.Time, error) {
+	if src.LeftAt == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(valueobjects.DateLayout, src.LeftAt)
+}
+
+func formatOptionalDate(src *time.Time) string {
+	if src == nil {
+		return ""
+	}
+	return src.Format(valueobjects.DateLayout)
+}
+
+func PersonalDataDTOFromTypes(src types.PersonalData) PersonalData {
+	result := PersonalData{
+		Student: StudentDTOFromTypes(src.Student),
+		Status: StudentStatus{
+			Status:       src.Status.Status,
+			LeftAt:       formatOptionalDate(src.Status.LeftAt),
+			AnonymizedAt: formatOptionalDate(src.Status.AnonymizedAt),
+			DeletedAt:    formatOptionalDate(src.Status.DeletedAt),
+		},
+		Debts:          make([]Debt, len(src.Debts)),
+		Results:        make([]DebtResult, len(src.Results)),
+		RetakeRequests: make([]RetakeRequest, len(src.RetakeRequests)),
+		RetakeBookings: make([]RetakeBooking, len(src.RetakeBookings)),
+		Audit:          make([]PersonalDataAudit, len(src.Audit)),
+	}
+	for i, debt := range src.Debts {
+		result.Debts[i] = DebtDTOFromTypes(debt)
+	}
+	for i, r := range src.Results {
+		result.Results[i] = DebtResultDTOFromTypes(r)
+	}
+	for i, request := range src.RetakeRequests {
+		result.RetakeRequests[i] = RetakeRequestDTOFromTypes(request)
+	}
+	for i, booking := range src.RetakeBookings {
+		result.RetakeBookings[i] = RetakeBookingDTOFromTypes(booking)
+	}
+	for i, audit := range src.Audit {
+		result.Audit[i] = PersonalDataAudit{
+			ID:        audit.ID,
+			Action:    audit.Action,
+			ActorUUID: audit.ActorUUID,
+			CreatedAt: audit.CreatedAt.Format(valueobjects.DateLayout),
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func TypesDebtResultEntryFromDTO(src DebtResultEntryDTO) types.DebtResultEntry {
+	return types.DebtResultEntry{
+		DebtID: src.DebtID,
+		Passed: src.Passed,
+		Grade:  src.Grade,
+	}
+}
+
+func DebtResultDTOFromTypes(src types.DebtResult) DebtResult {
+	return DebtResult{
+		ID:          src.ID,
+		DebtID:      src.DebtID,
+		SessionID:   src.SessionID,
+		TeacherUUID: src.TeacherUUID,
+		Passed:      src.Passed,
+		Grade:       src.Grade,
+		AttemptedAt: src.AttemptedAt.Format(valueobjects.DateLayout),
+		RecordedAt:  src.RecordedAt.Format(valueobjects.DateLayout),
+	}
+}
+
+func DebtResultReportDTOFromTypes(src types.DebtResultReport) DebtResultReport {
+	var err string
+	if src.Err != nil {
+		err = src.Err.Error()
+	}
+
+	return DebtResultReport{
+		DebtID:      src.DebtID,
+		StudentUUID: src.StudentUUID,
+		Recorded:    src.Recorded,
+		Closed:      src.Closed,
+		Error:       err,
+	}
+}
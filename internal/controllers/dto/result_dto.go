@@ -0,0 +1,47 @@
+package dto
+
+// DebtResultEntryDTO Grade is 2–5 for graded assessments, pass/fail ones take
+// Passed and no grade.
+type DebtResultEntryDTO struct {
+	DebtID int64 `json:"debt_id"`
+	Passed bool  `json:"passed"`
+	Grade  int64 `json:"grade"`
+}
+
+type SessionResultsDTO struct {
+	Results []DebtResultEntryDTO `json:"results"`
+}
+
+type DebtResult struct {
+	ID          int64  `json:"id"`
+	DebtID      int64  `json:"debt_id"`
+	SessionID   int64  `json:"session_id,omitempty"`
+	TeacherUUID string `json:"teacher_uuid"`
+	Passed      bool   `json:"passed"`
+	Grade       int64  `json:"grade,omitempty"`
+	AttemptedAt string `json:"attempted_at"`
+	RecordedAt  string `json:"recorded_at"`
+}
+
+type DebtResultReport struct {
+	DebtID      int64  `json:"debt_id"`
+	StudentUUID string `json:"student_uuid,omitempty"`
+	Recorded    bool   `json:"recorded"`
+	Closed      bool   `json:"closed"`
+	Error       string `json:"error,omitempty"`
+}
+
+type GetDebtResultDTO struct {
+	Err  error      `json:"error"`
+	Data DebtResult `json:"data"`
+}
+
+type GetAllDebtResultsDTO struct {
+	Err  error        `json:"error"`
+	Data []DebtResult `json:"data"`
+}
+
+type SessionResultsResponseDTO struct {
+	Err  error              `json:"error"`
+	Data []DebtResultReport `json:"data"`
+}
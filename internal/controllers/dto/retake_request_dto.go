@@ -0,0 +1,49 @@
+package dto
+
+type RetakeRequest struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	CreatedAt      string     `json:"created_at"`
+	UpdatedAt      string     `json:"updated_at"`
+	Debt           *Debt      `json:"debt"`
+	PreferredSlots []TimeSlot `json:"preferred_slots"`
+}
+
+// TimeSlot borders are formatted with valueobjects.DateLayout.
+type TimeSlot struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type StudentPreferences struct {
+	RequestID int64      `json:"request_id"`
+	Student   Student    `json:"student"`
+	Slots     []TimeSlot `json:"slots"`
+}
+
+type SlotSuggestion struct {
+	ExamID          int64                `json:"exam_id"`
+	Slot            *TimeSlot            `json:"slot"`
+	CoveredStudents []Student            `json:"covered_students"`
+	Preferences     []StudentPreferences `json:"preferences"`
+}
+
+type GetSlotSuggestionDTO struct {
+	Err  error          `json:"error"`
+	Data SlotSuggestion `json:"data"`
+}
+
+type GetAllRetakeRequestsDTO struct {
+	Err  error           `json:"error"`
+	Data []RetakeRequest `json:"data"`
+}
+
+type CreateRetakeRequestResponseDTO struct {
+	Err  error `json:"error"`
+	Data int64 `json:"id"`
+}
+
+type UpdateRetakeRequestStatusDTO struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func TypesRetakeSessionFromCreateDTO(src CreateRetakeSessionDTO) (*types.RetakeSession, error) {
+	date, err := time.Parse(valueobjects.DateLayout, src.Date)
+	if err != nil {
+		return nil, err
+	}
+	signupDeadline, err := time.Parse(valueobjects.DateLayout, src.SignupDeadline)
+	if err != nil {
+		return nil, err
+	}
+	// cancellation is allowed until the sign-up closes unless said otherwise
+	cancelDeadline := signupDeadline
+	if src.CancelDeadline != "" {
+		cancelDeadline, err = time.Parse(valueobjects.DateLayout, src.CancelDeadline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var room *types.Room
+	if src.RoomID != 0 {
+		room = &types.Room{ID: src.RoomID}
+	}
+
+	return &types.RetakeSession{
+		Exam:           &types.Exam{ID: src.ExamID},
+		Date:           date,
+		Address:        src.Address,
+		Room:           room,
+		Capacity:       src.Capacity,
+		SignupDeadline: signupDeadline,
+		CancelDeadline: cancelDeadline,
+	}, nil
+}
+
+func RetakeSessionDTOFromTypes(src types.RetakeSession) RetakeSession {
+	var (
+		ex      = Exam{}
+		teacher = Teacher{}
+	)
+	if src.Exam != nil {
+		ex = ExamDTOFromTypes(*src.Exam)
+	}
+	if src.Teacher != nil {
+		teacher = TeacherDTOFromTypes(*src.Teacher)
+	}
+	var room *Room
+	if src.Room != nil {
+		converted := RoomDTOFromTypes(*src.Room)
+		room = &converted
+	}
+
+	return RetakeSession{
+		ID:              src.ID,
+		Exam:            &ex,
+		Teacher:         &teacher,
+		Date:            src.Date.Format(valueobjects.DateLayout),
+		Address:         src.Address,
+		Room:            room,
+		Capacity:        src.Capacity,
+		SignupDeadline:  src.SignupDeadline.Format(valueobjects.DateLayout),
+		CancelDeadline:  src.CancelDeadline.Format(valueobjects.DateLayout),
+		BookedCount:     src.BookedCount,
+		WaitlistedCount: src.WaitlistedCount,
+	}
+}
+
+func RetakeBookingDTOFromTypes(src types.RetakeBooking) RetakeBooking {
+	var debt Debt
+	if src.Debt != nil {
+		debt = DebtDTOFromTypes(*src.Debt)
+	}
+
+	return RetakeBooking{
+		ID:        src.ID,
+		SessionID: src.SessionID,
+		Status:    src.Status,
+		Position:  src.Position,
+		Debt:      &debt,
+	}
+}
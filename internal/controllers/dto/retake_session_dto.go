@@ -0,0 +1,66 @@
+package dto
+
+// CreateRetakeSessionDTO dates are formatted with valueobjects.DateLayout.
+type CreateRetakeSessionDTO struct {
+	ExamID         int64  `json:"exam_id"`
+	Date           string `json:"date"`
+	Address        string `json:"address"`
+	RoomID         int64  `json:"room_id"`
+	Capacity       int64  `json:"capacity"`
+	SignupDeadline string `json:"signup_deadline"`
+	CancelDeadline string `json:"cancel_deadline"`
+	// publish despite conflicts with other retakes
+	Force bool `json:"force"`
+}
+
+type BookRetakeSessionDTO struct {
+	SessionID int64 `json:"session_id"`
+	DebtID    int64 `json:"debt_id"`
+}
+
+type CancelRetakeBookingDTO struct {
+	BookingID int64 `json:"booking_id"`
+}
+
+type RetakeSession struct {
+	ID              int64    `json:"id"`
+	Exam            *Exam    `json:"exam"`
+	Teacher         *Teacher `json:"teacher"`
+	Date            string   `json:"date"`
+	Address         string   `json:"address"`
+	Room            *Room    `json:"room,omitempty"`
+	Capacity        int64    `json:"capacity"`
+	SignupDeadline  string   `json:"signup_deadline"`
+	CancelDeadline  string   `json:"cancel_deadline"`
+	BookedCount     int64    `json:"booked"`
+	WaitlistedCount int64    `json:"waitlisted"`
+}
+
+type RetakeBooking struct {
+	ID        int64  `json:"id"`
+	SessionID int64  `json:"session_id"`
+	Status    string `json:"status"`
+	Position  int64  `json:"waitlist_position,omitempty"`
+	Debt      *Debt  `json:"debt"`
+}
+
+type GetAllRetakeSessionsDTO struct {
+	Err  error           `json:"error"`
+	Data []RetakeSession `json:"data"`
+}
+
+type GetAllRetakeBookingsDTO struct {
+	Err  error           `json:"error"`
+	Data []RetakeBooking `json:"data"`
+}
+
+type GetRetakeBookingDTO struct {
+	Err  error         `json:"error"`
+	Data RetakeBooking `json:"data"`
+}
+
+type CreateRetakeSessionResponseDTO struct {
+	Err       error              `json:"error"`
+	Data      int64              `json:"id"`
+	Conflicts []ScheduleConflict `json:"conflicts"`
+}
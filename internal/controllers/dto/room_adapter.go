@@ -0,0 +1,53 @@
+package dto
+
+import (
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func RoomDTOFromTypes(src types.Room) Room {
+	return Room{
+		ID:         src.ID,
+		Building:   src.Building,
+		Number:     src.Number,
+		Capacity:   src.Capacity,
+		Accessible: src.Accessible,
+	}
+}
+
+func TypesRoomFromCreateRoomDTO(src CreateRoomDTO) types.Room {
+	return types.Room{
+		Building:   src.Building,
+		Number:     src.Number,
+		Capacity:   src.Capacity,
+		Accessible: src.Accessible,
+	}
+}
+
+func TypesRoomFromUpdateRoomDTO(src UpdateRoomDTO) types.Room {
+	return types.Room{
+		ID:         src.ID,
+		Building:   src.Building,
+		Number:     src.Number,
+		Capacity:   src.Capacity,
+		Accessible: src.Accessible,
+	}
+}
+
+func ScheduleConflictDTOsFromTypes(src []types.ScheduleConflict) []ScheduleConflict {
+	result := make([]ScheduleConflict, len(src))
+	for i, conflict := range src {
+		result[i] = ScheduleConflict{
+			Kind:        conflict.Kind,
+			Date:        conflict.Date.Format(valueobjects.DateLayout),
+			ExamID:      conflict.ExamID,
+			DebtID:      conflict.DebtID,
+			SessionID:   conflict.SessionID,
+			RoomID:      conflict.RoomID,
+			TeacherUUID: conflict.TeacherUUID,
+			StudentUUID: conflict.StudentUUID,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package dto
+
+type Room struct {
+	ID         int64  `json:"id"`
+	Building   string `json:"building"`
+	Number     string `json:"number"`
+	Capacity   int64  `json:"capacity"`
+	Accessible bool   `json:"accessible"`
+}
+
+type CreateRoomDTO struct {
+	Building   string `json:"building"`
+	Number     string `json:"number"`
+	Capacity   int64  `json:"capacity"`
+	Accessible bool   `json:"accessible"`
+}
+
+type UpdateRoomDTO struct {
+	ID         int64  `json:"id"`
+	Building   string `json:"building"`
+	Number     string `json:"number"`
+	Capacity   int64  `json:"capacity"`
+	Accessible bool   `json:"accessible"`
+}
+
+type GetRoomDTO struct {
+	Err  error `json:"error"`
+	Data Room  `json:"data"`
+}
+
+type GetAllRoomsDTO struct {
+	Err  error  `json:"error"`
+	Data []Room `json:"data"`
+}
+
+type CreateRoomResponseDTO struct {
+	Err  error `json:"error"`
+	Data int64 `json:"id"`
+}
+
+// ScheduleConflict Date is formatted with valueobjects.DateLayout.
+type ScheduleConflict struct {
+	Kind        string `json:"kind"`
+	Date        string `json:"date"`
+	ExamID      int64  `json:"exam_id"`
+	DebtID      int64  `json:"debt_id,omitempty"`
+	SessionID   int64  `json:"session_id,omitempty"`
+	RoomID      int64  `json:"room_id,omitempty"`
+	TeacherUUID string `json:"teacher_uuid,omitempty"`
+	StudentUUID string `json:"student_uuid,omitempty"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/VanLavr/Diploma-fin/internal/services/types"
+
+// SearchHit is typed: Type is student, teacher, group or exam, and ID is
+// the uuid of a person or the id of a group or an exam.
+type SearchHit struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Rank     float64 `json:"rank"`
+}
+
+type SearchResponseDTO struct {
+	Err  error       `json:"error"`
+	Data []SearchHit `json:"data"`
+}
+
+func SearchHitDTOFromTypes(src types.SearchHit) SearchHit {
+	return SearchHit{
+		Type:     src.Kind,
+		ID:       src.ID,
+		Title:    src.Title,
+		Subtitle: src.Subtitle,
+		Rank:     src.Rank,
+	}
+}
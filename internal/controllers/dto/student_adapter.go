@@ -10,15 +10,18 @@ import (
 
 func ExamDTOFromTypes(src types.Exam) Exam {
 	return Exam{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:             src.ID,
+		Name:           src.Name,
+		AssessmentType: src.AssessmentType,
+		Version:        src.Version,
 	}
 }
 
 func GroupDTOFromTypes(src types.Group) Group {
 	return Group{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:      src.ID,
+		Name:    src.Name,
+		Version: src.Version,
 	}
 }
 
@@ -32,6 +35,7 @@ func DebtDTOFromTypes(src types.Debt) Debt {
 	if src.Exam != nil {
 		ex.ID = src.Exam.ID
 		ex.Name = src.Exam.Name
+		ex.AssessmentType = src.Exam.AssessmentType
 	}
 	if src.Teacher != nil {
 		teacher.UUID = src.Teacher.UUID
@@ -63,14 +67,26 @@ func DebtDTOFromTypes(src types.Debt) Debt {
 	if src.Date != nil {
 		date = src.Date.Format(time.RFC3339)
 	}
+	var closedAt string
+	if src.ClosedAt != nil {
+		closedAt = src.ClosedAt.Format(time.RFC3339)
+	}
+	var room *Room
+	if src.Room != nil {
+		converted := RoomDTOFromTypes(*src.Room)
+		room = &converted
+	}
 	return Debt{
 		ID:        src.ID,
 		Date:      date,
+		ClosedAt:  closedAt,
 		Address:   src.Address,
+		Room:      room,
 		Exam:      &ex,
 		Teacher:   &teacher,
 		Student:   &student,
 		GroupList: groups,
+		Version:   src.Version,
 	}
 }
 
@@ -111,7 +127,7 @@ func TypesDebtFromCreateDebtDTO(src CreateDebtDTO) (*types.Debt, error) {
 }
 
 func TypesExamFromCreateExamDTO(src CreateExamDTO) types.Exam {
-	return types.Exam{Name: src.Name}
+	return types.Exam{Name: src.Name, AssessmentType: src.AssessmentType}
 }
 
 func TypesGroupFromCreateGroupDTO(src CreateGroupDTO) types.Group {
@@ -148,6 +164,7 @@ func StudentDTOFromTypes(src types.Student) Student {
 		GroupName:  groupName,
 		Email:      src.Email,
 		GroupID:    groupID,
+		Version:    src.Version,
 	}
 }
 
@@ -158,6 +175,7 @@ func TeacherDTOFromTypes(src types.Teacher) Teacher {
 		LastName:   src.LastName,
 		MiddleName: src.MiddleName,
 		Email:      src.Email,
+		Version:    src.Version,
 	}
 }
 
@@ -183,8 +201,9 @@ func TypesTeacherFromUpdateTeachertDTO(src UpdateTeacherDTO) types.Teacher {
 
 func TypesExamFromUpdateExamDTO(src UpdateExamDTO) types.Exam {
 	return types.Exam{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:             src.ID,
+		Name:           src.Name,
+		AssessmentType: src.AssessmentType,
 	}
 }
 
@@ -208,3 +227,100 @@ func TypesDebtFromUpdateDebtDTO(src UpdateDebtDTO) (*types.Debt, error) {
 		Teacher: &types.Teacher{UUID: src.TeacherUUID},
 	}, nil
 }
+
+func RetakeRequestDTOFromTypes(src types.RetakeRequest) RetakeRequest {
+	var debt Debt
+	if src.Debt != nil {
+		debt = DebtDTOFromTypes(*src.Debt)
+	}
+
+	slots := make([]TimeSlot, len(src.PreferredSlots))
+	for i, slot := range src.PreferredSlots {
+		slots[i] = TimeSlotDTOFromTypes(slot)
+	}
+
+	return RetakeRequest{
+		ID:             src.ID,
+		Status:         src.Status,
+		CreatedAt:      src.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      src.UpdatedAt.Format(time.RFC3339),
+		Debt:           &debt,
+		PreferredSlots: slots,
+	}
+}
+
+func TimeSlotDTOFromTypes(src types.TimeSlot) TimeSlot {
+	return TimeSlot{
+		Start: src.Start.Format(valueobjects.DateLayout),
+		End:   src.End.Format(valueobjects.DateLayout),
+	}
+}
+
+func TypesTimeSlotsFromDTO(src []TimeSlot) ([]types.TimeSlot, error) {
+	result := make([]types.TimeSlot, len(src))
+	for i, slot := range src {
+		start, err := time.Parse(valueobjects.DateLayout, slot.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.Parse(valueobjects.DateLayout, slot.End)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = types.TimeSlot{
+			Start: start,
+			End:   end,
+		}
+	}
+
+	return result, nil
+}
+
+func SlotSuggestionDTOFromTypes(src types.SlotSuggestion) SlotSuggestion {
+	var slot *TimeSlot
+	if src.Slot != nil {
+		converted := TimeSlotDTOFromTypes(*src.Slot)
+		slot = &converted
+	}
+
+	covered := make([]Student, len(src.CoveredStudents))
+	for i, student := range src.CoveredStudents {
+		covered[i] = StudentDTOFromTypes(student)
+	}
+
+	preferences := make([]StudentPreferences, len(src.Preferences))
+	for i, preference := range src.Preferences {
+		slots := make([]TimeSlot, len(preference.Slots))
+		for j, slot := range preference.Slots {
+			slots[j] = TimeSlotDTOFromTypes(slot)
+		}
+
+		preferences[i] = StudentPreferences{
+			RequestID: preference.RequestID,
+			Student:   StudentDTOFromTypes(preference.Student),
+			Slots:     slots,
+		}
+	}
+
+	return SlotSuggestion{
+		ExamID:          src.ExamID,
+		Slot:            slot,
+		CoveredStudents: covered,
+		Preferences:     preferences,
+	}
+}
+
+func SetDateResultDTOFromTypes(src types.SetDateResult) SetDateResult {
+	result := SetDateResult{
+		DebtID:      src.DebtID,
+		StudentUUID: src.StudentUUID,
+		Scheduled:   src.Scheduled,
+		Notified:    src.Notified,
+	}
+	if src.Err != nil {
+		result.Error = src.Err.Error()
+	}
+
+	return result
+}
@@ -17,21 +17,11 @@ type GetStudentDTO struct {
 	Data Student `json:"data"`
 }
 
-type GetAllStudentsDTO struct {
-	Err  error     `json:"error"`
-	Data []Student `json:"data"`
-}
-
 type GetTeacherDTO struct {
 	Err  error   `json:"error"`
 	Data Teacher `json:"data"`
 }
 
-type GetAllTeachersDTO struct {
-	Err  error     `json:"error"`
-	Data []Teacher `json:"data"`
-}
-
 type GetExamDTO struct {
 	Err  error `json:"error"`
 	Data Exam  `json:"data"`
@@ -47,16 +37,6 @@ type GetDebtDTO struct {
 	Data Debt  `json:"data"`
 }
 
-type GetAllExamsDTO struct {
-	Err  error  `json:"error"`
-	Data []Exam `json:"data"`
-}
-
-type GetAllGroupsDTO struct {
-	Err  error   `json:"error"`
-	Data []Group `json:"data"`
-}
-
 type GetAllDebtsDTO struct {
 	Err  error  `json:"error"`
 	Data []Debt `json:"data"`
@@ -85,6 +65,7 @@ type Student struct {
 	GroupName  string `json:"group_name"`
 	Email      string `json:"email"`
 	GroupID    int64  `json:"group_id"`
+	Version    int64  `json:"version,omitempty"`
 }
 
 type Teacher struct {
@@ -93,21 +74,28 @@ type Teacher struct {
 	LastName   string `json:"last_name"`
 	MiddleName string `json:"middle_name"`
 	Email      string `json:"email"`
+	Version    int64  `json:"version,omitempty"`
 }
 
 type Debt struct {
-	ID      int64  `json:"id"`
-	Date    string `json:"date"`
-	Address string `json:"address"`
+	ID       int64  `json:"id"`
+	Date     string `json:"date"`
+	ClosedAt string `json:"closed_at,omitempty"`
+	Address  string `json:"address"`
+	Room     *Room  `json:"room,omitempty"`
 
 	Student   *Student `json:"student"`
 	Teacher   *Teacher `json:"teacher"`
 	Exam      *Exam    `json:"exam"`
 	GroupList []Group  `json:"groups"`
+	Version   int64    `json:"version,omitempty"`
 }
 
+// CreateExamDTO AssessmentType is one of exam, credit, graded_credit and
+// coursework, exam by default.
 type CreateExamDTO struct {
-	Name string `json:"name"`
+	Name           string `json:"name"`
+	AssessmentType string `json:"assessment_type"`
 }
 
 type CreateDebtDTO struct {
@@ -118,7 +106,8 @@ type CreateDebtDTO struct {
 }
 
 type RequireDebtDateAndPlace struct {
-	DebtID int64 `json:"debtID"`
+	DebtID         int64      `json:"debtID"`
+	PreferredSlots []TimeSlot `json:"preferred_slots"`
 }
 
 type CreateStudentDTO struct {
@@ -139,8 +128,9 @@ type CreateTeacherDTO struct {
 }
 
 type Group struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Version int64  `json:"version,omitempty"`
 }
 
 type UpdateTeacherPasswordDTO struct {
@@ -170,9 +160,11 @@ type UpdateTeacherDTO struct {
 	Email      string `json:"email"`
 }
 
+// UpdateExamDTO keeps the assessment type when it is omitted.
 type UpdateExamDTO struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	AssessmentType string `json:"assessment_type"`
 }
 
 type UpdateDebtDTO struct {
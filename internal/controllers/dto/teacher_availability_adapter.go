@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+const clockLayout = "15:04"
+
+// minuteOfDay parses HH:MM, "24:00" closes a window at midnight.
+func minuteOfDay(clock string) (int64, error) {
+	if clock == "24:00" {
+		return valueobjects.MinutesInDay, nil
+	}
+
+	parsed, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(parsed.Hour()*60 + parsed.Minute()), nil
+}
+
+func clockFromMinute(minute int64) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func TypesTeacherAvailabilityFromDTO(src TeacherAvailability) (*types.TeacherAvailability, error) {
+	result := &types.TeacherAvailability{
+		ID:   src.ID,
+		Kind: src.Kind,
+		Note: src.Note,
+	}
+
+	var err error
+	if src.Kind == valueobjects.AvailabilityWeekly {
+		result.Weekday = time.Weekday(src.Weekday)
+		if result.StartMinute, err = minuteOfDay(src.StartTime); err != nil {
+			return nil, err
+		}
+		if result.EndMinute, err = minuteOfDay(src.EndTime); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if result.StartsAt, err = time.Parse(valueobjects.DateLayout, src.StartsAt); err != nil {
+		return nil, err
+	}
+	if result.EndsAt, err = time.Parse(valueobjects.DateLayout, src.EndsAt); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func TeacherAvailabilityDTOFromTypes(src types.TeacherAvailability) TeacherAvailability {
+	result := TeacherAvailability{
+		ID:   src.ID,
+		Kind: src.Kind,
+		Note: src.Note,
+	}
+	if src.Kind == valueobjects.AvailabilityWeekly {
+		result.Weekday = int64(src.Weekday)
+		result.StartTime = clockFromMinute(src.StartMinute)
+		result.EndTime = clockFromMinute(src.EndMinute)
+	} else {
+		result.StartsAt = src.StartsAt.Format(valueobjects.DateLayout)
+		result.EndsAt = src.EndsAt.Format(valueobjects.DateLayout)
+	}
+
+	return result
+}
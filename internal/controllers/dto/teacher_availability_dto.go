@@ -0,0 +1,25 @@
+package dto
+
+// TeacherAvailability weekly windows use weekday (0 is Sunday) with
+// start_time and end_time as HH:MM, exceptions and blackouts use starts_at
+// and ends_at formatted with valueobjects.DateLayout.
+type TeacherAvailability struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Weekday   int64  `json:"weekday"`
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+	StartsAt  string `json:"starts_at,omitempty"`
+	EndsAt    string `json:"ends_at,omitempty"`
+	Note      string `json:"note"`
+}
+
+type GetTeacherAvailabilityDTO struct {
+	Err  error                 `json:"error"`
+	Data []TeacherAvailability `json:"data"`
+}
+
+type CreateTeacherAvailabilityResponseDTO struct {
+	Err  error `json:"error"`
+	Data int64 `json:"id"`
+}
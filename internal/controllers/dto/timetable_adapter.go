@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func TypesTimetableRequestFromDTO(src GenerateTimetableDTO) (*types.TimetableRequest, error) {
+	periodStart, err := time.Parse(valueobjects.DateLayout, src.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := time.Parse(valueobjects.DateLayout, src.Deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TimetableRequest{
+		PeriodStart:  periodStart,
+		Deadline:     deadline,
+		ExamIDs:      src.ExamIDs,
+		GroupIDs:     src.GroupIDs,
+		TeacherUUIDs: src.TeacherUUIDs,
+	}, nil
+}
+
+func TimetableDraftDTOFromTypes(src types.TimetableDraft) TimetableDraft {
+	items := make([]TimetableDraftItem, len(src.Items))
+	for i, item := range src.Items {
+		items[i] = TimetableDraftItemDTOFromTypes(item)
+	}
+
+	var appliedAt string
+	if src.AppliedAt != nil {
+		appliedAt = src.AppliedAt.Format(valueobjects.DateLayout)
+	}
+
+	return TimetableDraft{
+		ID:          src.ID,
+		Status:      src.Status,
+		PeriodStart: src.PeriodStart.Format(valueobjects.DateLayout),
+		Deadline:    src.Deadline.Format(valueobjects.DateLayout),
+		CreatedBy:   src.CreatedBy,
+		CreatedAt:   src.CreatedAt.Format(valueobjects.DateLayout),
+		AppliedAt:   appliedAt,
+		Items:       items,
+	}
+}
+
+func TimetableDraftItemDTOFromTypes(src types.TimetableDraftItem) TimetableDraftItem {
+	var (
+		room *Room
+		date string
+	)
+	if src.Room != nil {
+		converted := RoomDTOFromTypes(*src.Room)
+		room = &converted
+	}
+	if src.Date != nil {
+		date = src.Date.Format(valueobjects.DateLayout)
+	}
+
+	return TimetableDraftItem{
+		ID:     src.ID,
+		Debt:   DebtDTOFromTypes(*src.Debt),
+		Room:   room,
+		Date:   date,
+		Reason: src.Reason,
+	}
+}
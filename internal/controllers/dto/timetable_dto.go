@@ -0,0 +1,42 @@
+package dto
+
+// GenerateTimetableDTO dates are formatted with valueobjects.DateLayout,
+// empty filters select all unscheduled debts.
+type GenerateTimetableDTO struct {
+	PeriodStart  string   `json:"period_start"`
+	Deadline     string   `json:"deadline"`
+	ExamIDs      []int64  `json:"exam_ids"`
+	GroupIDs     []int64  `json:"group_ids"`
+	TeacherUUIDs []string `json:"teacher_uuids"`
+}
+
+type TimetableDraft struct {
+	ID          int64                `json:"id"`
+	Status      string               `json:"status"`
+	PeriodStart string               `json:"period_start"`
+	Deadline    string               `json:"deadline"`
+	CreatedBy   string               `json:"created_by"`
+	CreatedAt   string               `json:"created_at"`
+	AppliedAt   string               `json:"applied_at,omitempty"`
+	Items       []TimetableDraftItem `json:"items,omitempty"`
+}
+
+// TimetableDraftItem Date and Room are empty and Reason says why when the
+// debt could not be placed.
+type TimetableDraftItem struct {
+	ID     int64  `json:"id"`
+	Debt   Debt   `json:"debt"`
+	Room   *Room  `json:"room,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type GetTimetableDraftDTO struct {
+	Err  error          `json:"error"`
+	Data TimetableDraft `json:"data"`
+}
+
+type GetAllTimetableDraftsDTO struct {
+	Err  error            `json:"error"`
+	Data []TimetableDraft `json:"data"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+// TrashItem is typed like a SearchHit: Type is student, teacher, group or
+// exam, and ID is the uuid of a person or the id of a group or an exam.
+type TrashItem struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+type GetTrashResponseDTO struct {
+	Err  error       `json:"error"`
+	Data []TrashItem `json:"data"`
+}
+
+func TrashItemDTOFromTypes(src types.TrashItem) TrashItem {
+	return TrashItem{
+		Type:      src.Kind,
+		ID:        src.ID,
+		Title:     src.Title,
+		DeletedAt: src.DeletedAt.Format(valueobjects.DateLayout),
+	}
+}
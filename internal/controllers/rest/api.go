@@ -24,6 +24,16 @@ type Server struct {
 	groupHandler   *GroupHandler
 	authHandler    *AuthHandler
 	fileHandler    *FileHandler
+
+	retakeSessionHandler *RetakeSessionHandler
+	roomHandler          *RoomHandler
+	availabilityHandler  *TeacherAvailabilityHandler
+	timetableHandler     *TimetableHandler
+	resultHandler        *ResultHandler
+	documentHandler      *DocumentHandler
+	searchHandler        *SearchHandler
+	trashHandler         *TrashHandler
+	personalDataHandler  *PersonalDataHandler
 }
 
 func NewServer(
@@ -34,6 +44,15 @@ func NewServer(
 	examHandler *ExamHandler,
 	groupHandler *GroupHandler,
 	fileHandler *FileHandler,
+	retakeSessionHandler *RetakeSessionHandler,
+	roomHandler *RoomHandler,
+	availabilityHandler *TeacherAvailabilityHandler,
+	timetableHandler *TimetableHandler,
+	resultHandler *ResultHandler,
+	documentHandler *DocumentHandler,
+	searchHandler *SearchHandler,
+	trashHandler *TrashHandler,
+	personalDataHandler *PersonalDataHandler,
 ) *Server {
 	return &Server{
 		cfg:            cfg,
@@ -43,7 +62,17 @@ func NewServer(
 		groupHandler:   groupHandler,
 		authHandler:    authHandler,
 		fileHandler:    fileHandler,
-		gin:            gin.Default(),
+
+		retakeSessionHandler: retakeSessionHandler,
+		roomHandler:          roomHandler,
+		availabilityHandler:  availabilityHandler,
+		timetableHandler:     timetableHandler,
+		resultHandler:        resultHandler,
+		documentHandler:      documentHandler,
+		searchHandler:        searchHandler,
+		trashHandler:         trashHandler,
+		personalDataHandler:  personalDataHandler,
+		gin:                  gin.Default(),
 	}
 }
 
@@ -63,8 +92,9 @@ func (s *Server) Start(c context.Context) error {
 			"origin",
 			"Cache-Control",
 			"X-Request-With",
+			"If-Match",
 		},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -122,4 +152,13 @@ func (s *Server) setV1Routes(group *gin.RouterGroup) {
 	s.groupHandler.RegisterRoutes((v1))
 	s.teacherHandler.RegisterRoutes(v1)
 	s.fileHandler.RegisterRoutes(v1)
+	s.retakeSessionHandler.RegisterRoutes(v1)
+	s.roomHandler.RegisterRoutes(v1)
+	s.availabilityHandler.RegisterRoutes(v1)
+	s.timetableHandler.RegisterRoutes(v1)
+	s.resultHandler.RegisterRoutes(v1)
+	s.documentHandler.RegisterRoutes(v1)
+	s.searchHandler.RegisterRoutes(v1)
+	s.trashHandler.RegisterRoutes(v1)
+	s.personalDataHandler.RegisterRoutes(v1)
 }
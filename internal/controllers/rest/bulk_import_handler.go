@@ -0,0 +1,182 @@
+package rest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// required and optional CSV columns of every entity, the names match the
+// json fields of dto.BulkImportDTO
+var bulkCSVColumns = map[string]struct {
+	required []string
+	optional []string
+}{
+	"groups":   {required: []string{"name"}},
+	"teachers": {required: []string{"last_name", "first_name", "email"}, optional: []string{"middle_name"}},
+	"students": {required: []string{"last_name", "first_name", "email", "group"}, optional: []string{"middle_name"}},
+	"debts":    {required: []string{"exam", "student_email", "teacher_email"}},
+}
+
+// ImportJSON takes a dto.BulkImportDTO body. Groups, teachers and students
+// are created before debts, so a debt can refer to a person of the same body.
+func (fh FileHandler) ImportJSON(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var request dto.BulkImportDTO
+	if err := c.Bind(&request); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fh.bulkImport(c, request)
+}
+
+// ImportCSV takes a CSV of one entity, ?entity= is groups, teachers,
+// students or debts. The first line names the columns:
+//
+//	groups:   name
+//	teachers: last_name, first_name, middle_name (optional), email
+//	students: last_name, first_name, middle_name (optional), email, group
+//	debts:    exam, student_email, teacher_email
+//
+// The file is the "file" form field or the request body, ?delimiter= is
+// "," by default.
+func (fh FileHandler) ImportCSV(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(c.DefaultQuery("delimiter", ","))
+	if size == 0 || delimiter == utf8.RuneError {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delimiter"})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open file: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	request, err := bulkImportFromCSV(body, c.Query("entity"), delimiter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fh.bulkImport(c, *request)
+}
+
+// bulkImport answers with a result per record, the import job of the
+// batch is in the history and rolls it back like any other.
+func (fh FileHandler) bulkImport(c *gin.Context, request dto.BulkImportDTO) {
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	result, err := fh.fUsecase.BulkImport(c.Request.Context(), dto.TypesBulkImportFromDTO(request, uuid))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BulkImportResultDTO{
+		Err:  nil,
+		Data: dto.BulkImportResultDTOFromTypes(*result),
+	})
+}
+
+func bulkImportFromCSV(r io.Reader, entity string, delimiter rune) (*dto.BulkImportDTO, error) {
+	columns, ok := bulkCSVColumns[entity]
+	if !ok {
+		return nil, fmt.Errorf("%w: entity must be groups, teachers, students or debts", errors.ErrInvalidData)
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidData, err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", errors.ErrInvalidData)
+	}
+
+	header := make(map[string]int)
+	for i, name := range rows[0] {
+		// spreadsheets often save CSV with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range columns.required {
+		if _, ok := header[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", errors.ErrInvalidData, name)
+		}
+	}
+
+	request := &dto.BulkImportDTO{}
+	for _, row := range rows[1:] {
+		cell := func(name string) string {
+			if i, ok := header[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		switch entity {
+		case "groups":
+			request.Groups = append(request.Groups, dto.BulkGroupDTO{Name: cell("name")})
+		case "teachers":
+			request.Teachers = append(request.Teachers, dto.BulkTeacherDTO{
+				LastName:   cell("last_name"),
+				FirstName:  cell("first_name"),
+				MiddleName: cell("middle_name"),
+				Email:      cell("email"),
+			})
+		case "students":
+			request.Students = append(request.Students, dto.BulkStudentDTO{
+				LastName:   cell("last_name"),
+				FirstName:  cell("first_name"),
+				MiddleName: cell("middle_name"),
+				Email:      cell("email"),
+				Group:      cell("group"),
+			})
+		case "debts":
+			request.Debts = append(request.Debts, dto.BulkDebtDTO{
+				Exam:         cell("exam"),
+				StudentEmail: cell("student_email"),
+				TeacherEmail: cell("teacher_email"),
+			})
+		}
+	}
+
+	return request, nil
+}
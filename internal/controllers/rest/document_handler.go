@@ -0,0 +1,117 @@
+package rest
+
+import (
+	e "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type DocumentHandler struct {
+	documentUsecase logic.DocumentUsecase
+}
+
+func NewDocumentHandler(documentUsecase logic.DocumentUsecase) *DocumentHandler {
+	return &DocumentHandler{
+		documentUsecase: documentUsecase,
+	}
+}
+
+func (this DocumentHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/retake_session/:id/sheet", this.getRetakeSheet) // + admin,teacher
+	group.GET("/debt/:id/referral", this.getReferralSlip)       // + admin,teacher,student
+}
+
+// getRetakeSheet teachers only get sheets of their own sessions.
+func (this DocumentHandler) getRetakeSheet(c *gin.Context) {
+	role := c.Value(auth.RoleKey)
+	if role != auth.AdminRole && role != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var teacherUUID string
+	if role == auth.TeacherRole {
+		uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+			return
+		}
+		teacherUUID = uuid
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sheet, err := this.documentUsecase.RetakeSessionSheet(c.Request.Context(), int64(id), teacherUUID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	sendPDF(c, fmt.Sprintf("retake_sheet_%d.pdf", id), sheet)
+}
+
+// getReferralSlip teachers and students only get slips of their own debts.
+func (this DocumentHandler) getReferralSlip(c *gin.Context) {
+	role := c.Value(auth.RoleKey)
+	if role != auth.AdminRole && role != auth.TeacherRole && role != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var teacherUUID, studentUUID string
+	if role != auth.AdminRole {
+		uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+			return
+		}
+		if role == auth.TeacherRole {
+			teacherUUID = uuid
+		} else {
+			studentUUID = uuid
+		}
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slip, err := this.documentUsecase.ReferralSlip(c.Request.Context(), int64(id), teacherUUID, studentUUID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	sendPDF(c, fmt.Sprintf("referral_%d.pdf", id), slip)
+}
+
+func sendPDF(c *gin.Context, filename string, content []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+func documentErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrInvalidData), e.Is(err, errors.ErrInvalidFilters):
+		return http.StatusBadRequest
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
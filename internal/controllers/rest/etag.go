@@ -0,0 +1,62 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// setETag exposes the version of a student, a teacher, a group, an exam or
+// a debt, their PUT takes it back in If-Match.
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion reads the version of If-Match, * is any version and is
+// zero. It responds itself and returns false when the header is missing or
+// is not the ETag of a version.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch ifMatch {
+	case "":
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": errors.ErrVersionRequired.Error()})
+		return 0, false
+	case "*":
+		return 0, true
+	}
+
+	// a weak or a foreign tag never matches
+	tag, err := strconv.Unquote(ifMatch)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": errors.ErrVersionMismatch.Error()})
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": errors.ErrVersionMismatch.Error()})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// updateErrorStatus is the status of a failed versioned update.
+func updateErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	case e.Is(err, errors.ErrInvalidCommand):
+		return http.StatusBadRequest
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	e "errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/log"
@@ -25,17 +27,17 @@ func NewExamHandler(examUsecase logic.ExamUsecase) *ExamHandler {
 }
 
 func (this ExamHandler) RegisterRoutes(group *gin.RouterGroup) {
-	group.POST("/exam", this.CreateExam)                 // + admin
-	group.PUT("/exam", this.UpdateExam)                  // + admin
-	group.DELETE("/exam/:id", this.DeleteExam)           // + admin
-	group.GET("/exam/all/:limit/:offset", this.GetExams) // + admin
-	group.GET("/exam/:id", this.GetExam)                 // + admin
-
-	group.DELETE("/debt/:id", this.DeleteDebt)           // + admin
-	group.PUT("/debt", this.UpdateDebt)                  // + admin
-	group.POST("/debt", this.CreateDebt)                 // + admin
-	group.GET("/debt/:id", this.GetDebt)                 // + admin
-	group.GET("/debt/all/:limit/:offset", this.GetDebts) // + admin
+	group.POST("/exam", this.CreateExam)       // + admin
+	group.PUT("/exam", this.UpdateExam)        // + admin
+	group.DELETE("/exam/:id", this.DeleteExam) // + admin
+	group.GET("/exam/all", this.GetExams)      // + admin
+	group.GET("/exam/:id", this.GetExam)       // + admin
+
+	group.DELETE("/debt/:id", this.DeleteDebt) // + admin
+	group.PUT("/debt", this.UpdateDebt)        // + admin
+	group.POST("/debt", this.CreateDebt)       // + admin
+	group.GET("/debt/:id", this.GetDebt)       // + admin
+	group.GET("/debt/all", this.GetDebts)      // + admin
 }
 
 func (e ExamHandler) GetDebt(c *gin.Context) {
@@ -65,6 +67,7 @@ func (e ExamHandler) GetDebt(c *gin.Context) {
 
 	result := dto.DebtDTOFromTypes(*debt)
 
+	setETag(c, debt.Version)
 	c.JSON(http.StatusOK, dto.GetDebtDTO{
 		Err:  nil,
 		Data: result,
@@ -98,102 +101,81 @@ func (e ExamHandler) GetExam(c *gin.Context) {
 
 	result := dto.ExamDTOFromTypes(*exam)
 
+	setETag(c, exam.Version)
 	c.JSON(http.StatusOK, dto.GetExamDTO{
 		Err:  nil,
 		Data: result,
 	})
 }
 
+// GetDebts takes the filters of ExportDebts and ?sort= of id, date,
+// closed_at, exam, student, teacher and group.
 func (this ExamHandler) GetDebts(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
-	lim := c.Param("limit")
-	limit, err := strconv.Atoi(lim)
+	filters, err := debtFiltersFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	offsetStr := c.Param("offset")
-	offset, err := strconv.Atoi(offsetStr)
+	params, err := listParamsFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	debts, err := this.examUsecase.GetDebts(c.Request.Context(), int64(limit), int64(offset))
+	page, err := this.examUsecase.GetDebts(c.Request.Context(), *filters, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]dto.Debt, 0, len(debts))
-	for _, debt := range debts {
-		result = append(result, dto.DebtDTOFromTypes(debt))
-	}
-
-	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
-		Err:  nil,
-		Data: result,
-	})
+	c.JSON(http.StatusOK, dto.PageDTOFromTypes(page, dto.DebtDTOFromTypes))
 }
 
+// GetExams filters by repeated ?id=, ?name= and ?assessment_type= and sorts
+// by id, name and assessment_type.
 func (this ExamHandler) GetExams(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
-	lim := c.Param("limit")
-	limit, err := strconv.Atoi(lim)
+	filters, err := examFiltersFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	offsetStr := c.Param("offset")
-	offset, err := strconv.Atoi(offsetStr)
+	params, err := listParamsFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	exams, err := this.examUsecase.GetExams(c.Request.Context(), int64(limit), int64(offset))
+	page, err := this.examUsecase.GetExams(c.Request.Context(), *filters, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]dto.Exam, 0, len(exams))
-	for _, exam := range exams {
-		result = append(result, dto.ExamDTOFromTypes(exam))
+	c.JSON(http.StatusOK, dto.PageDTOFromTypes(page, dto.ExamDTOFromTypes))
+}
+
+func examFiltersFromQuery(c *gin.Context) (*types.ExamFilters, error) {
+	filters := &types.ExamFilters{
+		Names:           c.QueryArray("name"),
+		AssessmentTypes: c.QueryArray("assessment_type"),
 	}
 
-	c.JSON(http.StatusOK, dto.GetAllExamsDTO{
-		Err:  nil,
-		Data: result,
-	})
+	var err error
+	if filters.IDs, err = int64Query(c, "id"); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
 }
 
 func (this ExamHandler) DeleteExam(c *gin.Context) {
@@ -256,12 +238,18 @@ func (this ExamHandler) DeleteDebt(c *gin.Context) {
 	})
 }
 
+// UpdateDebt requires If-Match with the ETag of GetDebt.
 func (this ExamHandler) UpdateDebt(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var r dto.UpdateDebtDTO
 	if err := c.Bind(&r); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -280,26 +268,35 @@ func (this ExamHandler) UpdateDebt(c *gin.Context) {
 		return
 	}
 
+	debtTypes.Version = version
+
 	if err := this.examUsecase.UpdateDebt(c.Request.Context(), *debtTypes); err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if version != 0 {
+		setETag(c, version+1)
+	}
+
 	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
 		Err:  nil,
 		Data: nil,
 	})
 }
 
+// UpdateExam requires If-Match with the ETag of GetExam.
 func (this ExamHandler) UpdateExam(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var r dto.UpdateExamDTO
 	if err := c.Bind(&r); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -308,15 +305,17 @@ func (this ExamHandler) UpdateExam(c *gin.Context) {
 		})
 		return
 	}
+	exam := dto.TypesExamFromUpdateExamDTO(r)
+	exam.Version = version
 
-	if err := this.examUsecase.UpdateExam(c.Request.Context(), dto.TypesExamFromUpdateExamDTO(r)); err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+	if err := this.examUsecase.UpdateExam(c.Request.Context(), exam); err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if version != 0 {
+		setETag(c, version+1)
+	}
 	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
 		Err:  nil,
 		Data: nil,
@@ -339,7 +338,12 @@ func (this ExamHandler) CreateExam(c *gin.Context) {
 	}
 
 	id, err := this.examUsecase.CreateExam(c.Request.Context(), dto.TypesExamFromCreateExamDTO(r))
-	if err != nil {
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
@@ -8,6 +8,7 @@ import (
 
 	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/log"
@@ -24,11 +25,11 @@ func NewGroupHandler(groupUsecase logic.GroupUsecase) *GroupHandler {
 }
 
 func (this GroupHandler) RegisterRoutes(group *gin.RouterGroup) {
-	group.POST("/group", this.CreateGroup)                 // + admin
-	group.PUT("/group", this.UpdateGroup)                  // + admin
-	group.DELETE("/group/:id", this.DeleteGroup)           // + admin
-	group.GET("/group/all/:limit/:offset", this.GetGroups) // + admin
-	group.GET("/group/:id", this.GetGroup)                 // + admin
+	group.POST("/group", this.CreateGroup)       // + admin
+	group.PUT("/group", this.UpdateGroup)        // + admin
+	group.DELETE("/group/:id", this.DeleteGroup) // + admin
+	group.GET("/group/all", this.GetGroups)      // + admin
+	group.GET("/group/:id", this.GetGroup)       // + admin
 }
 
 func (g GroupHandler) CreateGroup(c *gin.Context) {
@@ -60,12 +61,19 @@ func (g GroupHandler) CreateGroup(c *gin.Context) {
 		Data: int(id),
 	})
 }
+
+// UpdateGroup requires If-Match with the ETag of GetGroup.
 func (g GroupHandler) UpdateGroup(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var r dto.UpdateGroupDTO
 	if err := c.Bind(&r); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -74,15 +82,17 @@ func (g GroupHandler) UpdateGroup(c *gin.Context) {
 		})
 		return
 	}
+	group := dto.TypesGroupFromUpdateGroupDTO(r)
+	group.Version = version
 
-	if err := g.groupUsecase.UpdateGroup(c.Request.Context(), dto.TypesGroupFromUpdateGroupDTO(r)); err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+	if err := g.groupUsecase.UpdateGroup(c.Request.Context(), group); err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if version != 0 {
+		setETag(c, version+1)
+	}
 	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
 		Err:  nil,
 		Data: nil,
@@ -117,50 +127,45 @@ func (g GroupHandler) DeleteGroup(c *gin.Context) {
 		Data: nil,
 	})
 }
+
+// GetGroups filters by repeated ?id= and ?name= and sorts by id and name.
 func (g GroupHandler) GetGroups(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
-	lim := c.Param("limit")
-	limit, err := strconv.Atoi(lim)
+	filters, err := groupFiltersFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	offsetStr := c.Param("offset")
-	offset, err := strconv.Atoi(offsetStr)
+	params, err := listParamsFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	groups, err := g.groupUsecase.GetGroups(c.Request.Context(), int64(limit), int64(offset))
+	page, err := g.groupUsecase.GetGroups(c.Request.Context(), *filters, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]dto.Group, 0, len(groups))
-	for _, group := range groups {
-		result = append(result, dto.GroupDTOFromTypes(group))
+	c.JSON(http.StatusOK, dto.PageDTOFromTypes(page, dto.GroupDTOFromTypes))
+}
+
+func groupFiltersFromQuery(c *gin.Context) (*types.GroupFilters, error) {
+	filters := &types.GroupFilters{
+		Names: c.QueryArray("name"),
 	}
 
-	c.JSON(http.StatusOK, dto.GetAllGroupsDTO{
-		Err:  nil,
-		Data: result,
-	})
+	var err error
+	if filters.IDs, err = int64Query(c, "id"); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
 }
 func (g GroupHandler) GetGroup(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
@@ -189,6 +194,7 @@ func (g GroupHandler) GetGroup(c *gin.Context) {
 
 	result := dto.GroupDTOFromTypes(*group)
 
+	setETag(c, group.Version)
 	c.JSON(http.StatusOK, dto.GetGroupDTO{
 		Err:  nil,
 		Data: result,
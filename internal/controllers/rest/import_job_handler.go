@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+// GetImportJobs is the import history, the newest jobs first. It takes
+// repeated ?status= and single ?limit= and ?offset=.
+func (fh FileHandler) GetImportJobs(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	filters := types.ImportJobFilters{Statuses: c.QueryArray("status")}
+	var err error
+	if filters.Limit, err = strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filters.Offset, err = strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jobs, err := fh.fUsecase.GetImportJobs(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]dto.ImportJob, len(jobs))
+	for i, job := range jobs {
+		result[i] = dto.ImportJobDTOFromTypes(job)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllImportJobsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+// GetImportJob is polled for the progress of an import.
+func (fh FileHandler) GetImportJob(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := fh.fUsecase.GetImportJob(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ImportJobDTO{
+		Err:  nil,
+		Data: dto.ImportJobDTOFromTypes(*job),
+	})
+}
+
+// RerunImportJob queues a finished job again. Debts imported by the earlier
+// run are counted as existing, so a rerun only creates what is missing.
+func (fh FileHandler) RerunImportJob(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := fh.fUsecase.RerunImportJob(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.ImportJobDTO{
+		Err:  nil,
+		Data: dto.ImportJobDTOFromTypes(*job),
+	})
+}
+
+// RollbackImportJob deletes what the job created and nothing references
+// since. Kept entities stay with the job, a later rollback tries them again.
+func (fh FileHandler) RollbackImportJob(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := fh.fUsecase.RollbackImportJob(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ImportRollbackDTO{
+		Err:  nil,
+		Data: dto.ImportRollbackDTOFromTypes(*report),
+	})
+}
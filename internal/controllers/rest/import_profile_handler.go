@@ -0,0 +1,191 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+func (fh FileHandler) CreateImportProfile(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var request dto.CreateImportProfileDTO
+	if err := c.Bind(&request); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	id, err := fh.fUsecase.CreateImportProfile(c.Request.Context(), dto.TypesImportProfileFromCreateImportProfileDTO(request))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateImportProfileResponseDTO{
+		Err:  nil,
+		Data: id,
+	})
+}
+
+func (fh FileHandler) UpdateImportProfile(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var request dto.UpdateImportProfileDTO
+	if err := c.Bind(&request); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err := fh.fUsecase.UpdateImportProfile(c.Request.Context(), dto.TypesImportProfileFromUpdateImportProfileDTO(request))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllImportProfilesDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (fh FileHandler) DeleteImportProfile(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if err := fh.fUsecase.DeleteImportProfile(c.Request.Context(), int64(id)); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllImportProfilesDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (fh FileHandler) GetImportProfiles(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Param("limit"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	offset, err := strconv.Atoi(c.Param("offset"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	profiles, err := fh.fUsecase.GetImportProfiles(c.Request.Context(), int64(limit), int64(offset))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	result := make([]dto.ImportProfile, len(profiles))
+	for i, profile := range profiles {
+		result[i] = dto.ImportProfileDTOFromTypes(profile)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllImportProfilesDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (fh FileHandler) GetImportProfile(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	profile, err := fh.fUsecase.GetImportProfile(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetImportProfileDTO{
+		Err:  nil,
+		Data: dto.ImportProfileDTOFromTypes(*profile),
+	})
+}
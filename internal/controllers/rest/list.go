@@ -0,0 +1,39 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// listParamsFromQuery reads ?sort=name,-date&cursor=&limit= of the list
+// endpoints.
+func listParamsFromQuery(c *gin.Context) (types.ListParams, error) {
+	params := types.ListParams{
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+
+	var err error
+	if params.Limit, err = strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+func listErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrInvalidFilters), e.Is(err, errors.ErrInvalidData):
+		return http.StatusBadRequest
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
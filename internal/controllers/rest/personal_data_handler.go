@@ -0,0 +1,167 @@
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	e "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type PersonalDataHandler struct {
+	personalDataUsecase logic.PersonalDataUsecase
+}
+
+func NewPersonalDataHandler(personalDataUsecase logic.PersonalDataUsecase) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		personalDataUsecase: personalDataUsecase,
+	}
+}
+
+func (this PersonalDataHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.PUT("/student/:uuid/status", this.SetStudentStatus)            // + admin
+	group.GET("/student/:uuid/personal_data", this.ExportPersonalData)   // + admin
+	group.DELETE("/student/:uuid/personal_data", this.ErasePersonalData) // + admin
+}
+
+// SetStudentStatus accepts {"status": "graduated", "left_at": "2024-06-30
+// 00:00:00"}, an active student goes without left_at.
+func (this PersonalDataHandler) SetStudentStatus(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var r dto.StudentStatus
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	leftAt, err := dto.LeftAtFromDTO(r)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = this.personalDataUsecase.SetStudentStatus(c.Request.Context(), c.Param("uuid"), r.Status, leftAt)
+	if err != nil {
+		c.JSON(personalDataErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+// ExportPersonalData accepts ?format=json|zip, json is the default. The
+// zip has a json file per section of the export.
+func (this PersonalDataHandler) ExportPersonalData(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", valueobjects.PersonalDataJSONFormat)
+	if format != valueobjects.PersonalDataJSONFormat && format != valueobjects.PersonalDataZIPFormat {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errors.ErrInvalidFilters.Error()})
+		return
+	}
+
+	uuid := c.Param("uuid")
+	data, err := this.personalDataUsecase.ExportPersonalData(c.Request.Context(), uuid, c.Value(auth.EntityUUIDKey).(string))
+	if err != nil {
+		c.JSON(personalDataErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := dto.PersonalDataDTOFromTypes(*data)
+	if format == valueobjects.PersonalDataJSONFormat {
+		c.JSON(http.StatusOK, dto.GetPersonalDataResponseDTO{Data: &result})
+		return
+	}
+
+	content, err := personalDataArchive(result)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "personal_data_"+uuid+".zip"))
+	c.Data(http.StatusOK, "application/zip", content)
+}
+
+// ErasePersonalData anonymizes the student, its debts and results are kept.
+func (this PersonalDataHandler) ErasePersonalData(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	err := this.personalDataUsecase.ErasePersonalData(c.Request.Context(), c.Param("uuid"), c.Value(auth.EntityUUIDKey).(string))
+	if err != nil {
+		c.JSON(personalDataErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func personalDataArchive(data dto.PersonalData) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name    string
+		section any
+	}{
+		{"student.json", data.Student},
+		{"status.json", data.Status},
+		{"debts.json", data.Debts},
+		{"results.json", data.Results},
+		{"retake_requests.json", data.RetakeRequests},
+		{"retake_bookings.json", data.RetakeBookings},
+		{"audit.json", data.Audit},
+	} {
+		f, err := w.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.section); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func personalDataErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrInvalidCommand):
+		return http.StatusBadRequest
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type ResultHandler struct {
+	resultUsecase logic.ResultUsecase
+}
+
+func NewResultHandler(resultUsecase logic.ResultUsecase) *ResultHandler {
+	return &ResultHandler{
+		resultUsecase: resultUsecase,
+	}
+}
+
+func (this ResultHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.POST("/debt/result", this.recordResult)                        // + teacher
+	group.POST("/retake_session/:id/results", this.recordSessionResults) // + teacher
+	group.GET("/debt/:id/results", this.getDebtResults)                  // + admin,teacher
+	group.GET("/student/results", this.getStudentResults)                // + student
+}
+
+func (this ResultHandler) recordResult(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.DebtResultEntryDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	result, err := this.resultUsecase.RecordResult(c.Request.Context(), uuid, dto.TypesDebtResultEntryFromDTO(r))
+	if err != nil {
+		c.JSON(resultErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetDebtResultDTO{
+		Err:  nil,
+		Data: dto.DebtResultDTOFromTypes(*result),
+	})
+}
+
+// recordSessionResults reports every entry separately, a failed entry does
+// not fail the request.
+func (this ResultHandler) recordSessionResults(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var r dto.SessionResultsDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	entries := make([]types.DebtResultEntry, len(r.Results))
+	for i, entry := range r.Results {
+		entries[i] = dto.TypesDebtResultEntryFromDTO(entry)
+	}
+
+	reports, err := this.resultUsecase.RecordSessionResults(c.Request.Context(), uuid, int64(id), entries)
+	if err != nil {
+		c.JSON(resultErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]dto.DebtResultReport, len(reports))
+	for i, report := range reports {
+		result[i] = dto.DebtResultReportDTOFromTypes(report)
+	}
+
+	c.JSON(http.StatusOK, dto.SessionResultsResponseDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this ResultHandler) getDebtResults(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole && c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := this.resultUsecase.GetDebtResults(c.Request.Context(), int64(id))
+	if err != nil {
+		c.JSON(resultErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllDebtResultsDTO{
+		Err:  nil,
+		Data: debtResultDTOs(results),
+	})
+}
+
+func (this ResultHandler) getStudentResults(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	results, err := this.resultUsecase.GetStudentResults(c.Request.Context(), uuid)
+	if err != nil {
+		c.JSON(resultErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllDebtResultsDTO{
+		Err:  nil,
+		Data: debtResultDTOs(results),
+	})
+}
+
+func debtResultDTOs(src []types.DebtResult) []dto.DebtResult {
+	result := make([]dto.DebtResult, len(src))
+	for i, item := range src {
+		result[i] = dto.DebtResultDTOFromTypes(item)
+	}
+
+	return result
+}
+
+func resultErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData), e.Is(err, errors.ErrInvalidFilters):
+		return http.StatusBadRequest
+	case e.Is(err, errors.ErrUserDoesNotHaveRights):
+		return http.StatusForbidden
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	case e.Is(err, errors.ErrResultAlreadyRecorded), e.Is(err, errors.ErrDebtClosed):
+		return http.StatusConflict
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
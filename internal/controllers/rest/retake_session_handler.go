@@ -0,0 +1,311 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type RetakeSessionHandler struct {
+	retakeSessionUsecase logic.RetakeSessionUsecase
+}
+
+func NewRetakeSessionHandler(retakeSessionUsecase logic.RetakeSessionUsecase) *RetakeSessionHandler {
+	return &RetakeSessionHandler{
+		retakeSessionUsecase: retakeSessionUsecase,
+	}
+}
+
+func (this RetakeSessionHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.POST("/retake_session", this.createSession)                  // + teacher
+	group.GET("/teacher/retake_sessions", this.getTeacherSessions)     // + teacher
+	group.GET("/retake_session/:id/bookings", this.getSessionBookings) // + teacher
+	group.GET("/student/retake_sessions", this.getAvailableSessions)   // + student
+	group.GET("/student/retake_bookings", this.getStudentBookings)     // + student
+	group.POST("/retake_session/book", this.book)                      // + student
+	group.POST("/retake_session/cancel", this.cancelBooking)           // + student
+}
+
+// retakeSessionErrorStatus maps usecase errors to http status codes.
+func retakeSessionErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErrInvalidData):
+		return http.StatusBadRequest
+	case e.Is(err, errors.ErrUserDoesNotHaveRights):
+		return http.StatusForbidden
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	case e.Is(err, errors.ErrRetakeAlreadyBooked), e.Is(err, errors.ErrScheduleConflict):
+		return http.StatusConflict
+	case e.Is(err, errors.ErrRetakeSignupClosed), e.Is(err, errors.ErrRetakeCancellationClosed):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (this RetakeSessionHandler) createSession(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.CreateRetakeSessionDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	session, err := dto.TypesRetakeSessionFromCreateDTO(r)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, conflicts, err := this.retakeSessionUsecase.CreateSession(c.Request.Context(), uuid, *session, r.Force)
+	if e.Is(err, errors.ErrScheduleConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": dto.ScheduleConflictDTOsFromTypes(conflicts),
+		})
+		return
+	}
+	if err != nil {
+		status := retakeSessionErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateRetakeSessionResponseDTO{
+		Err:       nil,
+		Data:      id,
+		Conflicts: dto.ScheduleConflictDTOsFromTypes(conflicts),
+	})
+}
+
+func (this RetakeSessionHandler) getTeacherSessions(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	sessions, err := this.retakeSessionUsecase.GetTeacherSessions(c.Request.Context(), uuid)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, dto.GetAllRetakeSessionsDTO{
+			Err:  err,
+			Data: nil,
+		})
+		return
+	}
+
+	result := make([]dto.RetakeSession, len(sessions))
+	for i, session := range sessions {
+		result[i] = dto.RetakeSessionDTOFromTypes(session)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeSessionsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this RetakeSessionHandler) getSessionBookings(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	bookings, err := this.retakeSessionUsecase.GetSessionBookings(c.Request.Context(), uuid, int64(sessionID))
+	if err != nil {
+		status := retakeSessionErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]dto.RetakeBooking, len(bookings))
+	for i, booking := range bookings {
+		result[i] = dto.RetakeBookingDTOFromTypes(booking)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeBookingsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this RetakeSessionHandler) getAvailableSessions(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	sessions, err := this.retakeSessionUsecase.GetAvailableSessions(c.Request.Context(), uuid)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, dto.GetAllRetakeSessionsDTO{
+			Err:  err,
+			Data: nil,
+		})
+		return
+	}
+
+	result := make([]dto.RetakeSession, len(sessions))
+	for i, session := range sessions {
+		result[i] = dto.RetakeSessionDTOFromTypes(session)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeSessionsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this RetakeSessionHandler) getStudentBookings(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	bookings, err := this.retakeSessionUsecase.GetStudentBookings(c.Request.Context(), uuid)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, dto.GetAllRetakeBookingsDTO{
+			Err:  err,
+			Data: nil,
+		})
+		return
+	}
+
+	result := make([]dto.RetakeBooking, len(bookings))
+	for i, booking := range bookings {
+		result[i] = dto.RetakeBookingDTOFromTypes(booking)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeBookingsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this RetakeSessionHandler) book(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.BookRetakeSessionDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	booking, err := this.retakeSessionUsecase.Book(c.Request.Context(), uuid, r.SessionID, r.DebtID)
+	if err != nil {
+		status := retakeSessionErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetRetakeBookingDTO{
+		Err:  nil,
+		Data: dto.RetakeBookingDTOFromTypes(*booking),
+	})
+}
+
+func (this RetakeSessionHandler) cancelBooking(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.StudentRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.CancelRetakeBookingDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if err := this.retakeSessionUsecase.CancelBooking(c.Request.Context(), uuid, r.BookingID); err != nil {
+		status := retakeSessionErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeBookingsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
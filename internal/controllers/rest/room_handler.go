@@ -0,0 +1,233 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type RoomHandler struct {
+	roomUsecase logic.RoomUsecase
+}
+
+func NewRoomHandler(roomUsecase logic.RoomUsecase) *RoomHandler {
+	return &RoomHandler{
+		roomUsecase: roomUsecase,
+	}
+}
+
+func (this RoomHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.POST("/room", this.CreateRoom)                 // + admin
+	group.PUT("/room", this.UpdateRoom)                  // + admin
+	group.DELETE("/room/:id", this.DeleteRoom)           // + admin
+	group.GET("/room/all/:limit/:offset", this.GetRooms) // + admin,teacher
+	group.GET("/room/:id", this.GetRoom)                 // + admin,teacher
+}
+
+func (r RoomHandler) CreateRoom(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var request dto.CreateRoomDTO
+	if err := c.Bind(&request); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	id, err := r.roomUsecase.CreateRoom(c.Request.Context(), dto.TypesRoomFromCreateRoomDTO(request))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateRoomResponseDTO{
+		Err:  nil,
+		Data: id,
+	})
+}
+
+func (r RoomHandler) UpdateRoom(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var request dto.UpdateRoomDTO
+	if err := c.Bind(&request); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err := r.roomUsecase.UpdateRoom(c.Request.Context(), dto.TypesRoomFromUpdateRoomDTO(request))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRoomsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (r RoomHandler) DeleteRoom(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = r.roomUsecase.DeleteRoom(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrRoomInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRoomsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+// GetRooms accepts optional ?min_capacity=30&accessible=true filters.
+func (r RoomHandler) GetRooms(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole && c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Param("limit"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	offset, err := strconv.Atoi(c.Param("offset"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	minCapacity, err := strconv.Atoi(c.DefaultQuery("min_capacity", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	accessible, err := strconv.ParseBool(c.DefaultQuery("accessible", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rooms, err := r.roomUsecase.GetRooms(c.Request.Context(), types.RoomFilters{
+		MinCapacity:    int64(minCapacity),
+		OnlyAccessible: accessible,
+		Limit:          int64(limit),
+		Offset:         int64(offset),
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	result := make([]dto.Room, len(rooms))
+	for i, room := range rooms {
+		result[i] = dto.RoomDTOFromTypes(room)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRoomsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (r RoomHandler) GetRoom(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole && c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	room, err := r.roomUsecase.GetRoom(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetRoomDTO{
+		Err:  nil,
+		Data: dto.RoomDTOFromTypes(*room),
+	})
+}
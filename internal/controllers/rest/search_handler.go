@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+type SearchHandler struct {
+	searchUsecase logic.SearchUsecase
+}
+
+func NewSearchHandler(searchUsecase logic.SearchUsecase) *SearchHandler {
+	return &SearchHandler{
+		searchUsecase: searchUsecase,
+	}
+}
+
+func (this SearchHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/search", this.Search) // + admin
+}
+
+// Search accepts ?q=лавр&type=student,teacher&limit=10, the hits are ordered
+// by rank.
+func (this SearchHandler) Search(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	request := types.SearchRequest{Query: c.Query("q")}
+	if kinds := c.Query("type"); kinds != "" {
+		request.Kinds = strings.Split(kinds, ",")
+	}
+	var err error
+	if request.Limit, err = strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hits, err := this.searchUsecase.Search(c.Request.Context(), request)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := dto.SearchResponseDTO{Data: make([]dto.SearchHit, len(hits))}
+	for i, hit := range hits {
+		result.Data[i] = dto.SearchHitDTOFromTypes(hit)
+	}
+	c.JSON(http.StatusOK, result)
+}
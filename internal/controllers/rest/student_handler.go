@@ -1,14 +1,14 @@
 package rest
 
 import (
-	"fmt"
+	e "errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/hasher"
@@ -26,15 +26,15 @@ func NewStudentHandler(studentUsecase logic.StudentUsecase) *StudentHandler {
 }
 
 func (this StudentHandler) RegisterRoutes(group *gin.RouterGroup) {
-	group.GET("/student/info", this.GetStudentInfo)            // + student | added
-	group.GET("/student/all_debts", this.getAllDebts)          // + student | modified
-	group.POST("/notification", this.sendNotification)         // + student | modified
-	group.POST("/student", this.CreateStudent)                 // + admin
-	group.PUT("/student", this.UpdateStudent)                  // + admin,student
-	group.DELETE("/student/:uuid", this.DeleteStduent)         // + admin
-	group.GET("/student/all/:limit/:offset", this.GetStudents) // + admin
-	group.GET("/student/:uuid", this.GetStudent)               // + admin,student
-	group.PUT("/student/pass", this.UpdatePassword)            // + student
+	group.GET("/student/info", this.GetStudentInfo)    // + student | added
+	group.GET("/student/all_debts", this.getAllDebts)  // + student | modified
+	group.POST("/notification", this.sendNotification) // + student | modified
+	group.POST("/student", this.CreateStudent)         // + admin
+	group.PUT("/student", this.UpdateStudent)          // + admin,student
+	group.DELETE("/student/:uuid", this.DeleteStduent) // + admin
+	group.GET("/student/all", this.GetStudents)        // + admin
+	group.GET("/student/:uuid", this.GetStudent)       // + admin,student
+	group.PUT("/student/pass", this.UpdatePassword)    // + student
 }
 
 func (s StudentHandler) GetStudentInfo(c *gin.Context) {
@@ -135,56 +135,55 @@ func (s StudentHandler) GetStudent(c *gin.Context) {
 
 	result := dto.StudentDTOFromTypes(*student)
 
+	setETag(c, student.Version)
 	c.JSON(http.StatusOK, dto.GetStudentDTO{
 		Err:  nil,
 		Data: result,
 	})
 }
 
+// GetStudents filters by repeated ?uuid=, ?email=, ?group_id= and
+// ?last_name= and sorts by uuid, last_name, first_name, middle_name, email
+// and group.
 func (this StudentHandler) GetStudents(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
-	lim := c.Param("limit")
-	limit, err := strconv.Atoi(lim)
+	filters, err := studentFiltersFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	offsetStr := c.Param("offset")
-	offset, err := strconv.Atoi(offsetStr)
+	params, err := listParamsFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	students, err := this.studentUsecase.GetStudents(c.Request.Context(), int64(limit), int64(offset))
+	page, err := this.studentUsecase.GetStudents(c.Request.Context(), *filters, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]dto.Student, 0, len(students))
-	for _, student := range students {
-		result = append(result, dto.StudentDTOFromTypes(student))
+	c.JSON(http.StatusOK, dto.PageDTOFromTypes(page, dto.StudentDTOFromTypes))
+}
+
+func studentFiltersFromQuery(c *gin.Context) (*types.StudentFilters, error) {
+	filters := &types.StudentFilters{
+		UUIDs:     c.QueryArray("uuid"),
+		Emails:    c.QueryArray("email"),
+		LastNames: c.QueryArray("last_name"),
 	}
 
-	c.JSON(http.StatusOK, dto.GetAllStudentsDTO{
-		Err:  nil,
-		Data: result,
-	})
+	var err error
+	if filters.GroupIDs, err = int64Query(c, "group_id"); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
 }
 
 func (this StudentHandler) DeleteStduent(c *gin.Context) {
@@ -207,12 +206,18 @@ func (this StudentHandler) DeleteStduent(c *gin.Context) {
 	})
 }
 
+// UpdateStudent requires If-Match with the ETag of GetStudent.
 func (this StudentHandler) UpdateStudent(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole && c.Value(auth.RoleKey) != auth.StudentRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var r dto.UpdateStudentDTO
 	if err := c.Bind(&r); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -221,15 +226,17 @@ func (this StudentHandler) UpdateStudent(c *gin.Context) {
 		})
 		return
 	}
+	student := dto.TypeStudentFromUpdateStudentDTO(r)
+	student.Version = version
 
-	if err := this.studentUsecase.UpdateStudent(c.Request.Context(), dto.TypeStudentFromUpdateStudentDTO(r)); err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+	if err := this.studentUsecase.UpdateStudent(c.Request.Context(), student); err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if version != 0 {
+		setETag(c, version+1)
+	}
 	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
 		Err:  nil,
 		Data: nil,
@@ -286,22 +293,42 @@ func (this StudentHandler) sendNotification(c *gin.Context) {
 		})
 		return
 	}
-	fmt.Println("2")
 
-	switch err := this.studentUsecase.SendNotification(c.Request.Context(), uuid, r.DebtID); err {
-	case nil:
+	slots, err := dto.TypesTimeSlotsFromDTO(r.PreferredSlots)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	id, err := this.studentUsecase.RequestRetake(c.Request.Context(), uuid, r.DebtID, slots)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrRetakeRequestAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": errors.ErrRetakeRequestAlreadyExists.Error()})
+		return
+	case e.Is(err, errors.ErrRetakeRequestCooldown):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": errors.ErrRetakeRequestCooldown.Error()})
+		return
+	case e.Is(err, errors.ErrUserDoesNotHaveRights):
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	case e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	default:
-		fmt.Println("3")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
 		})
 		return
 	}
 
-	fmt.Println("4")
-	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
+	c.JSON(http.StatusOK, dto.CreateRetakeRequestResponseDTO{
 		Err:  nil,
-		Data: nil,
+		Data: id,
 	})
 }
 
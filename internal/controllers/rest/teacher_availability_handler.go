@@ -0,0 +1,199 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type TeacherAvailabilityHandler struct {
+	availabilityUsecase logic.TeacherAvailabilityUsecase
+}
+
+func NewTeacherAvailabilityHandler(availabilityUsecase logic.TeacherAvailabilityUsecase) *TeacherAvailabilityHandler {
+	return &TeacherAvailabilityHandler{
+		availabilityUsecase: availabilityUsecase,
+	}
+}
+
+func (this TeacherAvailabilityHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/teacher/availability", this.getAvailability)           // + teacher
+	group.POST("/teacher/availability", this.createAvailability)       // + teacher
+	group.PUT("/teacher/availability", this.updateAvailability)        // + teacher
+	group.DELETE("/teacher/availability/:id", this.deleteAvailability) // + teacher
+}
+
+func (this TeacherAvailabilityHandler) getAvailability(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	entries, err := this.availabilityUsecase.GetAvailability(c.Request.Context(), uuid)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, dto.GetTeacherAvailabilityDTO{
+			Err:  err,
+			Data: nil,
+		})
+		return
+	}
+
+	result := make([]dto.TeacherAvailability, len(entries))
+	for i, entry := range entries {
+		result[i] = dto.TeacherAvailabilityDTOFromTypes(entry)
+	}
+
+	c.JSON(http.StatusOK, dto.GetTeacherAvailabilityDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this TeacherAvailabilityHandler) createAvailability(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.TeacherAvailability
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	entry, err := dto.TypesTeacherAvailabilityFromDTO(r)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := this.availabilityUsecase.CreateAvailability(c.Request.Context(), uuid, *entry)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateTeacherAvailabilityResponseDTO{
+		Err:  nil,
+		Data: id,
+	})
+}
+
+func (this TeacherAvailabilityHandler) updateAvailability(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.TeacherAvailability
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	entry, err := dto.TypesTeacherAvailabilityFromDTO(r)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = this.availabilityUsecase.UpdateAvailability(c.Request.Context(), uuid, *entry)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetTeacherAvailabilityDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (this TeacherAvailabilityHandler) deleteAvailability(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = this.availabilityUsecase.DeleteAvailability(c.Request.Context(), uuid, int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetTeacherAvailabilityDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
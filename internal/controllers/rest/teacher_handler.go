@@ -1,14 +1,16 @@
 package rest
 
 import (
-	"fmt"
+	e "errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/hasher"
@@ -28,15 +30,19 @@ func NewTeacherHandler(teacherUsecase logic.TeacherUsecase, studentUsecase logic
 }
 
 func (this TeacherHandler) RegisterRoutes(group *gin.RouterGroup) {
-	group.GET("/teacher/info", this.getTeacherInfo)            // + teacher | added
-	group.GET("/teacher/all_debts", this.getAllDebts)          // + teacher | modified
-	group.POST("/set_date", this.setDate)                      // + teacher | modified
-	group.POST("/teacher", this.CreateTeacher)                 // + admin
-	group.PUT("/teacher", this.UpdateTeacher)                  // + admin,teacher
-	group.DELETE("/teacher/:uuid", this.DeleteTeacher)         // + admin
-	group.GET("/teacher/all/:limit/:offset", this.GetTeachers) // + admin
-	group.GET("/teacher/:uuid", this.GetTeacher)               // + admin,teacher
-	group.PUT("/teacher/pass", this.UpdateTeacherPassword)     // + teacher
+	group.GET("/teacher/info", this.getTeacherInfo)        // + teacher | added
+	group.GET("/teacher/all_debts", this.getAllDebts)      // + teacher | modified
+	group.POST("/set_date", this.setDate)                  // + teacher | modified
+	group.POST("/teacher", this.CreateTeacher)             // + admin
+	group.PUT("/teacher", this.UpdateTeacher)              // + admin,teacher
+	group.DELETE("/teacher/:uuid", this.DeleteTeacher)     // + admin
+	group.GET("/teacher/all", this.GetTeachers)            // + admin
+	group.GET("/teacher/:uuid", this.GetTeacher)           // + admin,teacher
+	group.PUT("/teacher/pass", this.UpdateTeacherPassword) // + teacher
+
+	group.GET("/teacher/retake_requests", this.getRetakeRequests)                     // + teacher
+	group.PUT("/teacher/retake_request", this.updateRetakeRequest)                    // + teacher
+	group.GET("/teacher/retake_requests/suggestion/:exam_id", this.suggestRetakeSlot) // + teacher
 }
 
 func (t TeacherHandler) getTeacherInfo(c *gin.Context) {
@@ -126,6 +132,7 @@ func (t TeacherHandler) GetTeacher(c *gin.Context) {
 
 	result := dto.TeacherDTOFromTypes(teacher)
 
+	setETag(c, teacher.Version)
 	c.JSON(http.StatusOK, dto.GetTeacherDTO{
 		Err:  nil,
 		Data: result,
@@ -162,12 +169,18 @@ func (t TeacherHandler) CreateTeacher(c *gin.Context) {
 	})
 }
 
+// UpdateTeacher requires If-Match with the ETag of GetTeacher.
 func (t TeacherHandler) UpdateTeacher(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole && c.Value(auth.RoleKey) != auth.TeacherRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var r dto.UpdateTeacherDTO
 	if err := c.Bind(&r); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -176,15 +189,17 @@ func (t TeacherHandler) UpdateTeacher(c *gin.Context) {
 		})
 		return
 	}
+	teacher := dto.TypesTeacherFromUpdateTeachertDTO(r)
+	teacher.Version = version
 
-	if err := t.teacherUsecase.UpdateTeacher(c.Request.Context(), dto.TypesTeacherFromUpdateTeachertDTO(r)); err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+	if err := t.teacherUsecase.UpdateTeacher(c.Request.Context(), teacher); err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if version != 0 {
+		setETag(c, version+1)
+	}
 	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
 		Err:  nil,
 		Data: nil,
@@ -211,50 +226,40 @@ func (t TeacherHandler) DeleteTeacher(c *gin.Context) {
 	})
 }
 
+// GetTeachers filters by repeated ?uuid=, ?email= and ?last_name= and sorts
+// by uuid, last_name, first_name, middle_name and email.
 func (t TeacherHandler) GetTeachers(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
-	lim := c.Param("limit")
-	limit, err := strconv.Atoi(lim)
+	filters, err := teacherFiltersFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	offsetStr := c.Param("offset")
-	offset, err := strconv.Atoi(offsetStr)
+	params, err := listParamsFromQuery(c)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err,
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	teachers, err := t.teacherUsecase.GetTeachers(c.Request.Context(), int64(limit), int64(offset))
+	page, err := t.teacherUsecase.GetTeachers(c.Request.Context(), *filters, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
-		})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make([]dto.Teacher, 0, len(teachers))
-	for _, teacher := range teachers {
-		result = append(result, dto.TeacherDTOFromTypes(teacher))
-	}
+	c.JSON(http.StatusOK, dto.PageDTOFromTypes(page, dto.TeacherDTOFromTypes))
+}
 
-	c.JSON(http.StatusOK, dto.GetAllTeachersDTO{
-		Err:  nil,
-		Data: result,
-	})
+func teacherFiltersFromQuery(c *gin.Context) (*types.TeacherFilters, error) {
+	return &types.TeacherFilters{
+		UUIDs:     c.QueryArray("uuid"),
+		Emails:    c.QueryArray("email"),
+		LastNames: c.QueryArray("last_name"),
+	}, nil
 }
 
 func (this TeacherHandler) setDate(c *gin.Context) {
@@ -276,16 +281,48 @@ func (this TeacherHandler) setDate(c *gin.Context) {
 		return
 	}
 	request.TeacherUUID = uuid
-	fmt.Println(request)
 
-	switch err := this.teacherUsecase.SetDate(c.Request.Context(), request.TeacherUUID, request.ExamDate, request.Address, request.ExamID); err {
-	case nil:
+	report, err := this.teacherUsecase.SetDate(c.Request.Context(), request.TeacherUUID, types.SetDateRequest{
+		ExamID:       request.ExamID,
+		Date:         request.ExamDate,
+		Address:      request.Address,
+		RoomID:       request.RoomID,
+		Force:        request.Force,
+		GroupIDs:     request.GroupIDs,
+		StudentUUIDs: request.StudentUUIDs,
+	})
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrScheduleConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": dto.ScheduleConflictDTOsFromTypes(report.Conflicts),
+		})
+		return
+	case e.Is(err, errors.ErrInvalidData), e.Is(err, errors.ErrInvalidFilters):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
 	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
 		})
 		return
 	}
+
+	results := make([]dto.SetDateResult, len(report.Results))
+	for i, result := range report.Results {
+		results[i] = dto.SetDateResultDTOFromTypes(result)
+	}
+
+	c.JSON(http.StatusOK, dto.SetDateResponseDTO{
+		Err:       nil,
+		Data:      results,
+		Conflicts: dto.ScheduleConflictDTOsFromTypes(report.Conflicts),
+	})
 }
 
 func (this TeacherHandler) getAllDebts(c *gin.Context) {
@@ -321,3 +358,124 @@ func (this TeacherHandler) getAllDebts(c *gin.Context) {
 		Data: exams,
 	})
 }
+
+func (this TeacherHandler) getRetakeRequests(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	// pending requests are listed unless other statuses are asked for: ?status=pending&status=acknowledged
+	statuses := c.QueryArray("status")
+	if len(statuses) == 0 {
+		statuses = []string{valueobjects.RetakeRequestPending}
+	}
+
+	requests, err := this.teacherUsecase.GetRetakeRequests(c.Request.Context(), uuid, statuses)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidFilters):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, dto.GetAllRetakeRequestsDTO{
+			Err:  err,
+			Data: nil,
+		})
+		return
+	}
+
+	result := make([]dto.RetakeRequest, len(requests))
+	for i, request := range requests {
+		result[i] = dto.RetakeRequestDTOFromTypes(request)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeRequestsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this TeacherHandler) updateRetakeRequest(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.UpdateRetakeRequestStatusDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err := this.teacherUsecase.UpdateRetakeRequestStatus(c.Request.Context(), uuid, r.ID, r.Status)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRetakeRequestsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (this TeacherHandler) suggestRetakeSlot(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.TeacherRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	examID, err := strconv.Atoi(c.Param("exam_id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	suggestion, err := this.teacherUsecase.SuggestRetakeSlot(c.Request.Context(), uuid, int64(examID))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetSlotSuggestionDTO{
+		Err:  nil,
+		Data: dto.SlotSuggestionDTOFromTypes(*suggestion),
+	})
+}
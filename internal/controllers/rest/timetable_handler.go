@@ -0,0 +1,232 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type TimetableHandler struct {
+	timetableUsecase logic.TimetableUsecase
+}
+
+func NewTimetableHandler(timetableUsecase logic.TimetableUsecase) *TimetableHandler {
+	return &TimetableHandler{
+		timetableUsecase: timetableUsecase,
+	}
+}
+
+func (this TimetableHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.POST("/timetable/draft", this.GenerateDraft)        // + admin
+	group.GET("/timetable/drafts", this.GetDrafts)            // + admin
+	group.GET("/timetable/draft/:id", this.GetDraft)          // + admin
+	group.POST("/timetable/draft/:id/apply", this.ApplyDraft) // + admin
+	group.DELETE("/timetable/draft/:id", this.DiscardDraft)   // + admin
+}
+
+func (this TimetableHandler) GenerateDraft(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	var r dto.GenerateTimetableDTO
+	if err := c.Bind(&r); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	request, err := dto.TypesTimetableRequestFromDTO(r)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft, err := this.timetableUsecase.GenerateDraft(c.Request.Context(), uuid, *request)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetTimetableDraftDTO{
+		Err:  nil,
+		Data: dto.TimetableDraftDTOFromTypes(*draft),
+	})
+}
+
+// GetDrafts accepts an optional ?status=draft filter, drafts come without items.
+func (this TimetableHandler) GetDrafts(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	drafts, err := this.timetableUsecase.GetDrafts(c.Request.Context(), c.QueryArray("status"))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand), e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	result := make([]dto.TimetableDraft, len(drafts))
+	for i, draft := range drafts {
+		result[i] = dto.TimetableDraftDTOFromTypes(draft)
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllTimetableDraftsDTO{
+		Err:  nil,
+		Data: result,
+	})
+}
+
+func (this TimetableHandler) GetDraft(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	draft, err := this.timetableUsecase.GetDraft(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetTimetableDraftDTO{
+		Err:  nil,
+		Data: dto.TimetableDraftDTOFromTypes(*draft),
+	})
+}
+
+// ApplyDraft schedules every placed debt of the draft or none of them,
+// conflicts found meanwhile are returned with 409.
+func (this TimetableHandler) ApplyDraft(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	conflicts, err := this.timetableUsecase.ApplyDraft(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErrScheduleConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": dto.ScheduleConflictDTOsFromTypes(conflicts),
+		})
+		return
+	case e.Is(err, errors.ErrTimetableDraftClosed), e.Is(err, errors.ErrTimetableDraftOutdated):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllTimetableDraftsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
+
+func (this TimetableHandler) DiscardDraft(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = this.timetableUsecase.DiscardDraft(c.Request.Context(), int64(id))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErrTimetableDraftClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllTimetableDraftsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
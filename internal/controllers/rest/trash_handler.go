@@ -0,0 +1,94 @@
+package rest
+
+import (
+	e "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/auth"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type TrashHandler struct {
+	trashUsecase logic.TrashUsecase
+}
+
+func NewTrashHandler(trashUsecase logic.TrashUsecase) *TrashHandler {
+	return &TrashHandler{
+		trashUsecase: trashUsecase,
+	}
+}
+
+func (this TrashHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/trash", this.GetTrash)                          // + admin
+	group.POST("/trash/:type/:id/restore", this.RestoreDeleted) // + admin
+}
+
+// GetTrash accepts ?type=student,group&limit=20&offset=0, the latest deleted
+// go first.
+func (this TrashHandler) GetTrash(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	var filters types.TrashFilters
+	if kinds := c.Query("type"); kinds != "" {
+		filters.Kinds = strings.Split(kinds, ",")
+	}
+	var err error
+	if filters.Limit, err = strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filters.Offset, err = strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := this.trashUsecase.GetTrash(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	result := dto.GetTrashResponseDTO{Data: make([]dto.TrashItem, len(items))}
+	for i, item := range items {
+		result.Data[i] = dto.TrashItemDTOFromTypes(item)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (this TrashHandler) RestoreDeleted(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	err := this.trashUsecase.RestoreDeleted(c.Request.Context(), c.Param("type"), c.Param("id"))
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidCommand):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllDebtsDTO{
+		Err:  nil,
+		Data: nil,
+	})
+}
@@ -1,14 +1,26 @@
 package rest
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	e "errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xuri/excelize/v2"
 
+	"github.com/VanLavr/Diploma-fin/internal/controllers/dto"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/auth"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
 )
 
 type FileHandler struct {
@@ -20,15 +32,52 @@ func NewFileHandler(fUsecase logic.FileUsecase) *FileHandler {
 }
 
 func (fh FileHandler) RegisterRoutes(group *gin.RouterGroup) {
-	group.POST("/file/upload", fh.ParsFile) // + admin
+	group.POST("/file/upload", fh.ParsFile)                               // + admin
+	group.GET("/file/import/:token", fh.GetImportPreview)                 // + admin
+	group.POST("/file/import/:token/confirm", fh.ConfirmImport)           // + admin
+	group.GET("/import/jobs", fh.GetImportJobs)                           // + admin
+	group.GET("/import/jobs/:id", fh.GetImportJob)                        // + admin
+	group.POST("/import/jobs/:id/rerun", fh.RerunImportJob)               // + admin
+	group.POST("/import/jobs/:id/rollback", fh.RollbackImportJob)         // + admin
+	group.POST("/import/csv", fh.ImportCSV)                               // + admin
+	group.POST("/import/json", fh.ImportJSON)                             // + admin
+	group.POST("/import/profile", fh.CreateImportProfile)                 // + admin
+	group.PUT("/import/profile", fh.UpdateImportProfile)                  // + admin
+	group.DELETE("/import/profile/:id", fh.DeleteImportProfile)           // + admin
+	group.GET("/import/profile/all/:limit/:offset", fh.GetImportProfiles) // + admin
+	group.GET("/import/profile/:id", fh.GetImportProfile)                 // + admin
+	group.GET("/export/debts", fh.ExportDebts)                            // + admin
+	group.GET("/export/timetable", fh.ExportTimetable)                    // + admin
+	group.GET("/export/students", fh.ExportStudents)                      // + admin
 }
 
+// ParsFile queues an import job for the workbook and answers with the job
+// to poll at /import/jobs/:id. With ?dry_run=true nothing is imported, the
+// response is a report with the token for ConfirmImport. ?profile_id= picks
+// a stored import profile instead of the default matrix.
 func (fh FileHandler) ParsFile(c *gin.Context) {
 	if c.Value(auth.RoleKey) != auth.AdminRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
 		return
 	}
 
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	profileID, err := strconv.ParseInt(c.DefaultQuery("profile_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	file, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get file from form: " + err.Error()})
@@ -42,17 +91,254 @@ func (fh FileHandler) ParsFile(c *gin.Context) {
 	}
 	defer f.Close()
 
-	excelFile, err := excelize.OpenReader(f)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file: " + err.Error()})
+		return
+	}
+
+	excelFile, err := excelize.OpenReader(bytes.NewReader(data))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read Excel file: " + err.Error()})
 		return
 	}
 	defer excelFile.Close()
 
-	if err := fh.fUsecase.ParseFile(c.Request.Context(), excelFile); err != nil {
+	if dryRun {
+		report, err := fh.fUsecase.PreviewImport(c.Request.Context(), uuid, profileID, excelFile)
+		switch {
+		case err == nil:
+		case e.Is(err, errors.ErroNoItemsFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		default:
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse Excel data: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.ImportReportDTO{
+			Err:  nil,
+			Data: dto.ImportReportDTOFromTypes(*report),
+		})
+		return
+	}
+
+	checksum := sha256.Sum256(data)
+	job, err := fh.fUsecase.ImportFile(c.Request.Context(), types.ImportUpload{
+		Actor:     uuid,
+		ProfileID: profileID,
+		FileName:  file.Filename,
+		Checksum:  hex.EncodeToString(checksum[:]),
+	}, excelFile)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case e.Is(err, errors.ErroNoItemsFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse Excel data: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": nil})
+	c.JSON(http.StatusAccepted, dto.ImportJobDTO{
+		Err:  nil,
+		Data: dto.ImportJobDTOFromTypes(*job),
+	})
+}
+
+// GetImportPreview shows the report of a dry-run import against the current
+// data, a confirmed preview keeps its token and confirmed_at.
+func (fh FileHandler) GetImportPreview(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	report, err := fh.fUsecase.GetImportPreview(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ImportReportDTO{
+		Err:  nil,
+		Data: dto.ImportReportDTOFromTypes(*report),
+	})
+}
+
+// ConfirmImport queues an import job for the records of a dry-run, records
+// with issues were left out by the preview. A token can be confirmed once
+// until it expires.
+func (fh FileHandler) ConfirmImport(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	uuid, ok := c.Value(auth.EntityUUIDKey).(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errors.ErrCannotDetermineUUID.Error()})
+		return
+	}
+
+	job, err := fh.fUsecase.ConfirmImport(c.Request.Context(), uuid, c.Param("token"))
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.ImportJobDTO{
+		Err:  nil,
+		Data: dto.ImportJobDTOFromTypes(*job),
+	})
+}
+
+func importErrorStatus(err error) int {
+	switch {
+	case e.Is(err, errors.ErroNoItemsFound):
+		return http.StatusNotFound
+	case e.Is(err, errors.ErrImportPreviewClosed), e.Is(err, errors.ErrImportJobActive), e.Is(err, errors.ErrInTrash):
+		return http.StatusConflict
+	case e.Is(err, errors.ErrInvalidData), e.Is(err, errors.ErrInvalidFilters):
+		return http.StatusBadRequest
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return http.StatusInternalServerError
+	}
+}
+
+// ExportDebts takes the filters of GetDebtsFilters from the query string:
+// student_uuid, teacher_uuid, exam_id, debt_id and group_id can be repeated,
+// only_unscheduled, include_closed, from, to, limit and offset are single.
+// ?layout=matrix produces a workbook that /file/upload accepts back.
+func (fh FileHandler) ExportDebts(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	filters, err := debtFiltersFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := fh.fUsecase.ExportDebts(c.Request.Context(), *filters, c.DefaultQuery("layout", types.ExportListLayout))
+	sendWorkbook(c, "debts.xlsx", f, err)
+}
+
+// ExportTimetable takes the same filters as ExportDebts.
+func (fh FileHandler) ExportTimetable(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	filters, err := debtFiltersFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := fh.fUsecase.ExportTimetable(c.Request.Context(), *filters)
+	sendWorkbook(c, "timetable.xlsx", f, err)
+}
+
+// ExportStudents accepts repeated ?group_id=, all groups by default.
+func (fh FileHandler) ExportStudents(c *gin.Context) {
+	if c.Value(auth.RoleKey) != auth.AdminRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": errors.ErrUserDoesNotHaveRights.Error()})
+		return
+	}
+
+	groupIDs, err := int64Query(c, "group_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := fh.fUsecase.ExportStudents(c.Request.Context(), groupIDs)
+	sendWorkbook(c, "students.xlsx", f, err)
+}
+
+func sendWorkbook(c *gin.Context, filename string, f *excelize.File, err error) {
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErrInvalidData), e.Is(err, errors.ErrInvalidFilters):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Status(http.StatusOK)
+	if err := f.Write(c.Writer); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+	}
+}
+
+func debtFiltersFromQuery(c *gin.Context) (*types.DebtFilters, error) {
+	filters := &types.DebtFilters{
+		StudentUUIDs: c.QueryArray("student_uuid"),
+		TeacherUUIDs: c.QueryArray("teacher_uuid"),
+	}
+
+	var err error
+	if filters.ExamIDs, err = int64Query(c, "exam_id"); err != nil {
+		return nil, err
+	}
+	if filters.DebtIDs, err = int64Query(c, "debt_id"); err != nil {
+		return nil, err
+	}
+	if filters.GroupIDs, err = int64Query(c, "group_id"); err != nil {
+		return nil, err
+	}
+	if filters.OnlyUnscheduled, err = strconv.ParseBool(c.DefaultQuery("only_unscheduled", "false")); err != nil {
+		return nil, err
+	}
+	if filters.IncludeClosed, err = strconv.ParseBool(c.DefaultQuery("include_closed", "false")); err != nil {
+		return nil, err
+	}
+	if from := c.Query("from"); from != "" {
+		if filters.ScheduledFrom, err = time.Parse(valueobjects.DateLayout, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filters.ScheduledTo, err = time.Parse(valueobjects.DateLayout, to); err != nil {
+			return nil, err
+		}
+	}
+	if filters.Limit, err = strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64); err != nil {
+		return nil, err
+	}
+	if filters.Offset, err = strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
+}
+
+func int64Query(c *gin.Context, key string) ([]int64, error) {
+	values := c.QueryArray(key)
+	result := make([]int64, len(values))
+	for i, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = id
+	}
+
+	return result, nil
 }
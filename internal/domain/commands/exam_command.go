@@ -3,16 +3,21 @@ package commands
 import (
 	"time"
 
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/log"
 )
 
+// UpdateDebtByID is based on the Version of the debt, zero updates any
+// version.
 type UpdateDebtByID struct {
 	DebtID      int64
 	Date        time.Time
 	Address     string
+	RoomID      int64
 	TeacherUUID string
 	StudentUUID string
+	Version     int64
 }
 
 func (this UpdateDebtByID) Validate() error {
@@ -24,7 +29,16 @@ func (this UpdateDebtByID) Validate() error {
 }
 
 type CreateExam struct {
-	Name string
+	Name           string
+	AssessmentType string
+}
+
+func (this CreateExam) Validate() error {
+	if !valueobjects.IsValidAssessmentType(this.AssessmentType) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "assessment type", this.AssessmentType)
+	}
+
+	return nil
 }
 
 type CreateDebt struct {
@@ -34,9 +48,21 @@ type CreateDebt struct {
 	Date        time.Time
 }
 
+// UpdateExamByID keeps the assessment type when it is empty. It is based on
+// the Version of the exam, zero updates any version.
 type UpdateExamByID struct {
-	ID   int64
-	Name string
+	ID             int64
+	Name           string
+	AssessmentType string
+	Version        int64
+}
+
+func (this UpdateExamByID) Validate() error {
+	if this.AssessmentType != "" && !valueobjects.IsValidAssessmentType(this.AssessmentType) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "assessment type", this.AssessmentType)
+	}
+
+	return nil
 }
 
 type DeleteExam struct {
@@ -46,3 +72,27 @@ type DeleteExam struct {
 type DeleteDebt struct {
 	ID int64
 }
+
+type CreateDebtResult struct {
+	DebtID      int64
+	SessionID   int64
+	TeacherUUID string
+	Passed      bool
+	Grade       int64
+	AttemptedAt time.Time
+}
+
+func (this CreateDebtResult) Validate() error {
+	if this.DebtID == 0 || this.TeacherUUID == "" || this.AttemptedAt.IsZero() {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	if this.Grade != 0 && (this.Grade < valueobjects.MinGrade || this.Grade > valueobjects.MaxGrade) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "grade", this.Grade)
+	}
+
+	return nil
+}
+
+type CloseDebt struct {
+	DebtID int64
+}
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateImportPreview struct {
+	Token     string
+	CreatedBy string
+	// ProfileID is 0 for the default profile
+	ProfileID int64
+	Records   []models.ImportRecord
+	Issues    []models.ImportIssue
+	ExpiresAt time.Time
+}
+
+func (this CreateImportPreview) Validate() error {
+	if this.Token == "" || this.CreatedBy == "" || this.ExpiresAt.IsZero() {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type ConfirmImportPreview struct {
+	Token string
+}
+
+type CreateImportProfile struct {
+	Name          string
+	Layout        string
+	Sheets        []string
+	HeaderRow     int64
+	Separator     string
+	StudentFields []string
+	TeacherFields []string
+	Columns       map[string]string
+}
+
+func (this CreateImportProfile) Validate() error {
+	if !validImportProfile(this.Name, this.Layout, this.HeaderRow, this.Separator, this.StudentFields, this.TeacherFields, this.Columns) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type UpdateImportProfile struct {
+	ID            int64
+	Name          string
+	Layout        string
+	Sheets        []string
+	HeaderRow     int64
+	Separator     string
+	StudentFields []string
+	TeacherFields []string
+	Columns       map[string]string
+}
+
+func (this UpdateImportProfile) Validate() error {
+	if this.ID == 0 || !validImportProfile(this.Name, this.Layout, this.HeaderRow, this.Separator, this.StudentFields, this.TeacherFields, this.Columns) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type DeleteImportProfile struct {
+	ID int64
+}
+
+var columnLetters = regexp.MustCompile(`^[A-Za-z]{1,3}$`)
+
+func validImportProfile(name, layout string, headerRow int64, separator string, studentFields, teacherFields []string, columns map[string]string) bool {
+	if name == "" || headerRow < 1 {
+		return false
+	}
+
+	switch layout {
+	case valueobjects.ImportMatrixLayout:
+		return separator != "" &&
+			validPersonFields(studentFields, true) &&
+			validPersonFields(teacherFields, false)
+	case valueobjects.ImportListLayout:
+		students := make([]string, 0)
+		teachers := make([]string, 0)
+		for column, letters := range columns {
+			if !columnLetters.MatchString(letters) {
+				return false
+			}
+			switch {
+			case column == valueobjects.ImportExamColumn:
+			case column == valueobjects.ImportGroupField:
+				students = append(students, column)
+			case strings.HasPrefix(column, valueobjects.ImportStudentPrefix):
+				students = append(students, strings.TrimPrefix(column, valueobjects.ImportStudentPrefix))
+			case strings.HasPrefix(column, valueobjects.ImportTeacherPrefix):
+				teachers = append(teachers, strings.TrimPrefix(column, valueobjects.ImportTeacherPrefix))
+			default:
+				return false
+			}
+		}
+
+		_, ok := columns[valueobjects.ImportExamColumn]
+		return ok && validPersonFields(students, true) && validPersonFields(teachers, false)
+	}
+
+	return false
+}
+
+// validPersonFields checks that fields name a person once: an email, either
+// the full name or the last and the first names, and a group for students.
+func validPersonFields(fields []string, withGroup bool) bool {
+	set := make(map[string]bool)
+	for _, field := range fields {
+		if !valueobjects.IsValidImportField(field) || set[field] {
+			return false
+		}
+		if field == valueobjects.ImportGroupField && !withGroup {
+			return false
+		}
+		set[field] = true
+	}
+
+	named := set[valueobjects.ImportLastNameField] && set[valueobjects.ImportFirstNameField]
+	if set[valueobjects.ImportFullNameField] {
+		named = !set[valueobjects.ImportLastNameField] && !set[valueobjects.ImportFirstNameField] && !set[valueobjects.ImportMiddleNameField]
+	}
+
+	return named && set[valueobjects.ImportEmailField] && set[valueobjects.ImportGroupField] == withGroup
+}
+
+type CreateImportJob struct {
+	Source       string
+	Actor        string
+	FileName     string
+	Checksum     string
+	ProfileID    int64
+	PreviewToken string
+	Records      []models.ImportRecord
+	Issues       []models.ImportIssue
+	// Total is the size of a bulk batch, the other jobs count their records
+	Total int64
+}
+
+func (this CreateImportJob) Validate() error {
+	fromFile := this.Source == valueobjects.ImportFileSource
+	fromPreview := this.Source == valueobjects.ImportPreviewSource && this.PreviewToken != ""
+	fromBulk := this.Source == valueobjects.ImportBulkSource && len(this.Records) == 0
+	if this.Actor == "" || !(fromFile || fromPreview || fromBulk) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+// UpdateImportJob stores the progress of a running job, a completed or
+// failed status finishes it.
+type UpdateImportJob struct {
+	ID        int64
+	Status    string
+	Processed int64
+	Created   int64
+	Existing  int64
+	Failed    int64
+	Errors    []models.ImportIssue
+}
+
+func (this UpdateImportJob) Validate() error {
+	if this.ID == 0 || !valueobjects.IsValidImportStatus(this.Status) || this.Status == valueobjects.ImportQueuedStatus {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type RequeueImportJob struct {
+	ID int64
+}
+
+// RenewImportJob extends the lease of a running job without touching its
+// progress.
+type RenewImportJob struct {
+	ID int64
+}
+
+type AddImportJobEntities struct {
+	JobID    int64
+	Entities []models.ImportJobEntity
+}
+
+func (this AddImportJobEntities) Validate() error {
+	if this.JobID == 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	for _, entity := range this.Entities {
+		if !valueobjects.IsValidImportEntity(entity.Entity) || entity.EntityID == "" {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+		}
+	}
+
+	return nil
+}
+
+// RollbackImportJobEntity deletes an entity an import job created unless
+// something references it.
+type RollbackImportJobEntity struct {
+	JobID    int64
+	Entity   string
+	EntityID string
+}
+
+func (this RollbackImportJobEntity) Validate() error {
+	if this.JobID == 0 || !valueobjects.IsValidImportEntity(this.Entity) || this.EntityID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type FinishImportJobRollback struct {
+	ID int64
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// SetStudentStatus sets when a graduated or an expelled student left, an
+// active student has no LeftAt.
+type SetStudentStatus struct {
+	UUID   string
+	Status string
+	LeftAt time.Time
+}
+
+func (this SetStudentStatus) Validate() error {
+	if this.UUID == "" || !valueobjects.IsValidStudentStatus(this.Status) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "status", this.Status)
+	}
+	if (this.Status == valueobjects.StudentActiveStatus) != this.LeftAt.IsZero() {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "left at does not match the status", "status", this.Status)
+	}
+
+	return nil
+}
+
+// AnonymizeStudents replaces the personal data of the students of UUIDs or,
+// without UUIDs, of the graduated and expelled students who left before
+// LeftBefore. Anonymized students are skipped.
+type AnonymizeStudents struct {
+	UUIDs      []string
+	LeftBefore time.Time
+}
+
+func (this AnonymizeStudents) Validate() error {
+	if (len(this.UUIDs) == 0) == this.LeftBefore.IsZero() {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "either uuids or left before is required")
+	}
+	for _, uuid := range this.UUIDs {
+		if uuid == "" {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "empty uuid")
+		}
+	}
+
+	return nil
+}
+
+type CreatePersonalDataAudit struct {
+	StudentUUID string
+	Action      string
+	ActorUUID   string
+}
+
+func (this CreatePersonalDataAudit) Validate() error {
+	if this.StudentUUID == "" || !valueobjects.IsValidPersonalDataAction(this.Action) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "action", this.Action)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateRetakeRequest struct {
+	DebtID         int64
+	StudentUUID    string
+	TeacherUUID    string
+	PreferredSlots []models.TimeSlot
+}
+
+func (this CreateRetakeRequest) Validate() error {
+	if this.DebtID == 0 || this.StudentUUID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	if len(this.PreferredSlots) > valueobjects.MaxPreferredSlots {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "too many preferred slots")
+	}
+	for _, slot := range this.PreferredSlots {
+		if !slot.End.After(slot.Start) {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "slot ends before it starts")
+		}
+	}
+
+	return nil
+}
+
+// UpdateRetakeRequestStatus moves open (pending or acknowledged) requests
+// matched either by their ids or by the ids of their debts.
+type UpdateRetakeRequestStatus struct {
+	IDs     []int64
+	DebtIDs []int64
+	Status  string
+}
+
+func (this UpdateRetakeRequestStatus) Validate() error {
+	if len(this.IDs) == 0 && len(this.DebtIDs) == 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	if !valueobjects.IsValidRetakeRequestStatus(this.Status) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown status")
+	}
+
+	return nil
+}
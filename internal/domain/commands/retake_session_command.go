@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateRetakeSession struct {
+	ExamID         int64
+	TeacherUUID    string
+	Date           time.Time
+	Address        string
+	RoomID         int64
+	Capacity       int64
+	SignupDeadline time.Time
+	CancelDeadline time.Time
+}
+
+func (this CreateRetakeSession) Validate() error {
+	if this.ExamID == 0 || this.TeacherUUID == "" || this.Capacity <= 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	if this.SignupDeadline.After(this.Date) || this.CancelDeadline.After(this.Date) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "deadline is after the retake")
+	}
+
+	return nil
+}
+
+type CreateRetakeBooking struct {
+	SessionID   int64
+	DebtID      int64
+	StudentUUID string
+	Status      string
+}
+
+func (this CreateRetakeBooking) Validate() error {
+	if this.SessionID == 0 || this.DebtID == 0 || this.StudentUUID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	if this.Status != valueobjects.RetakeBookingBooked && this.Status != valueobjects.RetakeBookingWaitlisted {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown status")
+	}
+
+	return nil
+}
+
+type UpdateRetakeBookingStatus struct {
+	ID     int64
+	Status string
+}
+
+func (this UpdateRetakeBookingStatus) Validate() error {
+	if this.ID == 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	switch this.Status {
+	case valueobjects.RetakeBookingBooked, valueobjects.RetakeBookingWaitlisted, valueobjects.RetakeBookingCancelled:
+	default:
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown status")
+	}
+
+	return nil
+}
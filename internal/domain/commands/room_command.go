@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateRoom struct {
+	Building   string
+	Number     string
+	Capacity   int64
+	Accessible bool
+}
+
+func (this CreateRoom) Validate() error {
+	if this.Building == "" || this.Number == "" || this.Capacity <= 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type UpdateRoom struct {
+	ID         int64
+	Building   string
+	Number     string
+	Capacity   int64
+	Accessible bool
+}
+
+func (this UpdateRoom) Validate() error {
+	if this.ID == 0 || this.Building == "" || this.Number == "" || this.Capacity <= 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return nil
+}
+
+type DeleteRoom struct {
+	ID int64
+}
@@ -9,6 +9,8 @@ type CreateStudent struct {
 	Password   string
 }
 
+// UpdateStudent is based on the Version of the student, zero updates any
+// version.
 type UpdateStudent struct {
 	UUID       string
 	FirstName  string
@@ -16,6 +18,7 @@ type UpdateStudent struct {
 	MiddleName string
 	GroupID    int64
 	Email      string
+	Version    int64
 }
 
 type DeleteStudent struct {
@@ -26,9 +29,12 @@ type CreateGroup struct {
 	Name string
 }
 
+// UpdateGroup is based on the Version of the group, zero updates any
+// version.
 type UpdateGroup struct {
-	ID   int64
-	Name string
+	ID      int64
+	Name    string
+	Version int64
 }
 
 type DeleteGroup struct {
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateTeacherAvailability struct {
+	TeacherUUID string
+	Kind        string
+	Weekday     time.Weekday
+	StartMinute int64
+	EndMinute   int64
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Note        string
+}
+
+func (this CreateTeacherAvailability) Validate() error {
+	if this.TeacherUUID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return validateAvailability(this.Kind, this.Weekday, this.StartMinute, this.EndMinute, this.StartsAt, this.EndsAt)
+}
+
+// UpdateTeacherAvailability changes the entry only if it belongs to TeacherUUID.
+type UpdateTeacherAvailability struct {
+	ID          int64
+	TeacherUUID string
+	Kind        string
+	Weekday     time.Weekday
+	StartMinute int64
+	EndMinute   int64
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Note        string
+}
+
+func (this UpdateTeacherAvailability) Validate() error {
+	if this.ID == 0 || this.TeacherUUID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+
+	return validateAvailability(this.Kind, this.Weekday, this.StartMinute, this.EndMinute, this.StartsAt, this.EndsAt)
+}
+
+// DeleteTeacherAvailability removes the entry only if it belongs to TeacherUUID.
+type DeleteTeacherAvailability struct {
+	ID          int64
+	TeacherUUID string
+}
+
+func validateAvailability(kind string, weekday time.Weekday, startMinute, endMinute int64, startsAt, endsAt time.Time) error {
+	switch kind {
+	case valueobjects.AvailabilityWeekly:
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown weekday")
+		}
+		if startMinute < 0 || endMinute > valueobjects.MinutesInDay || startMinute >= endMinute {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "window must end after it starts within a day")
+		}
+	case valueobjects.AvailabilityException, valueobjects.AvailabilityBlackout:
+		if startsAt.IsZero() || !startsAt.Before(endsAt) {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "interval must end after it starts")
+		}
+	default:
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown kind")
+	}
+
+	return nil
+}
@@ -8,12 +8,15 @@ type CreateTeacher struct {
 	Password   string
 }
 
+// UpdateTeacher is based on the Version of the teacher, zero updates any
+// version.
 type UpdateTeacher struct {
 	UUID       string
 	FirstName  string
 	LastName   string
 	MiddleName string
 	Email      string
+	Version    int64
 }
 
 type DeleteTeacher struct {
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type CreateTimetableDraft struct {
+	PeriodStart time.Time
+	Deadline    time.Time
+	CreatedBy   string
+	Items       []CreateTimetableDraftItem
+}
+
+// CreateTimetableDraftItem zero RoomID and Date mark a debt that was not placed.
+type CreateTimetableDraftItem struct {
+	DebtID int64
+	RoomID int64
+	Date   time.Time
+	Reason string
+}
+
+func (this CreateTimetableDraft) Validate() error {
+	if !this.PeriodStart.Before(this.Deadline) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "period must end after it starts")
+	}
+	for _, item := range this.Items {
+		if item.DebtID == 0 {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+		}
+		if (item.RoomID == 0) != item.Date.IsZero() {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "placed item needs both room and date")
+		}
+	}
+
+	return nil
+}
+
+type UpdateTimetableDraftStatus struct {
+	ID     int64
+	Status string
+}
+
+func (this UpdateTimetableDraftStatus) Validate() error {
+	if this.ID == 0 {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "")
+	}
+	switch this.Status {
+	case valueobjects.TimetableAppliedStatus, valueobjects.TimetableDiscardedStatus:
+	default:
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "unknown status")
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"time"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// RestoreDeleted takes an entity out of the trash. ID is the uuid of a
+// person or the id of a group or an exam.
+type RestoreDeleted struct {
+	Kind string
+	ID   string
+}
+
+func (this RestoreDeleted) Validate() error {
+	if !valueobjects.IsValidTrashKind(this.Kind) || this.ID == "" {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "kind", this.Kind)
+	}
+
+	return nil
+}
+
+// PurgeDeleted drops the entities of the kind deleted before Before.
+type PurgeDeleted struct {
+	Kind   string
+	Before time.Time
+}
+
+func (this PurgeDeleted) Validate() error {
+	if !valueobjects.IsValidTrashKind(this.Kind) || this.Before.IsZero() {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_DOMAIN, "", "kind", this.Kind)
+	}
+
+	return nil
+}
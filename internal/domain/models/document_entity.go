@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RetakeSheet is the record sheet of a retake session, Debts are the booked
+// ones in the order they are printed.
+type RetakeSheet struct {
+	SessionID int64
+	Exam      Exam
+	Teacher   Teacher
+	Date      time.Time
+	Address   string
+	Room      *Room
+	Debts     []Debt
+	IssuedAt  time.Time
+}
+
+// ReferralSlip admits a single student to the retake of a debt.
+type ReferralSlip struct {
+	Debt     Debt
+	IssuedAt time.Time
+}
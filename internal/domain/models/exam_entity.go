@@ -3,16 +3,34 @@ package models
 import "time"
 
 type Exam struct {
-	ID   int64
-	Name string
+	ID             int64
+	Name           string
+	AssessmentType string
+	Version        int64
 }
 
 type Debt struct {
 	ID        int64
 	Address   string
 	Date      *time.Time
+	ClosedAt  *time.Time
+	Room      *Room
 	Exam      *Exam
 	Student   *Student
 	Teacher   *Teacher
 	GroupList []Group
+	Version   int64
+}
+
+// DebtResult Grade is 0 for pass/fail assessments. SessionID is 0 when the
+// debt was retaken outside of a retake session.
+type DebtResult struct {
+	ID          int64
+	DebtID      int64
+	SessionID   int64
+	TeacherUUID string
+	Passed      bool
+	Grade       int64
+	AttemptedAt time.Time
+	RecordedAt  time.Time
 }
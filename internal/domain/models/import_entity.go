@@ -0,0 +1,133 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ImportRecord is a single debt read from an imported file. Row and Column
+// point at the cell it came from, both start at 1.
+type ImportRecord struct {
+	Sheet             string
+	Row               int64
+	Column            int64
+	GroupName         string
+	StudentLastName   string
+	StudentFirstName  string
+	StudentMiddleName string
+	StudentEmail      string
+	TeacherLastName   string
+	TeacherFirstName  string
+	TeacherMiddleName string
+	TeacherEmail      string
+	ExamName          string
+}
+
+// Anonymize clears the student of the record when emails has their email,
+// the email becomes the one emails maps it to.
+func (this *ImportRecord) Anonymize(emails map[string]string) bool {
+	anonymized, ok := emails[this.StudentEmail]
+	if !ok {
+		return false
+	}
+
+	this.StudentLastName = ""
+	this.StudentFirstName = ""
+	this.StudentMiddleName = ""
+	this.StudentEmail = anonymized
+	return true
+}
+
+// ImportIssue is a validation error of an imported file, Row or Column is 0
+// when the issue concerns the whole row or column.
+type ImportIssue struct {
+	Sheet   string
+	Row     int64
+	Column  int64
+	Value   string
+	Message string
+}
+
+// Anonymize clears the value of the issue when it has one of the emails, a
+// cell with an email is a person. The emails in the message become the
+// ones emails maps them to.
+func (this *ImportIssue) Anonymize(emails map[string]string) bool {
+	changed := false
+	for email, anonymized := range emails {
+		if strings.Contains(this.Value, email) {
+			this.Value = ""
+			changed = true
+		}
+		if strings.Contains(this.Message, email) {
+			this.Message = strings.ReplaceAll(this.Message, email, anonymized)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+type ImportPreview struct {
+	Token       string
+	CreatedBy   string
+	ProfileID   *int64
+	Records     []ImportRecord
+	Issues      []ImportIssue
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+}
+
+// ImportProfile describes how to read an imported workbook. Sheets limits
+// the sheets to read, all of them when empty. The matrix layout splits
+// person cells by Separator into StudentFields and TeacherFields, the list
+// layout maps Columns (see valueobjects) to column letters.
+type ImportProfile struct {
+	ID            int64
+	Name          string
+	Layout        string
+	Sheets        []string
+	HeaderRow     int64
+	Separator     string
+	StudentFields []string
+	TeacherFields []string
+	Columns       map[string]string
+	CreatedAt     time.Time
+}
+
+// ImportJob is a background import. Issues are the parse issues of the
+// upload, Errors are the records that failed in the last run.
+type ImportJob struct {
+	ID           int64
+	Source       string
+	Status       string
+	Actor        string
+	FileName     string
+	Checksum     string
+	ProfileID    *int64
+	PreviewToken *string
+	Records      []ImportRecord
+	Issues       []ImportIssue
+	Errors       []ImportIssue
+	Total        int64
+	Processed    int64
+	Created      int64
+	Existing     int64
+	Failed       int64
+	Attempts     int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	RolledBackAt *time.Time
+}
+
+// ImportJobEntity is a group, student, teacher, exam or debt created by an
+// import job. EntityID is the uuid of students and teachers and the id of
+// the rest.
+type ImportJobEntity struct {
+	JobID     int64
+	Entity    string
+	EntityID  string
+	CreatedAt time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StudentStatus is where a student is in their studies. LeftAt is set for
+// a graduated or an expelled student, AnonymizedAt once their personal data
+// is gone, DeletedAt while the student is in the trash.
+type StudentStatus struct {
+	UUID         string
+	Status       string
+	LeftAt       *time.Time
+	AnonymizedAt *time.Time
+	DeletedAt    *time.Time
+}
+
+// PersonalDataAudit is an export, an erase or an anonymization of the
+// personal data of a student. It keeps nothing but the uuids, ActorUUID is
+// the admin and is empty for the retention job.
+type PersonalDataAudit struct {
+	ID          int64
+	StudentUUID string
+	Action      string
+	ActorUUID   string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type RetakeRequest struct {
+	ID             int64
+	Status         string
+	Debt           *Debt
+	PreferredSlots []TimeSlot
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
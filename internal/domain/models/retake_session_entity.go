@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type RetakeSession struct {
+	ID              int64
+	Exam            *Exam
+	Teacher         *Teacher
+	Date            time.Time
+	Address         string
+	Room            *Room
+	Capacity        int64
+	SignupDeadline  time.Time
+	CancelDeadline  time.Time
+	BookedCount     int64
+	WaitlistedCount int64
+	CreatedAt       time.Time
+}
+
+type RetakeBooking struct {
+	ID        int64
+	SessionID int64
+	Status    string
+	Debt      *Debt
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
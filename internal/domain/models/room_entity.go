@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Room struct {
+	ID         int64
+	Building   string
+	Number     string
+	Capacity   int64
+	Accessible bool
+}
+
+// ScheduledRetake is a single point of the retake timetable: either a
+// scheduled debt (StudentUUID is set) or a retake session (SessionID is set).
+type ScheduledRetake struct {
+	DebtID      int64
+	SessionID   int64
+	ExamID      int64
+	TeacherUUID string
+	StudentUUID string
+	RoomID      int64
+	Date        time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+// SearchHit is a student, a teacher, a group or an exam a search found. ID
+// is the uuid of a person or the id of a group or an exam. Rank is how close
+// the hit is to the query, hits are ordered by it.
+type SearchHit struct {
+	Kind     string
+	ID       string
+	Title    string
+	Subtitle string
+	Rank     float64
+}
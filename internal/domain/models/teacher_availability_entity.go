@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TeacherAvailability is either a weekly window (Weekday, StartMinute and
+// EndMinute are set) or a one-off interval (StartsAt and EndsAt are set).
+type TeacherAvailability struct {
+	ID          int64
+	TeacherUUID string
+	Kind        string
+	Weekday     time.Weekday
+	StartMinute int64
+	EndMinute   int64
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Note        string
+}
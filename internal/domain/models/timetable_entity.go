@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type TimetableDraft struct {
+	ID          int64
+	Status      string
+	PeriodStart time.Time
+	Deadline    time.Time
+	CreatedBy   string
+	CreatedAt   time.Time
+	AppliedAt   *time.Time
+	Items       []TimetableDraftItem
+}
+
+// TimetableDraftItem Date and Room are nil for a debt that was not placed,
+// Reason explains why.
+type TimetableDraftItem struct {
+	ID      int64
+	DraftID int64
+	Debt    *Debt
+	Room    *Room
+	Date    *time.Time
+	Reason  string
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TrashItem is a deleted student, teacher, group or exam. ID is the uuid of
+// a person or the id of a group or an exam, Title is its name.
+type TrashItem struct {
+	Kind      string
+	ID        string
+	Title     string
+	DeletedAt time.Time
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"time"
+
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 )
 
@@ -9,8 +11,24 @@ type GetDebtsFilters struct {
 	TeacherUUIDs []string
 	ExamIDs      []int64
 	DebtIDs      []int64
-	Limit        int64
-	Offset       int64
+	GroupIDs     []int64
+	// OnlyUnscheduled keeps debts that have no retake date yet
+	OnlyUnscheduled bool
+	// IncludeClosed also returns debts that were closed by a passing result
+	IncludeClosed bool
+	// IncludeDeleted also returns debts of students, teachers and exams
+	// that are in the trash
+	IncludeDeleted bool
+	// WithExamGroups fills the GroupList of every debt with the groups of
+	// the open debts of its exam, ordered by id, in the same query
+	WithExamGroups bool
+	// ScheduledFrom and ScheduledTo keep debts with a retake in [from, to),
+	// zero values leave the side open
+	ScheduledFrom time.Time
+	ScheduledTo   time.Time
+	Limit         int64
+	Offset        int64
+	Keyset        Keyset
 }
 
 func (this *GetDebtsFilters) Validate() error {
@@ -29,14 +47,25 @@ func (this *GetDebtsFilters) Validate() error {
 			return errors.ErrInvalidFilters
 		}
 	}
+	for _, id := range this.GroupIDs {
+		if id == 0 {
+			return errors.ErrInvalidFilters
+		}
+	}
+	if !this.ScheduledFrom.IsZero() && !this.ScheduledTo.IsZero() && !this.ScheduledFrom.Before(this.ScheduledTo) {
+		return errors.ErrInvalidFilters
+	}
 
 	return nil
 }
 
 type GetExamsFilters struct {
-	Limit  int64
-	Offset int64
-	IDs    []int64
+	Limit           int64
+	Offset          int64
+	IDs             []int64
+	Names           []string
+	AssessmentTypes []string
+	Keyset          Keyset
 }
 
 func (this *GetExamsFilters) Validate() error {
@@ -48,3 +77,19 @@ func (this *GetExamsFilters) Validate() error {
 
 	return nil
 }
+
+type GetDebtResultsFilters struct {
+	DebtIDs      []int64
+	SessionIDs   []int64
+	StudentUUIDs []string
+}
+
+func (this GetDebtResultsFilters) Validate() error {
+	for _, id := range this.StudentUUIDs {
+		if id == "" {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package query
+
+import "github.com/VanLavr/Diploma-fin/utils/errors"
+
+type GetImportProfilesFilters struct {
+	IDs    []int64
+	Limit  int64
+	Offset int64
+}
+
+func (this *GetImportProfilesFilters) Validate() error {
+	for _, id := range this.IDs {
+		if id == 0 {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
+
+// GetImportJobsFilters WithRecords loads the records of the jobs, they are
+// left out of the job history.
+type GetImportJobsFilters struct {
+	IDs         []int64
+	Statuses    []string
+	Actors      []string
+	Checksums   []string
+	WithRecords bool
+	Limit       int64
+	Offset      int64
+}
+
+func (this *GetImportJobsFilters) Validate() error {
+	for _, id := range this.IDs {
+		if id == 0 {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
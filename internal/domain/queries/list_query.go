@@ -0,0 +1,159 @@
+package query
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// Kinds of sort fields, they tell how the values of a cursor compare.
+const (
+	TextSortKind = "text"
+	IntSortKind  = "int"
+	TimeSortKind = "time"
+)
+
+// SortInfinity is the value of a missing time, it sorts after every other
+// time the way 'infinity' does in postgres.
+const SortInfinity = "infinity"
+
+// SortField is a field a listing of T is sorted and paged by. Value formats
+// the field of a row the way a cursor keeps it.
+type SortField[T any] struct {
+	Name  string
+	Kind  string
+	Value func(T) string
+}
+
+// Sort fields of the listings. The first field of each is unique and ends
+// every sort, so the order of the rows is total.
+var (
+	ExamSortFields = []SortField[models.Exam]{
+		{Name: "id", Kind: IntSortKind, Value: func(exam models.Exam) string { return formatSortInt(exam.ID) }},
+		{Name: "name", Kind: TextSortKind, Value: func(exam models.Exam) string { return exam.Name }},
+		{Name: "assessment_type", Kind: TextSortKind, Value: func(exam models.Exam) string { return exam.AssessmentType }},
+	}
+	GroupSortFields = []SortField[models.Group]{
+		{Name: "id", Kind: IntSortKind, Value: func(group models.Group) string { return formatSortInt(group.ID) }},
+		{Name: "name", Kind: TextSortKind, Value: func(group models.Group) string { return group.Name }},
+	}
+	StudentSortFields = []SortField[models.Student]{
+		{Name: "uuid", Kind: TextSortKind, Value: func(student models.Student) string { return student.UUID }},
+		{Name: "last_name", Kind: TextSortKind, Value: func(student models.Student) string { return student.LastName }},
+		{Name: "first_name", Kind: TextSortKind, Value: func(student models.Student) string { return student.FirstName }},
+		{Name: "middle_name", Kind: TextSortKind, Value: func(student models.Student) string { return student.MiddleName }},
+		{Name: "email", Kind: TextSortKind, Value: func(student models.Student) string { return student.Email }},
+		{Name: "group", Kind: TextSortKind, Value: func(student models.Student) string { return groupName(student.Group) }},
+	}
+	TeacherSortFields = []SortField[models.Teacher]{
+		{Name: "uuid", Kind: TextSortKind, Value: func(teacher models.Teacher) string { return teacher.UUID }},
+		{Name: "last_name", Kind: TextSortKind, Value: func(teacher models.Teacher) string { return teacher.LastName }},
+		{Name: "first_name", Kind: TextSortKind, Value: func(teacher models.Teacher) string { return teacher.FirstName }},
+		{Name: "middle_name", Kind: TextSortKind, Value: func(teacher models.Teacher) string { return teacher.MiddleName }},
+		{Name: "email", Kind: TextSortKind, Value: func(teacher models.Teacher) string { return teacher.Email }},
+	}
+	// DebtSortFields sort debts by the name of the exam, the last name of
+	// the student and of the teacher and the name of the group.
+	DebtSortFields = []SortField[models.Debt]{
+		{Name: "id", Kind: IntSortKind, Value: func(debt models.Debt) string { return formatSortInt(debt.ID) }},
+		{Name: "date", Kind: TimeSortKind, Value: func(debt models.Debt) string { return FormatSortTime(debt.Date) }},
+		{Name: "closed_at", Kind: TimeSortKind, Value: func(debt models.Debt) string { return FormatSortTime(debt.ClosedAt) }},
+		{Name: "exam", Kind: TextSortKind, Value: func(debt models.Debt) string {
+			if debt.Exam == nil {
+				return ""
+			}
+			return debt.Exam.Name
+		}},
+		{Name: "student", Kind: TextSortKind, Value: func(debt models.Debt) string {
+			if debt.Student == nil {
+				return ""
+			}
+			return debt.Student.LastName
+		}},
+		{Name: "teacher", Kind: TextSortKind, Value: func(debt models.Debt) string {
+			if debt.Teacher == nil {
+				return ""
+			}
+			return debt.Teacher.LastName
+		}},
+		{Name: "group", Kind: TextSortKind, Value: func(debt models.Debt) string {
+			if debt.Student == nil {
+				return ""
+			}
+			return groupName(debt.Student.Group)
+		}},
+	}
+)
+
+// Sort orders a listing by a field, descending when Desc is set.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Keyset pages a listing by the sort values of the last row of the previous
+// page: After holds a value for every field of Sort and only the rows that
+// sort after them are returned. An empty Sort leaves the rows unordered, an
+// empty After starts from the first row.
+type Keyset struct {
+	Sort  []Sort
+	After []string
+}
+
+func (this Keyset) Validate() error {
+	if len(this.After) != 0 && len(this.After) != len(this.Sort) {
+		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "cursor does not match the sort")
+	}
+
+	return nil
+}
+
+// CompareSortValues compares two values of a cursor of a field of the kind.
+// Values that do not parse sort first.
+func CompareSortValues(kind, a, b string) int {
+	switch kind {
+	case IntSortKind:
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return cmp.Compare(x, y)
+	case TimeSortKind:
+		switch {
+		case a == SortInfinity && b == SortInfinity:
+			return 0
+		case a == SortInfinity:
+			return 1
+		case b == SortInfinity:
+			return -1
+		}
+		x, _ := time.Parse(time.RFC3339Nano, a)
+		y, _ := time.Parse(time.RFC3339Nano, b)
+		return x.Compare(y)
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// FormatSortTime formats a time the way a cursor keeps it, nil is
+// SortInfinity.
+func FormatSortTime(t *time.Time) string {
+	if t == nil {
+		return SortInfinity
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatSortInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func groupName(group *models.Group) string {
+	if group == nil {
+		return ""
+	}
+	return group.Name
+}
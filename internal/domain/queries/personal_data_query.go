@@ -0,0 +1,22 @@
+package query
+
+import (
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// GetPersonalDataAuditFilters lists the audit in the order it was written.
+type GetPersonalDataAuditFilters struct {
+	StudentUUIDs []string
+	Actions      []string
+}
+
+func (this GetPersonalDataAuditFilters) Validate() error {
+	for _, uuid := range this.StudentUUIDs {
+		if uuid == "" {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "empty uuid")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type GetRetakeRequestsFilters struct {
+	IDs          []int64
+	DebtIDs      []int64
+	StudentUUIDs []string
+	TeacherUUIDs []string
+	ExamIDs      []int64
+	Statuses     []string
+	Limit        int64
+	Offset       int64
+}
+
+func (this GetRetakeRequestsFilters) Validate() error {
+	for _, id := range this.StudentUUIDs {
+		if id == "" {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
+		}
+	}
+	for _, id := range this.TeacherUUIDs {
+		if id == "" {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
+		}
+	}
+
+	return nil
+}
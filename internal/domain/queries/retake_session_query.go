@@ -0,0 +1,47 @@
+package query
+
+import (
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type GetRetakeSessionsFilters struct {
+	IDs          []int64
+	ExamIDs      []int64
+	TeacherUUIDs []string
+	// OnlyOpen leaves sessions whose sign-up deadline has not passed yet
+	OnlyOpen bool
+	Limit    int64
+	Offset   int64
+}
+
+func (this GetRetakeSessionsFilters) Validate() error {
+	for _, id := range this.TeacherUUIDs {
+		if id == "" {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
+		}
+	}
+
+	return nil
+}
+
+// GetRetakeBookingsFilters results are ordered by booking time, so the first
+// waitlisted booking of a session is the next one to be promoted.
+type GetRetakeBookingsFilters struct {
+	IDs          []int64
+	SessionIDs   []int64
+	DebtIDs      []int64
+	StudentUUIDs []string
+	Statuses     []string
+	Limit        int64
+}
+
+func (this GetRetakeBookingsFilters) Validate() error {
+	for _, id := range this.StudentUUIDs {
+		if id == "" {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+type GetRoomsFilters struct {
+	IDs            []int64
+	MinCapacity    int64
+	OnlyAccessible bool
+	Limit          int64
+	Offset         int64
+}
+
+func (this *GetRoomsFilters) Validate() error {
+	for _, id := range this.IDs {
+		if id == 0 {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
+
+// GetScheduledRetakesFilters selects retakes in the open interval (From, To)
+// that use any of the rooms, teachers or students.
+type GetScheduledRetakesFilters struct {
+	From         time.Time
+	To           time.Time
+	RoomIDs      []int64
+	TeacherUUIDs []string
+	StudentUUIDs []string
+}
+
+func (this *GetScheduledRetakesFilters) Validate() error {
+	if !this.From.Before(this.To) {
+		return errors.ErrInvalidFilters
+	}
+	if len(this.RoomIDs) == 0 && len(this.TeacherUUIDs) == 0 && len(this.StudentUUIDs) == 0 {
+		return errors.ErrInvalidFilters
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// Search looks the query up in the names and emails of students and
+// teachers and in the names of groups and exams. Empty Kinds searches all
+// of them.
+type Search struct {
+	Query string
+	Kinds []string
+	Limit int64
+}
+
+func (this Search) Validate() error {
+	if utf8.RuneCountInString(strings.TrimSpace(this.Query)) < valueobjects.SearchMinQueryLength {
+		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "query is too short")
+	}
+	for _, kind := range this.Kinds {
+		if !valueobjects.IsValidSearchKind(kind) {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "unknown kind", "kind", kind)
+		}
+	}
+	if this.Limit < 0 {
+		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "negative limit")
+	}
+
+	return nil
+}
+
+// Searches tells whether the kind is searched for.
+func (this Search) Searches(kind string) bool {
+	if len(this.Kinds) == 0 {
+		return true
+	}
+	for _, searched := range this.Kinds {
+		if searched == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,13 +7,17 @@ import (
 
 type GetGroupsFilters struct {
 	IDs    []int64
+	Names  []string
 	Limit  int64
 	Offset int64
+	Keyset Keyset
 }
 
 type SearchGroupFilters struct {
 	IDs   []int64
 	Names []string
+	// Deleted looks in the trash instead of the live rows
+	Deleted bool
 }
 
 type SearchStudentFilters struct {
@@ -22,17 +26,22 @@ type SearchStudentFilters struct {
 	LastNames   []string
 	MiddleNames []string
 	Emails      []string
+	// Deleted looks in the trash instead of the live rows
+	Deleted bool
 }
 
 type GetStudentsFilters struct {
-	Limit  int64
-	Offset int64
-	IDs    []string
-	Emails []string
+	Limit     int64
+	Offset    int64
+	IDs       []string
+	Emails    []string
+	GroupIDs  []int64
+	LastNames []string
+	Keyset    Keyset
 }
 
 func (this GetStudentsFilters) Validate() error {
-	if len(this.Emails) == 0 && len(this.IDs) == 0 && this.Limit == 0 {
+	if len(this.Emails) == 0 && len(this.IDs) == 0 && len(this.GroupIDs) == 0 && len(this.LastNames) == 0 && this.Limit == 0 {
 		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
 	}
 	for _, email := range this.Emails {
@@ -0,0 +1,27 @@
+package query
+
+import (
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+type GetTeacherAvailabilityFilters struct {
+	IDs          []int64
+	TeacherUUIDs []string
+	Kinds        []string
+}
+
+func (this *GetTeacherAvailabilityFilters) Validate() error {
+	for _, uuid := range this.TeacherUUIDs {
+		if uuid == "" {
+			return errors.ErrInvalidFilters
+		}
+	}
+	for _, kind := range this.Kinds {
+		if !valueobjects.IsValidAvailabilityKind(kind) {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
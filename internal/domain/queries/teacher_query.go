@@ -6,10 +6,12 @@ import (
 )
 
 type GetTeachersFilters struct {
-	UUIDs  []string
-	Emails []string
-	Limit  int64
-	Offset int64
+	UUIDs     []string
+	Emails    []string
+	LastNames []string
+	Limit     int64
+	Offset    int64
+	Keyset    Keyset
 }
 
 type SearchDebtsFilters struct {
@@ -24,6 +26,8 @@ type SearchDebtsFilters struct {
 type SearchExamFilters struct {
 	IDs   []int64
 	Names []string
+	// Deleted looks in the trash instead of the live rows
+	Deleted bool
 }
 
 type SearchTeacherFilters struct {
@@ -32,10 +36,12 @@ type SearchTeacherFilters struct {
 	LastNames   []string
 	MiddleNames []string
 	Emails      []string
+	// Deleted looks in the trash instead of the live rows
+	Deleted bool
 }
 
 func (this GetTeachersFilters) Validate() error {
-	if len(this.Emails) == 0 && len(this.UUIDs) == 0 && this.Limit == 0 {
+	if len(this.Emails) == 0 && len(this.UUIDs) == 0 && len(this.LastNames) == 0 && this.Limit == 0 {
 		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "")
 	}
 	for _, email := range this.Emails {
@@ -0,0 +1,20 @@
+package query
+
+import "github.com/VanLavr/Diploma-fin/utils/errors"
+
+type GetTimetableDraftsFilters struct {
+	IDs      []int64
+	Statuses []string
+	Limit    int64
+	Offset   int64
+}
+
+func (this *GetTimetableDraftsFilters) Validate() error {
+	for _, id := range this.IDs {
+		if id == 0 {
+			return errors.ErrInvalidFilters
+		}
+	}
+
+	return nil
+}
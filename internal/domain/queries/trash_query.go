@@ -0,0 +1,42 @@
+package query
+
+import (
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// GetTrashFilters lists the deleted entities, the latest deleted first.
+// Empty Kinds lists all of them.
+type GetTrashFilters struct {
+	Kinds  []string
+	Limit  int64
+	Offset int64
+}
+
+func (this GetTrashFilters) Validate() error {
+	for _, kind := range this.Kinds {
+		if !valueobjects.IsValidTrashKind(kind) {
+			return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "unknown kind", "kind", kind)
+		}
+	}
+	if this.Limit < 0 || this.Offset < 0 {
+		return log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_DOMAIN, "negative limit or offset")
+	}
+
+	return nil
+}
+
+// Lists tells whether the kind is listed.
+func (this GetTrashFilters) Lists(kind string) bool {
+	if len(this.Kinds) == 0 {
+		return true
+	}
+	for _, listed := range this.Kinds {
+		if listed == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+)
+
+// DocumentRenderer renders printable documents, results are PDF files.
+type DocumentRenderer interface {
+	RenderRetakeSheet(context.Context, models.RetakeSheet) ([]byte, error)
+	RenderReferralSlip(context.Context, models.ReferralSlip) ([]byte, error)
+}
@@ -11,13 +11,34 @@ import (
 type ExamRepository interface {
 	GetDebts(context.Context, query.GetDebtsFilters) ([]entities.Debt, error)
 	GetExams(context.Context, query.GetExamsFilters) ([]entities.Exam, error)
+	// CountDebts and CountExams count the rows of every page, they ignore
+	// the limit, the offset and the cursor of the filters.
+	CountDebts(context.Context, query.GetDebtsFilters) (int64, error)
+	CountExams(context.Context, query.GetExamsFilters) (int64, error)
 	GetExamByID(context.Context, query.GetExamsFilters) (*entities.Exam, error)
+	// UpdateDebt and CloseDebt bump the version of the debt. An update with
+	// a version returns errors.ErrVersionMismatch when it is not the current
+	// one and errors.ErroNoItemsFound when there is no such debt.
 	UpdateDebt(context.Context, commands.UpdateDebtByID) error
 	CreateExam(context.Context, commands.CreateExam) (int64, error)
 	CreateDebt(context.Context, commands.CreateDebt) (int64, error)
+	// UpdateExam bumps the version of the exam. A command with
+	// a version returns errors.ErrVersionMismatch when it is not the current
+	// one and errors.ErroNoItemsFound when there is no such exam.
 	UpdateExam(context.Context, commands.UpdateExamByID) error
+	// DeleteExam moves the exam to the trash, see TrashRepository.
 	DeleteExam(context.Context, commands.DeleteExam) error
 	DeleteDebt(context.Context, commands.DeleteDebt) error
 	SearchExams(context.Context, query.SearchExamFilters) ([]entities.Exam, error)
 	SearchDebts(context.Context, query.SearchDebtsFilters) ([]entities.Debt, error)
+	// CreateDebtResult returns errors.ErrResultAlreadyRecorded when the
+	// attempt already has a result.
+	CreateDebtResult(context.Context, commands.CreateDebtResult) (int64, error)
+	GetDebtResults(context.Context, query.GetDebtResultsFilters) ([]entities.DebtResult, error)
+	// CloseDebt returns errors.ErrDebtClosed when the debt is closed already
+	// and errors.ErroNoItemsFound when there is no such debt.
+	CloseDebt(context.Context, commands.CloseDebt) error
+	// LockDebt serializes results of the debt until the end of the
+	// transaction stored in the context.
+	LockDebt(context.Context, int64) error
 }
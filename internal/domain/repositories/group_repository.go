@@ -11,8 +11,14 @@ import (
 type GroupRepository interface {
 	GetGroupByID(context.Context, int64) (*models.Group, error)
 	GetGroups(context.Context, query.GetGroupsFilters) ([]models.Group, error)
+	// CountGroups ignores the limit, the offset and the cursor of the filters.
+	CountGroups(context.Context, query.GetGroupsFilters) (int64, error)
 	CreateGroup(context.Context, commands.CreateGroup) (int64, error)
+	// UpdateGroup bumps the version of the group. A command with
+	// a version returns errors.ErrVersionMismatch when it is not the current
+	// one and errors.ErroNoItemsFound when there is no such group.
 	UpdateGroup(context.Context, commands.UpdateGroup) error
+	// DeleteGroup moves the group to the trash, see TrashRepository.
 	DeleteGroup(context.Context, commands.DeleteGroup) error
 	SearchGroups(context.Context, query.SearchGroupFilters) ([]models.Group, error)
 }
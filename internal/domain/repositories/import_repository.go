@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type ImportRepository interface {
+	CreateImportPreview(context.Context, commands.CreateImportPreview) error
+	GetImportPreview(context.Context, string) (*models.ImportPreview, error)
+	// ConfirmImportPreview returns errors.ErrImportPreviewClosed when the
+	// preview is already confirmed or expired, so it is applied only once.
+	ConfirmImportPreview(context.Context, commands.ConfirmImportPreview) error
+
+	GetImportProfiles(context.Context, query.GetImportProfilesFilters) ([]models.ImportProfile, error)
+	CreateImportProfile(context.Context, commands.CreateImportProfile) (int64, error)
+	UpdateImportProfile(context.Context, commands.UpdateImportProfile) error
+	DeleteImportProfile(context.Context, commands.DeleteImportProfile) error
+
+	CreateImportJob(context.Context, commands.CreateImportJob) (int64, error)
+	GetImportJobs(context.Context, query.GetImportJobsFilters) ([]models.ImportJob, error)
+	// ClaimImportJob moves the oldest queued job, or a running one not
+	// updated for the lease, to running and returns its id. It returns
+	// errors.ErroNoItemsFound when there is nothing to run.
+	ClaimImportJob(ctx context.Context, lease time.Duration) (int64, error)
+	UpdateImportJob(context.Context, commands.UpdateImportJob) error
+	// RenewImportJob keeps a running job from being claimed again for
+	// another lease, a job that is not running is left as it is.
+	RenewImportJob(context.Context, commands.RenewImportJob) error
+	// RequeueImportJob returns errors.ErrImportJobActive when the job is
+	// queued or running.
+	RequeueImportJob(context.Context, commands.RequeueImportJob) error
+
+	AddImportJobEntities(context.Context, commands.AddImportJobEntities) error
+	GetImportJobEntities(ctx context.Context, jobID int64) ([]models.ImportJobEntity, error)
+	// RollbackImportJobEntity returns the tables referencing the entity and
+	// deletes it only when there are none. An entity deleted already is no
+	// longer tracked and counts as rolled back.
+	RollbackImportJobEntity(context.Context, commands.RollbackImportJobEntity) ([]string, error)
+	FinishImportJobRollback(context.Context, commands.FinishImportJobRollback) error
+}
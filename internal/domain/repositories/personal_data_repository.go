@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+// PersonalDataRepository keeps the status of the students and the audit of
+// what is done to their personal data. Anonymization keeps the rows, so the
+// debts and the results of a student still count in the statistics.
+type PersonalDataRepository interface {
+	// GetStudentStatus, SetStudentStatus and GetStudentPersonalData find
+	// the students in the trash too, the trash purge keeps a student with
+	// debts and their data is still theirs. They return
+	// errors.ErroNoItemsFound when there is no such student.
+	GetStudentStatus(context.Context, string) (*models.StudentStatus, error)
+	SetStudentStatus(context.Context, commands.SetStudentStatus) error
+	// GetStudentPersonalData is the student with their group, as
+	// GetStudentByUUID returns it.
+	GetStudentPersonalData(context.Context, string) (*models.Student, error)
+	// AnonymizeStudents returns the uuids of the students it anonymized,
+	// the ones in the trash included. The records, issues and errors of the
+	// import previews and jobs get the anonymized email instead of theirs
+	// and lose their names, so a rerun finds the anonymized student.
+	AnonymizeStudents(context.Context, commands.AnonymizeStudents) ([]string, error)
+	CreatePersonalDataAudit(context.Context, commands.CreatePersonalDataAudit) (int64, error)
+	GetPersonalDataAudit(context.Context, query.GetPersonalDataAuditFilters) ([]models.PersonalDataAudit, error)
+}
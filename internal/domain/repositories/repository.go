@@ -16,6 +16,17 @@ type Repository interface {
 	TeacherRepository
 	StudentMailer
 	GroupRepository
+	RetakeRequestRepository
+	RetakeSessionRepository
+	RoomRepository
+	ScheduleRepository
+	TeacherAvailabilityRepository
+	TimetableRepository
+	DocumentRenderer
+	ImportRepository
+	SearchRepository
+	TrashRepository
+	PersonalDataRepository
 }
 
 type TransactionRepository interface {
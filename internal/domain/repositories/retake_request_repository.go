@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type RetakeRequestRepository interface {
+	GetRetakeRequests(context.Context, query.GetRetakeRequestsFilters) ([]models.RetakeRequest, error)
+	CreateRetakeRequest(context.Context, commands.CreateRetakeRequest) (int64, error)
+	UpdateRetakeRequestStatus(context.Context, commands.UpdateRetakeRequestStatus) error
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type RetakeSessionRepository interface {
+	CreateRetakeSession(context.Context, commands.CreateRetakeSession) (int64, error)
+	GetRetakeSessions(context.Context, query.GetRetakeSessionsFilters) ([]models.RetakeSession, error)
+	// LockRetakeSession serializes bookings of the session until the end of
+	// the transaction stored in the context.
+	LockRetakeSession(context.Context, int64) error
+	GetRetakeBookings(context.Context, query.GetRetakeBookingsFilters) ([]models.RetakeBooking, error)
+	CreateRetakeBooking(context.Context, commands.CreateRetakeBooking) (int64, error)
+	UpdateRetakeBookingStatus(context.Context, commands.UpdateRetakeBookingStatus) error
+}
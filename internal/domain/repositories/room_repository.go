@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type RoomRepository interface {
+	GetRooms(context.Context, query.GetRoomsFilters) ([]models.Room, error)
+	CreateRoom(context.Context, commands.CreateRoom) (int64, error)
+	UpdateRoom(context.Context, commands.UpdateRoom) error
+	DeleteRoom(context.Context, commands.DeleteRoom) error
+}
+
+type ScheduleRepository interface {
+	GetScheduledRetakes(context.Context, query.GetScheduledRetakesFilters) ([]models.ScheduledRetake, error)
+	// LockSchedule serializes changes of the timetable until the end of the
+	// transaction stored in the context, so a conflict check stays true
+	// until its retake is written.
+	LockSchedule(context.Context) error
+}
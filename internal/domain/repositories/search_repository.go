@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type SearchRepository interface {
+	// Search returns the hits of the query ranked from the closest one.
+	Search(context.Context, query.Search) ([]models.SearchHit, error)
+}
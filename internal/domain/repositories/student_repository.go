@@ -11,8 +11,14 @@ import (
 type StudentRepository interface {
 	GetStudentByUUID(context.Context, string) (*models.Student, error)
 	GetStudents(context.Context, query.GetStudentsFilters) ([]models.Student, error)
+	// CountStudents ignores the limit, the offset and the cursor of the filters.
+	CountStudents(context.Context, query.GetStudentsFilters) (int64, error)
 	CreateStudent(context.Context, commands.CreateStudent) (string, error)
+	// UpdateStudent bumps the version of the student. A command with
+	// a version returns errors.ErrVersionMismatch when it is not the current
+	// one and errors.ErroNoItemsFound when there is no such student.
 	UpdateStudent(context.Context, commands.UpdateStudent) error
+	// DeleteStudent moves the student to the trash, see TrashRepository.
 	DeleteStudent(context.Context, commands.DeleteStudent) error
 	SearchStudents(context.Context, query.SearchStudentFilters) ([]models.Student, error)
 	ChangeStudentPassword(context.Context, string, string) error
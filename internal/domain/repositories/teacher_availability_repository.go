@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type TeacherAvailabilityRepository interface {
+	GetTeacherAvailability(context.Context, query.GetTeacherAvailabilityFilters) ([]models.TeacherAvailability, error)
+	CreateTeacherAvailability(context.Context, commands.CreateTeacherAvailability) (int64, error)
+	// UpdateTeacherAvailability and DeleteTeacherAvailability return
+	// errors.ErroNoItemsFound when the teacher has no such entry.
+	UpdateTeacherAvailability(context.Context, commands.UpdateTeacherAvailability) error
+	DeleteTeacherAvailability(context.Context, commands.DeleteTeacherAvailability) error
+}
@@ -10,9 +10,15 @@ import (
 
 type TeacherRepository interface {
 	GetTeachers(context.Context, query.GetTeachersFilters) ([]models.Teacher, error)
+	// CountTeachers ignores the limit, the offset and the cursor of the filters.
+	CountTeachers(context.Context, query.GetTeachersFilters) (int64, error)
 	GetTeacherByUUID(context.Context, string) (*models.Teacher, error)
 	CreateTeacher(context.Context, commands.CreateTeacher) (string, error)
+	// UpdateTeacher bumps the version of the teacher. A command with
+	// a version returns errors.ErrVersionMismatch when it is not the current
+	// one and errors.ErroNoItemsFound when there is no such teacher.
 	UpdateTeacher(context.Context, commands.UpdateTeacher) error
+	// DeleteTeacher moves the teacher to the trash, see TrashRepository.
 	DeleteTeacher(context.Context, commands.DeleteTeacher) error
 	SearchTeachers(context.Context, query.SearchTeacherFilters) ([]models.Teacher, error)
 	ChangeTeacherPassword(context.Context, string, string) error
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+type TimetableRepository interface {
+	// CreateTimetableDraft stores the draft together with its items.
+	CreateTimetableDraft(context.Context, commands.CreateTimetableDraft) (int64, error)
+	// GetTimetableDrafts returns drafts without items.
+	GetTimetableDrafts(context.Context, query.GetTimetableDraftsFilters) ([]models.TimetableDraft, error)
+	GetTimetableDraftItems(context.Context, int64) ([]models.TimetableDraftItem, error)
+	// LockTimetableDraft serializes applying of the draft until the end of
+	// the transaction stored in the context.
+	LockTimetableDraft(context.Context, int64) error
+	UpdateTimetableDraftStatus(context.Context, commands.UpdateTimetableDraftStatus) error
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+)
+
+// TrashRepository keeps what DeleteStudent, DeleteTeacher, DeleteGroup and
+// DeleteExam delete. A deleted entity is left out of every query of its
+// repository until it is restored or purged.
+type TrashRepository interface {
+	GetTrash(context.Context, query.GetTrashFilters) ([]models.TrashItem, error)
+	// RestoreDeleted returns errors.ErroNoItemsFound when the entity is not
+	// in the trash.
+	RestoreDeleted(context.Context, commands.RestoreDeleted) error
+	// PurgeDeleted drops the expired entities nothing references and returns
+	// how many it dropped, the referenced ones stay in the trash.
+	PurgeDeleted(context.Context, commands.PurgeDeleted) (int64, error)
+}
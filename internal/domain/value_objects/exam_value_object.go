@@ -3,3 +3,31 @@ package valueobjects
 const (
 	DateLayout string = "2006-01-02 15:04:05"
 )
+
+const (
+	AssessmentExam         string = "exam"
+	AssessmentCredit       string = "credit"
+	AssessmentGradedCredit string = "graded_credit"
+	AssessmentCoursework   string = "coursework"
+)
+
+// Grades of graded assessments, PassingGrade and above pass.
+const (
+	MinGrade     int64 = 2
+	PassingGrade int64 = 3
+	MaxGrade     int64 = 5
+)
+
+func IsValidAssessmentType(assessmentType string) bool {
+	switch assessmentType {
+	case AssessmentExam, AssessmentCredit, AssessmentGradedCredit, AssessmentCoursework:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsGradedAssessment tells whether results are 2–5 grades rather than pass/fail.
+func IsGradedAssessment(assessmentType string) bool {
+	return assessmentType != AssessmentCredit
+}
@@ -0,0 +1,117 @@
+package valueobjects
+
+import "time"
+
+// ImportPreviewTTL is how long a dry-run import can be confirmed.
+const ImportPreviewTTL time.Duration = 24 * time.Hour
+
+// Layouts of an imported sheet. The matrix has students in the header row,
+// teachers in the first column and exam names in the cells, the list has a
+// debt per row.
+const (
+	ImportMatrixLayout string = "matrix"
+	ImportListLayout   string = "list"
+)
+
+// Fields a person cell of the matrix is split into. FullName is split by
+// spaces into the last name, the first name and the rest as the middle name.
+const (
+	ImportLastNameField   string = "last_name"
+	ImportFirstNameField  string = "first_name"
+	ImportMiddleNameField string = "middle_name"
+	ImportFullNameField   string = "full_name"
+	ImportGroupField      string = "group"
+	ImportEmailField      string = "email"
+)
+
+// Columns of the list layout are the person fields prefixed with
+// ImportStudentPrefix or ImportTeacherPrefix, plus group and exam.
+const (
+	ImportStudentPrefix string = "student_"
+	ImportTeacherPrefix string = "teacher_"
+	ImportExamColumn    string = "exam"
+)
+
+func IsValidImportLayout(layout string) bool {
+	switch layout {
+	case ImportMatrixLayout, ImportListLayout:
+		return true
+	}
+
+	return false
+}
+
+func IsValidImportField(field string) bool {
+	switch field {
+	case ImportLastNameField, ImportFirstNameField, ImportMiddleNameField,
+		ImportFullNameField, ImportGroupField, ImportEmailField:
+		return true
+	}
+
+	return false
+}
+
+// Sources of import jobs. A bulk job is run by the CSV or JSON request that
+// creates it, it keeps no records, only what it created.
+const (
+	ImportFileSource    string = "file"
+	ImportPreviewSource string = "preview"
+	ImportBulkSource    string = "bulk"
+)
+
+// Statuses of import jobs. A completed job can still have failed records.
+const (
+	ImportQueuedStatus    string = "queued"
+	ImportRunningStatus   string = "running"
+	ImportCompletedStatus string = "completed"
+	ImportFailedStatus    string = "failed"
+)
+
+// ImportJobLease is how long a running job may go without an update before
+// another worker claims it.
+const ImportJobLease time.Duration = 5 * time.Minute
+
+// ImportJobRenewInterval is how often a worker renews the lease of the job it
+// runs. It does not wait for the records, one of them can wait on the mail
+// server for longer than the lease.
+const ImportJobRenewInterval time.Duration = ImportJobLease / 5
+
+// ImportJobPollInterval is how often an idle worker looks for queued jobs.
+const ImportJobPollInterval time.Duration = 10 * time.Second
+
+func IsValidImportStatus(status string) bool {
+	switch status {
+	case ImportQueuedStatus, ImportRunningStatus, ImportCompletedStatus, ImportFailedStatus:
+		return true
+	}
+
+	return false
+}
+
+// Entities an import job creates, in the order a rollback deletes them so
+// the debts go before what they reference.
+const (
+	ImportDebtEntity    string = "debt"
+	ImportStudentEntity string = "student"
+	ImportTeacherEntity string = "teacher"
+	ImportExamEntity    string = "exam"
+	ImportGroupEntity   string = "group"
+)
+
+var ImportRollbackOrder = []string{
+	ImportDebtEntity,
+	ImportStudentEntity,
+	ImportTeacherEntity,
+	ImportExamEntity,
+	ImportGroupEntity,
+}
+
+func IsValidImportEntity(entity string) bool {
+	for _, valid := range ImportRollbackOrder {
+		if entity == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package valueobjects
+
+import "time"
+
+// Statuses of a student. A graduated or an expelled student has left and
+// their personal data is anonymized once the retention is over.
+const (
+	StudentActiveStatus    string = "active"
+	StudentGraduatedStatus string = "graduated"
+	StudentExpelledStatus  string = "expelled"
+)
+
+func IsValidStudentStatus(status string) bool {
+	switch status {
+	case StudentActiveStatus, StudentGraduatedStatus, StudentExpelledStatus:
+		return true
+	}
+
+	return false
+}
+
+// Actions of the personal data audit. Anonymize is done by the retention
+// job, export and erase by an admin.
+const (
+	PersonalDataExportAction    string = "export"
+	PersonalDataEraseAction     string = "erase"
+	PersonalDataAnonymizeAction string = "anonymize"
+)
+
+func IsValidPersonalDataAction(action string) bool {
+	switch action {
+	case PersonalDataExportAction, PersonalDataEraseAction, PersonalDataAnonymizeAction:
+		return true
+	}
+
+	return false
+}
+
+// Formats of a personal data export. The zip has a json file per section.
+const (
+	PersonalDataJSONFormat string = "json"
+	PersonalDataZIPFormat  string = "zip"
+)
+
+// AnonymizedEmailPrefix is followed by the uuid in the email of an
+// anonymized student, the email stays unique and can not be mailed.
+const AnonymizedEmailPrefix string = "anonymized-"
+
+// DefaultPersonalDataRetentionYears is how many years after leaving the
+// personal data of a student is kept when the retention is not configured.
+const DefaultPersonalDataRetentionYears int = 5
+
+// PersonalDataRetentionInterval is how often the retention job looks for
+// students to anonymize.
+const PersonalDataRetentionInterval time.Duration = 24 * time.Hour
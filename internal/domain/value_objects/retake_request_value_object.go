@@ -0,0 +1,28 @@
+package valueobjects
+
+import "time"
+
+const (
+	RetakeRequestPending      string = "pending"
+	RetakeRequestAcknowledged string = "acknowledged"
+	RetakeRequestScheduled    string = "scheduled"
+	RetakeRequestDeclined     string = "declined"
+)
+
+// RetakeRequestCooldown is the minimal interval between two requests for the same debt.
+const RetakeRequestCooldown time.Duration = 24 * time.Hour
+
+// MaxPreferredSlots is how many availability windows a student can attach to a request.
+const MaxPreferredSlots int = 3
+
+// OpenRetakeRequestStatuses are the statuses a request can still be moved from.
+var OpenRetakeRequestStatuses = []string{RetakeRequestPending, RetakeRequestAcknowledged}
+
+func IsValidRetakeRequestStatus(status string) bool {
+	switch status {
+	case RetakeRequestPending, RetakeRequestAcknowledged, RetakeRequestScheduled, RetakeRequestDeclined:
+		return true
+	default:
+		return false
+	}
+}
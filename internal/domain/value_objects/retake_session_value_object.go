@@ -0,0 +1,10 @@
+package valueobjects
+
+const (
+	RetakeBookingBooked     string = "booked"
+	RetakeBookingWaitlisted string = "waitlisted"
+	RetakeBookingCancelled  string = "cancelled"
+)
+
+// ActiveRetakeBookingStatuses hold a seat or a place in the waitlist.
+var ActiveRetakeBookingStatuses = []string{RetakeBookingBooked, RetakeBookingWaitlisted}
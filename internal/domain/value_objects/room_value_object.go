@@ -0,0 +1,15 @@
+package valueobjects
+
+import "time"
+
+// RetakeDuration is the time a retake occupies its room, teacher and students.
+const RetakeDuration = 90 * time.Minute
+
+const (
+	ScheduleConflictRoom     = "room"
+	ScheduleConflictTeacher  = "teacher"
+	ScheduleConflictStudent  = "student"
+	ScheduleConflictCapacity = "capacity"
+	// ScheduleConflictAvailability means the teacher is not available at that time.
+	ScheduleConflictAvailability = "availability"
+)
@@ -0,0 +1,30 @@
+package valueobjects
+
+// Kinds of search hits.
+const (
+	SearchStudentKind string = "student"
+	SearchTeacherKind string = "teacher"
+	SearchGroupKind   string = "group"
+	SearchExamKind    string = "exam"
+)
+
+var SearchKinds = []string{
+	SearchStudentKind,
+	SearchTeacherKind,
+	SearchGroupKind,
+	SearchExamKind,
+}
+
+// SearchMinQueryLength is the shortest query in letters, a shorter one
+// matches too much to be ranked.
+const SearchMinQueryLength int = 2
+
+func IsValidSearchKind(kind string) bool {
+	for _, valid := range SearchKinds {
+		if kind == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,21 @@
+package valueobjects
+
+const (
+	// AvailabilityWeekly is a window that repeats every week.
+	AvailabilityWeekly = "weekly"
+	// AvailabilityException is a one-off window outside of the weekly ones.
+	AvailabilityException = "exception"
+	// AvailabilityBlackout is an interval when the teacher runs no retakes at all.
+	AvailabilityBlackout = "blackout"
+)
+
+const MinutesInDay = 24 * 60
+
+func IsValidAvailabilityKind(kind string) bool {
+	switch kind {
+	case AvailabilityWeekly, AvailabilityException, AvailabilityBlackout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package valueobjects
+
+import "time"
+
+const (
+	TimetableDraftStatus     = "draft"
+	TimetableAppliedStatus   = "applied"
+	TimetableDiscardedStatus = "discarded"
+)
+
+// Retakes generated by the timetable engine start on a grid of
+// RetakeDuration long slots between TimetableDayStart and TimetableDayEnd
+// (offsets from midnight) on every day except Sunday.
+const (
+	TimetableDayStart = 9 * time.Hour
+	TimetableDayEnd   = 18 * time.Hour
+)
+
+// reasons for debts left out of a draft
+const (
+	TimetableNoSlotReason = "no free slot for the teacher, a room and the student before the deadline"
+	TimetableNoRoomReason = "no rooms in the catalogue"
+)
@@ -0,0 +1,37 @@
+package valueobjects
+
+import "time"
+
+// Kinds of deleted entities in the trash.
+const (
+	TrashStudentKind string = "student"
+	TrashTeacherKind string = "teacher"
+	TrashGroupKind   string = "group"
+	TrashExamKind    string = "exam"
+)
+
+// TrashKinds are in the order a purge goes through them, groups are last as
+// the deleted students of a group keep it in place.
+var TrashKinds = []string{
+	TrashStudentKind,
+	TrashTeacherKind,
+	TrashExamKind,
+	TrashGroupKind,
+}
+
+func IsValidTrashKind(kind string) bool {
+	for _, valid := range TrashKinds {
+		if kind == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultTrashRetention is how long a deleted entity stays restorable when
+// the retention is not configured.
+const DefaultTrashRetention time.Duration = 30 * 24 * time.Hour
+
+// TrashPurgeInterval is how often the purge job looks for expired entities.
+const TrashPurgeInterval time.Duration = time.Hour
@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+// seedDebts gives a teacher debts debts of students of groups groups for
+// exams exams, the debts of every exam spread over all the groups.
+func seedDebts(tb testing.TB, repo Repository, groups, exams, debts int) string {
+	tb.Helper()
+
+	ctx := context.Background()
+	teacher, err := repo.CreateTeacher(ctx, commands.CreateTeacher{
+		FirstName: "Анна",
+		LastName:  "Сидорова",
+		Email:     "sidorova@example.com",
+	})
+	if err != nil {
+		tb.Fatalf("CreateTeacher: %v", err)
+	}
+
+	students := make([]string, groups)
+	for i := range students {
+		group, err := repo.CreateGroup(ctx, commands.CreateGroup{Name: fmt.Sprintf("ИВТ-%d", i+1)})
+		if err != nil {
+			tb.Fatalf("CreateGroup: %v", err)
+		}
+		if students[i], err = repo.CreateStudent(ctx, commands.CreateStudent{
+			FirstName: "Иван",
+			LastName:  "Петров",
+			Email:     fmt.Sprintf("petrov%d@example.com", i+1),
+			GroupID:   group,
+		}); err != nil {
+			tb.Fatalf("CreateStudent: %v", err)
+		}
+	}
+
+	examIDs := make([]int64, exams)
+	for i := range examIDs {
+		if examIDs[i], err = repo.CreateExam(ctx, commands.CreateExam{
+			Name:           fmt.Sprintf("Экзамен %d", i+1),
+			AssessmentType: valueobjects.AssessmentExam,
+		}); err != nil {
+			tb.Fatalf("CreateExam: %v", err)
+		}
+	}
+
+	for i := 0; i < debts; i++ {
+		if _, err := repo.CreateDebt(ctx, commands.CreateDebt{
+			ExamID:      examIDs[i%exams],
+			StudentUUID: students[(i/exams)%groups],
+			TeacherUUID: teacher,
+		}); err != nil {
+			tb.Fatalf("CreateDebt: %v", err)
+		}
+	}
+
+	return teacher
+}
+
+// BenchmarkExamGroups reads the debts of a teacher with 300 debts and the
+// groups of their exams, once with a GetDebts per debt the way the
+// usecases did and once with WithExamGroups.
+func BenchmarkExamGroups(b *testing.B, repo Repository) {
+	ctx := context.Background()
+	teacher := seedDebts(b, repo, 10, 30, 300)
+
+	b.Run("PerDebt", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{TeacherUUIDs: []string{teacher}})
+			if err != nil {
+				b.Fatalf("GetDebts: %v", err)
+			}
+			for j, debt := range debts {
+				byExam, err := repo.GetDebts(ctx, query.GetDebtsFilters{ExamIDs: []int64{debt.Exam.ID}})
+				if err != nil {
+					b.Fatalf("GetDebts of an exam: %v", err)
+				}
+				groups := make(map[int64]models.Group)
+				for _, other := range byExam {
+					groups[other.Student.Group.ID] = *other.Student.Group
+				}
+				debts[j].GroupList = make([]models.Group, 0, len(groups))
+				for _, group := range groups {
+					debts[j].GroupList = append(debts[j].GroupList, group)
+				}
+			}
+		}
+	})
+
+	b.Run("WithExamGroups", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetDebts(ctx, query.GetDebtsFilters{TeacherUUIDs: []string{teacher}, WithExamGroups: true}); err != nil {
+				b.Fatalf("GetDebts with exam groups: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,249 @@
+// Package contract checks that an implementation of the exam, student,
+// teacher, group, search, trash, personal data and transaction repositories
+// (and of the import repository as far as they touch it) behaves the way
+// the postgres one does: the same filters, paging, not found errors,
+// constraint violations and rollbacks.
+// Every implementation runs it from its own tests.
+package contract
+
+import (
+	"cmp"
+	"context"
+	e "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+// Repository is the part of repositories.Repository the contract covers.
+type Repository interface {
+	repositories.ExamRepository
+	repositories.StudentRepository
+	repositories.TeacherRepository
+	repositories.GroupRepository
+	repositories.SearchRepository
+	repositories.TrashRepository
+	repositories.PersonalDataRepository
+	repositories.ImportRepository
+	repositories.TransactionRepository
+}
+
+// NewRepository returns an empty repository, it is called once per test.
+type NewRepository func(t *testing.T) Repository
+
+// Run checks every repository of the contract.
+func Run(t *testing.T, newRepo NewRepository) {
+	t.Run("GroupRepository", func(t *testing.T) { GroupRepository(t, newRepo) })
+	t.Run("StudentRepository", func(t *testing.T) { StudentRepository(t, newRepo) })
+	t.Run("TeacherRepository", func(t *testing.T) { TeacherRepository(t, newRepo) })
+	t.Run("ExamRepository", func(t *testing.T) { ExamRepository(t, newRepo) })
+	t.Run("SearchRepository", func(t *testing.T) { SearchRepository(t, newRepo) })
+	t.Run("TrashRepository", func(t *testing.T) { TrashRepository(t, newRepo) })
+	t.Run("PersonalDataRepository", func(t *testing.T) { PersonalDataRepository(t, newRepo) })
+	t.Run("TransactionRepository", func(t *testing.T) { TransactionRepository(t, newRepo) })
+}
+
+// missingUUID is a well formed uuid no student or teacher has.
+const missingUUID = "00000000-0000-0000-0000-000000000000"
+
+// missingID is an id no row has, the sequences start from 1.
+const missingID int64 = 1 << 30
+
+func createGroup(t *testing.T, repo Repository, name string) int64 {
+	t.Helper()
+
+	id, err := repo.CreateGroup(context.Background(), commands.CreateGroup{Name: name})
+	if err != nil {
+		t.Fatalf("CreateGroup(%s): %v", name, err)
+	}
+	return id
+}
+
+func createStudent(t *testing.T, repo Repository, lastName, email string, groupID int64) string {
+	t.Helper()
+
+	uuid, err := repo.CreateStudent(context.Background(), commands.CreateStudent{
+		FirstName:  "Иван",
+		LastName:   lastName,
+		MiddleName: "Сергеевич",
+		Email:      email,
+		GroupID:    groupID,
+		Password:   "hash-" + email,
+	})
+	if err != nil {
+		t.Fatalf("CreateStudent(%s): %v", email, err)
+	}
+	return uuid
+}
+
+func createTeacher(t *testing.T, repo Repository, lastName, email string) string {
+	t.Helper()
+
+	uuid, err := repo.CreateTeacher(context.Background(), commands.CreateTeacher{
+		FirstName:  "Анна",
+		LastName:   lastName,
+		MiddleName: "Петровна",
+		Email:      email,
+		Password:   "hash-" + email,
+	})
+	if err != nil {
+		t.Fatalf("CreateTeacher(%s): %v", email, err)
+	}
+	return uuid
+}
+
+func createExam(t *testing.T, repo Repository, name string) int64 {
+	t.Helper()
+
+	id, err := repo.CreateExam(context.Background(), commands.CreateExam{
+		Name:           name,
+		AssessmentType: valueobjects.AssessmentExam,
+	})
+	if err != nil {
+		t.Fatalf("CreateExam(%s): %v", name, err)
+	}
+	return id
+}
+
+func createDebt(t *testing.T, repo Repository, examID int64, studentUUID, teacherUUID string) int64 {
+	t.Helper()
+
+	id, err := repo.CreateDebt(context.Background(), commands.CreateDebt{
+		ExamID:      examID,
+		StudentUUID: studentUUID,
+		TeacherUUID: teacherUUID,
+	})
+	if err != nil {
+		t.Fatalf("CreateDebt(%d, %s, %s): %v", examID, studentUUID, teacherUUID, err)
+	}
+	return id
+}
+
+// checkPages pages through all with a limit of 2 and checks the pages hold
+// every row exactly once. Only the set is compared, none of the queries
+// promise an order.
+func checkPages[T any, K cmp.Ordered](t *testing.T, all []T, key func(T) K, get func(limit, offset int64) ([]T, error)) {
+	t.Helper()
+
+	var paged []K
+	for offset := int64(0); offset <= int64(len(all)); offset += 2 {
+		rows, err := get(2, offset)
+		if err != nil {
+			t.Fatalf("limit 2 offset %d: %v", offset, err)
+		}
+		if want := min(2, len(all)-int(offset)); len(rows) != want {
+			t.Fatalf("limit 2 offset %d: got %d rows, want %d", offset, len(rows), want)
+		}
+		for _, row := range rows {
+			paged = append(paged, key(row))
+		}
+	}
+
+	if want := keys(all, key); !slices.Equal(sorted(paged), want) {
+		t.Errorf("pages = %v, want %v", sorted(paged), want)
+	}
+}
+
+// checkKeyset pages through the rows two at a time in the order of the sort,
+// every next page starts after the sort values of the last row of the
+// previous one, and checks the order of all of them.
+func checkKeyset[T any, K comparable](t *testing.T, fields []query.SortField[T], sort []query.Sort, key func(T) K, get func(keyset query.Keyset, limit int64) ([]T, error), want ...K) {
+	t.Helper()
+
+	keyset := query.Keyset{Sort: sort}
+	var got []K
+	for range len(want) + 1 {
+		rows, err := get(keyset, 2)
+		if err != nil {
+			t.Fatalf("sort %v after %v: %v", sort, keyset.After, err)
+		}
+		for _, row := range rows {
+			got = append(got, key(row))
+		}
+		if len(rows) < 2 {
+			break
+		}
+
+		last := rows[len(rows)-1]
+		keyset.After = make([]string, len(sort))
+		for i, s := range sort {
+			index := slices.IndexFunc(fields, func(field query.SortField[T]) bool { return field.Name == s.Field })
+			keyset.After[i] = fields[index].Value(last)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("sort %v = %v, want %v", sort, got, want)
+	}
+}
+
+func checkCount(t *testing.T, name string, count int64, err error, want int64) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if count != want {
+		t.Errorf("%s = %d, want %d", name, count, want)
+	}
+}
+
+// keys returns the sorted keys of the rows.
+func keys[T any, K cmp.Ordered](rows []T, key func(T) K) []K {
+	result := make([]K, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, key(row))
+	}
+	return sorted(result)
+}
+
+func sorted[K cmp.Ordered](values []K) []K {
+	result := slices.Clone(values)
+	slices.Sort(result)
+	return result
+}
+
+func checkKeys[T any, K cmp.Ordered](t *testing.T, name string, rows []T, key func(T) K, want ...K) {
+	t.Helper()
+
+	if got := keys(rows, key); !slices.Equal(got, sorted(want)) {
+		t.Errorf("%s = %v, want %v", name, got, sorted(want))
+	}
+}
+
+func checkNotFound(t *testing.T, name string, err error) {
+	t.Helper()
+
+	if !e.Is(err, pgx.ErrNoRows) {
+		t.Errorf("%s: err = %v, want pgx.ErrNoRows", name, err)
+	}
+}
+
+func checkUniqueViolation(t *testing.T, name string, err error) {
+	t.Helper()
+
+	if !tools.IsUniqueViolation(err) {
+		t.Errorf("%s: err = %v, want a unique violation", name, err)
+	}
+}
+
+func checkForeignKeyViolation(t *testing.T, name string, err error) {
+	t.Helper()
+
+	if !tools.IsForeignKeyViolation(err) {
+		t.Errorf("%s: err = %v, want a foreign key violation", name, err)
+	}
+}
+
+// date is a moment in UTC with the precision postgres keeps.
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
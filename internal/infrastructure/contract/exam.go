@@ -0,0 +1,771 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func examID(exam models.Exam) int64               { return exam.ID }
+func debtID(debt models.Debt) int64               { return debt.ID }
+func debtResultID(result models.DebtResult) int64 { return result.ID }
+
+// debts are three debts of two students of different groups to two
+// teachers for two exams:
+//
+//	first:  firstExam,  firstStudent,  firstTeacher
+//	second: secondExam, firstStudent,  secondTeacher
+//	third:  firstExam,  secondStudent, secondTeacher
+type debts struct {
+	firstGroup, secondGroup     int64
+	firstStudent, secondStudent string
+	firstTeacher, secondTeacher string
+	firstExam, secondExam       int64
+	first, second, third        int64
+}
+
+func newDebts(t *testing.T, repo Repository) debts {
+	t.Helper()
+
+	var d debts
+	d.firstGroup = createGroup(t, repo, "ИВТ-41")
+	d.secondGroup = createGroup(t, repo, "ИВТ-42")
+	d.firstStudent = createStudent(t, repo, "Петров", "petrov@example.com", d.firstGroup)
+	d.secondStudent = createStudent(t, repo, "Иванов", "ivanov@example.com", d.secondGroup)
+	d.firstTeacher = createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+	d.secondTeacher = createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+	d.firstExam = createExam(t, repo, "Базы данных")
+	d.secondExam = createExam(t, repo, "Сети")
+	d.first = createDebt(t, repo, d.firstExam, d.firstStudent, d.firstTeacher)
+	d.second = createDebt(t, repo, d.secondExam, d.firstStudent, d.secondTeacher)
+	d.third = createDebt(t, repo, d.firstExam, d.secondStudent, d.secondTeacher)
+	return d
+}
+
+// getDebt returns the debt closed or not, in the trash or not.
+func getDebt(t *testing.T, repo Repository, id int64) models.Debt {
+	t.Helper()
+
+	debts, err := repo.GetDebts(context.Background(), query.GetDebtsFilters{DebtIDs: []int64{id}, IncludeClosed: true, IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("GetDebts(%d): %v", id, err)
+	}
+	if len(debts) != 1 {
+		t.Fatalf("GetDebts(%d) = %+v, want one debt", id, debts)
+	}
+	return debts[0]
+}
+
+// ExamRepository checks every method of repositories.ExamRepository.
+func ExamRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateExam", func(t *testing.T) {
+		repo := newRepo(t)
+		if id := createExam(t, repo, "Базы данных"); id == 0 {
+			t.Error("id is 0")
+		}
+
+		_, err := repo.CreateExam(ctx, commands.CreateExam{Name: "Сети", AssessmentType: "oral"})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("CreateExam with an unknown assessment type: err = %v, want ErrInvalidCommand", err)
+		}
+	})
+
+	t.Run("GetExamByID", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createExam(t, repo, "Базы данных")
+
+		exam, err := repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{id}})
+		if err != nil {
+			t.Fatalf("GetExamByID: %v", err)
+		}
+		if want := (models.Exam{ID: id, Name: "Базы данных", AssessmentType: valueobjects.AssessmentExam, Version: 1}); *exam != want {
+			t.Errorf("exam = %+v, want %+v", *exam, want)
+		}
+
+		_, err = repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{missingID}})
+		checkNotFound(t, "GetExamByID of a missing exam", err)
+	})
+
+	t.Run("GetExams", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createExam(t, repo, "Алгебра")
+		b := createExam(t, repo, "Геометрия")
+		c := createExam(t, repo, "Физика")
+
+		_, err := repo.GetExams(ctx, query.GetExamsFilters{IDs: []int64{0}})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetExams with a zero id: err = %v, want ErrInvalidFilters", err)
+		}
+
+		all, err := repo.GetExams(ctx, query.GetExamsFilters{})
+		if err != nil {
+			t.Fatalf("GetExams: %v", err)
+		}
+		checkKeys(t, "GetExams", all, examID, a, b, c)
+
+		exams, err := repo.GetExams(ctx, query.GetExamsFilters{IDs: []int64{b, c, missingID}})
+		if err != nil {
+			t.Fatalf("GetExams by ids: %v", err)
+		}
+		checkKeys(t, "GetExams by ids", exams, examID, b, c)
+
+		exams, err = repo.GetExams(ctx, query.GetExamsFilters{Names: []string{"Алгебра", "Физика"}, AssessmentTypes: []string{valueobjects.AssessmentExam}})
+		if err != nil {
+			t.Fatalf("GetExams by names and assessment types: %v", err)
+		}
+		checkKeys(t, "GetExams by names and assessment types", exams, examID, a, c)
+
+		checkPages(t, all, examID, func(limit, offset int64) ([]models.Exam, error) {
+			return repo.GetExams(ctx, query.GetExamsFilters{Limit: limit, Offset: offset})
+		})
+		checkKeyset(t, query.ExamSortFields, []query.Sort{{Field: "name", Desc: true}, {Field: "id"}}, examID,
+			func(keyset query.Keyset, limit int64) ([]models.Exam, error) {
+				return repo.GetExams(ctx, query.GetExamsFilters{Keyset: keyset, Limit: limit})
+			}, c, b, a)
+
+		for _, keyset := range []query.Keyset{
+			{Sort: []query.Sort{{Field: "room"}}},
+			{Sort: []query.Sort{{Field: "name"}, {Field: "id"}}, After: []string{"Алгебра"}},
+		} {
+			_, err = repo.GetExams(ctx, query.GetExamsFilters{Keyset: keyset})
+			if !e.Is(err, errors.ErrInvalidFilters) {
+				t.Errorf("GetExams with keyset %+v: err = %v, want ErrInvalidFilters", keyset, err)
+			}
+		}
+	})
+
+	t.Run("CountExams", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createExam(t, repo, "Алгебра")
+		createExam(t, repo, "Геометрия")
+		createExam(t, repo, "Физика")
+
+		count, err := repo.CountExams(ctx, query.GetExamsFilters{})
+		checkCount(t, "CountExams", count, err, 3)
+
+		// limit, offset and cursor do not change the count
+		count, err = repo.CountExams(ctx, query.GetExamsFilters{
+			Names:  []string{"Алгебра", "Физика"},
+			Limit:  1,
+			Offset: 1,
+			Keyset: query.Keyset{Sort: []query.Sort{{Field: "id"}}, After: []string{strconv.FormatInt(a, 10)}},
+		})
+		checkCount(t, "CountExams by names", count, err, 2)
+	})
+
+	t.Run("SearchExams", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createExam(t, repo, "Алгебра")
+		b := createExam(t, repo, "Геометрия")
+		createExam(t, repo, "Физика")
+
+		exams, err := repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Алгебра", "Геометрия"}})
+		if err != nil {
+			t.Fatalf("SearchExams by names: %v", err)
+		}
+		checkKeys(t, "SearchExams by names", exams, examID, a, b)
+
+		exams, err = repo.SearchExams(ctx, query.SearchExamFilters{IDs: []int64{a}, Names: []string{"Геометрия"}})
+		if err != nil {
+			t.Fatalf("SearchExams by ids and names: %v", err)
+		}
+		if len(exams) != 0 {
+			t.Errorf("SearchExams by ids and names = %+v, want none", exams)
+		}
+	})
+
+	t.Run("UpdateExam", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createExam(t, repo, "Базы данных")
+
+		if err := repo.UpdateExam(ctx, commands.UpdateExamByID{ID: id, Name: "СУБД", AssessmentType: valueobjects.AssessmentCredit}); err != nil {
+			t.Fatalf("UpdateExam: %v", err)
+		}
+		// an empty assessment type keeps the current one
+		if err := repo.UpdateExam(ctx, commands.UpdateExamByID{ID: id, Name: "Базы данных"}); err != nil {
+			t.Fatalf("UpdateExam without an assessment type: %v", err)
+		}
+		exam, err := repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{id}})
+		if err != nil {
+			t.Fatalf("GetExamByID: %v", err)
+		}
+		if want := (models.Exam{ID: id, Name: "Базы данных", AssessmentType: valueobjects.AssessmentCredit, Version: 3}); *exam != want {
+			t.Errorf("exam = %+v, want %+v", *exam, want)
+		}
+
+		err = repo.UpdateExam(ctx, commands.UpdateExamByID{ID: id, Name: "СУБД", AssessmentType: "oral"})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("UpdateExam to an unknown assessment type: err = %v, want ErrInvalidCommand", err)
+		}
+
+		if err := repo.UpdateExam(ctx, commands.UpdateExamByID{ID: missingID, Name: "СУБД"}); err != nil {
+			t.Errorf("UpdateExam of a missing exam: %v", err)
+		}
+	})
+
+	t.Run("UpdateExamVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createExam(t, repo, "Базы данных")
+
+		if err := repo.UpdateExam(ctx, commands.UpdateExamByID{ID: id, Name: "СУБД", Version: 1}); err != nil {
+			t.Fatalf("UpdateExam of the current version: %v", err)
+		}
+		err := repo.UpdateExam(ctx, commands.UpdateExamByID{ID: id, Name: "Базы данных", Version: 1})
+		if !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateExam of a stale version: err = %v, want ErrVersionMismatch", err)
+		}
+		exam, err := repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{id}})
+		if err != nil {
+			t.Fatalf("GetExamByID: %v", err)
+		}
+		if exam.Name != "СУБД" || exam.Version != 2 {
+			t.Errorf("exam = %+v, want the first update only", *exam)
+		}
+
+		err = repo.UpdateExam(ctx, commands.UpdateExamByID{ID: missingID, Name: "СУБД", Version: 1})
+		if !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("UpdateExam of a missing exam with a version: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("DeleteExam", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		free := createExam(t, repo, "Физика")
+
+		if err := repo.DeleteExam(ctx, commands.DeleteExam{ID: free}); err != nil {
+			t.Fatalf("DeleteExam: %v", err)
+		}
+		_, err := repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{free}})
+		checkNotFound(t, "GetExamByID of a deleted exam", err)
+
+		if err := repo.DeleteExam(ctx, commands.DeleteExam{ID: d.firstExam}); err != nil {
+			t.Fatalf("DeleteExam with debts: %v", err)
+		}
+		_, err = repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{d.firstExam}})
+		checkNotFound(t, "GetExamByID of a deleted exam with debts", err)
+		if debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{d.first}}); err != nil || len(debts) != 0 {
+			t.Errorf("GetDebts of a deleted exam = %+v, %v, want none", debts, err)
+		}
+		if debt := getDebt(t, repo, d.first); debt.Exam == nil || debt.Exam.ID != d.firstExam {
+			t.Errorf("debt = %+v, want the debt of the deleted exam", debt)
+		}
+		found, err := repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Физика"}})
+		if err != nil {
+			t.Fatalf("SearchExams: %v", err)
+		}
+		checkKeys(t, "SearchExams after the deletes", found, examID)
+		found, err = repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Физика"}, Deleted: true})
+		if err != nil {
+			t.Fatalf("SearchExams in the trash: %v", err)
+		}
+		checkKeys(t, "SearchExams in the trash", found, examID, free)
+
+		if err := repo.DeleteExam(ctx, commands.DeleteExam{ID: missingID}); err != nil {
+			t.Errorf("DeleteExam of a missing exam: %v", err)
+		}
+	})
+
+	t.Run("CreateDebt", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+
+		debt := getDebt(t, repo, d.first)
+		if debt.Date != nil || debt.ClosedAt != nil || debt.Room != nil || debt.Address != "" {
+			t.Errorf("debt = %+v, want a debt that is not scheduled", debt)
+		}
+		if debt.Exam.ID != d.firstExam || debt.Exam.Name != "Базы данных" || debt.Exam.AssessmentType != valueobjects.AssessmentExam {
+			t.Errorf("exam = %+v", *debt.Exam)
+		}
+		if debt.Student.UUID != d.firstStudent || debt.Student.Email != "petrov@example.com" ||
+			*debt.Student.Group != (models.Group{ID: d.firstGroup, Name: "ИВТ-41"}) {
+			t.Errorf("student = %+v, group = %+v", *debt.Student, *debt.Student.Group)
+		}
+		if debt.Teacher.UUID != d.firstTeacher || debt.Teacher.Email != "sidorova@example.com" {
+			t.Errorf("teacher = %+v", *debt.Teacher)
+		}
+
+		_, err := repo.CreateDebt(ctx, commands.CreateDebt{ExamID: missingID, StudentUUID: d.firstStudent, TeacherUUID: d.firstTeacher})
+		checkForeignKeyViolation(t, "CreateDebt for a missing exam", err)
+		_, err = repo.CreateDebt(ctx, commands.CreateDebt{ExamID: d.firstExam, StudentUUID: missingUUID, TeacherUUID: d.firstTeacher})
+		checkForeignKeyViolation(t, "CreateDebt of a missing student", err)
+		_, err = repo.CreateDebt(ctx, commands.CreateDebt{ExamID: d.firstExam, StudentUUID: d.firstStudent, TeacherUUID: missingUUID})
+		checkForeignKeyViolation(t, "CreateDebt to a missing teacher", err)
+	})
+
+	t.Run("GetDebts", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		for id, update := range map[int64]commands.UpdateDebtByID{
+			d.first: {Date: date(2025, 6, 10, 10), StudentUUID: d.firstStudent, TeacherUUID: d.firstTeacher},
+			d.third: {Date: date(2025, 6, 20, 10), StudentUUID: d.secondStudent, TeacherUUID: d.secondTeacher},
+		} {
+			update.DebtID = id
+			if err := repo.UpdateDebt(ctx, update); err != nil {
+				t.Fatalf("UpdateDebt(%d): %v", id, err)
+			}
+		}
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.second}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+
+		_, err := repo.GetDebts(ctx, query.GetDebtsFilters{StudentUUIDs: []string{""}})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetDebts with an empty uuid: err = %v, want ErrInvalidFilters", err)
+		}
+
+		for _, tc := range []struct {
+			name    string
+			filters query.GetDebtsFilters
+			want    []int64
+		}{
+			{"open", query.GetDebtsFilters{}, []int64{d.first, d.third}},
+			{"with closed", query.GetDebtsFilters{IncludeClosed: true}, []int64{d.first, d.second, d.third}},
+			{"by students", query.GetDebtsFilters{StudentUUIDs: []string{d.firstStudent}, IncludeClosed: true}, []int64{d.first, d.second}},
+			{"by teachers", query.GetDebtsFilters{TeacherUUIDs: []string{d.secondTeacher}}, []int64{d.third}},
+			{"by exams", query.GetDebtsFilters{ExamIDs: []int64{d.firstExam}}, []int64{d.first, d.third}},
+			{"by debts", query.GetDebtsFilters{DebtIDs: []int64{d.first, d.second}}, []int64{d.first}},
+			{"by groups", query.GetDebtsFilters{GroupIDs: []int64{d.secondGroup}}, []int64{d.third}},
+			{"unscheduled", query.GetDebtsFilters{OnlyUnscheduled: true, IncludeClosed: true}, []int64{d.second}},
+			{"scheduled from", query.GetDebtsFilters{ScheduledFrom: date(2025, 6, 20, 10)}, []int64{d.third}},
+			{"scheduled to", query.GetDebtsFilters{ScheduledTo: date(2025, 6, 20, 10)}, []int64{d.first}},
+			{"scheduled between", query.GetDebtsFilters{ScheduledFrom: date(2025, 6, 10, 11), ScheduledTo: date(2025, 6, 20, 11)}, []int64{d.third}},
+		} {
+			debts, err := repo.GetDebts(ctx, tc.filters)
+			if err != nil {
+				t.Fatalf("GetDebts %s: %v", tc.name, err)
+			}
+			checkKeys(t, "GetDebts "+tc.name, debts, debtID, tc.want...)
+		}
+
+		all, err := repo.GetDebts(ctx, query.GetDebtsFilters{IncludeClosed: true})
+		if err != nil {
+			t.Fatalf("GetDebts: %v", err)
+		}
+		checkPages(t, all, debtID, func(limit, offset int64) ([]models.Debt, error) {
+			return repo.GetDebts(ctx, query.GetDebtsFilters{IncludeClosed: true, Limit: limit, Offset: offset})
+		})
+
+		// an unscheduled or an open debt sorts as if its date was infinity
+		for _, tc := range []struct {
+			sort []query.Sort
+			want []int64
+		}{
+			{[]query.Sort{{Field: "date"}, {Field: "id"}}, []int64{d.first, d.third, d.second}},
+			{[]query.Sort{{Field: "closed_at", Desc: true}, {Field: "id", Desc: true}}, []int64{d.third, d.first, d.second}},
+			{[]query.Sort{{Field: "group"}, {Field: "exam", Desc: true}, {Field: "id"}}, []int64{d.second, d.first, d.third}},
+			{[]query.Sort{{Field: "teacher"}, {Field: "student"}, {Field: "id"}}, []int64{d.third, d.second, d.first}},
+		} {
+			checkKeyset(t, query.DebtSortFields, tc.sort, debtID, func(keyset query.Keyset, limit int64) ([]models.Debt, error) {
+				return repo.GetDebts(ctx, query.GetDebtsFilters{IncludeClosed: true, Keyset: keyset, Limit: limit})
+			}, tc.want...)
+		}
+	})
+
+	t.Run("GetDebtsWithExamGroups", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+
+		examGroups := func(t *testing.T) map[int64][]int64 {
+			t.Helper()
+
+			debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{TeacherUUIDs: []string{d.secondTeacher}, WithExamGroups: true})
+			if err != nil {
+				t.Fatalf("GetDebts with exam groups: %v", err)
+			}
+			// the groups are ordered by id, they are not sorted here
+			result := make(map[int64][]int64)
+			for _, debt := range debts {
+				for _, group := range debt.GroupList {
+					result[debt.ID] = append(result[debt.ID], group.ID)
+				}
+			}
+			return result
+		}
+
+		got := examGroups(t)
+		if want := []int64{d.firstGroup}; !slices.Equal(got[d.second], want) {
+			t.Errorf("groups of the second debt = %v, want %v", got[d.second], want)
+		}
+		// the first debt is of another teacher and still counts
+		if want := []int64{d.firstGroup, d.secondGroup}; !slices.Equal(got[d.third], want) {
+			t.Errorf("groups of the third debt = %v, want %v", got[d.third], want)
+		}
+		if len(got) != 2 {
+			t.Errorf("debts = %v, want the second and the third", got)
+		}
+
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.first}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+		if got := examGroups(t); !slices.Equal(got[d.third], []int64{d.secondGroup}) {
+			t.Errorf("groups of the third debt after the first is closed = %v, want %v", got[d.third], []int64{d.secondGroup})
+		}
+
+		if debt := getDebt(t, repo, d.third); debt.GroupList != nil {
+			t.Errorf("GroupList without WithExamGroups = %+v, want nil", debt.GroupList)
+		}
+	})
+
+	t.Run("GetDebtsOfDeleted", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		fourth := createDebt(t, repo, d.secondExam, d.secondStudent, d.firstTeacher)
+
+		// the first debt goes with its exam, the second with its teacher,
+		// the third with its student
+		for name, err := range map[string]error{
+			"DeleteExam":    repo.DeleteExam(ctx, commands.DeleteExam{ID: d.firstExam}),
+			"DeleteTeacher": repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: d.secondTeacher}),
+		} {
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		fifth := createDebt(t, repo, d.secondExam, createStudent(t, repo, "Орлов", "orlov@example.com", d.firstGroup), d.firstTeacher)
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: d.secondStudent}); err != nil {
+			t.Fatalf("DeleteStudent: %v", err)
+		}
+
+		debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{})
+		if err != nil {
+			t.Fatalf("GetDebts: %v", err)
+		}
+		checkKeys(t, "GetDebts", debts, debtID, fifth)
+		count, err := repo.CountDebts(ctx, query.GetDebtsFilters{})
+		checkCount(t, "CountDebts", count, err, 1)
+
+		debts, err = repo.GetDebts(ctx, query.GetDebtsFilters{IncludeDeleted: true})
+		if err != nil {
+			t.Fatalf("GetDebts with deleted: %v", err)
+		}
+		checkKeys(t, "GetDebts with deleted", debts, debtID, d.first, d.second, d.third, fourth, fifth)
+		count, err = repo.CountDebts(ctx, query.GetDebtsFilters{IncludeDeleted: true})
+		checkCount(t, "CountDebts with deleted", count, err, 5)
+
+		found, err := repo.SearchDebts(ctx, query.SearchDebtsFilters{})
+		if err != nil {
+			t.Fatalf("SearchDebts: %v", err)
+		}
+		checkKeys(t, "SearchDebts", found, debtID, fifth)
+
+		// the group of the trashed student is not one of the exam any more
+		debts, err = repo.GetDebts(ctx, query.GetDebtsFilters{WithExamGroups: true})
+		if err != nil || len(debts) != 1 {
+			t.Fatalf("GetDebts with exam groups = %+v, %v, want the fifth debt", debts, err)
+		}
+		if groups := debts[0].GroupList; len(groups) != 1 || groups[0].ID != d.firstGroup {
+			t.Errorf("groups of the fifth debt = %+v, want the first group only", groups)
+		}
+	})
+
+	t.Run("CountDebts", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.second}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+
+		count, err := repo.CountDebts(ctx, query.GetDebtsFilters{})
+		checkCount(t, "CountDebts", count, err, 2)
+
+		count, err = repo.CountDebts(ctx, query.GetDebtsFilters{
+			IncludeClosed: true,
+			Limit:         1,
+			Keyset:        query.Keyset{Sort: []query.Sort{{Field: "id"}}, After: []string{strconv.FormatInt(d.third, 10)}},
+		})
+		checkCount(t, "CountDebts with closed", count, err, 3)
+
+		count, err = repo.CountDebts(ctx, query.GetDebtsFilters{GroupIDs: []int64{d.firstGroup}, IncludeClosed: true})
+		checkCount(t, "CountDebts by groups", count, err, 2)
+
+		_, err = repo.CountDebts(ctx, query.GetDebtsFilters{StudentUUIDs: []string{""}})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("CountDebts with an empty uuid: err = %v, want ErrInvalidFilters", err)
+		}
+	})
+
+	t.Run("SearchDebts", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.second}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+
+		for _, tc := range []struct {
+			name    string
+			filters query.SearchDebtsFilters
+			want    []int64
+		}{
+			{"open", query.SearchDebtsFilters{}, []int64{d.first, d.third}},
+			{"by ids", query.SearchDebtsFilters{IDs: []int64{d.first, d.second}}, []int64{d.first}},
+			{"by exam names", query.SearchDebtsFilters{ExamNames: []string{"Базы данных"}}, []int64{d.first, d.third}},
+			{"by students", query.SearchDebtsFilters{StudentUUIDs: []string{d.secondStudent}}, []int64{d.third}},
+			{"by teachers", query.SearchDebtsFilters{TeacherUUIDs: []string{d.firstTeacher}}, []int64{d.first}},
+			{"by emails", query.SearchDebtsFilters{
+				ExamNames:     []string{"Базы данных"},
+				StudentEmails: []string{"ivanov@example.com"},
+				TeacherEmails: []string{"kuznetsova@example.com"},
+			}, []int64{d.third}},
+			{"closed", query.SearchDebtsFilters{ExamNames: []string{"Сети"}}, nil},
+		} {
+			debts, err := repo.SearchDebts(ctx, tc.filters)
+			if err != nil {
+				t.Fatalf("SearchDebts %s: %v", tc.name, err)
+			}
+			checkKeys(t, "SearchDebts "+tc.name, debts, debtID, tc.want...)
+		}
+
+		debts, err := repo.SearchDebts(ctx, query.SearchDebtsFilters{IDs: []int64{d.third}})
+		if err != nil || len(debts) != 1 {
+			t.Fatalf("SearchDebts(%d) = %+v, %v", d.third, debts, err)
+		}
+		if group := debts[0].Student.Group; group == nil || *group != (models.Group{ID: d.secondGroup, Name: "ИВТ-42"}) {
+			t.Errorf("group = %+v, want the joined group", group)
+		}
+	})
+
+	t.Run("UpdateDebt", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+
+		if err := repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+			DebtID:      d.first,
+			Date:        date(2025, 6, 10, 10),
+			Address:     "ул. Ленина, 1",
+			StudentUUID: d.secondStudent,
+			TeacherUUID: d.secondTeacher,
+		}); err != nil {
+			t.Fatalf("UpdateDebt: %v", err)
+		}
+		debt := getDebt(t, repo, d.first)
+		if debt.Date == nil || !debt.Date.Equal(date(2025, 6, 10, 10)) || debt.Address != "ул. Ленина, 1" ||
+			debt.Student.UUID != d.secondStudent || debt.Teacher.UUID != d.secondTeacher || debt.Room != nil {
+			t.Errorf("debt = %+v, want the new date, address, student and teacher", debt)
+		}
+
+		// zero date unschedules the debt
+		if err := repo.UpdateDebt(ctx, commands.UpdateDebtByID{DebtID: d.first, StudentUUID: d.secondStudent, TeacherUUID: d.secondTeacher}); err != nil {
+			t.Fatalf("UpdateDebt with a zero date: %v", err)
+		}
+		if debt := getDebt(t, repo, d.first); debt.Date != nil {
+			t.Errorf("date = %v, want none", debt.Date)
+		}
+
+		err := repo.UpdateDebt(ctx, commands.UpdateDebtByID{DebtID: d.first, StudentUUID: d.firstStudent, TeacherUUID: missingUUID})
+		checkForeignKeyViolation(t, "UpdateDebt to a missing teacher", err)
+		err = repo.UpdateDebt(ctx, commands.UpdateDebtByID{DebtID: d.first, StudentUUID: d.firstStudent, TeacherUUID: d.firstTeacher, RoomID: missingID})
+		checkForeignKeyViolation(t, "UpdateDebt to a missing room", err)
+
+		if err := repo.UpdateDebt(ctx, commands.UpdateDebtByID{DebtID: missingID, StudentUUID: d.firstStudent, TeacherUUID: d.firstTeacher}); err != nil {
+			t.Errorf("UpdateDebt of a missing debt: %v", err)
+		}
+	})
+
+	t.Run("UpdateDebtVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		update := commands.UpdateDebtByID{DebtID: d.first, Address: "ул. Ленина, 1", StudentUUID: d.firstStudent, TeacherUUID: d.firstTeacher}
+
+		if debt := getDebt(t, repo, d.first); debt.Version != 1 {
+			t.Errorf("version of a new debt = %d, want 1", debt.Version)
+		}
+		update.Version = 1
+		if err := repo.UpdateDebt(ctx, update); err != nil {
+			t.Fatalf("UpdateDebt of the current version: %v", err)
+		}
+		stale := update
+		stale.Address = "ул. Мира, 2"
+		if err := repo.UpdateDebt(ctx, stale); !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateDebt of a stale version: err = %v, want ErrVersionMismatch", err)
+		}
+		if debt := getDebt(t, repo, d.first); debt.Address != "ул. Ленина, 1" || debt.Version != 2 {
+			t.Errorf("debt = %+v, want the first update only", debt)
+		}
+
+		// closing is an update as well
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.first}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+		update.Version = 2
+		if err := repo.UpdateDebt(ctx, update); !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateDebt of the version before the close: err = %v, want ErrVersionMismatch", err)
+		}
+
+		update.DebtID = missingID
+		if err := repo.UpdateDebt(ctx, update); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("UpdateDebt of a missing debt with a version: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("DeleteDebt", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		if _, err := repo.CreateDebtResult(ctx, commands.CreateDebtResult{
+			DebtID:      d.first,
+			TeacherUUID: d.firstTeacher,
+			AttemptedAt: date(2025, 6, 10, 10),
+		}); err != nil {
+			t.Fatalf("CreateDebtResult: %v", err)
+		}
+
+		// results go with the debt
+		if err := repo.DeleteDebt(ctx, commands.DeleteDebt{ID: d.first}); err != nil {
+			t.Fatalf("DeleteDebt: %v", err)
+		}
+		debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{IncludeClosed: true})
+		if err != nil {
+			t.Fatalf("GetDebts: %v", err)
+		}
+		checkKeys(t, "GetDebts after a delete", debts, debtID, d.second, d.third)
+		results, err := repo.GetDebtResults(ctx, query.GetDebtResultsFilters{DebtIDs: []int64{d.first}})
+		if err != nil {
+			t.Fatalf("GetDebtResults: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("results of a deleted debt = %+v, want none", results)
+		}
+
+		if err := repo.DeleteDebt(ctx, commands.DeleteDebt{ID: missingID}); err != nil {
+			t.Errorf("DeleteDebt of a missing debt: %v", err)
+		}
+	})
+
+	t.Run("CreateDebtResult", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		result := commands.CreateDebtResult{
+			DebtID:      d.first,
+			TeacherUUID: d.firstTeacher,
+			Passed:      true,
+			Grade:       4,
+			AttemptedAt: date(2025, 6, 10, 10),
+		}
+		if id, err := repo.CreateDebtResult(ctx, result); err != nil || id == 0 {
+			t.Fatalf("CreateDebtResult = %d, %v", id, err)
+		}
+
+		_, err := repo.CreateDebtResult(ctx, result)
+		if !e.Is(err, errors.ErrResultAlreadyRecorded) {
+			t.Errorf("CreateDebtResult of a recorded attempt: err = %v, want ErrResultAlreadyRecorded", err)
+		}
+
+		_, err = repo.CreateDebtResult(ctx, commands.CreateDebtResult{DebtID: d.first, AttemptedAt: date(2025, 6, 11, 10)})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("CreateDebtResult without a teacher: err = %v, want ErrInvalidCommand", err)
+		}
+		_, err = repo.CreateDebtResult(ctx, commands.CreateDebtResult{DebtID: d.first, TeacherUUID: d.firstTeacher, Grade: 6, AttemptedAt: date(2025, 6, 11, 10)})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("CreateDebtResult with a grade of 6: err = %v, want ErrInvalidCommand", err)
+		}
+
+		_, err = repo.CreateDebtResult(ctx, commands.CreateDebtResult{DebtID: missingID, TeacherUUID: d.firstTeacher, AttemptedAt: date(2025, 6, 11, 10)})
+		checkForeignKeyViolation(t, "CreateDebtResult of a missing debt", err)
+	})
+
+	t.Run("GetDebtResults", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+		var ids []int64
+		for _, result := range []commands.CreateDebtResult{
+			{DebtID: d.first, TeacherUUID: d.firstTeacher, Passed: true, Grade: 4, AttemptedAt: date(2025, 6, 20, 10)},
+			{DebtID: d.first, TeacherUUID: d.firstTeacher, AttemptedAt: date(2025, 6, 10, 10)},
+			{DebtID: d.third, TeacherUUID: d.secondTeacher, Passed: true, AttemptedAt: date(2025, 6, 15, 10)},
+		} {
+			id, err := repo.CreateDebtResult(ctx, result)
+			if err != nil {
+				t.Fatalf("CreateDebtResult: %v", err)
+			}
+			ids = append(ids, id)
+		}
+
+		_, err := repo.GetDebtResults(ctx, query.GetDebtResultsFilters{StudentUUIDs: []string{""}})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetDebtResults with an empty uuid: err = %v, want ErrInvalidFilters", err)
+		}
+
+		// results are ordered by the attempt
+		results, err := repo.GetDebtResults(ctx, query.GetDebtResultsFilters{DebtIDs: []int64{d.first}})
+		if err != nil {
+			t.Fatalf("GetDebtResults by debts: %v", err)
+		}
+		if len(results) != 2 || results[0].ID != ids[1] || results[1].ID != ids[0] {
+			t.Fatalf("GetDebtResults by debts = %+v, want results %d and %d", results, ids[1], ids[0])
+		}
+		got := results[1]
+		if got.DebtID != d.first || got.TeacherUUID != d.firstTeacher || !got.Passed || got.Grade != 4 ||
+			got.SessionID != 0 || !got.AttemptedAt.Equal(date(2025, 6, 20, 10)) || got.RecordedAt.IsZero() {
+			t.Errorf("result = %+v", got)
+		}
+		if results[0].Grade != 0 || results[0].Passed {
+			t.Errorf("result = %+v, want a failed attempt without a grade", results[0])
+		}
+
+		results, err = repo.GetDebtResults(ctx, query.GetDebtResultsFilters{StudentUUIDs: []string{d.secondStudent}})
+		if err != nil {
+			t.Fatalf("GetDebtResults by students: %v", err)
+		}
+		checkKeys(t, "GetDebtResults by students", results, debtResultID, ids[2])
+
+		results, err = repo.GetDebtResults(ctx, query.GetDebtResultsFilters{SessionIDs: []int64{missingID}})
+		if err != nil {
+			t.Fatalf("GetDebtResults by sessions: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("GetDebtResults by sessions = %+v, want none", results)
+		}
+	})
+
+	t.Run("CloseDebt", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.first}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+		closed := getDebt(t, repo, d.first)
+		if closed.ClosedAt == nil {
+			t.Fatal("closed_at is not set")
+		}
+
+		// a closed debt keeps the time it was closed first
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: d.first}); !e.Is(err, errors.ErrDebtClosed) {
+			t.Errorf("CloseDebt of a closed debt: err = %v, want ErrDebtClosed", err)
+		}
+		if again := getDebt(t, repo, d.first); again.ClosedAt == nil || !again.ClosedAt.Equal(*closed.ClosedAt) {
+			t.Errorf("closed_at = %v, want %v", again.ClosedAt, *closed.ClosedAt)
+		}
+
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: missingID}); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("CloseDebt of a missing debt: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("LockDebt", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newDebts(t, repo)
+
+		if err := repo.LockDebt(ctx, d.first); !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("LockDebt outside a transaction: err = %v, want ErrInvalidCommand", err)
+		}
+		err := repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			if err := repo.LockDebt(ctx, missingID); !e.Is(err, errors.ErroNoItemsFound) {
+				t.Errorf("LockDebt of a missing debt: err = %v, want ErroNoItemsFound", err)
+			}
+			return repo.LockDebt(ctx, d.first)
+		})
+		if err != nil {
+			t.Errorf("LockDebt: %v", err)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func groupID(group models.Group) int64 { return group.ID }
+
+// GroupRepository checks every method of repositories.GroupRepository.
+func GroupRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateGroup", func(t *testing.T) {
+		repo := newRepo(t)
+		first := createGroup(t, repo, "ИВТ-41")
+		// names are not unique
+		second := createGroup(t, repo, "ИВТ-41")
+		if first == 0 || first == second {
+			t.Errorf("ids = %d, %d, want two different ids", first, second)
+		}
+	})
+
+	t.Run("GetGroupByID", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createGroup(t, repo, "ИВТ-41")
+
+		group, err := repo.GetGroupByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetGroupByID: %v", err)
+		}
+		if *group != (models.Group{ID: id, Name: "ИВТ-41", Version: 1}) {
+			t.Errorf("group = %+v", *group)
+		}
+
+		_, err = repo.GetGroupByID(ctx, missingID)
+		checkNotFound(t, "GetGroupByID of a missing group", err)
+	})
+
+	t.Run("GetGroups", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createGroup(t, repo, "ИВТ-41")
+		b := createGroup(t, repo, "ИВТ-42")
+		c := createGroup(t, repo, "ПМИ-31")
+
+		all, err := repo.GetGroups(ctx, query.GetGroupsFilters{})
+		if err != nil {
+			t.Fatalf("GetGroups: %v", err)
+		}
+		checkKeys(t, "GetGroups", all, groupID, a, b, c)
+
+		groups, err := repo.GetGroups(ctx, query.GetGroupsFilters{IDs: []int64{a, c, missingID}})
+		if err != nil {
+			t.Fatalf("GetGroups by ids: %v", err)
+		}
+		checkKeys(t, "GetGroups by ids", groups, groupID, a, c)
+
+		groups, err = repo.GetGroups(ctx, query.GetGroupsFilters{Names: []string{"ИВТ-42", "ПМИ-31"}})
+		if err != nil {
+			t.Fatalf("GetGroups by names: %v", err)
+		}
+		checkKeys(t, "GetGroups by names", groups, groupID, b, c)
+
+		checkPages(t, all, groupID, func(limit, offset int64) ([]models.Group, error) {
+			return repo.GetGroups(ctx, query.GetGroupsFilters{Limit: limit, Offset: offset})
+		})
+		checkKeyset(t, query.GroupSortFields, []query.Sort{{Field: "name", Desc: true}, {Field: "id"}}, groupID,
+			func(keyset query.Keyset, limit int64) ([]models.Group, error) {
+				return repo.GetGroups(ctx, query.GetGroupsFilters{Keyset: keyset, Limit: limit})
+			}, c, b, a)
+	})
+
+	t.Run("CountGroups", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createGroup(t, repo, "ИВТ-41")
+		createGroup(t, repo, "ИВТ-42")
+		c := createGroup(t, repo, "ПМИ-31")
+
+		count, err := repo.CountGroups(ctx, query.GetGroupsFilters{Limit: 1, Offset: 1})
+		checkCount(t, "CountGroups", count, err, 3)
+
+		count, err = repo.CountGroups(ctx, query.GetGroupsFilters{IDs: []int64{a, c, missingID}})
+		checkCount(t, "CountGroups by ids", count, err, 2)
+	})
+
+	t.Run("SearchGroups", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createGroup(t, repo, "ИВТ-41")
+		b := createGroup(t, repo, "ИВТ-42")
+		c := createGroup(t, repo, "ИВТ-41")
+
+		groups, err := repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ИВТ-41"}})
+		if err != nil {
+			t.Fatalf("SearchGroups by names: %v", err)
+		}
+		checkKeys(t, "SearchGroups by names", groups, groupID, a, c)
+
+		groups, err = repo.SearchGroups(ctx, query.SearchGroupFilters{IDs: []int64{a, b}, Names: []string{"ИВТ-41"}})
+		if err != nil {
+			t.Fatalf("SearchGroups by ids and names: %v", err)
+		}
+		checkKeys(t, "SearchGroups by ids and names", groups, groupID, a)
+
+		groups, err = repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ПМИ-31"}})
+		if err != nil {
+			t.Fatalf("SearchGroups of a missing name: %v", err)
+		}
+		if len(groups) != 0 {
+			t.Errorf("SearchGroups of a missing name = %+v, want none", groups)
+		}
+	})
+
+	t.Run("UpdateGroup", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createGroup(t, repo, "ИВТ-41")
+
+		if err := repo.UpdateGroup(ctx, commands.UpdateGroup{ID: id, Name: "ИВТ-51"}); err != nil {
+			t.Fatalf("UpdateGroup: %v", err)
+		}
+		group, err := repo.GetGroupByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetGroupByID: %v", err)
+		}
+		if group.Name != "ИВТ-51" {
+			t.Errorf("name = %s, want ИВТ-51", group.Name)
+		}
+
+		if err := repo.UpdateGroup(ctx, commands.UpdateGroup{ID: missingID, Name: "ИВТ-51"}); err != nil {
+			t.Errorf("UpdateGroup of a missing group: %v", err)
+		}
+	})
+
+	t.Run("UpdateGroupVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createGroup(t, repo, "ИВТ-41")
+
+		if err := repo.UpdateGroup(ctx, commands.UpdateGroup{ID: id, Name: "ИВТ-51", Version: 1}); err != nil {
+			t.Fatalf("UpdateGroup of the current version: %v", err)
+		}
+		err := repo.UpdateGroup(ctx, commands.UpdateGroup{ID: id, Name: "ИВТ-61", Version: 1})
+		if !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateGroup of a stale version: err = %v, want ErrVersionMismatch", err)
+		}
+		group, err := repo.GetGroupByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetGroupByID: %v", err)
+		}
+		if *group != (models.Group{ID: id, Name: "ИВТ-51", Version: 2}) {
+			t.Errorf("group = %+v, want the first update only", *group)
+		}
+
+		err = repo.UpdateGroup(ctx, commands.UpdateGroup{ID: missingID, Name: "ИВТ-51", Version: 1})
+		if !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("UpdateGroup of a missing group with a version: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("DeleteGroup", func(t *testing.T) {
+		repo := newRepo(t)
+		empty := createGroup(t, repo, "ИВТ-41")
+		used := createGroup(t, repo, "ИВТ-42")
+		createStudent(t, repo, "Петров", "petrov@example.com", used)
+
+		if err := repo.DeleteGroup(ctx, commands.DeleteGroup{ID: empty}); err != nil {
+			t.Fatalf("DeleteGroup: %v", err)
+		}
+		_, err := repo.GetGroupByID(ctx, empty)
+		checkNotFound(t, "GetGroupByID of a deleted group", err)
+
+		if err := repo.DeleteGroup(ctx, commands.DeleteGroup{ID: used}); err != nil {
+			t.Fatalf("DeleteGroup with students: %v", err)
+		}
+		_, err = repo.GetGroupByID(ctx, used)
+		checkNotFound(t, "GetGroupByID of a deleted group with students", err)
+		groups, err := repo.GetGroups(ctx, query.GetGroupsFilters{})
+		if err != nil {
+			t.Fatalf("GetGroups: %v", err)
+		}
+		checkKeys(t, "GetGroups after the deletes", groups, groupID)
+		found, err := repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ИВТ-41", "ИВТ-42"}})
+		if err != nil {
+			t.Fatalf("SearchGroups: %v", err)
+		}
+		checkKeys(t, "SearchGroups after the deletes", found, groupID)
+		found, err = repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ИВТ-41"}, Deleted: true})
+		if err != nil {
+			t.Fatalf("SearchGroups in the trash: %v", err)
+		}
+		checkKeys(t, "SearchGroups in the trash", found, groupID, empty)
+
+		if err := repo.DeleteGroup(ctx, commands.DeleteGroup{ID: used}); err != nil {
+			t.Errorf("DeleteGroup of a deleted group: %v", err)
+		}
+
+		if err := repo.DeleteGroup(ctx, commands.DeleteGroup{ID: missingID}); err != nil {
+			t.Errorf("DeleteGroup of a missing group: %v", err)
+		}
+	})
+}
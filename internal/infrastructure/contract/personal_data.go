@@ -0,0 +1,265 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func auditID(audit models.PersonalDataAudit) int64 { return audit.ID }
+
+// PersonalDataRepository checks repositories.PersonalDataRepository.
+func PersonalDataRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	setStatus := func(t *testing.T, repo Repository, uuid, status string, year int) {
+		t.Helper()
+
+		cmd := commands.SetStudentStatus{UUID: uuid, Status: status}
+		if year != 0 {
+			cmd.LeftAt = date(year, 6, 30, 12)
+		}
+		if err := repo.SetStudentStatus(ctx, cmd); err != nil {
+			t.Fatalf("SetStudentStatus(%s, %s): %v", uuid, status, err)
+		}
+	}
+
+	t.Run("StudentStatus", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", createGroup(t, repo, "ИВТ-41"))
+
+		status, err := repo.GetStudentStatus(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentStatus: %v", err)
+		}
+		if status.Status != valueobjects.StudentActiveStatus || status.LeftAt != nil || status.AnonymizedAt != nil {
+			t.Errorf("GetStudentStatus = %+v, want an active student", status)
+		}
+
+		setStatus(t, repo, uuid, valueobjects.StudentGraduatedStatus, 2020)
+		status, err = repo.GetStudentStatus(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentStatus: %v", err)
+		}
+		if status.Status != valueobjects.StudentGraduatedStatus || status.LeftAt == nil || !status.LeftAt.Equal(date(2020, 6, 30, 12)) {
+			t.Errorf("GetStudentStatus = %+v, want graduated in 2020", status)
+		}
+		if student, err := repo.GetStudentByUUID(ctx, uuid); err != nil || student.Version != 2 {
+			t.Errorf("GetStudentByUUID = %+v, %v, want version 2", student, err)
+		}
+
+		setStatus(t, repo, uuid, valueobjects.StudentActiveStatus, 0)
+		if status, err := repo.GetStudentStatus(ctx, uuid); err != nil || status.LeftAt != nil {
+			t.Errorf("GetStudentStatus = %+v, %v, want no left at", status, err)
+		}
+
+		err = repo.SetStudentStatus(ctx, commands.SetStudentStatus{UUID: uuid, Status: valueobjects.StudentExpelledStatus})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("SetStudentStatus without left at: err = %v, want ErrInvalidCommand", err)
+		}
+
+		err = repo.SetStudentStatus(ctx, commands.SetStudentStatus{UUID: missingUUID, Status: valueobjects.StudentActiveStatus})
+		if !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("SetStudentStatus of a missing student: err = %v, want ErroNoItemsFound", err)
+		}
+		if _, err := repo.GetStudentStatus(ctx, missingUUID); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("GetStudentStatus of a missing student: err = %v, want ErroNoItemsFound", err)
+		}
+		if _, err := repo.GetStudentPersonalData(ctx, missingUUID); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("GetStudentPersonalData of a missing student: err = %v, want ErroNoItemsFound", err)
+		}
+
+		// a student in the trash still has their personal data
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: uuid}); err != nil {
+			t.Fatalf("DeleteStudent: %v", err)
+		}
+		if status, err := repo.GetStudentStatus(ctx, uuid); err != nil || status.DeletedAt == nil {
+			t.Errorf("GetStudentStatus of a deleted student = %+v, %v, want the time of the delete", status, err)
+		}
+		setStatus(t, repo, uuid, valueobjects.StudentExpelledStatus, 2021)
+		if student, err := repo.GetStudentPersonalData(ctx, uuid); err != nil || student.Email != "petrov@example.com" || student.Group.Name != "ИВТ-41" {
+			t.Errorf("GetStudentPersonalData of a deleted student = %+v, %v, want the student with the group", student, err)
+		}
+	})
+
+	t.Run("AnonymizeStudents", func(t *testing.T) {
+		repo := newRepo(t)
+		group := createGroup(t, repo, "ИВТ-41")
+		graduated := createStudent(t, repo, "Петров", "petrov@example.com", group)
+		expelled := createStudent(t, repo, "Иванов", "ivanov@example.com", group)
+		recent := createStudent(t, repo, "Смирнов", "smirnov@example.com", group)
+		active := createStudent(t, repo, "Кузнецов", "kuznetsov@example.com", group)
+		setStatus(t, repo, graduated, valueobjects.StudentGraduatedStatus, 2015)
+		setStatus(t, repo, expelled, valueobjects.StudentExpelledStatus, 2016)
+		setStatus(t, repo, recent, valueobjects.StudentGraduatedStatus, 2024)
+
+		teacher := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		debt := createDebt(t, repo, createExam(t, repo, "Базы данных"), graduated, teacher)
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: expelled}); err != nil {
+			t.Fatalf("DeleteStudent: %v", err)
+		}
+
+		uuids, err := repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{LeftBefore: date(2020, 1, 1, 0)})
+		if err != nil {
+			t.Fatalf("AnonymizeStudents: %v", err)
+		}
+		checkKeys(t, "AnonymizeStudents", uuids, func(uuid string) string { return uuid }, graduated, expelled)
+
+		student, err := repo.GetStudentByUUID(ctx, graduated)
+		if err != nil {
+			t.Fatalf("GetStudentByUUID: %v", err)
+		}
+		if student.FirstName != "" || student.LastName != "" || student.MiddleName != "" ||
+			student.Email != valueobjects.AnonymizedEmailPrefix+graduated || student.Password != "" {
+			t.Errorf("GetStudentByUUID = %+v, want no personal data", student)
+		}
+		if student.Group == nil || student.Group.ID != group {
+			t.Errorf("GetStudentByUUID = %+v, want the group kept", student)
+		}
+		if status, err := repo.GetStudentStatus(ctx, graduated); err != nil || status.AnonymizedAt == nil {
+			t.Errorf("GetStudentStatus = %+v, %v, want anonymized", status, err)
+		}
+		if got := getDebt(t, repo, debt); got.Student.UUID != graduated {
+			t.Errorf("debt = %+v, want the debt of the anonymized student kept", got)
+		}
+
+		uuids, err = repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{UUIDs: []string{graduated, active}})
+		if err != nil {
+			t.Fatalf("AnonymizeStudents by uuids: %v", err)
+		}
+		checkKeys(t, "AnonymizeStudents by uuids", uuids, func(uuid string) string { return uuid }, active)
+
+		if _, err := repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{}); !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("AnonymizeStudents without students: err = %v, want ErrInvalidCommand", err)
+		}
+	})
+
+	t.Run("AnonymizeStudentsInImports", func(t *testing.T) {
+		repo := newRepo(t)
+		group := createGroup(t, repo, "ИВТ-41")
+		petrov := createStudent(t, repo, "Петров", "petrov@example.com", group)
+		createStudent(t, repo, "Смирнов", "smirnov@example.com", group)
+
+		record := func(row int64, lastName, email string) models.ImportRecord {
+			return models.ImportRecord{
+				Sheet:            "ИВТ-41",
+				Row:              row,
+				Column:           2,
+				GroupName:        "ИВТ-41",
+				StudentLastName:  lastName,
+				StudentFirstName: "Иван",
+				StudentEmail:     email,
+				TeacherLastName:  "Сидорова",
+				TeacherEmail:     "sidorova@example.com",
+				ExamName:         "Базы данных",
+			}
+		}
+		records := []models.ImportRecord{record(2, "Петров", "petrov@example.com"), record(3, "Смирнов", "smirnov@example.com")}
+		issues := []models.ImportIssue{{Sheet: "ИВТ-41", Row: 4, Column: 2, Value: "Петров Иван petrov@example.com", Message: "unknown group"}}
+		if err := repo.CreateImportPreview(ctx, commands.CreateImportPreview{
+			Token:     "token",
+			CreatedBy: "admin",
+			Records:   records,
+			Issues:    issues,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}); err != nil {
+			t.Fatalf("CreateImportPreview: %v", err)
+		}
+		job, err := repo.CreateImportJob(ctx, commands.CreateImportJob{
+			Source:  valueobjects.ImportFileSource,
+			Actor:   "admin",
+			Records: records,
+			Issues:  issues,
+		})
+		if err != nil {
+			t.Fatalf("CreateImportJob: %v", err)
+		}
+		if err := repo.UpdateImportJob(ctx, commands.UpdateImportJob{
+			ID:     job,
+			Status: valueobjects.ImportCompletedStatus,
+			Failed: 1,
+			Errors: []models.ImportIssue{{Sheet: "ИВТ-41", Row: 2, Column: 2, Value: "Базы данных", Message: "email petrov@example.com is taken"}},
+		}); err != nil {
+			t.Fatalf("UpdateImportJob: %v", err)
+		}
+
+		if _, err := repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{UUIDs: []string{petrov}}); err != nil {
+			t.Fatalf("AnonymizeStudents: %v", err)
+		}
+
+		anonymized := record(2, "", valueobjects.AnonymizedEmailPrefix+petrov)
+		anonymized.StudentFirstName = ""
+		wantRecords := []models.ImportRecord{anonymized, records[1]}
+		wantIssue := models.ImportIssue{Sheet: "ИВТ-41", Row: 4, Column: 2, Message: "unknown group"}
+		preview, err := repo.GetImportPreview(ctx, "token")
+		if err != nil {
+			t.Fatalf("GetImportPreview: %v", err)
+		}
+		if !slices.Equal(preview.Records, wantRecords) || len(preview.Issues) != 1 || preview.Issues[0] != wantIssue {
+			t.Errorf("preview = %+v, %+v, want the student anonymized", preview.Records, preview.Issues)
+		}
+		jobs, err := repo.GetImportJobs(ctx, query.GetImportJobsFilters{IDs: []int64{job}, WithRecords: true})
+		if err != nil || len(jobs) != 1 {
+			t.Fatalf("GetImportJobs = %+v, %v, want the job", jobs, err)
+		}
+		wantError := models.ImportIssue{Sheet: "ИВТ-41", Row: 2, Column: 2, Value: "Базы данных", Message: "email " + valueobjects.AnonymizedEmailPrefix + petrov + " is taken"}
+		if !slices.Equal(jobs[0].Records, wantRecords) || len(jobs[0].Issues) != 1 || jobs[0].Issues[0] != wantIssue ||
+			len(jobs[0].Errors) != 1 || jobs[0].Errors[0] != wantError {
+			t.Errorf("job = %+v, %+v, %+v, want the student anonymized", jobs[0].Records, jobs[0].Issues, jobs[0].Errors)
+		}
+	})
+
+	t.Run("PersonalDataAudit", func(t *testing.T) {
+		repo := newRepo(t)
+		group := createGroup(t, repo, "ИВТ-41")
+		petrov := createStudent(t, repo, "Петров", "petrov@example.com", group)
+		ivanov := createStudent(t, repo, "Иванов", "ivanov@example.com", group)
+
+		var ids []int64
+		for _, audit := range []commands.CreatePersonalDataAudit{
+			{StudentUUID: petrov, Action: valueobjects.PersonalDataExportAction, ActorUUID: missingUUID},
+			{StudentUUID: ivanov, Action: valueobjects.PersonalDataAnonymizeAction},
+			{StudentUUID: petrov, Action: valueobjects.PersonalDataEraseAction, ActorUUID: missingUUID},
+		} {
+			id, err := repo.CreatePersonalDataAudit(ctx, audit)
+			if err != nil {
+				t.Fatalf("CreatePersonalDataAudit(%+v): %v", audit, err)
+			}
+			ids = append(ids, id)
+		}
+
+		all, err := repo.GetPersonalDataAudit(ctx, query.GetPersonalDataAuditFilters{})
+		if err != nil {
+			t.Fatalf("GetPersonalDataAudit: %v", err)
+		}
+		checkKeys(t, "GetPersonalDataAudit", all, auditID, ids...)
+		if len(all) != 0 && (all[0].ActorUUID != missingUUID || all[0].CreatedAt.IsZero()) {
+			t.Errorf("audit = %+v, want the actor and the time", all[0])
+		}
+
+		audit, err := repo.GetPersonalDataAudit(ctx, query.GetPersonalDataAuditFilters{StudentUUIDs: []string{petrov}})
+		if err != nil {
+			t.Fatalf("GetPersonalDataAudit of petrov: %v", err)
+		}
+		checkKeys(t, "GetPersonalDataAudit of petrov", audit, auditID, ids[0], ids[2])
+
+		audit, err = repo.GetPersonalDataAudit(ctx, query.GetPersonalDataAuditFilters{Actions: []string{valueobjects.PersonalDataAnonymizeAction}})
+		if err != nil {
+			t.Fatalf("GetPersonalDataAudit of anonymize: %v", err)
+		}
+		checkKeys(t, "GetPersonalDataAudit of anonymize", audit, auditID, ids[1])
+
+		_, err = repo.CreatePersonalDataAudit(ctx, commands.CreatePersonalDataAudit{StudentUUID: petrov, Action: "read"})
+		if !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("CreatePersonalDataAudit of an unknown action: err = %v, want ErrInvalidCommand", err)
+		}
+	})
+}
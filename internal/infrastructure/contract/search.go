@@ -0,0 +1,110 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"strconv"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func searchHitKey(hit models.SearchHit) string { return hit.Kind + ":" + hit.ID }
+
+// SearchRepository checks repositories.SearchRepository. Only the hits the
+// query is a part of are compared, the fuzzy matches and the exact ranks
+// are up to the implementation.
+func SearchRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	repo := newRepo(t)
+	ivt := createGroup(t, repo, "ИВТ-21")
+	pmi := createGroup(t, repo, "ПМИ-22")
+	lavrushko := createStudent(t, repo, "Лаврушко", "lavrushko@example.com", ivt)
+	createStudent(t, repo, "Петров", "petrov@example.com", pmi)
+	lavrov := createTeacher(t, repo, "Лавров", "lavrov@example.com")
+	sidorova := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+	databases := createExam(t, repo, "Базы данных")
+	createExam(t, repo, "Сети")
+
+	search := func(t *testing.T, search query.Search) []models.SearchHit {
+		t.Helper()
+
+		hits, err := repo.Search(ctx, search)
+		if err != nil {
+			t.Fatalf("Search(%+v): %v", search, err)
+		}
+		for i := 1; i < len(hits); i++ {
+			if hits[i].Rank > hits[i-1].Rank {
+				t.Errorf("Search(%+v): hits are not ordered by rank: %+v", search, hits)
+			}
+		}
+		return hits
+	}
+
+	t.Run("ByPrefix", func(t *testing.T) {
+		hits := search(t, query.Search{Query: "лавр"})
+		checkKeys(t, "Search лавр", hits, searchHitKey,
+			valueobjects.SearchStudentKind+":"+lavrushko, valueobjects.SearchTeacherKind+":"+lavrov)
+
+		for _, hit := range hits {
+			if hit.Kind != valueobjects.SearchStudentKind {
+				continue
+			}
+			want := models.SearchHit{
+				Kind:     valueobjects.SearchStudentKind,
+				ID:       lavrushko,
+				Title:    "Лаврушко Иван Сергеевич",
+				Subtitle: "ИВТ-21, lavrushko@example.com",
+				Rank:     hit.Rank,
+			}
+			if hit != want || hit.Rank <= 0 {
+				t.Errorf("hit = %+v, want %+v with a positive rank", hit, want)
+			}
+		}
+	})
+
+	t.Run("ByEmail", func(t *testing.T) {
+		hits := search(t, query.Search{Query: "sidorova@example"})
+		checkKeys(t, "Search by email", hits, searchHitKey, valueobjects.SearchTeacherKind+":"+sidorova)
+	})
+
+	t.Run("GroupsAndExams", func(t *testing.T) {
+		hits := search(t, query.Search{Query: "ивт"})
+		checkKeys(t, "Search ивт", hits, searchHitKey, valueobjects.SearchGroupKind+":"+strconv.FormatInt(ivt, 10))
+
+		hits = search(t, query.Search{Query: "базы"})
+		checkKeys(t, "Search базы", hits, searchHitKey, valueobjects.SearchExamKind+":"+strconv.FormatInt(databases, 10))
+		if len(hits) == 1 && hits[0].Subtitle != valueobjects.AssessmentExam {
+			t.Errorf("hit = %+v, want the assessment type in the subtitle", hits[0])
+		}
+	})
+
+	t.Run("Kinds", func(t *testing.T) {
+		hits := search(t, query.Search{Query: "лавр", Kinds: []string{valueobjects.SearchTeacherKind}})
+		checkKeys(t, "Search лавр in teachers", hits, searchHitKey, valueobjects.SearchTeacherKind+":"+lavrov)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		all := search(t, query.Search{Query: "лавр"})
+		hits := search(t, query.Search{Query: "лавр", Limit: 1})
+		if len(hits) != 1 || len(all) == 0 || hits[0] != all[0] {
+			t.Errorf("Search with limit 1 = %+v, want the first of %+v", hits, all)
+		}
+	})
+
+	t.Run("InvalidFilters", func(t *testing.T) {
+		for name, search := range map[string]query.Search{
+			"a short query":    {Query: " л "},
+			"an unknown kind":  {Query: "лавр", Kinds: []string{"room"}},
+			"a negative limit": {Query: "лавр", Limit: -1},
+		} {
+			if _, err := repo.Search(ctx, search); !e.Is(err, errors.ErrInvalidFilters) {
+				t.Errorf("Search with %s: err = %v, want ErrInvalidFilters", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func studentUUID(student models.Student) string { return student.UUID }
+
+// StudentRepository checks every method of repositories.StudentRepository.
+func StudentRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateStudent", func(t *testing.T) {
+		repo := newRepo(t)
+		groupID := createGroup(t, repo, "ИВТ-41")
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", groupID)
+		if uuid == "" {
+			t.Fatal("uuid is empty")
+		}
+
+		_, err := repo.CreateStudent(ctx, commands.CreateStudent{Email: "petrov@example.com", GroupID: groupID})
+		checkUniqueViolation(t, "CreateStudent with a taken email", err)
+
+		_, err = repo.CreateStudent(ctx, commands.CreateStudent{Email: "ivanov@example.com", GroupID: missingID})
+		checkForeignKeyViolation(t, "CreateStudent in a missing group", err)
+	})
+
+	t.Run("GetStudentByUUID", func(t *testing.T) {
+		repo := newRepo(t)
+		groupID := createGroup(t, repo, "ИВТ-41")
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", groupID)
+
+		student, err := repo.GetStudentByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentByUUID: %v", err)
+		}
+		want := models.Student{
+			UUID:       uuid,
+			FirstName:  "Иван",
+			LastName:   "Петров",
+			MiddleName: "Сергеевич",
+			Email:      "petrov@example.com",
+			Password:   "hash-petrov@example.com",
+			Version:    1,
+		}
+		if got := *student; got.Group == nil || *got.Group != (models.Group{ID: groupID, Name: "ИВТ-41"}) {
+			t.Errorf("group = %+v, want the joined group", got.Group)
+		} else if got.Group = nil; got != want {
+			t.Errorf("student = %+v, want %+v", got, want)
+		}
+
+		_, err = repo.GetStudentByUUID(ctx, missingUUID)
+		checkNotFound(t, "GetStudentByUUID of a missing student", err)
+	})
+
+	t.Run("GetStudents", func(t *testing.T) {
+		repo := newRepo(t)
+		first := createGroup(t, repo, "ИВТ-41")
+		second := createGroup(t, repo, "ИВТ-42")
+		a := createStudent(t, repo, "Петров", "petrov@example.com", first)
+		b := createStudent(t, repo, "Иванов", "ivanov@example.com", first)
+		c := createStudent(t, repo, "Смирнов", "smirnov@example.com", second)
+
+		_, err := repo.GetStudents(ctx, query.GetStudentsFilters{})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetStudents without filters: err = %v, want ErrInvalidFilters", err)
+		}
+		_, err = repo.GetStudents(ctx, query.GetStudentsFilters{Emails: []string{""}})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetStudents with an empty email: err = %v, want ErrInvalidFilters", err)
+		}
+
+		students, err := repo.GetStudents(ctx, query.GetStudentsFilters{IDs: []string{a, c}})
+		if err != nil {
+			t.Fatalf("GetStudents by ids: %v", err)
+		}
+		checkKeys(t, "GetStudents by ids", students, studentUUID, a, c)
+		for _, student := range students {
+			if student.Group == nil || student.Group.Name == "" || student.Password == "" {
+				t.Errorf("student = %+v, want the group name and the password", student)
+			}
+		}
+
+		students, err = repo.GetStudents(ctx, query.GetStudentsFilters{Emails: []string{"ivanov@example.com"}})
+		if err != nil {
+			t.Fatalf("GetStudents by emails: %v", err)
+		}
+		checkKeys(t, "GetStudents by emails", students, studentUUID, b)
+
+		students, err = repo.GetStudents(ctx, query.GetStudentsFilters{GroupIDs: []int64{first}, IDs: []string{b, c}})
+		if err != nil {
+			t.Fatalf("GetStudents by groups and ids: %v", err)
+		}
+		checkKeys(t, "GetStudents by groups and ids", students, studentUUID, b)
+
+		students, err = repo.GetStudents(ctx, query.GetStudentsFilters{LastNames: []string{"Петров", "Смирнов"}})
+		if err != nil {
+			t.Fatalf("GetStudents by last names: %v", err)
+		}
+		checkKeys(t, "GetStudents by last names", students, studentUUID, a, c)
+
+		all, err := repo.GetStudents(ctx, query.GetStudentsFilters{Limit: 10})
+		if err != nil {
+			t.Fatalf("GetStudents: %v", err)
+		}
+		checkKeys(t, "GetStudents", all, studentUUID, a, b, c)
+
+		checkPages(t, all, studentUUID, func(limit, offset int64) ([]models.Student, error) {
+			return repo.GetStudents(ctx, query.GetStudentsFilters{Limit: limit, Offset: offset})
+		})
+		checkKeyset(t, query.StudentSortFields, []query.Sort{{Field: "group"}, {Field: "last_name", Desc: true}, {Field: "uuid"}}, studentUUID,
+			func(keyset query.Keyset, limit int64) ([]models.Student, error) {
+				return repo.GetStudents(ctx, query.GetStudentsFilters{Keyset: keyset, Limit: limit})
+			}, a, b, c)
+	})
+
+	t.Run("CountStudents", func(t *testing.T) {
+		repo := newRepo(t)
+		first := createGroup(t, repo, "ИВТ-41")
+		createStudent(t, repo, "Петров", "petrov@example.com", first)
+		createStudent(t, repo, "Иванов", "ivanov@example.com", first)
+		createStudent(t, repo, "Смирнов", "smirnov@example.com", createGroup(t, repo, "ИВТ-42"))
+
+		// no filters are needed to count
+		count, err := repo.CountStudents(ctx, query.GetStudentsFilters{})
+		checkCount(t, "CountStudents", count, err, 3)
+
+		count, err = repo.CountStudents(ctx, query.GetStudentsFilters{GroupIDs: []int64{first}, Limit: 1})
+		checkCount(t, "CountStudents by groups", count, err, 2)
+	})
+
+	t.Run("SearchStudents", func(t *testing.T) {
+		repo := newRepo(t)
+		groupID := createGroup(t, repo, "ИВТ-41")
+		a := createStudent(t, repo, "Петров", "petrov@example.com", groupID)
+		b := createStudent(t, repo, "Иванов", "ivanov@example.com", groupID)
+		c := createStudent(t, repo, "Петров", "petrov2@example.com", groupID)
+
+		students, err := repo.SearchStudents(ctx, query.SearchStudentFilters{LastNames: []string{"Петров"}})
+		if err != nil {
+			t.Fatalf("SearchStudents by last names: %v", err)
+		}
+		checkKeys(t, "SearchStudents by last names", students, studentUUID, a, c)
+		for _, student := range students {
+			if student.Group == nil || *student.Group != (models.Group{ID: groupID}) || student.Password != "" {
+				t.Errorf("student = %+v, want only the group id and no password", student)
+			}
+		}
+
+		students, err = repo.SearchStudents(ctx, query.SearchStudentFilters{
+			UUIDs:       []string{a, b, c},
+			FirstNames:  []string{"Иван"},
+			MiddleNames: []string{"Сергеевич"},
+			Emails:      []string{"ivanov@example.com", "petrov2@example.com"},
+		})
+		if err != nil {
+			t.Fatalf("SearchStudents by every filter: %v", err)
+		}
+		checkKeys(t, "SearchStudents by every filter", students, studentUUID, b, c)
+
+		students, err = repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"missing@example.com"}})
+		if err != nil {
+			t.Fatalf("SearchStudents of a missing email: %v", err)
+		}
+		if len(students) != 0 {
+			t.Errorf("SearchStudents of a missing email = %+v, want none", students)
+		}
+	})
+
+	t.Run("UpdateStudent", func(t *testing.T) {
+		repo := newRepo(t)
+		first := createGroup(t, repo, "ИВТ-41")
+		second := createGroup(t, repo, "ИВТ-42")
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", first)
+		createStudent(t, repo, "Иванов", "ivanov@example.com", first)
+
+		if err := repo.UpdateStudent(ctx, commands.UpdateStudent{
+			UUID:       uuid,
+			FirstName:  "Пётр",
+			LastName:   "Петров",
+			MiddleName: "Ильич",
+			Email:      "p.petrov@example.com",
+			GroupID:    second,
+		}); err != nil {
+			t.Fatalf("UpdateStudent: %v", err)
+		}
+		student, err := repo.GetStudentByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentByUUID: %v", err)
+		}
+		if student.FirstName != "Пётр" || student.MiddleName != "Ильич" || student.Email != "p.petrov@example.com" ||
+			student.Group == nil || student.Group.ID != second || student.Password != "hash-petrov@example.com" {
+			t.Errorf("student = %+v, want the new fields and the old password", student)
+		}
+
+		err = repo.UpdateStudent(ctx, commands.UpdateStudent{UUID: uuid, Email: "ivanov@example.com", GroupID: second})
+		checkUniqueViolation(t, "UpdateStudent to a taken email", err)
+
+		err = repo.UpdateStudent(ctx, commands.UpdateStudent{UUID: uuid, Email: "p.petrov@example.com", GroupID: missingID})
+		checkForeignKeyViolation(t, "UpdateStudent to a missing group", err)
+
+		if err := repo.UpdateStudent(ctx, commands.UpdateStudent{UUID: missingUUID, Email: "x@example.com", GroupID: first}); err != nil {
+			t.Errorf("UpdateStudent of a missing student: %v", err)
+		}
+	})
+
+	t.Run("UpdateStudentVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		groupID := createGroup(t, repo, "ИВТ-41")
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", groupID)
+
+		update := commands.UpdateStudent{UUID: uuid, LastName: "Петров", Email: "p.petrov@example.com", GroupID: groupID, Version: 1}
+		if err := repo.UpdateStudent(ctx, update); err != nil {
+			t.Fatalf("UpdateStudent of the current version: %v", err)
+		}
+		update.Email = "petrov@example.com"
+		if err := repo.UpdateStudent(ctx, update); !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateStudent of a stale version: err = %v, want ErrVersionMismatch", err)
+		}
+		student, err := repo.GetStudentByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentByUUID: %v", err)
+		}
+		if student.Email != "p.petrov@example.com" || student.Version != 2 {
+			t.Errorf("student = %+v, want the first update only", student)
+		}
+
+		update.UUID = missingUUID
+		if err := repo.UpdateStudent(ctx, update); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("UpdateStudent of a missing student with a version: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("DeleteStudent", func(t *testing.T) {
+		repo := newRepo(t)
+		groupID := createGroup(t, repo, "ИВТ-41")
+		free := createStudent(t, repo, "Петров", "petrov@example.com", groupID)
+		indebted := createStudent(t, repo, "Иванов", "ivanov@example.com", groupID)
+		debtID := createDebt(t, repo, createExam(t, repo, "Базы данных"), indebted, createTeacher(t, repo, "Сидорова", "sidorova@example.com"))
+
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: free}); err != nil {
+			t.Fatalf("DeleteStudent: %v", err)
+		}
+		_, err := repo.GetStudentByUUID(ctx, free)
+		checkNotFound(t, "GetStudentByUUID of a deleted student", err)
+
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: indebted}); err != nil {
+			t.Fatalf("DeleteStudent with debts: %v", err)
+		}
+		_, err = repo.GetStudentByUUID(ctx, indebted)
+		checkNotFound(t, "GetStudentByUUID of a deleted student with debts", err)
+		students, err := repo.GetStudents(ctx, query.GetStudentsFilters{GroupIDs: []int64{groupID}})
+		if err != nil {
+			t.Fatalf("GetStudents: %v", err)
+		}
+		checkKeys(t, "GetStudents after the deletes", students, studentUUID)
+		emails := []string{"petrov@example.com", "ivanov@example.com"}
+		found, err := repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: emails})
+		if err != nil {
+			t.Fatalf("SearchStudents: %v", err)
+		}
+		checkKeys(t, "SearchStudents after the deletes", found, studentUUID)
+		found, err = repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: emails, Deleted: true})
+		if err != nil {
+			t.Fatalf("SearchStudents in the trash: %v", err)
+		}
+		checkKeys(t, "SearchStudents in the trash", found, studentUUID, free, indebted)
+		if debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{debtID}}); err != nil || len(debts) != 0 {
+			t.Errorf("GetDebts of a deleted student = %+v, %v, want none", debts, err)
+		}
+		if debt := getDebt(t, repo, debtID); debt.Student == nil || debt.Student.UUID != indebted {
+			t.Errorf("debt = %+v, want the debt of the deleted student", debt)
+		}
+
+		if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: missingUUID}); err != nil {
+			t.Errorf("DeleteStudent of a missing student: %v", err)
+		}
+	})
+
+	t.Run("ChangeStudentPassword", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", createGroup(t, repo, "ИВТ-41"))
+
+		if err := repo.ChangeStudentPassword(ctx, uuid, "new-hash"); err != nil {
+			t.Fatalf("ChangeStudentPassword: %v", err)
+		}
+		student, err := repo.GetStudentByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetStudentByUUID: %v", err)
+		}
+		if student.Password != "new-hash" {
+			t.Errorf("password = %s, want new-hash", student.Password)
+		}
+
+		if err := repo.ChangeStudentPassword(ctx, missingUUID, "new-hash"); err != nil {
+			t.Errorf("ChangeStudentPassword of a missing student: %v", err)
+		}
+	})
+
+	t.Run("GetAmountOfDebtsForStudent", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createStudent(t, repo, "Петров", "petrov@example.com", createGroup(t, repo, "ИВТ-41"))
+		teacher := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+
+		amount, err := repo.GetAmountOfDebtsForStudent(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetAmountOfDebtsForStudent: %v", err)
+		}
+		if amount != 0 {
+			t.Errorf("amount = %d, want 0", amount)
+		}
+
+		createDebt(t, repo, createExam(t, repo, "Базы данных"), uuid, teacher)
+		closed := createDebt(t, repo, createExam(t, repo, "Сети"), uuid, teacher)
+		if err := repo.CloseDebt(ctx, commands.CloseDebt{DebtID: closed}); err != nil {
+			t.Fatalf("CloseDebt: %v", err)
+		}
+
+		// closed debts are counted too
+		amount, err = repo.GetAmountOfDebtsForStudent(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetAmountOfDebtsForStudent: %v", err)
+		}
+		if amount != 2 {
+			t.Errorf("amount = %d, want 2", amount)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func teacherUUID(teacher models.Teacher) string { return teacher.UUID }
+
+// TeacherRepository checks every method of repositories.TeacherRepository.
+func TeacherRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateTeacher", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		if uuid == "" {
+			t.Fatal("uuid is empty")
+		}
+
+		_, err := repo.CreateTeacher(ctx, commands.CreateTeacher{Email: "sidorova@example.com"})
+		checkUniqueViolation(t, "CreateTeacher with a taken email", err)
+	})
+
+	t.Run("GetTeacherByUUID", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+
+		teacher, err := repo.GetTeacherByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetTeacherByUUID: %v", err)
+		}
+		want := models.Teacher{
+			UUID:       uuid,
+			FirstName:  "Анна",
+			LastName:   "Сидорова",
+			MiddleName: "Петровна",
+			Email:      "sidorova@example.com",
+			Password:   "hash-sidorova@example.com",
+			Version:    1,
+		}
+		if *teacher != want {
+			t.Errorf("teacher = %+v, want %+v", *teacher, want)
+		}
+
+		_, err = repo.GetTeacherByUUID(ctx, missingUUID)
+		checkNotFound(t, "GetTeacherByUUID of a missing teacher", err)
+	})
+
+	t.Run("GetTeachers", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		b := createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+		c := createTeacher(t, repo, "Попова", "popova@example.com")
+
+		_, err := repo.GetTeachers(ctx, query.GetTeachersFilters{})
+		if !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetTeachers without filters: err = %v, want ErrInvalidFilters", err)
+		}
+
+		teachers, err := repo.GetTeachers(ctx, query.GetTeachersFilters{UUIDs: []string{a, c}})
+		if err != nil {
+			t.Fatalf("GetTeachers by uuids: %v", err)
+		}
+		checkKeys(t, "GetTeachers by uuids", teachers, teacherUUID, a, c)
+		for _, teacher := range teachers {
+			if teacher.Password == "" {
+				t.Errorf("teacher = %+v, want the password", teacher)
+			}
+		}
+
+		teachers, err = repo.GetTeachers(ctx, query.GetTeachersFilters{UUIDs: []string{a, b}, Emails: []string{"kuznetsova@example.com"}})
+		if err != nil {
+			t.Fatalf("GetTeachers by uuids and emails: %v", err)
+		}
+		checkKeys(t, "GetTeachers by uuids and emails", teachers, teacherUUID, b)
+
+		all, err := repo.GetTeachers(ctx, query.GetTeachersFilters{Limit: 10})
+		if err != nil {
+			t.Fatalf("GetTeachers: %v", err)
+		}
+		checkKeys(t, "GetTeachers", all, teacherUUID, a, b, c)
+
+		teachers, err = repo.GetTeachers(ctx, query.GetTeachersFilters{LastNames: []string{"Кузнецова", "Попова"}})
+		if err != nil {
+			t.Fatalf("GetTeachers by last names: %v", err)
+		}
+		checkKeys(t, "GetTeachers by last names", teachers, teacherUUID, b, c)
+
+		checkPages(t, all, teacherUUID, func(limit, offset int64) ([]models.Teacher, error) {
+			return repo.GetTeachers(ctx, query.GetTeachersFilters{Limit: limit, Offset: offset})
+		})
+		checkKeyset(t, query.TeacherSortFields, []query.Sort{{Field: "last_name"}, {Field: "uuid"}}, teacherUUID,
+			func(keyset query.Keyset, limit int64) ([]models.Teacher, error) {
+				return repo.GetTeachers(ctx, query.GetTeachersFilters{Keyset: keyset, Limit: limit})
+			}, b, c, a)
+	})
+
+	t.Run("CountTeachers", func(t *testing.T) {
+		repo := newRepo(t)
+		createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+		createTeacher(t, repo, "Сидорова", "sidorova2@example.com")
+
+		count, err := repo.CountTeachers(ctx, query.GetTeachersFilters{})
+		checkCount(t, "CountTeachers", count, err, 3)
+
+		count, err = repo.CountTeachers(ctx, query.GetTeachersFilters{LastNames: []string{"Сидорова"}, Limit: 1, Offset: 1})
+		checkCount(t, "CountTeachers by last names", count, err, 2)
+	})
+
+	t.Run("SearchTeachers", func(t *testing.T) {
+		repo := newRepo(t)
+		a := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		b := createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+		c := createTeacher(t, repo, "Сидорова", "sidorova2@example.com")
+
+		teachers, err := repo.SearchTeachers(ctx, query.SearchTeacherFilters{LastNames: []string{"Сидорова"}})
+		if err != nil {
+			t.Fatalf("SearchTeachers by last names: %v", err)
+		}
+		checkKeys(t, "SearchTeachers by last names", teachers, teacherUUID, a, c)
+
+		teachers, err = repo.SearchTeachers(ctx, query.SearchTeacherFilters{
+			UUIDs:       []string{a, b, c},
+			FirstNames:  []string{"Анна"},
+			MiddleNames: []string{"Петровна"},
+			Emails:      []string{"kuznetsova@example.com", "sidorova2@example.com"},
+		})
+		if err != nil {
+			t.Fatalf("SearchTeachers by every filter: %v", err)
+		}
+		checkKeys(t, "SearchTeachers by every filter", teachers, teacherUUID, b, c)
+
+		teachers, err = repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"missing@example.com"}})
+		if err != nil {
+			t.Fatalf("SearchTeachers of a missing email: %v", err)
+		}
+		if len(teachers) != 0 {
+			t.Errorf("SearchTeachers of a missing email = %+v, want none", teachers)
+		}
+	})
+
+	t.Run("UpdateTeacher", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+
+		if err := repo.UpdateTeacher(ctx, commands.UpdateTeacher{
+			UUID:       uuid,
+			FirstName:  "Мария",
+			LastName:   "Сидорова",
+			MiddleName: "Ивановна",
+			Email:      "m.sidorova@example.com",
+		}); err != nil {
+			t.Fatalf("UpdateTeacher: %v", err)
+		}
+		teacher, err := repo.GetTeacherByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetTeacherByUUID: %v", err)
+		}
+		if teacher.FirstName != "Мария" || teacher.MiddleName != "Ивановна" || teacher.Email != "m.sidorova@example.com" ||
+			teacher.Password != "hash-sidorova@example.com" {
+			t.Errorf("teacher = %+v, want the new fields and the old password", teacher)
+		}
+
+		err = repo.UpdateTeacher(ctx, commands.UpdateTeacher{UUID: uuid, Email: "kuznetsova@example.com"})
+		checkUniqueViolation(t, "UpdateTeacher to a taken email", err)
+
+		if err := repo.UpdateTeacher(ctx, commands.UpdateTeacher{UUID: missingUUID, Email: "x@example.com"}); err != nil {
+			t.Errorf("UpdateTeacher of a missing teacher: %v", err)
+		}
+	})
+
+	t.Run("UpdateTeacherVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+
+		update := commands.UpdateTeacher{UUID: uuid, LastName: "Сидорова", Email: "m.sidorova@example.com", Version: 1}
+		if err := repo.UpdateTeacher(ctx, update); err != nil {
+			t.Fatalf("UpdateTeacher of the current version: %v", err)
+		}
+		update.Email = "sidorova@example.com"
+		if err := repo.UpdateTeacher(ctx, update); !e.Is(err, errors.ErrVersionMismatch) {
+			t.Errorf("UpdateTeacher of a stale version: err = %v, want ErrVersionMismatch", err)
+		}
+		teacher, err := repo.GetTeacherByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetTeacherByUUID: %v", err)
+		}
+		if teacher.Email != "m.sidorova@example.com" || teacher.Version != 2 {
+			t.Errorf("teacher = %+v, want the first update only", teacher)
+		}
+
+		update.UUID = missingUUID
+		if err := repo.UpdateTeacher(ctx, update); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("UpdateTeacher of a missing teacher with a version: err = %v, want ErroNoItemsFound", err)
+		}
+	})
+
+	t.Run("DeleteTeacher", func(t *testing.T) {
+		repo := newRepo(t)
+		free := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		busy := createTeacher(t, repo, "Кузнецова", "kuznetsova@example.com")
+		student := createStudent(t, repo, "Петров", "petrov@example.com", createGroup(t, repo, "ИВТ-41"))
+		createDebt(t, repo, createExam(t, repo, "Базы данных"), student, busy)
+
+		if err := repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: free}); err != nil {
+			t.Fatalf("DeleteTeacher: %v", err)
+		}
+		_, err := repo.GetTeacherByUUID(ctx, free)
+		checkNotFound(t, "GetTeacherByUUID of a deleted teacher", err)
+
+		if err := repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: busy}); err != nil {
+			t.Fatalf("DeleteTeacher with debts: %v", err)
+		}
+		_, err = repo.GetTeacherByUUID(ctx, busy)
+		checkNotFound(t, "GetTeacherByUUID of a deleted teacher with debts", err)
+		teachers, err := repo.GetTeachers(ctx, query.GetTeachersFilters{UUIDs: []string{free, busy}})
+		if err != nil {
+			t.Fatalf("GetTeachers: %v", err)
+		}
+		checkKeys(t, "GetTeachers after the deletes", teachers, teacherUUID)
+		found, err := repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"sidorova@example.com"}})
+		if err != nil {
+			t.Fatalf("SearchTeachers: %v", err)
+		}
+		checkKeys(t, "SearchTeachers after the deletes", found, teacherUUID)
+		found, err = repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"sidorova@example.com"}, Deleted: true})
+		if err != nil {
+			t.Fatalf("SearchTeachers in the trash: %v", err)
+		}
+		checkKeys(t, "SearchTeachers in the trash", found, teacherUUID, free)
+
+		if err := repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: missingUUID}); err != nil {
+			t.Errorf("DeleteTeacher of a missing teacher: %v", err)
+		}
+	})
+
+	t.Run("ChangeTeacherPassword", func(t *testing.T) {
+		repo := newRepo(t)
+		uuid := createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+
+		if err := repo.ChangeTeacherPassword(ctx, uuid, "new-hash"); err != nil {
+			t.Fatalf("ChangeTeacherPassword: %v", err)
+		}
+		teacher, err := repo.GetTeacherByUUID(ctx, uuid)
+		if err != nil {
+			t.Fatalf("GetTeacherByUUID: %v", err)
+		}
+		if teacher.Password != "new-hash" {
+			t.Errorf("password = %s, want new-hash", teacher.Password)
+		}
+
+		if err := repo.ChangeTeacherPassword(ctx, missingUUID, "new-hash"); err != nil {
+			t.Errorf("ChangeTeacherPassword of a missing teacher: %v", err)
+		}
+	})
+}
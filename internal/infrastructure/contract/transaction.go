@@ -0,0 +1,125 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+var errAbort = e.New("abort the transaction")
+
+// createAll creates a debt with a group, student, teacher and exam of its
+// own, every one of them in ctx.
+func createAll(ctx context.Context, repo Repository) error {
+	groupID, err := repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-41"})
+	if err != nil {
+		return err
+	}
+	// what the transaction created is visible inside of it
+	if _, err := repo.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+	student, err := repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Иван",
+		LastName:  "Петров",
+		Email:     "petrov@example.com",
+		GroupID:   groupID,
+	})
+	if err != nil {
+		return err
+	}
+	teacher, err := repo.CreateTeacher(ctx, commands.CreateTeacher{
+		FirstName: "Анна",
+		LastName:  "Сидорова",
+		Email:     "sidorova@example.com",
+	})
+	if err != nil {
+		return err
+	}
+	examID, err := repo.CreateExam(ctx, commands.CreateExam{Name: "Базы данных", AssessmentType: valueobjects.AssessmentExam})
+	if err != nil {
+		return err
+	}
+	_, err = repo.CreateDebt(ctx, commands.CreateDebt{ExamID: examID, StudentUUID: student, TeacherUUID: teacher})
+	return err
+}
+
+// checkCreated checks that exactly want rows of each table of createAll are
+// visible outside of the transaction.
+func checkCreated(t *testing.T, repo Repository, want int) {
+	t.Helper()
+
+	ctx := context.Background()
+	groups, err := repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ИВТ-41"}})
+	if err != nil || len(groups) != want {
+		t.Errorf("SearchGroups = %+v, %v, want %d", groups, err, want)
+	}
+	students, err := repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"petrov@example.com"}})
+	if err != nil || len(students) != want {
+		t.Errorf("SearchStudents = %+v, %v, want %d", students, err, want)
+	}
+	teachers, err := repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"sidorova@example.com"}})
+	if err != nil || len(teachers) != want {
+		t.Errorf("SearchTeachers = %+v, %v, want %d", teachers, err, want)
+	}
+	exams, err := repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Базы данных"}})
+	if err != nil || len(exams) != want {
+		t.Errorf("SearchExams = %+v, %v, want %d", exams, err, want)
+	}
+	debts, err := repo.GetDebts(ctx, query.GetDebtsFilters{})
+	if err != nil || len(debts) != want {
+		t.Errorf("GetDebts = %+v, %v, want %d", debts, err, want)
+	}
+}
+
+// TransactionRepository checks that the create methods of the other
+// repositories take part in repositories.TransactionRepository.
+func TransactionRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	t.Run("Commit", func(t *testing.T) {
+		repo := newRepo(t)
+		err := repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			return createAll(ctx, repo)
+		})
+		if err != nil {
+			t.Fatalf("PerformTransaction: %v", err)
+		}
+		checkCreated(t, repo, 1)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		repo := newRepo(t)
+		err := repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			if err := createAll(ctx, repo); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !e.Is(err, errAbort) {
+			t.Fatalf("PerformTransaction = %v, want %v", err, errAbort)
+		}
+		checkCreated(t, repo, 0)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		repo := newRepo(t)
+		// the inner transaction joins the outer one, it is rolled back with it
+		err := repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			if err := repo.PerformTransaction(ctx, func(ctx context.Context) error {
+				return createAll(ctx, repo)
+			}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !e.Is(err, errAbort) {
+			t.Fatalf("PerformTransaction = %v, want %v", err, errAbort)
+		}
+		checkCreated(t, repo, 0)
+	})
+}
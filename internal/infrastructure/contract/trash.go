@@ -0,0 +1,188 @@
+package contract
+
+import (
+	"context"
+	e "errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func trashItemKey(item models.TrashItem) string { return item.Kind + ":" + item.ID }
+
+// TrashRepository checks repositories.TrashRepository with the deletes of
+// the other repositories.
+func TrashRepository(t *testing.T, newRepo NewRepository) {
+	ctx := context.Background()
+
+	// trashed deletes a student with a debt, its teacher and exam, and a
+	// group nothing is in.
+	type trashed struct {
+		student, teacher string
+		exam, group      int64
+		debt             int64
+	}
+	newTrashed := func(t *testing.T, repo Repository) trashed {
+		t.Helper()
+
+		var d trashed
+		d.student = createStudent(t, repo, "Петров", "petrov@example.com", createGroup(t, repo, "ИВТ-41"))
+		d.teacher = createTeacher(t, repo, "Сидорова", "sidorova@example.com")
+		d.exam = createExam(t, repo, "Базы данных")
+		d.debt = createDebt(t, repo, d.exam, d.student, d.teacher)
+		d.group = createGroup(t, repo, "ИВТ-42")
+
+		for name, err := range map[string]error{
+			"DeleteStudent": repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: d.student}),
+			"DeleteTeacher": repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: d.teacher}),
+			"DeleteExam":    repo.DeleteExam(ctx, commands.DeleteExam{ID: d.exam}),
+			"DeleteGroup":   repo.DeleteGroup(ctx, commands.DeleteGroup{ID: d.group}),
+		} {
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		return d
+	}
+
+	t.Run("GetTrash", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newTrashed(t, repo)
+
+		items, err := repo.GetTrash(ctx, query.GetTrashFilters{})
+		if err != nil {
+			t.Fatalf("GetTrash: %v", err)
+		}
+		checkKeys(t, "GetTrash", items, trashItemKey,
+			valueobjects.TrashStudentKind+":"+d.student,
+			valueobjects.TrashTeacherKind+":"+d.teacher,
+			valueobjects.TrashExamKind+":"+strconv.FormatInt(d.exam, 10),
+			valueobjects.TrashGroupKind+":"+strconv.FormatInt(d.group, 10))
+		for i, item := range items {
+			if i > 0 && item.DeletedAt.After(items[i-1].DeletedAt) {
+				t.Errorf("GetTrash = %+v, want the latest deleted first", items)
+			}
+			if item.Kind == valueobjects.TrashStudentKind && item.Title != "Петров Иван Сергеевич" {
+				t.Errorf("item = %+v, want the name of the student as the title", item)
+			}
+		}
+
+		items, err = repo.GetTrash(ctx, query.GetTrashFilters{Kinds: []string{valueobjects.TrashExamKind}})
+		if err != nil {
+			t.Fatalf("GetTrash of exams: %v", err)
+		}
+		checkKeys(t, "GetTrash of exams", items, trashItemKey, valueobjects.TrashExamKind+":"+strconv.FormatInt(d.exam, 10))
+
+		items, err = repo.GetTrash(ctx, query.GetTrashFilters{Limit: 1, Offset: 3})
+		if err != nil || len(items) != 1 {
+			t.Errorf("GetTrash limit 1 offset 3 = %+v, %v, want the last item", items, err)
+		}
+
+		if _, err := repo.GetTrash(ctx, query.GetTrashFilters{Kinds: []string{"room"}}); !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetTrash of an unknown kind: err = %v, want ErrInvalidFilters", err)
+		}
+	})
+
+	t.Run("RestoreDeleted", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newTrashed(t, repo)
+
+		if err := repo.RestoreDeleted(ctx, commands.RestoreDeleted{Kind: valueobjects.TrashStudentKind, ID: d.student}); err != nil {
+			t.Fatalf("RestoreDeleted of a student: %v", err)
+		}
+		if _, err := repo.GetStudentByUUID(ctx, d.student); err != nil {
+			t.Errorf("GetStudentByUUID of a restored student: %v", err)
+		}
+		if err := repo.RestoreDeleted(ctx, commands.RestoreDeleted{Kind: valueobjects.TrashExamKind, ID: strconv.FormatInt(d.exam, 10)}); err != nil {
+			t.Fatalf("RestoreDeleted of an exam: %v", err)
+		}
+		if _, err := repo.GetExamByID(ctx, query.GetExamsFilters{IDs: []int64{d.exam}}); err != nil {
+			t.Errorf("GetExamByID of a restored exam: %v", err)
+		}
+
+		items, err := repo.GetTrash(ctx, query.GetTrashFilters{})
+		if err != nil {
+			t.Fatalf("GetTrash: %v", err)
+		}
+		checkKeys(t, "GetTrash after the restores", items, trashItemKey,
+			valueobjects.TrashTeacherKind+":"+d.teacher,
+			valueobjects.TrashGroupKind+":"+strconv.FormatInt(d.group, 10))
+
+		for name, restore := range map[string]commands.RestoreDeleted{
+			"a restored student": {Kind: valueobjects.TrashStudentKind, ID: d.student},
+			"a missing teacher":  {Kind: valueobjects.TrashTeacherKind, ID: missingUUID},
+			"a missing group":    {Kind: valueobjects.TrashGroupKind, ID: strconv.FormatInt(missingID, 10)},
+		} {
+			if err := repo.RestoreDeleted(ctx, restore); !e.Is(err, errors.ErroNoItemsFound) {
+				t.Errorf("RestoreDeleted of %s: err = %v, want ErroNoItemsFound", name, err)
+			}
+		}
+		for name, restore := range map[string]commands.RestoreDeleted{
+			"an unknown kind":      {Kind: "room", ID: "1"},
+			"a malformed group id": {Kind: valueobjects.TrashGroupKind, ID: "ИВТ-42"},
+		} {
+			if err := repo.RestoreDeleted(ctx, restore); !e.Is(err, errors.ErrInvalidCommand) {
+				t.Errorf("RestoreDeleted of %s: err = %v, want ErrInvalidCommand", name, err)
+			}
+		}
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		repo := newRepo(t)
+		d := newTrashed(t, repo)
+
+		for _, kind := range valueobjects.TrashKinds {
+			purged, err := repo.PurgeDeleted(ctx, commands.PurgeDeleted{Kind: kind, Before: time.Now().Add(-time.Hour)})
+			if err != nil || purged != 0 {
+				t.Errorf("PurgeDeleted of %s deleted an hour ago = %d, %v, want nothing purged", kind, purged, err)
+			}
+		}
+
+		// the debt keeps the student, the teacher and the exam
+		for _, kind := range valueobjects.TrashKinds {
+			want := int64(0)
+			if kind == valueobjects.TrashGroupKind {
+				want = 1
+			}
+			purged, err := repo.PurgeDeleted(ctx, commands.PurgeDeleted{Kind: kind, Before: time.Now().Add(time.Hour)})
+			if err != nil || purged != want {
+				t.Errorf("PurgeDeleted of %s = %d, %v, want %d purged", kind, purged, err, want)
+			}
+		}
+
+		items, err := repo.GetTrash(ctx, query.GetTrashFilters{})
+		if err != nil {
+			t.Fatalf("GetTrash: %v", err)
+		}
+		checkKeys(t, "GetTrash after the purge", items, trashItemKey,
+			valueobjects.TrashStudentKind+":"+d.student,
+			valueobjects.TrashTeacherKind+":"+d.teacher,
+			valueobjects.TrashExamKind+":"+strconv.FormatInt(d.exam, 10))
+		if err := repo.RestoreDeleted(ctx, commands.RestoreDeleted{Kind: valueobjects.TrashGroupKind, ID: strconv.FormatInt(d.group, 10)}); !e.Is(err, errors.ErroNoItemsFound) {
+			t.Errorf("RestoreDeleted of a purged group: err = %v, want ErroNoItemsFound", err)
+		}
+
+		if err := repo.DeleteDebt(ctx, commands.DeleteDebt{ID: d.debt}); err != nil {
+			t.Fatalf("DeleteDebt: %v", err)
+		}
+		for _, kind := range valueobjects.TrashKinds {
+			if _, err := repo.PurgeDeleted(ctx, commands.PurgeDeleted{Kind: kind, Before: time.Now().Add(time.Hour)}); err != nil {
+				t.Fatalf("PurgeDeleted of %s: %v", kind, err)
+			}
+		}
+		items, err = repo.GetTrash(ctx, query.GetTrashFilters{})
+		if err != nil || len(items) != 0 {
+			t.Errorf("GetTrash after the debt is gone = %+v, %v, want an empty trash", items, err)
+		}
+
+		if _, err := repo.PurgeDeleted(ctx, commands.PurgeDeleted{Kind: valueobjects.TrashGroupKind}); !e.Is(err, errors.ErrInvalidCommand) {
+			t.Errorf("PurgeDeleted without a time: err = %v, want ErrInvalidCommand", err)
+		}
+	})
+}
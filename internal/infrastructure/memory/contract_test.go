@@ -0,0 +1,17 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/infrastructure/contract"
+)
+
+func TestContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) contract.Repository {
+		return NewRepository(NewStudentMailer())
+	})
+}
+
+func BenchmarkExamGroups(b *testing.B) {
+	contract.BenchmarkExamGroups(b, NewRepository(NewStudentMailer()))
+}
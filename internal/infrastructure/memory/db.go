@@ -0,0 +1,320 @@
+// Package memory keeps the whole repository in process memory. It follows
+// the filters, ordering and constraints of the postgres package, so the
+// usecases can be run without a database.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/infrastructure/pdf"
+	"github.com/VanLavr/Diploma-fin/utils/config"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type repository struct {
+	repositories.Connector
+	repositories.TransactionRepository
+	repositories.ExamRepository
+	repositories.StudentRepository
+	repositories.TeacherRepository
+	repositories.StudentMailer
+	repositories.GroupRepository
+	repositories.RetakeRequestRepository
+	repositories.RetakeSessionRepository
+	repositories.RoomRepository
+	repositories.ScheduleRepository
+	repositories.TeacherAvailabilityRepository
+	repositories.TimetableRepository
+	repositories.DocumentRenderer
+	repositories.ImportRepository
+	repositories.SearchRepository
+	repositories.TrashRepository
+	repositories.PersonalDataRepository
+}
+
+// NewRepository returns an empty repository, mails go to the mailer.
+func NewRepository(mailer repositories.StudentMailer) repositories.Repository {
+	db := NewStore()
+
+	return &repository{
+		Connector:                     NewConnector(),
+		TransactionRepository:         NewTransaction(db),
+		ExamRepository:                NewExamRepo(db),
+		StudentRepository:             NewStudentRepo(db),
+		TeacherRepository:             NewTeacherRepo(db),
+		GroupRepository:               NewGroupRepo(db),
+		RetakeRequestRepository:       NewRetakeRequestRepo(db),
+		RetakeSessionRepository:       NewRetakeSessionRepo(db),
+		RoomRepository:                NewRoomRepo(db),
+		ScheduleRepository:            NewScheduleRepo(db),
+		TeacherAvailabilityRepository: NewTeacherAvailabilityRepo(db),
+		TimetableRepository:           NewTimetableRepo(db),
+		ImportRepository:              NewImportRepo(db),
+		SearchRepository:              NewSearchRepo(db),
+		TrashRepository:               NewTrashRepo(db),
+		PersonalDataRepository:        NewPersonalDataRepo(db),
+		StudentMailer:                 mailer,
+		DocumentRenderer:              pdf.NewDocumentRenderer(),
+	}
+}
+
+// Store holds the tables. Rows are values and are replaced as a whole on
+// update, so a copy of the maps is a snapshot a transaction can restore.
+type Store struct {
+	mu sync.Mutex
+	// tx serializes transactions, there is no isolation between them
+	tx     sync.Mutex
+	tables tables
+}
+
+type tables struct {
+	seq int64
+
+	groups       map[int64]models.Group
+	students     map[string]studentRow
+	teachers     map[string]teacherRow
+	exams        map[int64]models.Exam
+	debts        map[int64]debtRow
+	debtResults  map[int64]models.DebtResult
+	rooms        map[int64]models.Room
+	requests     map[int64]retakeRequestRow
+	sessions     map[int64]retakeSessionRow
+	bookings     map[int64]retakeBookingRow
+	availability map[int64]models.TeacherAvailability
+	drafts       map[int64]models.TimetableDraft
+	draftItems   map[int64]timetableDraftItemRow
+	previews     map[string]models.ImportPreview
+	profiles     map[int64]models.ImportProfile
+	jobs         map[int64]models.ImportJob
+	entities     map[importEntityKey]importEntityRow
+	audit        map[int64]models.PersonalDataAudit
+	// trash plays the deleted_at columns of the trashed tables
+	trash map[trashKey]time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		tables: tables{
+			groups:       make(map[int64]models.Group),
+			students:     make(map[string]studentRow),
+			teachers:     make(map[string]teacherRow),
+			exams:        make(map[int64]models.Exam),
+			debts:        make(map[int64]debtRow),
+			debtResults:  make(map[int64]models.DebtResult),
+			rooms:        make(map[int64]models.Room),
+			requests:     make(map[int64]retakeRequestRow),
+			sessions:     make(map[int64]retakeSessionRow),
+			bookings:     make(map[int64]retakeBookingRow),
+			availability: make(map[int64]models.TeacherAvailability),
+			drafts:       make(map[int64]models.TimetableDraft),
+			draftItems:   make(map[int64]timetableDraftItemRow),
+			previews:     make(map[string]models.ImportPreview),
+			profiles:     make(map[int64]models.ImportProfile),
+			jobs:         make(map[int64]models.ImportJob),
+			entities:     make(map[importEntityKey]importEntityRow),
+			audit:        make(map[int64]models.PersonalDataAudit),
+			trash:        make(map[trashKey]time.Time),
+		},
+	}
+}
+
+// nextID plays all the serial columns, ids are unique across tables.
+func (this *Store) nextID() int64 {
+	this.tables.seq++
+	return this.tables.seq
+}
+
+func (this *tables) snapshot() tables {
+	snapshot := *this
+	snapshot.groups = maps.Clone(this.groups)
+	snapshot.students = maps.Clone(this.students)
+	snapshot.teachers = maps.Clone(this.teachers)
+	snapshot.exams = maps.Clone(this.exams)
+	snapshot.debts = maps.Clone(this.debts)
+	snapshot.debtResults = maps.Clone(this.debtResults)
+	snapshot.rooms = maps.Clone(this.rooms)
+	snapshot.requests = maps.Clone(this.requests)
+	snapshot.sessions = maps.Clone(this.sessions)
+	snapshot.bookings = maps.Clone(this.bookings)
+	snapshot.availability = maps.Clone(this.availability)
+	snapshot.drafts = maps.Clone(this.drafts)
+	snapshot.draftItems = maps.Clone(this.draftItems)
+	snapshot.previews = maps.Clone(this.previews)
+	snapshot.profiles = maps.Clone(this.profiles)
+	snapshot.jobs = maps.Clone(this.jobs)
+	snapshot.entities = maps.Clone(this.entities)
+	snapshot.audit = maps.Clone(this.audit)
+	snapshot.trash = maps.Clone(this.trash)
+	return snapshot
+}
+
+// newUUID plays uuid_generate_v4().
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// the codes tools.IsUniqueViolation and tools.IsForeignKeyViolation look for
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23505", ConstraintName: constraint, Message: "duplicate key value violates unique constraint"}
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23503", ConstraintName: constraint, Message: "violates foreign key constraint"}
+}
+
+// rowsOf returns the rows of a table in the order of the key, the order
+// postgres returns a heap scan in while nothing is updated.
+func rowsOf[K comparable, V any](table map[K]V, key func(V) int64) []V {
+	result := make([]V, 0, len(table))
+	for _, row := range table {
+		result = append(result, row)
+	}
+	slices.SortFunc(result, func(a, b V) int { return cmp.Compare(key(a), key(b)) })
+	return result
+}
+
+// page applies OFFSET and LIMIT, zero values are left out as in the queries.
+func page[T any](rows []T, limit, offset int64) []T {
+	if offset != 0 {
+		if offset >= int64(len(rows)) {
+			return nil
+		}
+		rows = rows[offset:]
+	}
+	if limit != 0 && limit < int64(len(rows)) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// orderByKeyset sorts the rows by the sort of the keyset and keeps the rows
+// after its cursor, as the ORDER BY and the row comparison of the queries.
+func orderByKeyset[T any](rows []T, fields []query.SortField[T], keyset query.Keyset) ([]T, error) {
+	if err := keyset.Validate(); err != nil {
+		return nil, err
+	}
+
+	sortFields := make([]query.SortField[T], len(keyset.Sort))
+	for i, sort := range keyset.Sort {
+		index := slices.IndexFunc(fields, func(field query.SortField[T]) bool { return field.Name == sort.Field })
+		if index < 0 {
+			return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_INFRASTRUCTURE, "", "sort", sort.Field)
+		}
+		sortFields[i] = fields[index]
+	}
+	compare := func(row T, values []string) int {
+		for i, field := range sortFields {
+			order := query.CompareSortValues(field.Kind, field.Value(row), values[i])
+			if keyset.Sort[i].Desc {
+				order = -order
+			}
+			if order != 0 {
+				return order
+			}
+		}
+		return 0
+	}
+	valuesOf := func(row T) []string {
+		values := make([]string, len(sortFields))
+		for i, field := range sortFields {
+			values[i] = field.Value(row)
+		}
+		return values
+	}
+
+	var result []T
+	for _, row := range rows {
+		if len(keyset.After) != 0 && compare(row, keyset.After) <= 0 {
+			continue
+		}
+		result = append(result, row)
+	}
+	slices.SortStableFunc(result, func(a, b T) int { return compare(a, valuesOf(b)) })
+	return result, nil
+}
+
+type connector struct{}
+
+func NewConnector() repositories.Connector {
+	return &connector{}
+}
+
+// ConnectToPostgres implements repositories.Connector, there is nothing to
+// connect to.
+func (c *connector) ConnectToPostgres(*config.Config) (*pgxpool.Pool, error) {
+	return nil, nil
+}
+
+func (c *connector) CloseConnectionWithPostgres(context.Context) error {
+	return nil
+}
+
+type transaction struct {
+	db *Store
+}
+
+func NewTransaction(db *Store) repositories.TransactionRepository {
+	return &transaction{
+		db: db,
+	}
+}
+
+// transactionMarker is stored under valueobjects.TransactionKey, it is not
+// a pgx.Tx, so tools.GetTransaction does not mistake it for one.
+type transactionMarker struct{}
+
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(valueobjects.TransactionKey{}).(transactionMarker)
+	return ok
+}
+
+// PerformTransaction implements repositories.TransactionRepository.
+// A transaction started inside another one joins it.
+func (t *transaction) PerformTransaction(ctx context.Context, wrapper func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return wrapper(ctx)
+	}
+
+	t.db.tx.Lock()
+	defer t.db.tx.Unlock()
+
+	t.db.mu.Lock()
+	snapshot := t.db.tables.snapshot()
+	t.db.mu.Unlock()
+
+	err := wrapper(context.WithValue(ctx, valueobjects.TransactionKey{}, transactionMarker{}))
+	if err != nil {
+		t.db.mu.Lock()
+		t.db.tables = snapshot
+		t.db.mu.Unlock()
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// now is the clock of the default and now() columns.
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,563 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// debtRow RoomID is 0 and Date is nil while the debt is not scheduled.
+type debtRow struct {
+	ID          int64
+	ExamID      int64
+	StudentUUID string
+	TeacherUUID string
+	Date        *time.Time
+	Address     string
+	RoomID      int64
+	ClosedAt    *time.Time
+	Version     int64
+}
+
+type examRepo struct {
+	db *Store
+}
+
+func NewExamRepo(db *Store) repositories.ExamRepository {
+	return &examRepo{
+		db: db,
+	}
+}
+
+func examID(exam models.Exam) int64           { return exam.ID }
+func debtID(debt debtRow) int64               { return debt.ID }
+func resultID(result models.DebtResult) int64 { return result.ID }
+
+// debt joins the exam, the student with the group, the teacher and the
+// room of the debt, the way GetDebts selects them.
+func (this *Store) debt(row debtRow) models.Debt {
+	exam := this.tables.exams[row.ExamID]
+	student := this.tables.students[row.StudentUUID]
+	teacher := this.tables.teachers[row.TeacherUUID]
+
+	debt := models.Debt{
+		ID:       row.ID,
+		Address:  row.Address,
+		Date:     row.Date,
+		ClosedAt: row.ClosedAt,
+		Version:  row.Version,
+		Exam:     &models.Exam{ID: exam.ID, Name: exam.Name, AssessmentType: exam.AssessmentType},
+		Student: &models.Student{
+			UUID:       student.UUID,
+			FirstName:  student.FirstName,
+			LastName:   student.LastName,
+			MiddleName: student.MiddleName,
+			Email:      student.Email,
+			Group:      &models.Group{ID: student.GroupID, Name: this.tables.groups[student.GroupID].Name},
+		},
+		Teacher: &models.Teacher{
+			UUID:       teacher.UUID,
+			FirstName:  teacher.FirstName,
+			LastName:   teacher.LastName,
+			MiddleName: teacher.MiddleName,
+			Email:      teacher.Email,
+		},
+	}
+	if room, ok := this.tables.rooms[row.RoomID]; ok {
+		debt.Room = &room
+	}
+	return debt
+}
+
+// debtInTrash plays debtNotDeleted, the student, the teacher or the exam of
+// the debt is in the trash.
+func (this *Store) debtInTrash(row debtRow) bool {
+	return this.inTrash(valueobjects.TrashStudentKind, row.StudentUUID) ||
+		this.inTrash(valueobjects.TrashTeacherKind, row.TeacherUUID) ||
+		this.inTrash(valueobjects.TrashExamKind, strconv.FormatInt(row.ExamID, 10))
+}
+
+// checkDebt plays the foreign keys of the debts table.
+func (this *Store) checkDebt(examID int64, studentUUID, teacherUUID string, roomID int64) error {
+	if _, ok := this.tables.exams[examID]; !ok {
+		return foreignKeyViolation("debts_exam_id_fkey")
+	}
+	if _, ok := this.tables.students[studentUUID]; !ok {
+		return foreignKeyViolation("debts_student_uuid_fkey")
+	}
+	if _, ok := this.tables.teachers[teacherUUID]; !ok {
+		return foreignKeyViolation("debts_teacher_uuid_fkey")
+	}
+	if _, ok := this.tables.rooms[roomID]; roomID != 0 && !ok {
+		return foreignKeyViolation("debts_room_id_fkey")
+	}
+	return nil
+}
+
+// deleteDebt drops the debt with everything that cascades from it.
+func (this *Store) deleteDebt(id int64) {
+	for requestID, request := range this.tables.requests {
+		if request.DebtID == id {
+			delete(this.tables.requests, requestID)
+		}
+	}
+	for bookingID, booking := range this.tables.bookings {
+		if booking.DebtID == id {
+			delete(this.tables.bookings, bookingID)
+		}
+	}
+	for resultID, result := range this.tables.debtResults {
+		if result.DebtID == id {
+			delete(this.tables.debtResults, resultID)
+		}
+	}
+	for itemID, item := range this.tables.draftItems {
+		if item.DebtID == id {
+			delete(this.tables.draftItems, itemID)
+		}
+	}
+	delete(this.tables.debts, id)
+}
+
+// SearchDebts implements repositories.ExamRepository.
+// Closed debts and debts in the trash are left out.
+func (this *examRepo) SearchDebts(ctx context.Context, filters query.SearchDebtsFilters) ([]models.Debt, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var debts []models.Debt
+	for _, row := range rowsOf(this.db.tables.debts, debtID) {
+		if row.ClosedAt != nil || this.db.debtInTrash(row) {
+			continue
+		}
+		debt := this.db.debt(row)
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, debt.ID) {
+			continue
+		}
+		if len(filters.ExamNames) > 0 && !slices.Contains(filters.ExamNames, debt.Exam.Name) {
+			continue
+		}
+		if len(filters.StudentUUIDs) > 0 && !slices.Contains(filters.StudentUUIDs, debt.Student.UUID) {
+			continue
+		}
+		if len(filters.TeacherUUIDs) > 0 && !slices.Contains(filters.TeacherUUIDs, debt.Teacher.UUID) {
+			continue
+		}
+		if len(filters.StudentEmails) > 0 && !slices.Contains(filters.StudentEmails, debt.Student.Email) {
+			continue
+		}
+		if len(filters.TeacherEmails) > 0 && !slices.Contains(filters.TeacherEmails, debt.Teacher.Email) {
+			continue
+		}
+		debts = append(debts, models.Debt{
+			ID:      debt.ID,
+			Date:    debt.Date,
+			Exam:    &models.Exam{ID: debt.Exam.ID, Name: debt.Exam.Name},
+			Student: debt.Student,
+			Teacher: debt.Teacher,
+		})
+	}
+
+	return debts, nil
+}
+
+// SearchExams implements repositories.ExamRepository.
+func (this *examRepo) SearchExams(ctx context.Context, filters query.SearchExamFilters) ([]models.Exam, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var exams []models.Exam
+	for _, exam := range rowsOf(this.db.tables.exams, examID) {
+		if this.db.inTrash(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10)) != filters.Deleted {
+			continue
+		}
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, exam.ID) {
+			continue
+		}
+		if len(filters.Names) > 0 && !slices.Contains(filters.Names, exam.Name) {
+			continue
+		}
+		exams = append(exams, exam)
+	}
+
+	return exams, nil
+}
+
+// CreateDebt implements repositories.ExamRepository.
+// The date is not stored, a new debt is not scheduled.
+func (this *examRepo) CreateDebt(ctx context.Context, createDebt commands.CreateDebt) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if err := this.db.checkDebt(createDebt.ExamID, createDebt.StudentUUID, createDebt.TeacherUUID, 0); err != nil {
+		return 0, err
+	}
+
+	id := this.db.nextID()
+	this.db.tables.debts[id] = debtRow{
+		ID:          id,
+		ExamID:      createDebt.ExamID,
+		StudentUUID: createDebt.StudentUUID,
+		TeacherUUID: createDebt.TeacherUUID,
+		Version:     1,
+	}
+	return id, nil
+}
+
+// DeleteDebt implements repositories.ExamRepository.
+func (this *examRepo) DeleteDebt(ctx context.Context, debt commands.DeleteDebt) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	this.db.deleteDebt(debt.ID)
+	return nil
+}
+
+// GetExamByID implements repositories.ExamRepository.
+func (this *examRepo) GetExamByID(ctx context.Context, query query.GetExamsFilters) (*models.Exam, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	for _, exam := range rowsOf(this.db.tables.exams, examID) {
+		if slices.Contains(query.IDs, exam.ID) && !this.db.inTrash(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10)) {
+			return &exam, nil
+		}
+	}
+
+	return nil, log.ErrorWrapper(pgx.ErrNoRows, errors.ERR_INFRASTRUCTURE, "")
+}
+
+// DeleteExam implements repositories.ExamRepository.
+func (this *examRepo) DeleteExam(ctx context.Context, exam commands.DeleteExam) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.exams[exam.ID]; ok {
+		this.db.moveToTrash(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10))
+	}
+	return nil
+}
+
+// UpdateExam implements repositories.ExamRepository.
+func (this *examRepo) UpdateExam(ctx context.Context, exam commands.UpdateExamByID) error {
+	if err := exam.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.exams[exam.ID]
+	if update, err := this.db.checkVersion(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10), ok, row.Version, exam.Version); !update {
+		return err
+	}
+
+	row.Name = exam.Name
+	if exam.AssessmentType != "" {
+		row.AssessmentType = exam.AssessmentType
+	}
+	row.Version++
+	this.db.tables.exams[exam.ID] = row
+	return nil
+}
+
+// CreateExam implements repositories.ExamRepository.
+func (this *examRepo) CreateExam(ctx context.Context, exam commands.CreateExam) (int64, error) {
+	if err := exam.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	id := this.db.nextID()
+	this.db.tables.exams[id] = models.Exam{ID: id, Name: exam.Name, AssessmentType: exam.AssessmentType, Version: 1}
+	return id, nil
+}
+
+// GetExams implements repositories.ExamRepository.
+func (this *examRepo) GetExams(ctx context.Context, filters query.GetExamsFilters) ([]models.Exam, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	result, err := orderByKeyset(this.db.exams(filters), query.ExamSortFields, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CountExams implements repositories.ExamRepository.
+func (this *examRepo) CountExams(ctx context.Context, filters query.GetExamsFilters) (int64, error) {
+	if err := filters.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	return int64(len(this.db.exams(filters))), nil
+}
+
+func (this *Store) exams(filters query.GetExamsFilters) []models.Exam {
+	var result []models.Exam
+	for _, exam := range rowsOf(this.tables.exams, examID) {
+		if this.inTrash(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10)) {
+			continue
+		}
+		if len(filters.IDs) != 0 && !slices.Contains(filters.IDs, exam.ID) {
+			continue
+		}
+		if len(filters.Names) != 0 && !slices.Contains(filters.Names, exam.Name) {
+			continue
+		}
+		if len(filters.AssessmentTypes) != 0 && !slices.Contains(filters.AssessmentTypes, exam.AssessmentType) {
+			continue
+		}
+		result = append(result, exam)
+	}
+	return result
+}
+
+// GetDebts implements repositories.ExamRepository.
+func (this *examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters) ([]models.Debt, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	result, err := orderByKeyset(this.db.debts(filters), query.DebtSortFields, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
+	result = page(result, filters.Limit, filters.Offset)
+	if filters.WithExamGroups {
+		this.db.withExamGroups(result)
+	}
+
+	return result, nil
+}
+
+// withExamGroups plays the exam_groups of the query, the groups of the
+// open debts of every exam ordered by id, debts in the trash left out.
+func (this *Store) withExamGroups(debts []models.Debt) {
+	groups := make(map[int64][]models.Group)
+	for _, debt := range this.debts(query.GetDebtsFilters{}) {
+		group := models.Group{ID: debt.Student.Group.ID, Name: debt.Student.Group.Name}
+		if !slices.ContainsFunc(groups[debt.Exam.ID], func(g models.Group) bool { return g.ID == group.ID }) {
+			groups[debt.Exam.ID] = append(groups[debt.Exam.ID], group)
+		}
+	}
+	for _, list := range groups {
+		slices.SortFunc(list, func(a, b models.Group) int { return cmp.Compare(a.ID, b.ID) })
+	}
+
+	for i, debt := range debts {
+		debts[i].GroupList = append([]models.Group{}, groups[debt.Exam.ID]...)
+	}
+}
+
+// CountDebts implements repositories.ExamRepository.
+func (this *examRepo) CountDebts(ctx context.Context, filters query.GetDebtsFilters) (int64, error) {
+	if err := filters.Validate(); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	return int64(len(this.db.debts(filters))), nil
+}
+
+func (this *Store) debts(filters query.GetDebtsFilters) []models.Debt {
+	var result []models.Debt
+	for _, row := range rowsOf(this.tables.debts, debtID) {
+		if !filters.IncludeDeleted && this.debtInTrash(row) {
+			continue
+		}
+		debt := this.debt(row)
+		if len(filters.StudentUUIDs) > 0 && !slices.Contains(filters.StudentUUIDs, debt.Student.UUID) {
+			continue
+		}
+		if len(filters.TeacherUUIDs) > 0 && !slices.Contains(filters.TeacherUUIDs, debt.Teacher.UUID) {
+			continue
+		}
+		if len(filters.ExamIDs) > 0 && !slices.Contains(filters.ExamIDs, debt.Exam.ID) {
+			continue
+		}
+		if len(filters.DebtIDs) > 0 && !slices.Contains(filters.DebtIDs, debt.ID) {
+			continue
+		}
+		if len(filters.GroupIDs) > 0 && !slices.Contains(filters.GroupIDs, debt.Student.Group.ID) {
+			continue
+		}
+		if filters.OnlyUnscheduled && debt.Date != nil {
+			continue
+		}
+		if !filters.IncludeClosed && debt.ClosedAt != nil {
+			continue
+		}
+		// a comparison with a null date is not true
+		if !filters.ScheduledFrom.IsZero() && (debt.Date == nil || debt.Date.Before(filters.ScheduledFrom)) {
+			continue
+		}
+		if !filters.ScheduledTo.IsZero() && (debt.Date == nil || !debt.Date.Before(filters.ScheduledTo)) {
+			continue
+		}
+		result = append(result, debt)
+	}
+	return result
+}
+
+// UpdateDebt implements repositories.ExamRepository.
+func (this *examRepo) UpdateDebt(ctx context.Context, setCommand commands.UpdateDebtByID) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.debts[setCommand.DebtID]
+	if update, err := this.db.checkVersion(debtKind, strconv.FormatInt(setCommand.DebtID, 10), ok, row.Version, setCommand.Version); !update {
+		return err
+	}
+	if err := this.db.checkDebt(row.ExamID, setCommand.StudentUUID, setCommand.TeacherUUID, setCommand.RoomID); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	// zero date unschedules the debt
+	row.Date = nil
+	if !setCommand.Date.IsZero() {
+		date := setCommand.Date
+		row.Date = &date
+	}
+	row.RoomID = setCommand.RoomID
+	row.TeacherUUID = setCommand.TeacherUUID
+	row.StudentUUID = setCommand.StudentUUID
+	row.Address = setCommand.Address
+	row.Version++
+	this.db.tables.debts[setCommand.DebtID] = row
+	return nil
+}
+
+// CreateDebtResult implements repositories.ExamRepository.
+func (this *examRepo) CreateDebtResult(ctx context.Context, result commands.CreateDebtResult) (int64, error) {
+	if err := result.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.debts[result.DebtID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("debt_results_debt_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.sessions[result.SessionID]; result.SessionID != 0 && !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("debt_results_session_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.teachers[result.TeacherUUID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("debt_results_teacher_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	for _, recorded := range this.db.tables.debtResults {
+		if recorded.DebtID == result.DebtID && recorded.AttemptedAt.Equal(result.AttemptedAt) {
+			return 0, log.ErrorWrapper(errors.ErrResultAlreadyRecorded, errors.ERR_INFRASTRUCTURE, "", "debt", result.DebtID)
+		}
+	}
+
+	id := this.db.nextID()
+	this.db.tables.debtResults[id] = models.DebtResult{
+		ID:          id,
+		DebtID:      result.DebtID,
+		SessionID:   result.SessionID,
+		TeacherUUID: result.TeacherUUID,
+		Passed:      result.Passed,
+		Grade:       result.Grade,
+		AttemptedAt: result.AttemptedAt,
+		RecordedAt:  now(),
+	}
+	return id, nil
+}
+
+// GetDebtResults implements repositories.ExamRepository.
+// Results are ordered by the attempt.
+func (this *examRepo) GetDebtResults(ctx context.Context, filters query.GetDebtResultsFilters) ([]models.DebtResult, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.DebtResult
+	for _, item := range rowsOf(this.db.tables.debtResults, resultID) {
+		debt, ok := this.db.tables.debts[item.DebtID]
+		if !ok {
+			continue
+		}
+		if len(filters.DebtIDs) > 0 && !slices.Contains(filters.DebtIDs, item.DebtID) {
+			continue
+		}
+		if len(filters.SessionIDs) > 0 && !slices.Contains(filters.SessionIDs, item.SessionID) {
+			continue
+		}
+		if len(filters.StudentUUIDs) > 0 && !slices.Contains(filters.StudentUUIDs, debt.StudentUUID) {
+			continue
+		}
+		result = append(result, item)
+	}
+	slices.SortStableFunc(result, func(a, b models.DebtResult) int { return a.AttemptedAt.Compare(b.AttemptedAt) })
+
+	return result, nil
+}
+
+// CloseDebt implements repositories.ExamRepository.
+// A closed debt keeps the time it was closed first.
+func (this *examRepo) CloseDebt(ctx context.Context, command commands.CloseDebt) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.debts[command.DebtID]
+	if !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "debt", command.DebtID)
+	}
+	if row.ClosedAt != nil {
+		return log.ErrorWrapper(errors.ErrDebtClosed, errors.ERR_INFRASTRUCTURE, "", "debt", command.DebtID)
+	}
+	closedAt := now()
+	row.ClosedAt = &closedAt
+	row.Version++
+	this.db.tables.debts[command.DebtID] = row
+	return nil
+}
+
+// LockDebt implements repositories.ExamRepository.
+// Transactions are serialized already, it only checks that the debt exists.
+func (this *examRepo) LockDebt(ctx context.Context, id int64) error {
+	if !inTransaction(ctx) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "debt can be locked inside a transaction only")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.debts[id]; !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+	return nil
+}
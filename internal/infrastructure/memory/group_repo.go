@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type groupRepo struct {
+	db *Store
+}
+
+func NewGroupRepo(db *Store) repositories.GroupRepository {
+	return &groupRepo{
+		db: db,
+	}
+}
+
+// SearchGroups implements repositories.GroupRepository.
+func (this *groupRepo) SearchGroups(ctx context.Context, filters query.SearchGroupFilters) ([]models.Group, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.Group
+	for _, group := range rowsOf(this.db.tables.groups, func(group models.Group) int64 { return group.ID }) {
+		if this.db.inTrash(valueobjects.TrashGroupKind, strconv.FormatInt(group.ID, 10)) != filters.Deleted {
+			continue
+		}
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, group.ID) {
+			continue
+		}
+		if len(filters.Names) > 0 && !slices.Contains(filters.Names, group.Name) {
+			continue
+		}
+		result = append(result, group)
+	}
+
+	return result, nil
+}
+
+// CreateGroup implements repositories.GroupRepository.
+func (this *groupRepo) CreateGroup(ctx context.Context, group commands.CreateGroup) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	id := this.db.nextID()
+	this.db.tables.groups[id] = models.Group{ID: id, Name: group.Name, Version: 1}
+	return id, nil
+}
+
+// DeleteGroup implements repositories.GroupRepository.
+func (this *groupRepo) DeleteGroup(ctx context.Context, group commands.DeleteGroup) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.groups[group.ID]; ok {
+		this.db.moveToTrash(valueobjects.TrashGroupKind, strconv.FormatInt(group.ID, 10))
+	}
+	return nil
+}
+
+// GetGroupByID implements repositories.GroupRepository.
+func (this *groupRepo) GetGroupByID(ctx context.Context, id int64) (*models.Group, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	group, ok := this.db.tables.groups[id]
+	if !ok || this.db.inTrash(valueobjects.TrashGroupKind, strconv.FormatInt(id, 10)) {
+		return nil, log.ErrorWrapper(pgx.ErrNoRows, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return &group, nil
+}
+
+// GetGroups implements repositories.GroupRepository.
+func (this *groupRepo) GetGroups(ctx context.Context, filters query.GetGroupsFilters) ([]models.Group, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	result, err := orderByKeyset(this.db.groups(filters), query.GroupSortFields, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CountGroups implements repositories.GroupRepository.
+func (this *groupRepo) CountGroups(ctx context.Context, filters query.GetGroupsFilters) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	return int64(len(this.db.groups(filters))), nil
+}
+
+func (this *Store) groups(filters query.GetGroupsFilters) []models.Group {
+	var result []models.Group
+	for _, group := range rowsOf(this.tables.groups, func(group models.Group) int64 { return group.ID }) {
+		if this.inTrash(valueobjects.TrashGroupKind, strconv.FormatInt(group.ID, 10)) {
+			continue
+		}
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, group.ID) {
+			continue
+		}
+		if len(filters.Names) > 0 && !slices.Contains(filters.Names, group.Name) {
+			continue
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// UpdateGroup implements repositories.GroupRepository.
+func (this *groupRepo) UpdateGroup(ctx context.Context, group commands.UpdateGroup) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.groups[group.ID]
+	if update, err := this.db.checkVersion(valueobjects.TrashGroupKind, strconv.FormatInt(group.ID, 10), ok, row.Version, group.Version); !update {
+		return err
+	}
+
+	row.Name = group.Name
+	row.Version++
+	this.db.tables.groups[group.ID] = row
+	return nil
+}
@@ -0,0 +1,576 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type importEntityKey struct {
+	entity   string
+	entityID string
+}
+
+// importEntityRow keeps the insert order, created_at is the same for the
+// entities tracked in one batch.
+type importEntityRow struct {
+	seq int64
+	models.ImportJobEntity
+}
+
+type importRepo struct {
+	db *Store
+}
+
+func NewImportRepo(db *Store) repositories.ImportRepository {
+	return &importRepo{
+		db: db,
+	}
+}
+
+func profileID(profile models.ImportProfile) int64 { return profile.ID }
+func jobID(job models.ImportJob) int64             { return job.ID }
+func entitySeq(entity importEntityRow) int64       { return entity.seq }
+
+// orEmpty plays the not null jsonb columns, a nil slice is stored as [].
+// The slice is copied, as the column would be.
+func orEmpty[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return slices.Clone(values)
+}
+
+// CreateImportPreview implements repositories.ImportRepository.
+func (this *importRepo) CreateImportPreview(ctx context.Context, preview commands.CreateImportPreview) error {
+	if err := preview.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.previews[preview.Token]; ok {
+		return log.ErrorWrapper(uniqueViolation("import_previews_pkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	row := models.ImportPreview{
+		Token:     preview.Token,
+		CreatedBy: preview.CreatedBy,
+		Records:   orEmpty(preview.Records),
+		Issues:    orEmpty(preview.Issues),
+		CreatedAt: now(),
+		ExpiresAt: preview.ExpiresAt,
+	}
+	if preview.ProfileID != 0 {
+		if _, ok := this.db.tables.profiles[preview.ProfileID]; !ok {
+			return log.ErrorWrapper(foreignKeyViolation("import_previews_profile_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+		}
+		id := preview.ProfileID
+		row.ProfileID = &id
+	}
+	this.db.tables.previews[preview.Token] = row
+	return nil
+}
+
+// GetImportPreview implements repositories.ImportRepository.
+func (this *importRepo) GetImportPreview(ctx context.Context, token string) (*models.ImportPreview, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	preview, ok := this.db.tables.previews[token]
+	if !ok {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return &preview, nil
+}
+
+// ConfirmImportPreview implements repositories.ImportRepository.
+func (this *importRepo) ConfirmImportPreview(ctx context.Context, confirm commands.ConfirmImportPreview) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	preview, ok := this.db.tables.previews[confirm.Token]
+	if !ok || preview.ConfirmedAt != nil || !preview.ExpiresAt.After(now()) {
+		return log.ErrorWrapper(errors.ErrImportPreviewClosed, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	confirmedAt := now()
+	preview.ConfirmedAt = &confirmedAt
+	this.db.tables.previews[confirm.Token] = preview
+	return nil
+}
+
+// GetImportProfiles implements repositories.ImportRepository.
+func (this *importRepo) GetImportProfiles(ctx context.Context, filters query.GetImportProfilesFilters) ([]models.ImportProfile, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.profiles, profileID)
+	slices.SortStableFunc(rows, func(a, b models.ImportProfile) int { return strings.Compare(a.Name, b.Name) })
+
+	var result []models.ImportProfile
+	for _, profile := range rows {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, profile.ID) {
+			continue
+		}
+		result = append(result, profile)
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// importProfile keeps the arrays and the map not null, the columns are not
+// null in the table.
+func importProfile(id int64, name, layout string, sheets []string, headerRow int64, separator string, studentFields, teacherFields []string, columns map[string]string) models.ImportProfile {
+	profile := models.ImportProfile{
+		ID:            id,
+		Name:          name,
+		Layout:        layout,
+		Sheets:        orEmpty(sheets),
+		HeaderRow:     headerRow,
+		Separator:     separator,
+		StudentFields: orEmpty(studentFields),
+		TeacherFields: orEmpty(teacherFields),
+		Columns:       map[string]string{},
+	}
+	for field, column := range columns {
+		profile.Columns[field] = column
+	}
+
+	return profile
+}
+
+func (this *Store) checkImportProfileName(id int64, name string) error {
+	for _, profile := range this.tables.profiles {
+		if profile.ID != id && profile.Name == name {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "import profile already exists")
+		}
+	}
+	return nil
+}
+
+// CreateImportProfile implements repositories.ImportRepository.
+func (this *importRepo) CreateImportProfile(ctx context.Context, profile commands.CreateImportProfile) (int64, error) {
+	if err := profile.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if err := this.db.checkImportProfileName(0, profile.Name); err != nil {
+		return 0, err
+	}
+
+	id := this.db.nextID()
+	row := importProfile(id, profile.Name, profile.Layout, profile.Sheets, profile.HeaderRow, profile.Separator, profile.StudentFields, profile.TeacherFields, profile.Columns)
+	row.CreatedAt = now()
+	this.db.tables.profiles[id] = row
+	return id, nil
+}
+
+// UpdateImportProfile implements repositories.ImportRepository.
+func (this *importRepo) UpdateImportProfile(ctx context.Context, profile commands.UpdateImportProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	current, ok := this.db.tables.profiles[profile.ID]
+	if !ok {
+		return nil
+	}
+	if err := this.db.checkImportProfileName(profile.ID, profile.Name); err != nil {
+		return err
+	}
+
+	row := importProfile(profile.ID, profile.Name, profile.Layout, profile.Sheets, profile.HeaderRow, profile.Separator, profile.StudentFields, profile.TeacherFields, profile.Columns)
+	row.CreatedAt = current.CreatedAt
+	this.db.tables.profiles[profile.ID] = row
+	return nil
+}
+
+// DeleteImportProfile implements repositories.ImportRepository.
+// Previews and jobs made with the profile lose the reference.
+func (this *importRepo) DeleteImportProfile(ctx context.Context, profile commands.DeleteImportProfile) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	for token, preview := range this.db.tables.previews {
+		if preview.ProfileID != nil && *preview.ProfileID == profile.ID {
+			preview.ProfileID = nil
+			this.db.tables.previews[token] = preview
+		}
+	}
+	for id, job := range this.db.tables.jobs {
+		if job.ProfileID != nil && *job.ProfileID == profile.ID {
+			job.ProfileID = nil
+			this.db.tables.jobs[id] = job
+		}
+	}
+	delete(this.db.tables.profiles, profile.ID)
+	return nil
+}
+
+// CreateImportJob implements repositories.ImportRepository.
+func (this *importRepo) CreateImportJob(ctx context.Context, job commands.CreateImportJob) (int64, error) {
+	if err := job.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	createdAt := now()
+	row := models.ImportJob{
+		Source:    job.Source,
+		Status:    valueobjects.ImportQueuedStatus,
+		Actor:     job.Actor,
+		FileName:  job.FileName,
+		Checksum:  job.Checksum,
+		Records:   orEmpty(job.Records),
+		Issues:    orEmpty(job.Issues),
+		Errors:    []models.ImportIssue{},
+		Total:     int64(len(job.Records)),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	// the request creating a bulk job runs it
+	if job.Source == valueobjects.ImportBulkSource {
+		row.Status = valueobjects.ImportRunningStatus
+		row.Total = job.Total
+		row.Attempts = 1
+		row.StartedAt = &createdAt
+	}
+	if job.ProfileID != 0 {
+		if _, ok := this.db.tables.profiles[job.ProfileID]; !ok {
+			return 0, log.ErrorWrapper(foreignKeyViolation("import_jobs_profile_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+		}
+		id := job.ProfileID
+		row.ProfileID = &id
+	}
+	if job.PreviewToken != "" {
+		if _, ok := this.db.tables.previews[job.PreviewToken]; !ok {
+			return 0, log.ErrorWrapper(foreignKeyViolation("import_jobs_preview_token_fkey"), errors.ERR_INFRASTRUCTURE, "")
+		}
+		token := job.PreviewToken
+		row.PreviewToken = &token
+	}
+
+	row.ID = this.db.nextID()
+	this.db.tables.jobs[row.ID] = row
+	return row.ID, nil
+}
+
+// GetImportJobs implements repositories.ImportRepository.
+// The newest jobs go first.
+func (this *importRepo) GetImportJobs(ctx context.Context, filters query.GetImportJobsFilters) ([]models.ImportJob, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.jobs, jobID)
+	slices.Reverse(rows)
+
+	var result []models.ImportJob
+	for _, job := range rows {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, job.ID) {
+			continue
+		}
+		if len(filters.Statuses) > 0 && !slices.Contains(filters.Statuses, job.Status) {
+			continue
+		}
+		if len(filters.Actors) > 0 && !slices.Contains(filters.Actors, job.Actor) {
+			continue
+		}
+		if len(filters.Checksums) > 0 && !slices.Contains(filters.Checksums, job.Checksum) {
+			continue
+		}
+		if !filters.WithRecords {
+			job.Records = []models.ImportRecord{}
+		}
+		result = append(result, job)
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// ClaimImportJob implements repositories.ImportRepository.
+// A claimed job starts over with zeroed counters.
+func (this *importRepo) ClaimImportJob(ctx context.Context, lease time.Duration) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	claimedAt := now()
+	for _, job := range rowsOf(this.db.tables.jobs, jobID) {
+		expired := job.Status == valueobjects.ImportRunningStatus && job.UpdatedAt.Before(claimedAt.Add(-lease))
+		if job.Status != valueobjects.ImportQueuedStatus && !expired {
+			continue
+		}
+
+		job.Status = valueobjects.ImportRunningStatus
+		job.Attempts++
+		job.Processed, job.Created, job.Existing, job.Failed = 0, 0, 0, 0
+		job.Errors = []models.ImportIssue{}
+		job.StartedAt = &claimedAt
+		job.UpdatedAt = claimedAt
+		job.FinishedAt = nil
+		this.db.tables.jobs[job.ID] = job
+		return job.ID, nil
+	}
+
+	return 0, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+}
+
+// UpdateImportJob implements repositories.ImportRepository.
+func (this *importRepo) UpdateImportJob(ctx context.Context, job commands.UpdateImportJob) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.jobs[job.ID]
+	if !ok {
+		return nil
+	}
+
+	row.Status = job.Status
+	row.Processed = job.Processed
+	row.Created = job.Created
+	row.Existing = job.Existing
+	row.Failed = job.Failed
+	row.Errors = orEmpty(job.Errors)
+	row.UpdatedAt = now()
+	if job.Status == valueobjects.ImportCompletedStatus || job.Status == valueobjects.ImportFailedStatus {
+		finishedAt := row.UpdatedAt
+		row.FinishedAt = &finishedAt
+	}
+	this.db.tables.jobs[job.ID] = row
+	return nil
+}
+
+// RenewImportJob implements repositories.ImportRepository.
+func (this *importRepo) RenewImportJob(ctx context.Context, job commands.RenewImportJob) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.jobs[job.ID]
+	if !ok || row.Status != valueobjects.ImportRunningStatus {
+		return nil
+	}
+
+	row.UpdatedAt = now()
+	this.db.tables.jobs[job.ID] = row
+	return nil
+}
+
+// RequeueImportJob implements repositories.ImportRepository.
+func (this *importRepo) RequeueImportJob(ctx context.Context, job commands.RequeueImportJob) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.jobs[job.ID]
+	if !ok || row.Status == valueobjects.ImportQueuedStatus || row.Status == valueobjects.ImportRunningStatus {
+		return log.ErrorWrapper(errors.ErrImportJobActive, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	row.Status = valueobjects.ImportQueuedStatus
+	row.UpdatedAt = now()
+	row.RolledBackAt = nil
+	this.db.tables.jobs[job.ID] = row
+	return nil
+}
+
+// AddImportJobEntities implements repositories.ImportRepository.
+// An entity tracked already keeps its job.
+func (this *importRepo) AddImportJobEntities(ctx context.Context, add commands.AddImportJobEntities) error {
+	if err := add.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.jobs[add.JobID]; !ok && len(add.Entities) > 0 {
+		return log.ErrorWrapper(foreignKeyViolation("import_job_entities_job_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	createdAt := now()
+	for _, entity := range add.Entities {
+		key := importEntityKey{entity: entity.Entity, entityID: entity.EntityID}
+		if _, ok := this.db.tables.entities[key]; ok {
+			continue
+		}
+		this.db.tables.entities[key] = importEntityRow{
+			seq: this.db.nextID(),
+			ImportJobEntity: models.ImportJobEntity{
+				JobID:     add.JobID,
+				Entity:    entity.Entity,
+				EntityID:  entity.EntityID,
+				CreatedAt: createdAt,
+			},
+		}
+	}
+
+	return nil
+}
+
+// GetImportJobEntities implements repositories.ImportRepository.
+func (this *importRepo) GetImportJobEntities(ctx context.Context, jobID int64) ([]models.ImportJobEntity, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.entities, entitySeq)
+	slices.Reverse(rows)
+
+	var result []models.ImportJobEntity
+	for _, row := range rows {
+		if row.JobID == jobID {
+			result = append(result, row.ImportJobEntity)
+		}
+	}
+
+	return result, nil
+}
+
+// importEntityReferences reports whether an imported entity exists and
+// what keeps it in place, by the names the postgres rollback reports.
+func (this *Store) importEntityReferences(entity, uuid string, id int64) (bool, []string) {
+	var references []string
+	reference := func(name string, found bool) {
+		if found {
+			references = append(references, name)
+		}
+	}
+
+	switch entity {
+	case valueobjects.ImportDebtEntity:
+		debt, ok := this.tables.debts[id]
+		if !ok {
+			return false, nil
+		}
+		reference("rooms", debt.RoomID != 0)
+		reference("debt_results", anyRow(this.tables.debtResults, func(row models.DebtResult) bool { return row.DebtID == id }))
+		reference("retake_requests", anyRow(this.tables.requests, func(row retakeRequestRow) bool { return row.DebtID == id }))
+		reference("retake_bookings", anyRow(this.tables.bookings, func(row retakeBookingRow) bool { return row.DebtID == id }))
+		reference("timetable_draft_items", anyRow(this.tables.draftItems, func(row timetableDraftItemRow) bool { return row.DebtID == id }))
+	case valueobjects.ImportStudentEntity:
+		if _, ok := this.tables.students[uuid]; !ok {
+			return false, nil
+		}
+		reference("debts", anyRow(this.tables.debts, func(row debtRow) bool { return row.StudentUUID == uuid }))
+		reference("retake_requests", anyRow(this.tables.requests, func(row retakeRequestRow) bool { return row.StudentUUID == uuid }))
+		reference("retake_bookings", anyRow(this.tables.bookings, func(row retakeBookingRow) bool { return row.StudentUUID == uuid }))
+	case valueobjects.ImportTeacherEntity:
+		if _, ok := this.tables.teachers[uuid]; !ok {
+			return false, nil
+		}
+		reference("debts", anyRow(this.tables.debts, func(row debtRow) bool { return row.TeacherUUID == uuid }))
+		reference("debt_results", anyRow(this.tables.debtResults, func(row models.DebtResult) bool { return row.TeacherUUID == uuid }))
+		reference("retake_requests", anyRow(this.tables.requests, func(row retakeRequestRow) bool { return row.TeacherUUID == uuid }))
+		reference("retake_sessions", anyRow(this.tables.sessions, func(row retakeSessionRow) bool { return row.TeacherUUID == uuid }))
+		reference("teacher_availability", anyRow(this.tables.availability, func(row models.TeacherAvailability) bool { return row.TeacherUUID == uuid }))
+	case valueobjects.ImportExamEntity:
+		if _, ok := this.tables.exams[id]; !ok {
+			return false, nil
+		}
+		reference("debts", anyRow(this.tables.debts, func(row debtRow) bool { return row.ExamID == id }))
+		reference("retake_sessions", anyRow(this.tables.sessions, func(row retakeSessionRow) bool { return row.ExamID == id }))
+	case valueobjects.ImportGroupEntity:
+		if _, ok := this.tables.groups[id]; !ok {
+			return false, nil
+		}
+		reference("students", anyRow(this.tables.students, func(row studentRow) bool { return row.GroupID == id }))
+	}
+
+	return true, references
+}
+
+func anyRow[K comparable, V any](table map[K]V, match func(V) bool) bool {
+	for _, row := range table {
+		if match(row) {
+			return true
+		}
+	}
+	return false
+}
+
+// RollbackImportJobEntity implements repositories.ImportRepository.
+func (this *importRepo) RollbackImportJobEntity(ctx context.Context, rollback commands.RollbackImportJobEntity) ([]string, error) {
+	if err := rollback.Validate(); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	if rollback.Entity != valueobjects.ImportStudentEntity && rollback.Entity != valueobjects.ImportTeacherEntity {
+		var err error
+		if id, err = strconv.ParseInt(rollback.EntityID, 10, 64); err != nil {
+			return nil, log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, err.Error())
+		}
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	exists, references := this.db.importEntityReferences(rollback.Entity, rollback.EntityID, id)
+	if len(references) > 0 {
+		return references, nil
+	}
+	if exists {
+		switch rollback.Entity {
+		case valueobjects.ImportDebtEntity:
+			this.db.deleteDebt(id)
+		case valueobjects.ImportStudentEntity:
+			delete(this.db.tables.students, rollback.EntityID)
+		case valueobjects.ImportTeacherEntity:
+			delete(this.db.tables.teachers, rollback.EntityID)
+		case valueobjects.ImportExamEntity:
+			delete(this.db.tables.exams, id)
+		case valueobjects.ImportGroupEntity:
+			delete(this.db.tables.groups, id)
+		}
+	}
+
+	delete(this.db.tables.entities, importEntityKey{entity: rollback.Entity, entityID: rollback.EntityID})
+	return nil, nil
+}
+
+// FinishImportJobRollback implements repositories.ImportRepository.
+func (this *importRepo) FinishImportJobRollback(ctx context.Context, finish commands.FinishImportJobRollback) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	job, ok := this.db.tables.jobs[finish.ID]
+	if !ok {
+		return nil
+	}
+
+	rolledBackAt := now()
+	job.RolledBackAt = &rolledBackAt
+	job.UpdatedAt = rolledBackAt
+	this.db.tables.jobs[finish.ID] = job
+	return nil
+}
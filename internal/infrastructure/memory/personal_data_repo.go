@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type personalDataRepo struct {
+	db *Store
+}
+
+func NewPersonalDataRepo(db *Store) repositories.PersonalDataRepository {
+	return &personalDataRepo{
+		db: db,
+	}
+}
+
+// GetStudentStatus implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetStudentStatus(ctx context.Context, uuid string) (*models.StudentStatus, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.students[uuid]
+	if !ok {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", uuid)
+	}
+
+	result := &models.StudentStatus{
+		UUID:         row.UUID,
+		Status:       row.Status,
+		LeftAt:       row.LeftAt,
+		AnonymizedAt: row.AnonymizedAt,
+	}
+	if deletedAt, ok := this.db.tables.trash[trashKey{kind: valueobjects.TrashStudentKind, id: uuid}]; ok {
+		result.DeletedAt = &deletedAt
+	}
+
+	return result, nil
+}
+
+// GetStudentPersonalData implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetStudentPersonalData(ctx context.Context, uuid string) (*models.Student, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.students[uuid]
+	if !ok {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", uuid)
+	}
+
+	result := this.db.student(row)
+	return &result, nil
+}
+
+// SetStudentStatus implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) SetStudentStatus(ctx context.Context, status commands.SetStudentStatus) error {
+	if err := status.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.students[status.UUID]
+	if !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", status.UUID)
+	}
+
+	row.Status = status.Status
+	row.LeftAt = nil
+	if !status.LeftAt.IsZero() {
+		leftAt := status.LeftAt
+		row.LeftAt = &leftAt
+	}
+	row.Version++
+	this.db.tables.students[status.UUID] = row
+	return nil
+}
+
+// AnonymizeStudents implements repositories.PersonalDataRepository. The
+// students are anonymized in the import previews and jobs as well.
+func (this *personalDataRepo) AnonymizeStudents(ctx context.Context, anonymize commands.AnonymizeStudents) ([]string, error) {
+	if err := anonymize.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []string
+	emails := make(map[string]string)
+	for _, row := range rowsOf(this.db.tables.students, studentSeq) {
+		if row.AnonymizedAt != nil {
+			continue
+		}
+		if len(anonymize.UUIDs) != 0 {
+			if !slices.Contains(anonymize.UUIDs, row.UUID) {
+				continue
+			}
+		} else if row.Status == valueobjects.StudentActiveStatus || row.LeftAt == nil || !row.LeftAt.Before(anonymize.LeftBefore) {
+			continue
+		}
+
+		anonymizedAt := now()
+		if row.Email != "" {
+			emails[row.Email] = valueobjects.AnonymizedEmailPrefix + row.UUID
+		}
+		row.FirstName = ""
+		row.LastName = ""
+		row.MiddleName = ""
+		row.Email = valueobjects.AnonymizedEmailPrefix + row.UUID
+		row.Password = ""
+		row.AnonymizedAt = &anonymizedAt
+		row.Version++
+		this.db.tables.students[row.UUID] = row
+		result = append(result, row.UUID)
+	}
+	if len(emails) != 0 {
+		this.db.anonymizeImports(emails)
+	}
+
+	return result, nil
+}
+
+// anonymizeImports anonymizes the students of emails in the records,
+// issues and errors of the import previews and jobs. The slices are copied,
+// a transaction snapshot shares them.
+func (this *Store) anonymizeImports(emails map[string]string) {
+	for token, preview := range this.tables.previews {
+		preview.Records = anonymizedRecords(preview.Records, emails)
+		preview.Issues = anonymizedIssues(preview.Issues, emails)
+		this.tables.previews[token] = preview
+	}
+	for id, job := range this.tables.jobs {
+		job.Records = anonymizedRecords(job.Records, emails)
+		job.Issues = anonymizedIssues(job.Issues, emails)
+		job.Errors = anonymizedIssues(job.Errors, emails)
+		this.tables.jobs[id] = job
+	}
+}
+
+func anonymizedRecords(records []models.ImportRecord, emails map[string]string) []models.ImportRecord {
+	records = slices.Clone(records)
+	for i := range records {
+		records[i].Anonymize(emails)
+	}
+	return records
+}
+
+func anonymizedIssues(issues []models.ImportIssue, emails map[string]string) []models.ImportIssue {
+	issues = slices.Clone(issues)
+	for i := range issues {
+		issues[i].Anonymize(emails)
+	}
+	return issues
+}
+
+// CreatePersonalDataAudit implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) CreatePersonalDataAudit(ctx context.Context, audit commands.CreatePersonalDataAudit) (int64, error) {
+	if err := audit.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	id := this.db.nextID()
+	this.db.tables.audit[id] = models.PersonalDataAudit{
+		ID:          id,
+		StudentUUID: audit.StudentUUID,
+		Action:      audit.Action,
+		ActorUUID:   audit.ActorUUID,
+		CreatedAt:   now(),
+	}
+	return id, nil
+}
+
+// GetPersonalDataAudit implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetPersonalDataAudit(ctx context.Context, filters query.GetPersonalDataAuditFilters) ([]models.PersonalDataAudit, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.PersonalDataAudit
+	for _, audit := range rowsOf(this.db.tables.audit, func(audit models.PersonalDataAudit) int64 { return audit.ID }) {
+		if len(filters.StudentUUIDs) != 0 && !slices.Contains(filters.StudentUUIDs, audit.StudentUUID) {
+			continue
+		}
+		if len(filters.Actions) != 0 && !slices.Contains(filters.Actions, audit.Action) {
+			continue
+		}
+		result = append(result, audit)
+	}
+
+	return result, nil
+}
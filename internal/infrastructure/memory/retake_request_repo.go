@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// retakeRequestRow keeps the preferred slots of the request, they are
+// deleted together with it.
+type retakeRequestRow struct {
+	ID             int64
+	DebtID         int64
+	StudentUUID    string
+	TeacherUUID    string
+	Status         string
+	PreferredSlots []models.TimeSlot
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type retakeRequestRepo struct {
+	db *Store
+}
+
+func NewRetakeRequestRepo(db *Store) repositories.RetakeRequestRepository {
+	return &retakeRequestRepo{
+		db: db,
+	}
+}
+
+func requestID(request retakeRequestRow) int64 { return request.ID }
+
+// GetRetakeRequests implements repositories.RetakeRequestRepository.
+// The newest requests go first, slots are ordered by their start.
+func (this *retakeRequestRepo) GetRetakeRequests(ctx context.Context, filters query.GetRetakeRequestsFilters) ([]models.RetakeRequest, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.requests, requestID)
+	slices.SortStableFunc(rows, func(a, b retakeRequestRow) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	var result []models.RetakeRequest
+	for _, row := range rows {
+		debt, ok := this.db.tables.debts[row.DebtID]
+		if !ok {
+			continue
+		}
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, row.ID) {
+			continue
+		}
+		if len(filters.DebtIDs) > 0 && !slices.Contains(filters.DebtIDs, row.DebtID) {
+			continue
+		}
+		if len(filters.StudentUUIDs) > 0 && !slices.Contains(filters.StudentUUIDs, row.StudentUUID) {
+			continue
+		}
+		if len(filters.TeacherUUIDs) > 0 && !slices.Contains(filters.TeacherUUIDs, row.TeacherUUID) {
+			continue
+		}
+		if len(filters.ExamIDs) > 0 && !slices.Contains(filters.ExamIDs, debt.ExamID) {
+			continue
+		}
+		if len(filters.Statuses) > 0 && !slices.Contains(filters.Statuses, row.Status) {
+			continue
+		}
+
+		// the student and the teacher are the ones of the request
+		joined := this.db.debt(debtRow{
+			ID:          debt.ID,
+			ExamID:      debt.ExamID,
+			StudentUUID: row.StudentUUID,
+			TeacherUUID: row.TeacherUUID,
+			Date:        debt.Date,
+			Address:     debt.Address,
+		})
+		joined.Exam.AssessmentType = ""
+		slots := slices.Clone(row.PreferredSlots)
+		slices.SortStableFunc(slots, func(a, b models.TimeSlot) int { return a.Start.Compare(b.Start) })
+
+		result = append(result, models.RetakeRequest{
+			ID:     row.ID,
+			Status: row.Status,
+			Debt: &models.Debt{
+				ID:      joined.ID,
+				Date:    joined.Date,
+				Address: joined.Address,
+				Exam:    joined.Exam,
+				Student: joined.Student,
+				Teacher: joined.Teacher,
+			},
+			PreferredSlots: slots,
+			CreatedAt:      row.CreatedAt,
+			UpdatedAt:      row.UpdatedAt,
+		})
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CreateRetakeRequest implements repositories.RetakeRequestRepository.
+// A debt has a single open request at a time.
+func (this *retakeRequestRepo) CreateRetakeRequest(ctx context.Context, request commands.CreateRetakeRequest) (int64, error) {
+	if err := request.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.debts[request.DebtID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_requests_debt_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.students[request.StudentUUID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_requests_student_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.teachers[request.TeacherUUID]; request.TeacherUUID != "" && !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_requests_teacher_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	for _, open := range this.db.tables.requests {
+		if open.DebtID == request.DebtID && slices.Contains(valueobjects.OpenRetakeRequestStatuses, open.Status) {
+			return 0, log.ErrorWrapper(errors.ErrRetakeRequestAlreadyExists, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	id := this.db.nextID()
+	createdAt := now()
+	this.db.tables.requests[id] = retakeRequestRow{
+		ID:             id,
+		DebtID:         request.DebtID,
+		StudentUUID:    request.StudentUUID,
+		TeacherUUID:    request.TeacherUUID,
+		Status:         valueobjects.RetakeRequestPending,
+		PreferredSlots: slices.Clone(request.PreferredSlots),
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}
+	return id, nil
+}
+
+// UpdateRetakeRequestStatus implements repositories.RetakeRequestRepository.
+// Requests that are not open any more are left as they are.
+func (this *retakeRequestRepo) UpdateRetakeRequestStatus(ctx context.Context, update commands.UpdateRetakeRequestStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	updatedAt := now()
+	for id, row := range this.db.tables.requests {
+		if !slices.Contains(valueobjects.OpenRetakeRequestStatuses, row.Status) {
+			continue
+		}
+		if len(update.IDs) > 0 && !slices.Contains(update.IDs, row.ID) {
+			continue
+		}
+		if len(update.DebtIDs) > 0 && !slices.Contains(update.DebtIDs, row.DebtID) {
+			continue
+		}
+		row.Status = update.Status
+		row.UpdatedAt = updatedAt
+		this.db.tables.requests[id] = row
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type retakeSessionRow struct {
+	ID             int64
+	ExamID         int64
+	TeacherUUID    string
+	Date           time.Time
+	Address        string
+	RoomID         int64
+	Capacity       int64
+	SignupDeadline time.Time
+	CancelDeadline time.Time
+	CreatedAt      time.Time
+}
+
+type retakeBookingRow struct {
+	ID          int64
+	SessionID   int64
+	DebtID      int64
+	StudentUUID string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type retakeSessionRepo struct {
+	db *Store
+}
+
+func NewRetakeSessionRepo(db *Store) repositories.RetakeSessionRepository {
+	return &retakeSessionRepo{
+		db: db,
+	}
+}
+
+func sessionID(session retakeSessionRow) int64 { return session.ID }
+func bookingID(booking retakeBookingRow) int64 { return booking.ID }
+
+// CreateRetakeSession implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) CreateRetakeSession(ctx context.Context, session commands.CreateRetakeSession) (int64, error) {
+	if err := session.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.exams[session.ExamID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_sessions_exam_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.teachers[session.TeacherUUID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_sessions_teacher_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.rooms[session.RoomID]; session.RoomID != 0 && !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_sessions_room_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	id := this.db.nextID()
+	this.db.tables.sessions[id] = retakeSessionRow{
+		ID:             id,
+		ExamID:         session.ExamID,
+		TeacherUUID:    session.TeacherUUID,
+		Date:           session.Date,
+		Address:        session.Address,
+		RoomID:         session.RoomID,
+		Capacity:       session.Capacity,
+		SignupDeadline: session.SignupDeadline,
+		CancelDeadline: session.CancelDeadline,
+		CreatedAt:      now(),
+	}
+	return id, nil
+}
+
+// GetRetakeSessions implements repositories.RetakeSessionRepository.
+// Sessions are ordered by date.
+func (this *retakeSessionRepo) GetRetakeSessions(ctx context.Context, filters query.GetRetakeSessionsFilters) ([]models.RetakeSession, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.sessions, sessionID)
+	slices.SortStableFunc(rows, func(a, b retakeSessionRow) int { return a.Date.Compare(b.Date) })
+
+	var result []models.RetakeSession
+	for _, row := range rows {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, row.ID) {
+			continue
+		}
+		if len(filters.ExamIDs) > 0 && !slices.Contains(filters.ExamIDs, row.ExamID) {
+			continue
+		}
+		if len(filters.TeacherUUIDs) > 0 && !slices.Contains(filters.TeacherUUIDs, row.TeacherUUID) {
+			continue
+		}
+		if filters.OnlyOpen && !row.SignupDeadline.After(now()) {
+			continue
+		}
+
+		exam := this.db.tables.exams[row.ExamID]
+		teacher := this.db.tables.teachers[row.TeacherUUID]
+		session := models.RetakeSession{
+			ID:             row.ID,
+			Exam:           &exam,
+			Teacher:        &models.Teacher{UUID: teacher.UUID, FirstName: teacher.FirstName, LastName: teacher.LastName, MiddleName: teacher.MiddleName, Email: teacher.Email},
+			Date:           row.Date,
+			Address:        row.Address,
+			Capacity:       row.Capacity,
+			SignupDeadline: row.SignupDeadline,
+			CancelDeadline: row.CancelDeadline,
+			CreatedAt:      row.CreatedAt,
+		}
+		if room, ok := this.db.tables.rooms[row.RoomID]; ok {
+			session.Room = &room
+		}
+		for _, booking := range this.db.tables.bookings {
+			if booking.SessionID != row.ID {
+				continue
+			}
+			switch booking.Status {
+			case valueobjects.RetakeBookingBooked:
+				session.BookedCount++
+			case valueobjects.RetakeBookingWaitlisted:
+				session.WaitlistedCount++
+			}
+		}
+		result = append(result, session)
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// LockRetakeSession implements repositories.RetakeSessionRepository.
+// Transactions are serialized already, it only checks that the session
+// exists.
+func (this *retakeSessionRepo) LockRetakeSession(ctx context.Context, id int64) error {
+	if !inTransaction(ctx) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "session can be locked inside a transaction only")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.sessions[id]; !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+	return nil
+}
+
+// GetRetakeBookings implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) GetRetakeBookings(ctx context.Context, filters query.GetRetakeBookingsFilters) ([]models.RetakeBooking, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.bookings, bookingID)
+	slices.SortStableFunc(rows, func(a, b retakeBookingRow) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	var result []models.RetakeBooking
+	for _, row := range rows {
+		debt, ok := this.db.tables.debts[row.DebtID]
+		if !ok {
+			continue
+		}
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, row.ID) {
+			continue
+		}
+		if len(filters.SessionIDs) > 0 && !slices.Contains(filters.SessionIDs, row.SessionID) {
+			continue
+		}
+		if len(filters.DebtIDs) > 0 && !slices.Contains(filters.DebtIDs, row.DebtID) {
+			continue
+		}
+		if len(filters.StudentUUIDs) > 0 && !slices.Contains(filters.StudentUUIDs, row.StudentUUID) {
+			continue
+		}
+		if len(filters.Statuses) > 0 && !slices.Contains(filters.Statuses, row.Status) {
+			continue
+		}
+
+		// the student is the one of the booking
+		debt.StudentUUID = row.StudentUUID
+		joined := this.db.debt(debt)
+		joined.Exam.AssessmentType = ""
+		result = append(result, models.RetakeBooking{
+			ID:        row.ID,
+			SessionID: row.SessionID,
+			Status:    row.Status,
+			Debt: &models.Debt{
+				ID:      joined.ID,
+				Exam:    joined.Exam,
+				Student: joined.Student,
+				Teacher: joined.Teacher,
+			},
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+
+	return page(result, filters.Limit, 0), nil
+}
+
+// CreateRetakeBooking implements repositories.RetakeSessionRepository.
+// A debt holds a seat or a waitlist place in one session only.
+func (this *retakeSessionRepo) CreateRetakeBooking(ctx context.Context, booking commands.CreateRetakeBooking) (int64, error) {
+	if err := booking.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.sessions[booking.SessionID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_bookings_session_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.debts[booking.DebtID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_bookings_debt_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	if _, ok := this.db.tables.students[booking.StudentUUID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("retake_bookings_student_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+	for _, active := range this.db.tables.bookings {
+		if active.DebtID == booking.DebtID && active.Status != valueobjects.RetakeBookingCancelled {
+			return 0, log.ErrorWrapper(errors.ErrRetakeAlreadyBooked, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	id := this.db.nextID()
+	createdAt := now()
+	this.db.tables.bookings[id] = retakeBookingRow{
+		ID:          id,
+		SessionID:   booking.SessionID,
+		DebtID:      booking.DebtID,
+		StudentUUID: booking.StudentUUID,
+		Status:      booking.Status,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	return id, nil
+}
+
+// UpdateRetakeBookingStatus implements repositories.RetakeSessionRepository.
+// A cancelled booking stays cancelled.
+func (this *retakeSessionRepo) UpdateRetakeBookingStatus(ctx context.Context, update commands.UpdateRetakeBookingStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.bookings[update.ID]
+	if !ok || row.Status == valueobjects.RetakeBookingCancelled {
+		return nil
+	}
+	if update.Status != valueobjects.RetakeBookingCancelled {
+		for _, active := range this.db.tables.bookings {
+			if active.ID != row.ID && active.DebtID == row.DebtID && active.Status != valueobjects.RetakeBookingCancelled {
+				return log.ErrorWrapper(uniqueViolation("retake_bookings_active_debt_idx"), errors.ERR_INFRASTRUCTURE, "")
+			}
+		}
+	}
+
+	row.Status = update.Status
+	row.UpdatedAt = now()
+	this.db.tables.bookings[update.ID] = row
+	return nil
+}
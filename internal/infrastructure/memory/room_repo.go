@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type roomRepo struct {
+	db *Store
+}
+
+func NewRoomRepo(db *Store) repositories.RoomRepository {
+	return &roomRepo{
+		db: db,
+	}
+}
+
+func roomID(room models.Room) int64 { return room.ID }
+
+// checkRoomNumber plays the unique (building, number) constraint.
+func (this *Store) checkRoomNumber(id int64, building, number string) error {
+	for _, room := range this.tables.rooms {
+		if room.ID != id && room.Building == building && room.Number == number {
+			return uniqueViolation("rooms_building_number_key")
+		}
+	}
+	return nil
+}
+
+// GetRooms implements repositories.RoomRepository.
+// Rooms are ordered by the building and the number.
+func (this *roomRepo) GetRooms(ctx context.Context, filters query.GetRoomsFilters) ([]models.Room, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.Room
+	for _, room := range rowsOf(this.db.tables.rooms, roomID) {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, room.ID) {
+			continue
+		}
+		if filters.MinCapacity != 0 && room.Capacity < filters.MinCapacity {
+			continue
+		}
+		if filters.OnlyAccessible && !room.Accessible {
+			continue
+		}
+		result = append(result, room)
+	}
+	slices.SortStableFunc(result, func(a, b models.Room) int {
+		return cmp.Or(cmp.Compare(a.Building, b.Building), cmp.Compare(a.Number, b.Number))
+	})
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CreateRoom implements repositories.RoomRepository.
+func (this *roomRepo) CreateRoom(ctx context.Context, room commands.CreateRoom) (int64, error) {
+	if err := room.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if err := this.db.checkRoomNumber(0, room.Building, room.Number); err != nil {
+		return 0, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "room already exists")
+	}
+
+	id := this.db.nextID()
+	this.db.tables.rooms[id] = models.Room{
+		ID:         id,
+		Building:   room.Building,
+		Number:     room.Number,
+		Capacity:   room.Capacity,
+		Accessible: room.Accessible,
+	}
+	return id, nil
+}
+
+// UpdateRoom implements repositories.RoomRepository.
+func (this *roomRepo) UpdateRoom(ctx context.Context, room commands.UpdateRoom) error {
+	if err := room.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.rooms[room.ID]; !ok {
+		return nil
+	}
+	if err := this.db.checkRoomNumber(room.ID, room.Building, room.Number); err != nil {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "room already exists")
+	}
+
+	this.db.tables.rooms[room.ID] = models.Room{
+		ID:         room.ID,
+		Building:   room.Building,
+		Number:     room.Number,
+		Capacity:   room.Capacity,
+		Accessible: room.Accessible,
+	}
+	return nil
+}
+
+// DeleteRoom implements repositories.RoomRepository.
+// Draft items placed in the room go with it.
+func (this *roomRepo) DeleteRoom(ctx context.Context, room commands.DeleteRoom) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	for _, debt := range this.db.tables.debts {
+		if debt.RoomID == room.ID {
+			return log.ErrorWrapper(errors.ErrRoomInUse, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+	for _, session := range this.db.tables.sessions {
+		if session.RoomID == room.ID {
+			return log.ErrorWrapper(errors.ErrRoomInUse, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	for id, item := range this.db.tables.draftItems {
+		if item.RoomID == room.ID {
+			delete(this.db.tables.draftItems, id)
+		}
+	}
+	delete(this.db.tables.rooms, room.ID)
+	return nil
+}
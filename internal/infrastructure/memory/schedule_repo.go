@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type scheduleRepo struct {
+	db *Store
+}
+
+func NewScheduleRepo(db *Store) repositories.ScheduleRepository {
+	return &scheduleRepo{
+		db: db,
+	}
+}
+
+// GetScheduledRetakes implements repositories.ScheduleRepository.
+// Debts go before sessions at the same time.
+func (this *scheduleRepo) GetScheduledRetakes(ctx context.Context, filters query.GetScheduledRetakesFilters) ([]models.ScheduledRetake, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.ScheduledRetake
+	for _, debt := range rowsOf(this.db.tables.debts, debtID) {
+		if debt.Date == nil || !debt.Date.After(filters.From) || !debt.Date.Before(filters.To) {
+			continue
+		}
+		if !(debt.RoomID != 0 && slices.Contains(filters.RoomIDs, debt.RoomID)) &&
+			!slices.Contains(filters.TeacherUUIDs, debt.TeacherUUID) &&
+			!slices.Contains(filters.StudentUUIDs, debt.StudentUUID) {
+			continue
+		}
+		result = append(result, models.ScheduledRetake{
+			DebtID:      debt.ID,
+			ExamID:      debt.ExamID,
+			TeacherUUID: debt.TeacherUUID,
+			StudentUUID: debt.StudentUUID,
+			RoomID:      debt.RoomID,
+			Date:        *debt.Date,
+		})
+	}
+
+	// sessions have no student, a student-only lookup skips them
+	for _, session := range rowsOf(this.db.tables.sessions, sessionID) {
+		if !session.Date.After(filters.From) || !session.Date.Before(filters.To) {
+			continue
+		}
+		if !(session.RoomID != 0 && slices.Contains(filters.RoomIDs, session.RoomID)) &&
+			!slices.Contains(filters.TeacherUUIDs, session.TeacherUUID) {
+			continue
+		}
+		result = append(result, models.ScheduledRetake{
+			SessionID:   session.ID,
+			ExamID:      session.ExamID,
+			TeacherUUID: session.TeacherUUID,
+			RoomID:      session.RoomID,
+			Date:        session.Date,
+		})
+	}
+	slices.SortStableFunc(result, func(a, b models.ScheduledRetake) int { return a.Date.Compare(b.Date) })
+
+	return result, nil
+}
+
+// LockSchedule implements repositories.ScheduleRepository.
+// Transactions are serialized already, it only checks that one is open.
+func (this *scheduleRepo) LockSchedule(ctx context.Context) error {
+	if !inTransaction(ctx) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "schedule can be locked inside a transaction only")
+	}
+	return nil
+}
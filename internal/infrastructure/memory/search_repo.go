@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+type searchRepo struct {
+	db *Store
+}
+
+func NewSearchRepo(db *Store) repositories.SearchRepository {
+	return &searchRepo{
+		db: db,
+	}
+}
+
+// Search implements repositories.SearchRepository.
+// The fuzzy matches of pg_trgm are not played: a hit contains the query or
+// every word of the query starts a word of the hit. The rank is the share of
+// the document the query covers.
+func (this *searchRepo) Search(ctx context.Context, search query.Search) ([]models.SearchHit, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	text := strings.ToLower(strings.TrimSpace(search.Query))
+	var result []models.SearchHit
+	add := func(hit models.SearchHit, document string) {
+		if rank, ok := searchRank(text, strings.ToLower(document)); ok {
+			hit.Rank = rank
+			result = append(result, hit)
+		}
+	}
+
+	if search.Searches(valueobjects.SearchStudentKind) {
+		for _, row := range rowsOf(this.db.tables.students, studentSeq) {
+			if this.db.inTrash(valueobjects.TrashStudentKind, row.UUID) {
+				continue
+			}
+			student := this.db.student(row)
+			add(models.SearchHit{
+				Kind:     valueobjects.SearchStudentKind,
+				ID:       student.UUID,
+				Title:    searchTitle(" ", student.LastName, student.FirstName, student.MiddleName),
+				Subtitle: searchTitle(", ", student.Group.Name, student.Email),
+			}, personSearchDocument(student.LastName, student.FirstName, student.MiddleName, student.Email))
+		}
+	}
+	if search.Searches(valueobjects.SearchTeacherKind) {
+		for _, row := range rowsOf(this.db.tables.teachers, teacherSeq) {
+			if this.db.inTrash(valueobjects.TrashTeacherKind, row.UUID) {
+				continue
+			}
+			add(models.SearchHit{
+				Kind:     valueobjects.SearchTeacherKind,
+				ID:       row.UUID,
+				Title:    searchTitle(" ", row.LastName, row.FirstName, row.MiddleName),
+				Subtitle: row.Email,
+			}, personSearchDocument(row.LastName, row.FirstName, row.MiddleName, row.Email))
+		}
+	}
+	if search.Searches(valueobjects.SearchGroupKind) {
+		for _, group := range rowsOf(this.db.tables.groups, func(group models.Group) int64 { return group.ID }) {
+			if this.db.inTrash(valueobjects.TrashGroupKind, strconv.FormatInt(group.ID, 10)) {
+				continue
+			}
+			add(models.SearchHit{
+				Kind:  valueobjects.SearchGroupKind,
+				ID:    strconv.FormatInt(group.ID, 10),
+				Title: group.Name,
+			}, group.Name)
+		}
+	}
+	if search.Searches(valueobjects.SearchExamKind) {
+		for _, exam := range rowsOf(this.db.tables.exams, examID) {
+			if this.db.inTrash(valueobjects.TrashExamKind, strconv.FormatInt(exam.ID, 10)) {
+				continue
+			}
+			add(models.SearchHit{
+				Kind:     valueobjects.SearchExamKind,
+				ID:       strconv.FormatInt(exam.ID, 10),
+				Title:    exam.Name,
+				Subtitle: exam.AssessmentType,
+			}, exam.Name)
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b models.SearchHit) int {
+		return cmp.Or(
+			cmp.Compare(b.Rank, a.Rank),
+			strings.Compare(a.Kind, b.Kind),
+			strings.Compare(a.Title, b.Title),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+	return page(result, search.Limit, 0), nil
+}
+
+func personSearchDocument(lastName, firstName, middleName, email string) string {
+	return lastName + " " + firstName + " " + middleName + " " + email
+}
+
+// searchTitle joins the parts that are set.
+func searchTitle(separator string, parts ...string) string {
+	return strings.Join(slices.DeleteFunc(parts, func(part string) bool { return part == "" }), separator)
+}
+
+func searchRank(text, document string) (float64, bool) {
+	rank := float64(utf8.RuneCountInString(text)) / float64(max(utf8.RuneCountInString(strings.TrimSpace(document)), 1))
+	if strings.Contains(document, text) {
+		return min(rank, 1), true
+	}
+
+	notWord := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	words := strings.FieldsFunc(document, notWord)
+	for _, prefix := range strings.FieldsFunc(text, notWord) {
+		if !slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, prefix) }) {
+			return 0, false
+		}
+	}
+	return min(rank, 1), true
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+// Mail is a message the mailer would have sent.
+type Mail struct {
+	To   string
+	Body string
+}
+
+// StudentMailer implements repositories.StudentMailer and keeps the mails
+// instead of sending them.
+type StudentMailer struct {
+	mu    sync.Mutex
+	mails []Mail
+}
+
+func NewStudentMailer() *StudentMailer {
+	return &StudentMailer{}
+}
+
+// Mails returns the mails sent so far, the oldest first.
+func (this *StudentMailer) Mails() []Mail {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	return append([]Mail(nil), this.mails...)
+}
+
+func (this *StudentMailer) send(to, body string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	this.mails = append(this.mails, Mail{To: to, Body: body})
+}
+
+// NotifyNewDateAndPlace implements repositories.StudentMailer.
+func (this *StudentMailer) NotifyNewDateAndPlace(ctx context.Context, studentEmail, subjectName, date, place string) error {
+	if studentEmail == "" || !strings.Contains(studentEmail, "@") {
+		return errors.ErrInvalidData
+	}
+
+	this.send(studentEmail, fmt.Sprintf("%s %s %s", subjectName, date, place))
+	return nil
+}
+
+// SendPassword implements repositories.StudentMailer.
+func (this *StudentMailer) SendPassword(ctx context.Context, email, password string) error {
+	if email == "" || !strings.Contains(email, "@") {
+		return errors.ErrInvalidData
+	}
+
+	this.send(email, password)
+	return nil
+}
+
+// SendNotification implements repositories.StudentMailer.
+func (this *StudentMailer) SendNotification(ctx context.Context, student models.Student, teacherEmail string, exam models.Exam) error {
+	if teacherEmail == "" {
+		return fmt.Errorf("teacher email is required")
+	}
+
+	this.send(teacherEmail, fmt.Sprintf("%s %s %s", student.LastName, student.FirstName, exam.Name))
+	return nil
+}
@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type studentRow struct {
+	seq        int64
+	UUID       string
+	FirstName  string
+	LastName   string
+	MiddleName string
+	Email      string
+	GroupID    int64
+	Password   string
+	Version    int64
+	// the columns of the personal data retention
+	Status       string
+	LeftAt       *time.Time
+	AnonymizedAt *time.Time
+}
+
+type studentRepo struct {
+	db *Store
+}
+
+func NewStudentRepo(db *Store) repositories.StudentRepository {
+	return &studentRepo{
+		db: db,
+	}
+}
+
+func studentSeq(student studentRow) int64 { return student.seq }
+
+// student joins the group the way the queries do.
+func (this *Store) student(row studentRow) models.Student {
+	return models.Student{
+		UUID:       row.UUID,
+		FirstName:  row.FirstName,
+		LastName:   row.LastName,
+		MiddleName: row.MiddleName,
+		Email:      row.Email,
+		Group:      &models.Group{ID: row.GroupID, Name: this.tables.groups[row.GroupID].Name},
+		Password:   row.Password,
+		Version:    row.Version,
+	}
+}
+
+// checkStudent plays the unique email and the group foreign key.
+func (this *Store) checkStudent(uuid, email string, groupID int64) error {
+	if _, ok := this.tables.groups[groupID]; !ok {
+		return foreignKeyViolation("students_group_id_fkey")
+	}
+	for _, student := range this.tables.students {
+		if student.UUID != uuid && student.Email == email {
+			return uniqueViolation("students_email_key")
+		}
+	}
+	return nil
+}
+
+// GetAmountOfDebtsForStudent implements repositories.StudentRepository.
+func (this *studentRepo) GetAmountOfDebtsForStudent(ctx context.Context, uuid string) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result int64
+	for _, debt := range this.db.tables.debts {
+		if debt.StudentUUID == uuid {
+			result++
+		}
+	}
+
+	return result, nil
+}
+
+// ChangeStudentPassword implements repositories.StudentRepository.
+func (this *studentRepo) ChangeStudentPassword(ctx context.Context, uuid, password string) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if student, ok := this.db.tables.students[uuid]; ok && !this.db.inTrash(valueobjects.TrashStudentKind, uuid) {
+		student.Password = password
+		this.db.tables.students[uuid] = student
+	}
+	return nil
+}
+
+// SearchStudents implements repositories.StudentRepository.
+// The group is left unjoined, only its id is set.
+func (this *studentRepo) SearchStudents(ctx context.Context, filters query.SearchStudentFilters) ([]models.Student, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var students []models.Student
+	for _, row := range rowsOf(this.db.tables.students, studentSeq) {
+		if this.db.inTrash(valueobjects.TrashStudentKind, row.UUID) != filters.Deleted {
+			continue
+		}
+		if len(filters.UUIDs) > 0 && !slices.Contains(filters.UUIDs, row.UUID) {
+			continue
+		}
+		if len(filters.FirstNames) > 0 && !slices.Contains(filters.FirstNames, row.FirstName) {
+			continue
+		}
+		if len(filters.LastNames) > 0 && !slices.Contains(filters.LastNames, row.LastName) {
+			continue
+		}
+		if len(filters.MiddleNames) > 0 && !slices.Contains(filters.MiddleNames, row.MiddleName) {
+			continue
+		}
+		if len(filters.Emails) > 0 && !slices.Contains(filters.Emails, row.Email) {
+			continue
+		}
+		students = append(students, models.Student{
+			UUID:       row.UUID,
+			FirstName:  row.FirstName,
+			LastName:   row.LastName,
+			MiddleName: row.MiddleName,
+			Email:      row.Email,
+			Group:      &models.Group{ID: row.GroupID},
+		})
+	}
+
+	return students, nil
+}
+
+// GetStudentByUUID implements repositories.StudentRepository.
+func (this *studentRepo) GetStudentByUUID(ctx context.Context, uuid string) (*models.Student, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.students[uuid]
+	if !ok || this.db.inTrash(valueobjects.TrashStudentKind, uuid) {
+		return nil, pgx.ErrNoRows
+	}
+
+	result := this.db.student(row)
+	return &result, nil
+}
+
+// DeleteStudent implements repositories.StudentRepository.
+func (this *studentRepo) DeleteStudent(ctx context.Context, student commands.DeleteStudent) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.students[student.UUID]; ok {
+		this.db.moveToTrash(valueobjects.TrashStudentKind, student.UUID)
+	}
+	return nil
+}
+
+// UpdateStudent implements repositories.StudentRepository.
+func (this *studentRepo) UpdateStudent(ctx context.Context, student commands.UpdateStudent) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.students[student.UUID]
+	if update, err := this.db.checkVersion(valueobjects.TrashStudentKind, student.UUID, ok, row.Version, student.Version); !update {
+		return err
+	}
+	if err := this.db.checkStudent(student.UUID, student.Email, student.GroupID); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	row.FirstName = student.FirstName
+	row.LastName = student.LastName
+	row.MiddleName = student.MiddleName
+	row.Email = student.Email
+	row.GroupID = student.GroupID
+	row.Version++
+	this.db.tables.students[student.UUID] = row
+	return nil
+}
+
+// CreateStudent implements repositories.StudentRepository.
+func (this *studentRepo) CreateStudent(ctx context.Context, student commands.CreateStudent) (string, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if err := this.db.checkStudent("", student.Email, student.GroupID); err != nil {
+		return "", err
+	}
+
+	uuid := newUUID()
+	this.db.tables.students[uuid] = studentRow{
+		seq:        this.db.nextID(),
+		UUID:       uuid,
+		FirstName:  student.FirstName,
+		LastName:   student.LastName,
+		MiddleName: student.MiddleName,
+		Email:      student.Email,
+		GroupID:    student.GroupID,
+		Password:   student.Password,
+		Version:    1,
+		Status:     valueobjects.StudentActiveStatus,
+	}
+	return uuid, nil
+}
+
+// GetStudents implements repositories.StudentRepository.
+func (this *studentRepo) GetStudents(ctx context.Context, filters query.GetStudentsFilters) ([]models.Student, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	result, err := orderByKeyset(this.db.students(filters), query.StudentSortFields, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CountStudents implements repositories.StudentRepository.
+func (this *studentRepo) CountStudents(ctx context.Context, filters query.GetStudentsFilters) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	return int64(len(this.db.students(filters))), nil
+}
+
+func (this *Store) students(filters query.GetStudentsFilters) []models.Student {
+	var result []models.Student
+	for _, row := range rowsOf(this.tables.students, studentSeq) {
+		if this.inTrash(valueobjects.TrashStudentKind, row.UUID) {
+			continue
+		}
+		if len(filters.IDs) != 0 && !slices.Contains(filters.IDs, row.UUID) {
+			continue
+		}
+		if len(filters.Emails) != 0 && !slices.Contains(filters.Emails, row.Email) {
+			continue
+		}
+		if len(filters.GroupIDs) != 0 && !slices.Contains(filters.GroupIDs, row.GroupID) {
+			continue
+		}
+		if len(filters.LastNames) != 0 && !slices.Contains(filters.LastNames, row.LastName) {
+			continue
+		}
+		result = append(result, this.student(row))
+	}
+	return result
+}
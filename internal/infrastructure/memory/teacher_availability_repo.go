@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type teacherAvailabilityRepo struct {
+	db *Store
+}
+
+func NewTeacherAvailabilityRepo(db *Store) repositories.TeacherAvailabilityRepository {
+	return &teacherAvailabilityRepo{
+		db: db,
+	}
+}
+
+func availabilityID(entry models.TeacherAvailability) int64 { return entry.ID }
+
+// availabilityEntry keeps only the fields that make sense for the kind, as
+// the queries store the rest as NULL.
+func availabilityEntry(id int64, teacherUUID, kind string, weekday time.Weekday, startMinute, endMinute int64, startsAt, endsAt time.Time, note string) models.TeacherAvailability {
+	entry := models.TeacherAvailability{
+		ID:          id,
+		TeacherUUID: teacherUUID,
+		Kind:        kind,
+		Note:        note,
+	}
+	if kind == valueobjects.AvailabilityWeekly {
+		entry.Weekday = weekday
+		entry.StartMinute = startMinute
+		entry.EndMinute = endMinute
+	} else {
+		entry.StartsAt = startsAt
+		entry.EndsAt = endsAt
+	}
+
+	return entry
+}
+
+// GetTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) GetTeacherAvailability(ctx context.Context, filters query.GetTeacherAvailabilityFilters) ([]models.TeacherAvailability, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.availability, availabilityID)
+	slices.SortStableFunc(rows, func(a, b models.TeacherAvailability) int {
+		return cmp.Or(
+			strings.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Weekday, b.Weekday),
+			cmp.Compare(a.StartMinute, b.StartMinute),
+			a.StartsAt.Compare(b.StartsAt),
+		)
+	})
+
+	var result []models.TeacherAvailability
+	for _, entry := range rows {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, entry.ID) {
+			continue
+		}
+		if len(filters.TeacherUUIDs) > 0 && !slices.Contains(filters.TeacherUUIDs, entry.TeacherUUID) {
+			continue
+		}
+		if len(filters.Kinds) > 0 && !slices.Contains(filters.Kinds, entry.Kind) {
+			continue
+		}
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// CreateTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) CreateTeacherAvailability(ctx context.Context, entry commands.CreateTeacherAvailability) (int64, error) {
+	if err := entry.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.teachers[entry.TeacherUUID]; !ok {
+		return 0, log.ErrorWrapper(foreignKeyViolation("teacher_availability_teacher_uuid_fkey"), errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	id := this.db.nextID()
+	this.db.tables.availability[id] = availabilityEntry(id, entry.TeacherUUID, entry.Kind, entry.Weekday, entry.StartMinute, entry.EndMinute, entry.StartsAt, entry.EndsAt, entry.Note)
+	return id, nil
+}
+
+// UpdateTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) UpdateTeacherAvailability(ctx context.Context, entry commands.UpdateTeacherAvailability) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	current, ok := this.db.tables.availability[entry.ID]
+	if !ok || current.TeacherUUID != entry.TeacherUUID {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	this.db.tables.availability[entry.ID] = availabilityEntry(entry.ID, entry.TeacherUUID, entry.Kind, entry.Weekday, entry.StartMinute, entry.EndMinute, entry.StartsAt, entry.EndsAt, entry.Note)
+	return nil
+}
+
+// DeleteTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) DeleteTeacherAvailability(ctx context.Context, entry commands.DeleteTeacherAvailability) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	current, ok := this.db.tables.availability[entry.ID]
+	if !ok || current.TeacherUUID != entry.TeacherUUID {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	delete(this.db.tables.availability, entry.ID)
+	return nil
+}
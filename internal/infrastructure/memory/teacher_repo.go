@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type teacherRow struct {
+	seq int64
+	models.Teacher
+}
+
+type teacherRepo struct {
+	db *Store
+}
+
+func NewTeacherRepo(db *Store) repositories.TeacherRepository {
+	return &teacherRepo{
+		db: db,
+	}
+}
+
+func teacherSeq(teacher teacherRow) int64 { return teacher.seq }
+
+func (this *Store) checkTeacherEmail(uuid, email string) error {
+	for _, teacher := range this.tables.teachers {
+		if teacher.UUID != uuid && teacher.Email == email {
+			return uniqueViolation("teachers_email_key")
+		}
+	}
+	return nil
+}
+
+// ChangeTeacherPassword implements repositories.TeacherRepository.
+func (this *teacherRepo) ChangeTeacherPassword(ctx context.Context, uuid, password string) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if teacher, ok := this.db.tables.teachers[uuid]; ok && !this.db.inTrash(valueobjects.TrashTeacherKind, uuid) {
+		teacher.Password = password
+		this.db.tables.teachers[uuid] = teacher
+	}
+	return nil
+}
+
+// SearchTeachers implements repositories.TeacherRepository.
+func (this *teacherRepo) SearchTeachers(ctx context.Context, filters query.SearchTeacherFilters) ([]models.Teacher, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var teachers []models.Teacher
+	for _, row := range rowsOf(this.db.tables.teachers, teacherSeq) {
+		if this.db.inTrash(valueobjects.TrashTeacherKind, row.UUID) != filters.Deleted {
+			continue
+		}
+		if len(filters.UUIDs) > 0 && !slices.Contains(filters.UUIDs, row.UUID) {
+			continue
+		}
+		if len(filters.FirstNames) > 0 && !slices.Contains(filters.FirstNames, row.FirstName) {
+			continue
+		}
+		if len(filters.LastNames) > 0 && !slices.Contains(filters.LastNames, row.LastName) {
+			continue
+		}
+		if len(filters.MiddleNames) > 0 && !slices.Contains(filters.MiddleNames, row.MiddleName) {
+			continue
+		}
+		if len(filters.Emails) > 0 && !slices.Contains(filters.Emails, row.Email) {
+			continue
+		}
+		teacher := row.Teacher
+		teacher.Password = ""
+		teachers = append(teachers, teacher)
+	}
+
+	return teachers, nil
+}
+
+// CreateTeacher implements repositories.TeacherRepository.
+func (this *teacherRepo) CreateTeacher(ctx context.Context, teacher commands.CreateTeacher) (string, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if err := this.db.checkTeacherEmail("", teacher.Email); err != nil {
+		return "", err
+	}
+
+	uuid := newUUID()
+	this.db.tables.teachers[uuid] = teacherRow{
+		seq: this.db.nextID(),
+		Teacher: models.Teacher{
+			UUID:       uuid,
+			FirstName:  teacher.FirstName,
+			LastName:   teacher.LastName,
+			MiddleName: teacher.MiddleName,
+			Email:      teacher.Email,
+			Password:   teacher.Password,
+			Version:    1,
+		},
+	}
+	return uuid, nil
+}
+
+// DeleteTeacher implements repositories.TeacherRepository.
+func (this *teacherRepo) DeleteTeacher(ctx context.Context, teacher commands.DeleteTeacher) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.teachers[teacher.UUID]; ok {
+		this.db.moveToTrash(valueobjects.TrashTeacherKind, teacher.UUID)
+	}
+	return nil
+}
+
+// GetTeacherByUUID implements repositories.TeacherRepository.
+func (this *teacherRepo) GetTeacherByUUID(ctx context.Context, uuid string) (*models.Teacher, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.teachers[uuid]
+	if !ok || this.db.inTrash(valueobjects.TrashTeacherKind, uuid) {
+		return nil, pgx.ErrNoRows
+	}
+
+	result := row.Teacher
+	return &result, nil
+}
+
+// UpdateTeacher implements repositories.TeacherRepository.
+func (this *teacherRepo) UpdateTeacher(ctx context.Context, teacher commands.UpdateTeacher) error {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	row, ok := this.db.tables.teachers[teacher.UUID]
+	if update, err := this.db.checkVersion(valueobjects.TrashTeacherKind, teacher.UUID, ok, row.Version, teacher.Version); !update {
+		return err
+	}
+	if err := this.db.checkTeacherEmail(teacher.UUID, teacher.Email); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	row.FirstName = teacher.FirstName
+	row.LastName = teacher.LastName
+	row.MiddleName = teacher.MiddleName
+	row.Email = teacher.Email
+	row.Version++
+	this.db.tables.teachers[teacher.UUID] = row
+	return nil
+}
+
+// GetTeachers implements repositories.TeacherRepository.
+func (this *teacherRepo) GetTeachers(ctx context.Context, filters query.GetTeachersFilters) ([]models.Teacher, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	result, err := orderByKeyset(this.db.teachers(filters), query.TeacherSortFields, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// CountTeachers implements repositories.TeacherRepository.
+func (this *teacherRepo) CountTeachers(ctx context.Context, filters query.GetTeachersFilters) (int64, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	return int64(len(this.db.teachers(filters))), nil
+}
+
+func (this *Store) teachers(filters query.GetTeachersFilters) []models.Teacher {
+	var result []models.Teacher
+	for _, row := range rowsOf(this.tables.teachers, teacherSeq) {
+		if this.inTrash(valueobjects.TrashTeacherKind, row.UUID) {
+			continue
+		}
+		if len(filters.UUIDs) != 0 && !slices.Contains(filters.UUIDs, row.UUID) {
+			continue
+		}
+		if len(filters.Emails) != 0 && !slices.Contains(filters.Emails, row.Email) {
+			continue
+		}
+		if len(filters.LastNames) != 0 && !slices.Contains(filters.LastNames, row.LastName) {
+			continue
+		}
+		result = append(result, row.Teacher)
+	}
+	return result
+}
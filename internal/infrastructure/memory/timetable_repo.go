@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type timetableDraftItemRow struct {
+	ID      int64
+	DraftID int64
+	DebtID  int64
+	RoomID  int64
+	Date    *time.Time
+	Reason  string
+}
+
+type timetableRepo struct {
+	db *Store
+}
+
+func NewTimetableRepo(db *Store) repositories.TimetableRepository {
+	return &timetableRepo{
+		db: db,
+	}
+}
+
+func draftID(draft models.TimetableDraft) int64    { return draft.ID }
+func draftItemID(item timetableDraftItemRow) int64 { return item.ID }
+
+// CreateTimetableDraft implements repositories.TimetableRepository.
+func (this *timetableRepo) CreateTimetableDraft(ctx context.Context, draft commands.CreateTimetableDraft) (int64, error) {
+	if err := draft.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	for _, item := range draft.Items {
+		if _, ok := this.db.tables.debts[item.DebtID]; !ok {
+			return 0, log.ErrorWrapper(foreignKeyViolation("timetable_draft_items_debt_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+		}
+		if _, ok := this.db.tables.rooms[item.RoomID]; item.RoomID != 0 && !ok {
+			return 0, log.ErrorWrapper(foreignKeyViolation("timetable_draft_items_room_id_fkey"), errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	id := this.db.nextID()
+	this.db.tables.drafts[id] = models.TimetableDraft{
+		ID:          id,
+		Status:      valueobjects.TimetableDraftStatus,
+		PeriodStart: draft.PeriodStart,
+		Deadline:    draft.Deadline,
+		CreatedBy:   draft.CreatedBy,
+		CreatedAt:   now(),
+	}
+	for _, item := range draft.Items {
+		row := timetableDraftItemRow{
+			ID:      this.db.nextID(),
+			DraftID: id,
+			DebtID:  item.DebtID,
+			Reason:  item.Reason,
+		}
+		if item.RoomID != 0 {
+			date := item.Date
+			row.RoomID, row.Date = item.RoomID, &date
+		}
+		this.db.tables.draftItems[row.ID] = row
+	}
+
+	return id, nil
+}
+
+// GetTimetableDrafts implements repositories.TimetableRepository.
+func (this *timetableRepo) GetTimetableDrafts(ctx context.Context, filters query.GetTimetableDraftsFilters) ([]models.TimetableDraft, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	rows := rowsOf(this.db.tables.drafts, draftID)
+	slices.SortFunc(rows, func(a, b models.TimetableDraft) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	var result []models.TimetableDraft
+	for _, draft := range rows {
+		if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, draft.ID) {
+			continue
+		}
+		if len(filters.Statuses) > 0 && !slices.Contains(filters.Statuses, draft.Status) {
+			continue
+		}
+		result = append(result, draft)
+	}
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// GetTimetableDraftItems implements repositories.TimetableRepository.
+// Placed items come first, by date and room.
+func (this *timetableRepo) GetTimetableDraftItems(ctx context.Context, draftID int64) ([]models.TimetableDraftItem, error) {
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.TimetableDraftItem
+	for _, row := range rowsOf(this.db.tables.draftItems, draftItemID) {
+		if row.DraftID != draftID {
+			continue
+		}
+		debt, ok := this.db.tables.debts[row.DebtID]
+		if !ok {
+			continue
+		}
+
+		joined := this.db.debt(debt)
+		joined.Exam.AssessmentType = ""
+		item := models.TimetableDraftItem{
+			ID:      row.ID,
+			DraftID: row.DraftID,
+			Date:    row.Date,
+			Reason:  row.Reason,
+			Debt: &models.Debt{
+				ID:      joined.ID,
+				Date:    joined.Date,
+				Exam:    joined.Exam,
+				Student: joined.Student,
+				Teacher: joined.Teacher,
+			},
+		}
+		if room, ok := this.db.tables.rooms[row.RoomID]; ok {
+			item.Room = &room
+		}
+		result = append(result, item)
+	}
+
+	slices.SortStableFunc(result, func(a, b models.TimetableDraftItem) int {
+		switch {
+		case a.Date == nil && b.Date == nil:
+		case a.Date == nil:
+			return 1
+		case b.Date == nil:
+			return -1
+		default:
+			if c := a.Date.Compare(*b.Date); c != 0 {
+				return c
+			}
+		}
+		var aRoom, bRoom models.Room
+		if a.Room != nil {
+			aRoom = *a.Room
+		}
+		if b.Room != nil {
+			bRoom = *b.Room
+		}
+		return cmp.Or(strings.Compare(aRoom.Building, bRoom.Building), strings.Compare(aRoom.Number, bRoom.Number))
+	})
+
+	return result, nil
+}
+
+// LockTimetableDraft implements repositories.TimetableRepository.
+// Transactions are serialized already, it only checks that the draft exists.
+func (this *timetableRepo) LockTimetableDraft(ctx context.Context, id int64) error {
+	if !inTransaction(ctx) {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "draft can be locked inside a transaction only")
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	if _, ok := this.db.tables.drafts[id]; !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+	return nil
+}
+
+// UpdateTimetableDraftStatus implements repositories.TimetableRepository.
+// Only drafts are moved, applied and discarded drafts stay as they are.
+func (this *timetableRepo) UpdateTimetableDraftStatus(ctx context.Context, update commands.UpdateTimetableDraftStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	draft, ok := this.db.tables.drafts[update.ID]
+	if !ok || draft.Status != valueobjects.TimetableDraftStatus {
+		return nil
+	}
+
+	draft.Status = update.Status
+	if update.Status == valueobjects.TimetableAppliedStatus {
+		appliedAt := now()
+		draft.AppliedAt = &appliedAt
+	}
+	this.db.tables.drafts[update.ID] = draft
+	return nil
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// trashKey is the kind and the uuid or the id of a deleted row.
+type trashKey struct {
+	kind string
+	id   string
+}
+
+// trashImportEntities are the entities of the import rollback whose
+// references keep a row of the kind in place.
+var trashImportEntities = map[string]string{
+	valueobjects.TrashStudentKind: valueobjects.ImportStudentEntity,
+	valueobjects.TrashTeacherKind: valueobjects.ImportTeacherEntity,
+	valueobjects.TrashGroupKind:   valueobjects.ImportGroupEntity,
+	valueobjects.TrashExamKind:    valueobjects.ImportExamEntity,
+}
+
+func (this *Store) inTrash(kind, id string) bool {
+	_, ok := this.tables.trash[trashKey{kind: kind, id: id}]
+	return ok
+}
+
+// moveToTrash keeps the time a deleted row was deleted first.
+func (this *Store) moveToTrash(kind, id string) {
+	if !this.inTrash(kind, id) {
+		this.tables.trash[trashKey{kind: kind, id: id}] = now()
+	}
+}
+
+// trashed returns the row of a deleted entity, false once the row is gone.
+func (this *Store) trashed(key trashKey) (models.TrashItem, bool) {
+	item := models.TrashItem{Kind: key.kind, ID: key.id, DeletedAt: this.tables.trash[key]}
+	id, _ := strconv.ParseInt(key.id, 10, 64)
+
+	switch key.kind {
+	case valueobjects.TrashStudentKind:
+		row, ok := this.tables.students[key.id]
+		item.Title = searchTitle(" ", row.LastName, row.FirstName, row.MiddleName)
+		return item, ok
+	case valueobjects.TrashTeacherKind:
+		row, ok := this.tables.teachers[key.id]
+		item.Title = searchTitle(" ", row.LastName, row.FirstName, row.MiddleName)
+		return item, ok
+	case valueobjects.TrashGroupKind:
+		row, ok := this.tables.groups[id]
+		item.Title = row.Name
+		return item, ok
+	case valueobjects.TrashExamKind:
+		row, ok := this.tables.exams[id]
+		item.Title = row.Name
+		return item, ok
+	}
+
+	return item, false
+}
+
+type trashRepo struct {
+	db *Store
+}
+
+func NewTrashRepo(db *Store) repositories.TrashRepository {
+	return &trashRepo{
+		db: db,
+	}
+}
+
+// GetTrash implements repositories.TrashRepository.
+func (this *trashRepo) GetTrash(ctx context.Context, filters query.GetTrashFilters) ([]models.TrashItem, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var result []models.TrashItem
+	for key := range this.db.tables.trash {
+		if !filters.Lists(key.kind) {
+			continue
+		}
+		if item, ok := this.db.trashed(key); ok {
+			result = append(result, item)
+		}
+	}
+	slices.SortFunc(result, func(a, b models.TrashItem) int {
+		return cmp.Or(
+			b.DeletedAt.Compare(a.DeletedAt),
+			strings.Compare(a.Kind, b.Kind),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+
+	return page(result, filters.Limit, filters.Offset), nil
+}
+
+// RestoreDeleted implements repositories.TrashRepository.
+func (this *trashRepo) RestoreDeleted(ctx context.Context, restore commands.RestoreDeleted) error {
+	if err := restore.Validate(); err != nil {
+		return err
+	}
+	if restore.Kind == valueobjects.TrashGroupKind || restore.Kind == valueobjects.TrashExamKind {
+		if _, err := strconv.ParseInt(restore.ID, 10, 64); err != nil {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, err.Error())
+		}
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	key := trashKey{kind: restore.Kind, id: restore.ID}
+	if _, ok := this.db.tables.trash[key]; !ok {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "not in the trash", "kind", restore.Kind, "id", restore.ID)
+	}
+	if _, ok := this.db.trashed(key); !ok {
+		delete(this.db.tables.trash, key)
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "not in the trash", "kind", restore.Kind, "id", restore.ID)
+	}
+
+	delete(this.db.tables.trash, key)
+	return nil
+}
+
+// PurgeDeleted implements repositories.TrashRepository.
+func (this *trashRepo) PurgeDeleted(ctx context.Context, purge commands.PurgeDeleted) (int64, error) {
+	if err := purge.Validate(); err != nil {
+		return 0, err
+	}
+
+	this.db.mu.Lock()
+	defer this.db.mu.Unlock()
+
+	var purged int64
+	for key, deletedAt := range this.db.tables.trash {
+		if key.kind != purge.Kind || !deletedAt.Before(purge.Before) {
+			continue
+		}
+
+		id, _ := strconv.ParseInt(key.id, 10, 64)
+		exists, references := this.db.importEntityReferences(trashImportEntities[key.kind], key.id, id)
+		if len(references) > 0 {
+			continue
+		}
+		if exists {
+			switch key.kind {
+			case valueobjects.TrashStudentKind:
+				delete(this.db.tables.students, key.id)
+			case valueobjects.TrashTeacherKind:
+				delete(this.db.tables.teachers, key.id)
+			case valueobjects.TrashGroupKind:
+				delete(this.db.tables.groups, id)
+			case valueobjects.TrashExamKind:
+				delete(this.db.tables.exams, id)
+			}
+			purged++
+		}
+		delete(this.db.tables.trash, key)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// debtKind is the kind of the debts, none of them is ever in the trash.
+const debtKind = "debt"
+
+// checkVersion plays the where clause of the postgres versioned updates and
+// tells whether the update of the row of the kind goes through. An update
+// with a version only goes through when it is the current one.
+func (this *Store) checkVersion(kind, id string, exists bool, current, version int64) (bool, error) {
+	switch {
+	case (!exists || this.inTrash(kind, id)) && version != 0:
+		return false, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "kind", kind)
+	case !exists || this.inTrash(kind, id):
+		return false, nil
+	case version != 0 && version != current:
+		return false, log.ErrorWrapper(errors.ErrVersionMismatch, errors.ERR_INFRASTRUCTURE, "", "kind", kind, "version", version)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,281 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// DejaVu covers Cyrillic, the core PDF fonts do not.
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	regularFont []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	boldFont []byte
+)
+
+const (
+	fontFamily = "DejaVu"
+	dateLayout = "02.01.2006 15:04"
+	dayLayout  = "02.01.2006"
+
+	margin    float64 = 15
+	pageWidth float64 = 210 - 2*margin
+	rowHeight float64 = 8
+)
+
+type renderer struct{}
+
+func NewDocumentRenderer() repositories.DocumentRenderer {
+	return &renderer{}
+}
+
+// sheetColumns are the widths of the sheet table, they fill the page width.
+var sheetColumns = []struct {
+	title string
+	width float64
+}{
+	{"№", 10},
+	{"ФИО студента", 70},
+	{"Группа", 30},
+	{"Оценка", 30},
+	{"Подпись преподавателя", 40},
+}
+
+// RenderRetakeSheet implements repositories.DocumentRenderer.
+func (this *renderer) RenderRetakeSheet(ctx context.Context, sheet models.RetakeSheet) ([]byte, error) {
+	doc := newDocument(sheet.IssuedAt)
+	doc.SetTitle(sheetTitle(sheet.Exam.AssessmentType), true)
+	doc.AddPage()
+
+	heading(doc, sheetTitle(sheet.Exam.AssessmentType), fmt.Sprintf("на пересдачу академической задолженности № %d", sheet.SessionID))
+	field(doc, "Дисциплина", sheet.Exam.Name)
+	field(doc, "Форма контроля", assessmentName(sheet.Exam.AssessmentType))
+	field(doc, "Преподаватель", fullName(sheet.Teacher.LastName, sheet.Teacher.FirstName, sheet.Teacher.MiddleName))
+	field(doc, "Дата и время", sheet.Date.Format(dateLayout))
+	field(doc, "Место проведения", place(sheet.Address, sheet.Room))
+	doc.Ln(4)
+
+	sheetHeader(doc)
+	for i, debt := range sheet.Debts {
+		// the header is repeated on every page of a long sheet
+		if doc.GetY()+rowHeight > 297-margin {
+			doc.AddPage()
+			sheetHeader(doc)
+		}
+
+		var student, group string
+		if debt.Student != nil {
+			student = fullName(debt.Student.LastName, debt.Student.FirstName, debt.Student.MiddleName)
+			if debt.Student.Group != nil {
+				group = debt.Student.Group.Name
+			}
+		}
+		cells := []string{fmt.Sprint(i + 1), student, group, "", ""}
+		for j, column := range sheetColumns {
+			align := "L"
+			if j == 0 {
+				align = "C"
+			}
+			doc.CellFormat(column.width, rowHeight, fit(doc, cells[j], column.width), "1", 0, align, false, 0, "")
+		}
+		doc.Ln(-1)
+	}
+
+	doc.Ln(6)
+	doc.SetFont(fontFamily, "", 10)
+	doc.CellFormat(pageWidth, rowHeight, fmt.Sprintf("Всего студентов в ведомости: %d", len(sheet.Debts)), "", 1, "L", false, 0, "")
+	doc.CellFormat(pageWidth, rowHeight, "Из них сдали: ______    не сдали: ______    не явились: ______", "", 1, "L", false, 0, "")
+	doc.Ln(4)
+	signature(doc, "Преподаватель", shortName(sheet.Teacher.LastName, sheet.Teacher.FirstName, sheet.Teacher.MiddleName))
+	signature(doc, "Декан", "")
+	doc.CellFormat(pageWidth, rowHeight, "Дата выдачи: "+sheet.IssuedAt.Format(dayLayout), "", 1, "L", false, 0, "")
+
+	return output(doc)
+}
+
+// RenderReferralSlip implements repositories.DocumentRenderer.
+func (this *renderer) RenderReferralSlip(ctx context.Context, slip models.ReferralSlip) ([]byte, error) {
+	debt := slip.Debt
+	doc := newDocument(slip.IssuedAt)
+	doc.SetTitle(fmt.Sprintf("Направление № %d", debt.ID), true)
+	doc.AddPage()
+
+	var student, group string
+	if debt.Student != nil {
+		student = fullName(debt.Student.LastName, debt.Student.FirstName, debt.Student.MiddleName)
+		if debt.Student.Group != nil {
+			group = debt.Student.Group.Name
+		}
+	}
+	var exam models.Exam
+	if debt.Exam != nil {
+		exam = *debt.Exam
+	}
+	var teacher string
+	if debt.Teacher != nil {
+		teacher = fullName(debt.Teacher.LastName, debt.Teacher.FirstName, debt.Teacher.MiddleName)
+	}
+	var date string
+	if debt.Date != nil {
+		date = debt.Date.Format(dateLayout)
+	}
+
+	heading(doc, fmt.Sprintf("НАПРАВЛЕНИЕ № %d", debt.ID), "на пересдачу академической задолженности")
+	field(doc, "Студент", student)
+	field(doc, "Группа", group)
+	field(doc, "Дисциплина", exam.Name)
+	field(doc, "Форма контроля", assessmentName(exam.AssessmentType))
+	field(doc, "Преподаватель", teacher)
+	field(doc, "Дата и время", date)
+	field(doc, "Место проведения", place(debt.Address, debt.Room))
+	doc.Ln(6)
+
+	doc.SetFont(fontFamily, "", 10)
+	for _, label := range []string{"Оценка", "Дата сдачи", "Подпись преподавателя"} {
+		doc.CellFormat(60, rowHeight, label, "1", 0, "L", false, 0, "")
+		doc.CellFormat(pageWidth-60, rowHeight, "", "1", 1, "L", false, 0, "")
+	}
+
+	doc.Ln(8)
+	signature(doc, "Декан", "")
+	doc.CellFormat(pageWidth, rowHeight, "Дата выдачи: "+slip.IssuedAt.Format(dayLayout), "", 1, "L", false, 0, "")
+	doc.SetFont(fontFamily, "", 8)
+	doc.CellFormat(pageWidth, rowHeight, "Направление сдаётся преподавателю после пересдачи и возвращается в деканат.", "", 1, "L", false, 0, "")
+
+	return output(doc)
+}
+
+func newDocument(issuedAt time.Time) *fpdf.Fpdf {
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.AddUTF8FontFromBytes(fontFamily, "", regularFont)
+	doc.AddUTF8FontFromBytes(fontFamily, "B", boldFont)
+	doc.SetMargins(margin, margin, margin)
+	doc.SetAutoPageBreak(true, margin)
+	doc.SetCreationDate(issuedAt)
+
+	return doc
+}
+
+func output(doc *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not render pdf")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func heading(doc *fpdf.Fpdf, title, subtitle string) {
+	doc.SetFont(fontFamily, "B", 14)
+	doc.CellFormat(pageWidth, 8, title, "", 1, "C", false, 0, "")
+	doc.SetFont(fontFamily, "", 11)
+	doc.CellFormat(pageWidth, 7, subtitle, "", 1, "C", false, 0, "")
+	doc.Ln(6)
+}
+
+func field(doc *fpdf.Fpdf, label, value string) {
+	doc.SetFont(fontFamily, "B", 10)
+	doc.CellFormat(45, 7, label+":", "", 0, "L", false, 0, "")
+	doc.SetFont(fontFamily, "", 10)
+	doc.MultiCell(pageWidth-45, 7, value, "", "L", false)
+}
+
+func sheetHeader(doc *fpdf.Fpdf) {
+	doc.SetFont(fontFamily, "B", 9)
+	for _, column := range sheetColumns {
+		doc.CellFormat(column.width, rowHeight, fit(doc, column.title, column.width), "1", 0, "C", false, 0, "")
+	}
+	doc.Ln(-1)
+	doc.SetFont(fontFamily, "", 9)
+}
+
+func signature(doc *fpdf.Fpdf, role, name string) {
+	doc.SetFont(fontFamily, "", 10)
+	line := role + " ______________________"
+	if name != "" {
+		line += " / " + name + " /"
+	}
+	doc.CellFormat(pageWidth, rowHeight+2, line, "", 1, "L", false, 0, "")
+}
+
+// fit cuts the text so that it stays inside a cell of the given width.
+func fit(doc *fpdf.Fpdf, text string, width float64) string {
+	const padding = 2
+	if doc.GetStringWidth(text) <= width-padding {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && doc.GetStringWidth(string(runes)+"…") > width-padding {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
+
+func sheetTitle(assessmentType string) string {
+	switch assessmentType {
+	case valueobjects.AssessmentCredit, valueobjects.AssessmentGradedCredit:
+		return "ЗАЧЁТНАЯ ВЕДОМОСТЬ"
+	case valueobjects.AssessmentCoursework:
+		return "ВЕДОМОСТЬ ЗАЩИТЫ КУРСОВЫХ РАБОТ"
+	default:
+		return "ЭКЗАМЕНАЦИОННАЯ ВЕДОМОСТЬ"
+	}
+}
+
+func assessmentName(assessmentType string) string {
+	switch assessmentType {
+	case valueobjects.AssessmentCredit:
+		return "зачёт"
+	case valueobjects.AssessmentGradedCredit:
+		return "дифференцированный зачёт"
+	case valueobjects.AssessmentCoursework:
+		return "курсовая работа"
+	default:
+		return "экзамен"
+	}
+}
+
+func fullName(lastName, firstName, middleName string) string {
+	return strings.Join(strings.Fields(lastName+" "+firstName+" "+middleName), " ")
+}
+
+// shortName turns Иванов Иван Иванович into Иванов И. И.
+func shortName(lastName, firstName, middleName string) string {
+	name := lastName
+	for _, part := range []string{firstName, middleName} {
+		if initial := []rune(strings.TrimSpace(part)); len(initial) > 0 {
+			name += " " + string(initial[0]) + "."
+		}
+	}
+
+	return name
+}
+
+func place(address string, room *models.Room) string {
+	if room == nil {
+		return address
+	}
+
+	// SetDate stores the room as "building, number" when no address is given
+	classroom := fmt.Sprintf("%s, ауд. %s", room.Building, room.Number)
+	if address == "" || address == fmt.Sprintf("%s, %s", room.Building, room.Number) {
+		return classroom
+	}
+
+	return address + " (" + classroom + ")"
+}
@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+var (
+	pageCount = regexp.MustCompile(`/Type /Pages\s*/Kids \[[^\]]*\]\s*/Count (\d+)`)
+	issuedAt  = time.Date(2025, 5, 30, 9, 15, 0, 0, time.UTC)
+)
+
+// checkPDF checks the header, the number of pages and the title and the
+// creation date of the info dictionary. The page contents are compressed,
+// so the text of the fields is not checked.
+func checkPDF(t *testing.T, doc []byte, pages int, title string) {
+	t.Helper()
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-")) {
+		t.Fatalf("document starts with %q, want %%PDF-", doc[:min(len(doc), 8)])
+	}
+	match := pageCount.FindSubmatch(doc)
+	if match == nil || string(match[1]) != fmt.Sprint(pages) {
+		t.Errorf("pages = %s, want %d", match, pages)
+	}
+
+	// UTF-8 titles are stored as UTF-16BE with a byte order mark
+	encoded := []byte{0xfe, 0xff}
+	for _, unit := range utf16.Encode([]rune(title)) {
+		encoded = binary.BigEndian.AppendUint16(encoded, unit)
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`).Replace(string(encoded))
+	if !bytes.Contains(doc, []byte("/Title ("+escaped+")")) {
+		t.Errorf("document has no title %q", title)
+	}
+	if !bytes.Contains(doc, []byte("/CreationDate (D:20250530091500)")) {
+		t.Error("document has no creation date of the issue time")
+	}
+}
+
+func sheetDebts(n int) []models.Debt {
+	debts := make([]models.Debt, n)
+	for i := range debts {
+		debts[i] = models.Debt{
+			ID: int64(i + 1),
+			Student: &models.Student{
+				LastName:  "Константинопольский",
+				FirstName: "Александр",
+				Group:     &models.Group{Name: "ИВТ-41"},
+			},
+		}
+	}
+	return debts
+}
+
+func TestRenderRetakeSheet(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		assessment string
+		debts      int
+		pages      int
+		title      string
+	}{
+		{"empty exam sheet", valueobjects.AssessmentExam, 0, 1, "ЭКЗАМЕНАЦИОННАЯ ВЕДОМОСТЬ"},
+		{"credit sheet", valueobjects.AssessmentCredit, 5, 1, "ЗАЧЁТНАЯ ВЕДОМОСТЬ"},
+		{"coursework sheet on pages", valueobjects.AssessmentCoursework, 60, 3, "ВЕДОМОСТЬ ЗАЩИТЫ КУРСОВЫХ РАБОТ"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := NewDocumentRenderer().RenderRetakeSheet(context.Background(), models.RetakeSheet{
+				SessionID: 7,
+				Exam:      models.Exam{Name: "Базы данных", AssessmentType: tc.assessment},
+				Teacher:   models.Teacher{LastName: "Сидорова", FirstName: "Анна", MiddleName: "Павловна"},
+				Date:      time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+				Room:      &models.Room{Building: "Корпус 1", Number: "101"},
+				Debts:     sheetDebts(tc.debts),
+				IssuedAt:  issuedAt,
+			})
+			if err != nil {
+				t.Fatalf("RenderRetakeSheet: %v", err)
+			}
+			checkPDF(t, doc, tc.pages, tc.title)
+		})
+	}
+}
+
+func TestRenderReferralSlip(t *testing.T) {
+	date := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		debt models.Debt
+	}{
+		{"scheduled debt", models.Debt{
+			ID:      42,
+			Date:    &date,
+			Address: "Корпус 1, 101",
+			Exam:    &models.Exam{Name: "Базы данных", AssessmentType: valueobjects.AssessmentGradedCredit},
+			Student: &models.Student{LastName: "Петров", FirstName: "Иван", Group: &models.Group{Name: "ИВТ-41"}},
+			Teacher: &models.Teacher{LastName: "Сидорова", FirstName: "Анна"},
+		}},
+		// the relations are optional, a slip is rendered anyway
+		{"debt without relations", models.Debt{ID: 42}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := NewDocumentRenderer().RenderReferralSlip(context.Background(), models.ReferralSlip{
+				Debt:     tc.debt,
+				IssuedAt: issuedAt,
+			})
+			if err != nil {
+				t.Fatalf("RenderReferralSlip: %v", err)
+			}
+			checkPDF(t, doc, 1, "Направление № 42")
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	doc := newDocument(issuedAt)
+	doc.SetFont(fontFamily, "", 9)
+
+	if got := fit(doc, "ИВТ-41", 30); got != "ИВТ-41" {
+		t.Errorf("fit of a short text = %q, want it whole", got)
+	}
+	long := strings.Repeat("Константинопольский ", 5)
+	got := fit(doc, long, 70)
+	if !strings.HasSuffix(got, "…") || !strings.HasPrefix(long, strings.TrimSuffix(got, "…")) || doc.GetStringWidth(got) > 70 {
+		t.Errorf("fit of a long text = %q, want it cut to the cell", got)
+	}
+}
+
+func TestShortName(t *testing.T) {
+	for _, tc := range []struct {
+		last, first, middle string
+		want                string
+	}{
+		{"Иванов", "Иван", "Иванович", "Иванов И. И."},
+		{"Иванов", "Иван", "", "Иванов И."},
+		{"Иванов", "", " ", "Иванов"},
+	} {
+		if got := shortName(tc.last, tc.first, tc.middle); got != tc.want {
+			t.Errorf("shortName(%q, %q, %q) = %q, want %q", tc.last, tc.first, tc.middle, got, tc.want)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	room := &models.Room{Building: "Корпус 1", Number: "101"}
+	for _, tc := range []struct {
+		name    string
+		address string
+		room    *models.Room
+		want    string
+	}{
+		{"address only", "online", nil, "online"},
+		{"room only", "", room, "Корпус 1, ауд. 101"},
+		{"address made of the room", "Корпус 1, 101", room, "Корпус 1, ауд. 101"},
+		{"address and room", "ул. Ленина, 1", room, "ул. Ленина, 1 (Корпус 1, ауд. 101)"},
+	} {
+		if got := place(tc.address, tc.room); got != tc.want {
+			t.Errorf("place of %s = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
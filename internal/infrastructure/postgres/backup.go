@@ -0,0 +1,362 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pressly/goose/v3"
+
+	"github.com/VanLavr/Diploma-fin/utils/config"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// restoreBatchSize is how many rows a statement of Restore inserts.
+const restoreBatchSize = 500
+
+// Backup writes every table but the migration history into a zip at path,
+// see backupManifest. The tables are read in one repeatable read
+// transaction, so the backup is consistent while the app keeps running.
+func Backup(ctx context.Context, cfg *config.Config, path string) (err error) {
+	db, err := sql.Open("pgx", cfg.DbString)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer db.Close()
+
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		return err
+	}
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not get the schema version")
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer tx.Rollback()
+
+	tables, err := backupTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// the archive appears at path once it is complete
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not create the backup")
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	w := newBackupWriter(f, version)
+	for _, table := range tables {
+		err := w.WriteTable(table, func(write func(row []byte) error) error {
+			rows, err := tx.QueryContext(ctx, "SELECT to_jsonb(t)::text FROM "+pgx.Identifier{table}.Sanitize()+" t")
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var row []byte
+				if err := rows.Scan(&row); err != nil {
+					return err
+				}
+				if err := write(row); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not back up", "table", table)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not write the manifest")
+	}
+	if err := f.Close(); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	log.Logger.Info("backed up", "path", path, "schema_version", version, "tables", len(tables))
+	return nil
+}
+
+// Restore loads a backup of Backup into a database without data. The
+// database may already be migrated, as the app migrates on start, but not
+// past the schema version of the backup. It checks the archive first,
+// migrates the database up to the schema version of the backup and loads
+// the tables in one transaction. The row counts are checked again before
+// the commit. The migrations commit on their own, a failed load migrates
+// the database back down to the version it had, so it can be retried.
+func Restore(ctx context.Context, cfg *config.Config, path string) (err error) {
+	archive, err := openBackup(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	manifest := archive.manifest
+
+	db, err := sql.Open("pgx", cfg.DbString)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer db.Close()
+
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		return err
+	}
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not get the schema version")
+	}
+	if version > manifest.SchemaVersion {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "the database is newer than the backup", "schema_version", version, "backup_version", manifest.SchemaVersion)
+	}
+	if version != 0 {
+		table, err := filledTable(ctx, db)
+		if err != nil {
+			return err
+		}
+		if table != "" {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "restore needs an empty database", "table", table)
+		}
+	}
+	if !slices.ContainsFunc(provider.ListSources(), func(source *goose.Source) bool {
+		return source.Version == manifest.SchemaVersion
+	}) {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "unknown schema version", "schema_version", manifest.SchemaVersion)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		// a canceled restore is migrated down as well
+		if _, downErr := provider.DownTo(context.WithoutCancel(ctx), version); downErr != nil {
+			log.Logger.Error(downErr.Error(), errors.MethodKey, log.GetMethodName())
+		}
+	}()
+	if _, err := provider.UpTo(ctx, manifest.SchemaVersion); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not migrate", "schema_version", manifest.SchemaVersion)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer tx.Rollback()
+
+	tables, err := backupTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	restored := make(map[string]bool)
+	for _, table := range manifest.Tables {
+		restored[table.Name] = true
+	}
+	if !slices.Equal(slices.Sorted(maps.Keys(restored)), slices.Sorted(slices.Values(tables))) {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "tables of the backup do not match the schema")
+	}
+
+	identifiers := make([]string, len(tables))
+	for i, table := range tables {
+		identifiers[i] = pgx.Identifier{table}.Sanitize()
+	}
+	if len(identifiers) != 0 {
+		if _, err := tx.ExecContext(ctx, "TRUNCATE "+strings.Join(identifiers, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not truncate")
+		}
+	}
+
+	for _, table := range manifest.Tables {
+		identifier := pgx.Identifier{table.Name}.Sanitize()
+		insert := fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM jsonb_populate_recordset(NULL::%[1]s, $1::jsonb)", identifier)
+		err := archive.ReadTable(table, restoreBatchSize, func(batch [][]byte) error {
+			rows := append([]byte{'['}, bytes.Join(batch, []byte{','})...)
+			_, err := tx.ExecContext(ctx, insert, string(append(rows, ']')))
+			return err
+		})
+		if err != nil {
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not restore", "table", table.Name)
+		}
+
+		var count int64
+		if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM "+identifier).Scan(&count); err != nil {
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		if count != table.Rows {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "rows mismatch", "table", table.Name, "rows", count, "want", table.Rows)
+		}
+	}
+
+	if err := resetSequences(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	log.Logger.Info("restored", "path", path, "schema_version", manifest.SchemaVersion, "created_at", manifest.CreatedAt, "tables", len(manifest.Tables))
+	return nil
+}
+
+// filledTable returns the first table holding a row, or "" when the
+// tables are empty.
+func filledTable(ctx context.Context, db *sql.DB) (string, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer tx.Rollback()
+
+	tables, err := backupTables(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	for _, table := range tables {
+		var filled bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+pgx.Identifier{table}.Sanitize()+")").Scan(&filled); err != nil {
+			return "", log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "", "table", table)
+		}
+		if filled {
+			return table, nil
+		}
+	}
+
+	return "", nil
+}
+
+// backupTables returns the tables of the schema but the migration history,
+// every table after the ones it references.
+func backupTables(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT tablename FROM pg_tables
+		WHERE schemaname = current_schema() AND tablename <> 'goose_db_version'
+		ORDER BY tablename`)
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list tables")
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list tables")
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list tables")
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT DISTINCT c.relname, r.relname
+		FROM pg_constraint f
+		JOIN pg_class c ON c.oid = f.conrelid
+		JOIN pg_class r ON r.oid = f.confrelid
+		WHERE f.contype = 'f' AND f.connamespace = current_schema()::regnamespace`)
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list foreign keys")
+	}
+	references := make(map[string][]string)
+	for rows.Next() {
+		var table, referenced string
+		if err := rows.Scan(&table, &referenced); err != nil {
+			rows.Close()
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list foreign keys")
+		}
+		if table != referenced {
+			references[table] = append(references[table], referenced)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list foreign keys")
+	}
+
+	return sortTables(tables, references)
+}
+
+// sortTables puts every table after the ones it references, each pass
+// over tables places the ones whose references are already placed.
+func sortTables(tables []string, references map[string][]string) ([]string, error) {
+	result := make([]string, 0, len(tables))
+	placed := make(map[string]bool)
+	for len(result) < len(tables) {
+		before := len(result)
+		for _, table := range tables {
+			if placed[table] {
+				continue
+			}
+			if slices.ContainsFunc(references[table], func(referenced string) bool { return !placed[referenced] }) {
+				continue
+			}
+			placed[table] = true
+			result = append(result, table)
+		}
+		if len(result) == before {
+			return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "foreign keys of the tables make a cycle")
+		}
+	}
+
+	return result, nil
+}
+
+// resetSequences moves the sequences of the serial columns past the
+// restored ids.
+func resetSequences(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'`)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list sequences")
+	}
+	type serial struct{ table, column string }
+	var serials []serial
+	for rows.Next() {
+		var s serial
+		if err := rows.Scan(&s.table, &s.column); err != nil {
+			rows.Close()
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list sequences")
+		}
+		serials = append(serials, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not list sequences")
+	}
+
+	for _, s := range serials {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence($1, $2), coalesce(max(%[1]s), 0) + 1, false) FROM %[2]s",
+			pgx.Identifier{s.column}.Sanitize(), pgx.Identifier{s.table}.Sanitize(),
+		), pgx.Identifier{s.table}.Sanitize(), s.column)
+		if err != nil {
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not reset the sequence", "table", s.table, "column", s.column)
+		}
+	}
+
+	return nil
+}
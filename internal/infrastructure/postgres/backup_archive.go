@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	e "errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// backupFormatVersion changes when the layout of the archive does, the
+// schema of the tables is versioned by the migrations.
+const backupFormatVersion = 1
+
+const backupManifestFile = "manifest.json"
+
+// backupManifest describes a backup archive: a zip with a file of json
+// lines per table, a row per line, in the order the tables are restored in.
+type backupManifest struct {
+	FormatVersion int           `json:"format_version"`
+	SchemaVersion int64         `json:"schema_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Tables        []backupTable `json:"tables"`
+}
+
+type backupTable struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Rows   int64  `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+type backupWriter struct {
+	zip      *zip.Writer
+	manifest backupManifest
+}
+
+func newBackupWriter(w io.Writer, schemaVersion int64) *backupWriter {
+	return &backupWriter{
+		zip: zip.NewWriter(w),
+		manifest: backupManifest{
+			FormatVersion: backupFormatVersion,
+			SchemaVersion: schemaVersion,
+			CreatedAt:     time.Now().UTC(),
+		},
+	}
+}
+
+// WriteTable stores the rows the rows function writes, each one a json
+// object without line breaks.
+func (this *backupWriter) WriteTable(name string, rows func(write func(row []byte) error) error) error {
+	table := backupTable{Name: name, File: name + ".jsonl"}
+	f, err := this.zip.Create(table.File)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	w := io.MultiWriter(f, hash)
+	err = rows(func(row []byte) error {
+		if bytes.IndexByte(row, '\n') >= 0 {
+			return fmt.Errorf("row of %s has a line break", name)
+		}
+		table.Rows++
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte{'\n'})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	table.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	this.manifest.Tables = append(this.manifest.Tables, table)
+	return nil
+}
+
+// Close writes the manifest, the archive is not complete without it.
+func (this *backupWriter) Close() error {
+	f, err := this.zip.Create(backupManifestFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(this.manifest); err != nil {
+		return err
+	}
+
+	return this.zip.Close()
+}
+
+type backupReader struct {
+	zip      *zip.ReadCloser
+	files    map[string]*zip.File
+	manifest backupManifest
+}
+
+// openBackup reads the manifest and checks the checksum and the number of
+// rows of every table, so a broken archive fails before anything is
+// restored. The errors of the archive are errors.ErrInvalidData.
+func openBackup(path string) (_ *backupReader, err error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not open the backup")
+	}
+	defer func() {
+		if err != nil {
+			archive.Close()
+		}
+	}()
+
+	result := &backupReader{zip: archive, files: make(map[string]*zip.File)}
+	for _, f := range archive.File {
+		result.files[f.Name] = f
+	}
+
+	f, ok := result.files[backupManifestFile]
+	if !ok {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "no manifest in the backup")
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, err.Error())
+	}
+	err = json.NewDecoder(r).Decode(&result.manifest)
+	r.Close()
+	if err != nil {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "can not read the manifest: "+err.Error())
+	}
+	if result.manifest.FormatVersion != backupFormatVersion {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "unknown backup format", "format_version", result.manifest.FormatVersion)
+	}
+
+	names := make(map[string]bool)
+	for _, table := range result.manifest.Tables {
+		if names[table.Name] {
+			return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "table is in the backup twice", "table", table.Name)
+		}
+		names[table.Name] = true
+
+		hash := sha256.New()
+		var rows int64
+		err := result.ReadTable(table, 1, func(batch [][]byte) error {
+			rows++
+			hash.Write(batch[0])
+			hash.Write([]byte{'\n'})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != table.SHA256 {
+			return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "checksum mismatch", "table", table.Name)
+		}
+		if rows != table.Rows {
+			return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "rows mismatch", "table", table.Name, "rows", rows, "want", table.Rows)
+		}
+	}
+
+	return result, nil
+}
+
+// ReadTable passes the rows of the table to load in batches of up to size
+// rows. The rows are only valid until load returns.
+func (this *backupReader) ReadTable(table backupTable, size int, load func(batch [][]byte) error) error {
+	f, ok := this.files[table.File]
+	if !ok {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "no file of the table in the backup", "table", table.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, err.Error(), "table", table.Name)
+	}
+	defer rc.Close()
+
+	r := bufio.NewReader(rc)
+	batch := make([][]byte, 0, size)
+	for {
+		row, err := r.ReadBytes('\n')
+		if len(row) != 0 {
+			if row[len(row)-1] != '\n' {
+				return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "truncated row", "table", table.Name)
+			}
+			batch = append(batch, row[:len(row)-1])
+		}
+		if len(batch) == size || (len(batch) != 0 && err != nil) {
+			if err := load(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		if e.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			// a zip checks the crc of a file as it reaches its end
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, err.Error(), "table", table.Name)
+		}
+	}
+}
+
+func (this *backupReader) Close() error {
+	return this.zip.Close()
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	e "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/utils/config"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func writeTestBackup(t *testing.T, tables map[string][]string, order ...string) string {
+	t.Helper()
+	return writeTestBackupAt(t, 20250516100000, tables, order...)
+}
+
+func writeTestBackupAt(t *testing.T, version int64, tables map[string][]string, order ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := newBackupWriter(f, version)
+	for _, table := range order {
+		err := w.WriteTable(table, func(write func(row []byte) error) error {
+			for _, row := range tables[table] {
+				if err := write([]byte(row)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WriteTable(%s): %v", table, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func TestBackupArchive(t *testing.T) {
+	tables := map[string][]string{
+		"groups":   {`{"id": 1, "name": "ИВТ-41"}`, `{"id": 2, "name": "ИВТ-42"}`},
+		"students": {`{"uuid": "a", "group_id": 1}`},
+		"rooms":    nil,
+	}
+	path := writeTestBackup(t, tables, "groups", "rooms", "students")
+
+	archive, err := openBackup(path)
+	if err != nil {
+		t.Fatalf("openBackup: %v", err)
+	}
+	defer archive.Close()
+
+	if archive.manifest.SchemaVersion != 20250516100000 || archive.manifest.FormatVersion != backupFormatVersion {
+		t.Errorf("manifest = %+v, want the schema and the format version", archive.manifest)
+	}
+	var names []string
+	for _, table := range archive.manifest.Tables {
+		names = append(names, table.Name)
+
+		var rows []string
+		err := archive.ReadTable(table, 1, func(batch [][]byte) error {
+			for _, row := range batch {
+				rows = append(rows, string(row))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ReadTable(%s): %v", table.Name, err)
+		}
+		if !slices.Equal(rows, tables[table.Name]) || table.Rows != int64(len(rows)) {
+			t.Errorf("%s = %v of %d rows, want %v", table.Name, rows, table.Rows, tables[table.Name])
+		}
+	}
+	if want := []string{"groups", "rooms", "students"}; !slices.Equal(names, want) {
+		t.Errorf("tables = %v, want %v in the order they were written", names, want)
+	}
+
+	var batches int
+	err = archive.ReadTable(archive.manifest.Tables[0], 500, func(batch [][]byte) error {
+		batches++
+		return nil
+	})
+	if err != nil || batches != 1 {
+		t.Errorf("ReadTable in batches of 500 = %d batches, %v, want 1", batches, err)
+	}
+}
+
+func TestBackupArchiveChecksum(t *testing.T) {
+	path := writeTestBackup(t, map[string][]string{"groups": {`{"id": 1, "name": "ИВТ-41"}`}}, "groups")
+
+	// rewrite the archive with another row but the same manifest
+	src, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(t.TempDir(), "broken.zip")
+	f, err := os.Create(broken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for _, file := range src.File {
+		dst, err := w.Create(file.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Name == "groups.jsonl" {
+			io.WriteString(dst, `{"id": 1, "name": "ИВТ-42"}`+"\n")
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(dst, r)
+		r.Close()
+	}
+	src.Close()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := openBackup(broken); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("openBackup of a changed table: err = %v, want ErrInvalidData", err)
+	}
+}
+
+func TestBackupArchiveRowWithLineBreak(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "backup.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	err = newBackupWriter(f, 1).WriteTable("groups", func(write func(row []byte) error) error {
+		return write([]byte("{\n}"))
+	})
+	if err == nil {
+		t.Errorf("WriteTable of a row with a line break: err = nil, want an error")
+	}
+}
+
+func TestSortTables(t *testing.T) {
+	tables := []string{"debts", "exams", "groups", "retake_requests", "students", "teachers"}
+	references := map[string][]string{
+		"debts":           {"exams", "students", "teachers"},
+		"retake_requests": {"debts", "students", "teachers"},
+		"students":        {"groups"},
+	}
+
+	sorted, err := sortTables(tables, references)
+	if err != nil {
+		t.Fatalf("sortTables: %v", err)
+	}
+	if want := []string{"exams", "groups", "students", "teachers", "debts", "retake_requests"}; !slices.Equal(sorted, want) {
+		t.Errorf("sortTables = %v, want %v", sorted, want)
+	}
+
+	if _, err := sortTables([]string{"a", "b"}, map[string][]string{"a": {"b"}, "b": {"a"}}); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("sortTables of a cycle: err = %v, want ErrInvalidData", err)
+	}
+}
+
+func TestRestoreFailureMigratesDown(t *testing.T) {
+	dsn := os.Getenv(testDBStringKey)
+	if dsn == "" {
+		t.Skip(testDBStringKey + " is not set")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		t.Fatalf("newMigrationProvider: %v", err)
+	}
+	if _, err := provider.DownTo(ctx, 0); err != nil {
+		t.Fatalf("DownTo: %v", err)
+	}
+
+	// a backup of groups only does not match the schema
+	path := writeTestBackup(t, map[string][]string{"groups": {`{"id": 1, "name": "ИВТ-41"}`}}, "groups")
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := Restore(ctx, &config.Config{DbString: dsn}, path); !e.Is(err, errors.ErrInvalidData) {
+			t.Errorf("Restore attempt %d: err = %v, want ErrInvalidData", attempt, err)
+		}
+		if version, err := provider.GetDBVersion(ctx); err != nil || version != 0 {
+			t.Errorf("schema version after attempt %d = %d, %v, want 0", attempt, version, err)
+		}
+	}
+}
+
+func TestRestoreMigratedDatabase(t *testing.T) {
+	dsn := os.Getenv(testDBStringKey)
+	if dsn == "" {
+		t.Skip(testDBStringKey + " is not set")
+	}
+
+	ctx := context.Background()
+	cfg := &config.Config{DbString: dsn}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		t.Fatalf("newMigrationProvider: %v", err)
+	}
+	if _, err := provider.DownTo(ctx, 0); err != nil {
+		t.Fatalf("DownTo: %v", err)
+	}
+	t.Cleanup(func() { provider.DownTo(context.Background(), 0) })
+	// as the app leaves it when it migrates on start
+	if _, err := provider.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	latest, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		t.Fatalf("GetDBVersion: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	if err := Backup(ctx, cfg, path); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := Restore(ctx, cfg, path); err != nil {
+		t.Fatalf("Restore into a migrated database: %v", err)
+	}
+
+	// a failed restore keeps the schema the database had
+	groups := writeTestBackupAt(t, latest, map[string][]string{"groups": {`{"id": 1, "name": "ИВТ-41"}`}}, "groups")
+	if err := Restore(ctx, cfg, groups); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("Restore of a mismatching backup: err = %v, want ErrInvalidData", err)
+	}
+	if version, err := provider.GetDBVersion(ctx); err != nil || version != latest {
+		t.Errorf("schema version = %d, %v, want %d", version, err, latest)
+	}
+	older := writeTestBackup(t, map[string][]string{"groups": {`{"id": 1, "name": "ИВТ-41"}`}}, "groups")
+	if err := Restore(ctx, cfg, older); !e.Is(err, errors.ErrInvalidCommand) {
+		t.Errorf("Restore of an older backup: err = %v, want ErrInvalidCommand", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO groups (name) VALUES ('ИВТ-41')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := Restore(ctx, cfg, path); !e.Is(err, errors.ErrInvalidCommand) {
+		t.Errorf("Restore into a database with data: err = %v, want ErrInvalidCommand", err)
+	}
+}
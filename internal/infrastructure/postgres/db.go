@@ -9,9 +9,11 @@ import (
 	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
 	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/infrastructure/mail"
+	"github.com/VanLavr/Diploma-fin/internal/infrastructure/pdf"
 	"github.com/VanLavr/Diploma-fin/utils/config"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
 	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
 )
 
 type repository struct {
@@ -22,6 +24,17 @@ type repository struct {
 	repositories.TeacherRepository
 	repositories.StudentMailer
 	repositories.GroupRepository
+	repositories.RetakeRequestRepository
+	repositories.RetakeSessionRepository
+	repositories.RoomRepository
+	repositories.ScheduleRepository
+	repositories.TeacherAvailabilityRepository
+	repositories.TimetableRepository
+	repositories.DocumentRenderer
+	repositories.ImportRepository
+	repositories.SearchRepository
+	repositories.TrashRepository
+	repositories.PersonalDataRepository
 }
 
 func NewRepository(
@@ -32,13 +45,24 @@ func NewRepository(
 	errors.FatalOnError(err)
 
 	return &repository{
-		Connector:             connector,
-		TransactionRepository: NewTransaction(conn),
-		ExamRepository:        NewExamRepo(conn),
-		StudentRepository:     NewStudentRepo(conn),
-		TeacherRepository:     NewTeacherRepo(conn),
-		GroupRepository:       NewGroupRepo(conn),
-		StudentMailer:         mail.NewStudentMailer(cfg),
+		Connector:                     connector,
+		TransactionRepository:         NewTransaction(conn),
+		ExamRepository:                NewExamRepo(conn),
+		StudentRepository:             NewStudentRepo(conn),
+		TeacherRepository:             NewTeacherRepo(conn),
+		GroupRepository:               NewGroupRepo(conn),
+		RetakeRequestRepository:       NewRetakeRequestRepo(conn),
+		RetakeSessionRepository:       NewRetakeSessionRepo(conn),
+		RoomRepository:                NewRoomRepo(conn),
+		ScheduleRepository:            NewScheduleRepo(conn),
+		TeacherAvailabilityRepository: NewTeacherAvailabilityRepo(conn),
+		TimetableRepository:           NewTimetableRepo(conn),
+		ImportRepository:              NewImportRepo(conn),
+		SearchRepository:              NewSearchRepo(conn),
+		TrashRepository:               NewTrashRepo(conn),
+		PersonalDataRepository:        NewPersonalDataRepo(conn),
+		StudentMailer:                 mail.NewStudentMailer(cfg),
+		DocumentRenderer:              pdf.NewDocumentRenderer(),
 	}
 }
 
@@ -103,7 +127,13 @@ func NewTransaction(conn *pgxpool.Pool) repositories.TransactionRepository {
 	}
 }
 
+// PerformTransaction implements repositories.TransactionRepository.
+// A transaction started inside another one joins it.
 func (t *transaction) PerformTransaction(ctx context.Context, wrapper func(ctx context.Context) error) error {
+	if _, ok := tools.GetTransaction(ctx); ok {
+		return wrapper(ctx)
+	}
+
 	tx, err := t.db.Begin(ctx)
 	if err != nil {
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
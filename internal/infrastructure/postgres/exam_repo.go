@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
@@ -51,7 +53,9 @@ func (this *examRepo) SearchDebts(ctx context.Context, filters query.SearchDebts
 		LeftJoin("exams e ON d.exam_id = e.id").
 		LeftJoin("students s ON d.student_uuid = s.uuid").
 		LeftJoin("groups g ON s.group_id = g.id").
-		LeftJoin("teachers t ON d.teacher_uuid = t.uuid")
+		LeftJoin("teachers t ON d.teacher_uuid = t.uuid").
+		Where("d.closed_at IS NULL").
+		Where(debtNotDeleted)
 
 	// Apply filters
 	if len(filters.IDs) > 0 {
@@ -82,7 +86,12 @@ func (this *examRepo) SearchDebts(ctx context.Context, filters query.SearchDebts
 
 	// Execute the query
 	fmt.Println("DEBUG: ", sqlQuery)
-	rows, err := this.db.Query(ctx, sqlQuery, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sqlQuery, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -144,8 +153,9 @@ func (this *examRepo) SearchDebts(ctx context.Context, filters query.SearchDebts
 }
 
 func (this *examRepo) SearchExams(ctx context.Context, filters query.SearchExamFilters) ([]models.Exam, error) {
-	query := sq.Select("id", "name").
-		From("exams")
+	query := sq.Select("id", "name", "assessment_type").
+		From("exams").
+		Where(notDeleted(filters.Deleted))
 
 	if len(filters.IDs) > 0 {
 		query = query.Where(sq.Eq{"id": filters.IDs})
@@ -161,7 +171,12 @@ func (this *examRepo) SearchExams(ctx context.Context, filters query.SearchExamF
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := this.db.Query(ctx, sqlQuery, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sqlQuery, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -171,7 +186,7 @@ func (this *examRepo) SearchExams(ctx context.Context, filters query.SearchExamF
 	var exams []models.Exam
 	for rows.Next() {
 		var exam models.Exam
-		if err := rows.Scan(&exam.ID, &exam.Name); err != nil {
+		if err := rows.Scan(&exam.ID, &exam.Name, &exam.AssessmentType); err != nil {
 			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 			return nil, fmt.Errorf("failed to scan exam: %w", err)
 		}
@@ -205,7 +220,12 @@ func (this *examRepo) CreateDebt(ctx context.Context, createDebt commands.Create
 		return 0, err
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var id int64
 	if err := row.Scan(&id); err != nil {
@@ -223,7 +243,12 @@ func (this *examRepo) DeleteDebt(ctx context.Context, debt commands.DeleteDebt)
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	if _, err = this.db.Exec(ctx, sql, args...); err != nil {
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
@@ -232,15 +257,20 @@ func (this *examRepo) DeleteDebt(ctx context.Context, debt commands.DeleteDebt)
 
 // GetExamByID implements repositories.ExamRepository.
 func (this *examRepo) GetExamByID(ctx context.Context, query query.GetExamsFilters) (*models.Exam, error) {
-	sql, args, err := sq.Select("id", "name").From("exams").Where(sq.Eq{"id": query.IDs}).PlaceholderFormat(sq.Dollar).ToSql()
+	sql, args, err := sq.Select("id", "name", "assessment_type", "version").From("exams").Where(sq.Eq{"id": query.IDs, "deleted_at": nil}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	result := new(models.Exam)
-	if err := row.Scan(&result.ID, &result.Name); err != nil {
+	if err := row.Scan(&result.ID, &result.Name, &result.AssessmentType, &result.Version); err != nil {
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
@@ -249,49 +279,34 @@ func (this *examRepo) GetExamByID(ctx context.Context, query query.GetExamsFilte
 
 // DeleteExam implements repositories.ExamRepository.
 func (this *examRepo) DeleteExam(ctx context.Context, exam commands.DeleteExam) error {
-	sql, args, err := sq.Delete("exams").Where(sq.Eq{"id": exam.ID}).PlaceholderFormat(sq.Dollar).ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if _, err = this.db.Exec(ctx, sql, args...); err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	return nil
+	return moveToTrash(ctx, this.db, "exams", sq.Eq{"id": exam.ID})
 }
 
 // UpdateExam implements repositories.ExamRepository.
 func (this *examRepo) UpdateExam(ctx context.Context, exam commands.UpdateExamByID) error {
-	query := sq.Update("exams").
-		Set("name", exam.Name).
-		Where(sq.Eq{"id": exam.ID}).
-		PlaceholderFormat(sq.Dollar)
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	if err := exam.Validate(); err != nil {
+		return err
 	}
 
-	if tx, ok := tools.GetTransaction(ctx); ok {
-		_, err = tx.Exec(ctx, sql, args...)
-	} else {
-		_, err = this.db.Exec(ctx, sql, args...)
-	}
-
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	update := sq.Update("exams").Set("name", exam.Name)
+	if exam.AssessmentType != "" {
+		update = update.Set("assessment_type", exam.AssessmentType)
 	}
 
-	return nil
+	return updateVersion(ctx, this.db, update, "exams", sq.Eq{"id": exam.ID, "deleted_at": nil}, exam.Version)
 }
 
 // CreateExam implements repositories.ExamRepository.
 func (this *examRepo) CreateExam(ctx context.Context, exam commands.CreateExam) (int64, error) {
+	if err := exam.Validate(); err != nil {
+		return 0, err
+	}
+
 	sql, args, err := sq.
 		Insert("exams").
 		SetMap(sq.Eq{
-			"name": exam.Name,
+			"name":            exam.Name,
+			"assessment_type": exam.AssessmentType,
 		}).
 		Suffix("RETURNING id").
 		PlaceholderFormat(sq.Dollar).
@@ -301,7 +316,12 @@ func (this *examRepo) CreateExam(ctx context.Context, exam commands.CreateExam)
 		return 0, err
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var id int64
 	if err := row.Scan(&id); err != nil {
@@ -316,34 +336,42 @@ func (this examRepo) GetExams(ctx context.Context, filters query.GetExamsFilters
 	if err := filters.Validate(); err != nil {
 		return nil, err
 	}
-	query := sq.Select("id", "name")
+	query := sq.Select("id", "name", "assessment_type", "version")
 	query = query.From("exams")
+	query = query.Where(examConditions(filters))
 	query = query.PlaceholderFormat(sq.Dollar)
 
-	if len(filters.IDs) != 0 {
-		query = query.Where(sq.Eq{"id": filters.IDs})
-	}
 	if filters.Offset != 0 {
 		query = query.Offset(uint64(filters.Offset))
 	}
 	if filters.Limit != 0 {
 		query = query.Limit(uint64(filters.Limit))
 	}
+	query, err := orderByKeyset(query, examSortColumns, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := this.db.Query(ctx, sql, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var result []models.Exam
 	for rows.Next() {
 		var exam models.Exam
-		if err := rows.Scan(&exam.ID, &exam.Name); err != nil {
+		if err := rows.Scan(&exam.ID, &exam.Name, &exam.AssessmentType, &exam.Version); err != nil {
 			return nil, err
 		}
 
@@ -353,6 +381,155 @@ func (this examRepo) GetExams(ctx context.Context, filters query.GetExamsFilters
 	return result, nil
 }
 
+// CountExams implements repositories.ExamRepository.
+func (this examRepo) CountExams(ctx context.Context, filters query.GetExamsFilters) (int64, error) {
+	if err := filters.Validate(); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := sq.Select("count(*)").
+		From("exams").
+		Where(examConditions(filters)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(&count); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return count, nil
+}
+
+func examConditions(filters query.GetExamsFilters) sq.And {
+	conditions := sq.And{sq.Eq{"deleted_at": nil}}
+	if len(filters.IDs) != 0 {
+		conditions = append(conditions, sq.Eq{"id": filters.IDs})
+	}
+	if len(filters.Names) != 0 {
+		conditions = append(conditions, sq.Eq{"name": filters.Names})
+	}
+	if len(filters.AssessmentTypes) != 0 {
+		conditions = append(conditions, sq.Eq{"assessment_type": filters.AssessmentTypes})
+	}
+	return conditions
+}
+
+// CountDebts implements repositories.ExamRepository.
+func (this examRepo) CountDebts(ctx context.Context, filters query.GetDebtsFilters) (int64, error) {
+	if err := filters.Validate(); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	sql, args, err := joinDebts(sq.Select("count(*)").From("debts d")).
+		Where(debtConditions(filters)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(&count); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+
+	return count, nil
+}
+
+// debtNotDeleted leaves out the debts of trashed students, teachers and
+// exams, joined as s, t and e.
+const debtNotDeleted = "s.deleted_at IS NULL AND t.deleted_at IS NULL AND e.deleted_at IS NULL"
+
+// joinDebts joins what a debt is listed with, debts are aliased d.
+func joinDebts(builder sq.SelectBuilder) sq.SelectBuilder {
+	return builder.
+		LeftJoin("rooms r ON d.room_id = r.id").
+		LeftJoin("students s ON d.student_uuid = s.uuid").
+		LeftJoin("groups g ON s.group_id = g.id").
+		LeftJoin("teachers t ON d.teacher_uuid = t.uuid").
+		LeftJoin("exams e ON d.exam_id = e.id")
+}
+
+func debtConditions(filters query.GetDebtsFilters) sq.And {
+	conditions := sq.And{}
+	if len(filters.StudentUUIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"s.uuid": filters.StudentUUIDs})
+	}
+	if len(filters.TeacherUUIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"t.uuid": filters.TeacherUUIDs})
+	}
+	if len(filters.ExamIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"e.id": filters.ExamIDs})
+	}
+	if len(filters.DebtIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"d.id": filters.DebtIDs})
+	}
+	if len(filters.GroupIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"g.id": filters.GroupIDs})
+	}
+	if filters.OnlyUnscheduled {
+		conditions = append(conditions, sq.Expr("d.date IS NULL"))
+	}
+	if !filters.IncludeClosed {
+		conditions = append(conditions, sq.Expr("d.closed_at IS NULL"))
+	}
+	if !filters.IncludeDeleted {
+		conditions = append(conditions, sq.Expr(debtNotDeleted))
+	}
+	if !filters.ScheduledFrom.IsZero() {
+		conditions = append(conditions, sq.GtOrEq{"d.date": filters.ScheduledFrom})
+	}
+	if !filters.ScheduledTo.IsZero() {
+		conditions = append(conditions, sq.Lt{"d.date": filters.ScheduledTo})
+	}
+	return conditions
+}
+
+// withExamGroups adds the group ids and names of the open debts of the
+// exam of every debt, debts in the trash left out. The groups are
+// aggregated once per exam of the debts the filters select, not once per
+// debt.
+func withExamGroups(builder sq.SelectBuilder, filters query.GetDebtsFilters) sq.SelectBuilder {
+	exams := joinDebts(sq.Select("d.exam_id").From("debts d")).Where(debtConditions(filters))
+	groups := sq.Select("DISTINCT d.exam_id", "g.id AS group_id", "g.name AS group_name").
+		From("debts d").
+		Join("students s ON d.student_uuid = s.uuid").
+		Join("groups g ON s.group_id = g.id").
+		Join("teachers t ON d.teacher_uuid = t.uuid").
+		Join("exams e ON d.exam_id = e.id").
+		Where("d.closed_at IS NULL").
+		Where(debtNotDeleted).
+		Where(sq.Expr("d.exam_id IN (?)", exams))
+	examGroups := sq.Select(
+		"eg.exam_id",
+		"array_agg(eg.group_id ORDER BY eg.group_id) AS group_ids",
+		"array_agg(eg.group_name ORDER BY eg.group_id) AS group_names",
+	).
+		FromSelect(groups, "eg").
+		GroupBy("eg.exam_id")
+
+	return builder.
+		PrefixExpr(sq.Expr("WITH exam_groups AS (?)", examGroups)).
+		Columns("COALESCE(eg.group_ids, '{}')", "COALESCE(eg.group_names, '{}')").
+		LeftJoin("exam_groups eg ON eg.exam_id = d.exam_id")
+}
+
 func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters) ([]models.Debt, error) {
 	if err := filters.Validate(); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -363,6 +540,7 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 		"d.id",
 		"e.id",
 		"e.name",
+		"COALESCE(e.assessment_type, '')",
 		"t.uuid",
 		"t.first_name",
 		"t.last_name",
@@ -376,13 +554,20 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 		"g.name",
 		"s.email",
 		"d.date",
+		"d.closed_at",
+		"d.version",
 		"COALESCE(d.address, '')",
+		"COALESCE(r.id, 0)",
+		"COALESCE(r.building, '')",
+		"COALESCE(r.number, '')",
+		"COALESCE(r.capacity, 0)",
+		"COALESCE(r.accessible, false)",
 	)
-	query = query.From("debts d")
-	query = query.LeftJoin("students s ON d.student_uuid = s.uuid")
-	query = query.LeftJoin("groups g ON s.group_id = g.id")
-	query = query.LeftJoin("teachers t ON d.teacher_uuid = t.uuid")
-	query = query.LeftJoin("exams e ON d.exam_id = e.id")
+	query = joinDebts(query.From("debts d"))
+	query = query.Where(debtConditions(filters))
+	if filters.WithExamGroups {
+		query = withExamGroups(query, filters)
+	}
 
 	if filters.Limit != 0 {
 		query = query.Limit(uint64(filters.Limit))
@@ -390,17 +575,9 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 	if filters.Offset != 0 {
 		query = query.Offset(uint64(filters.Offset))
 	}
-	if len(filters.StudentUUIDs) > 0 {
-		query = query.Where(sq.Eq{"s.uuid": filters.StudentUUIDs})
-	}
-	if len(filters.TeacherUUIDs) > 0 {
-		query = query.Where(sq.Eq{"t.uuid": filters.TeacherUUIDs})
-	}
-	if len(filters.ExamIDs) > 0 {
-		query = query.Where(sq.Eq{"e.id": filters.ExamIDs})
-	}
-	if len(filters.DebtIDs) > 0 {
-		query = query.Where(sq.Eq{"d.id": filters.DebtIDs})
+	query, err := orderByKeyset(query, debtSortColumns, filters.Keyset)
+	if err != nil {
+		return nil, err
 	}
 	query = query.PlaceholderFormat(sq.Dollar)
 	sql, args, err := query.ToSql()
@@ -410,11 +587,17 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
 	}
 
-	rows, err := this.db.Query(ctx, sql, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
 	}
+	defer rows.Close()
 
 	var result []models.Debt
 	for rows.Next() {
@@ -424,11 +607,15 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 				Group: &models.Group{},
 			},
 			Teacher: &models.Teacher{},
+			Room:    &models.Room{},
 		}
-		if err := rows.Scan(
+		var groupIDs []int64
+		var groupNames []string
+		dest := []any{
 			&debt.ID,
 			&debt.Exam.ID,
 			&debt.Exam.Name,
+			&debt.Exam.AssessmentType,
 			&debt.Teacher.UUID,
 			&debt.Teacher.FirstName,
 			&debt.Teacher.LastName,
@@ -442,11 +629,31 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 			&debt.Student.Group.Name,
 			&debt.Student.Email,
 			&debt.Date,
+			&debt.ClosedAt,
+			&debt.Version,
 			&debt.Address,
-		); err != nil {
+			&debt.Room.ID,
+			&debt.Room.Building,
+			&debt.Room.Number,
+			&debt.Room.Capacity,
+			&debt.Room.Accessible,
+		}
+		if filters.WithExamGroups {
+			dest = append(dest, &groupIDs, &groupNames)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
 		}
+		if debt.Room.ID == 0 {
+			debt.Room = nil
+		}
+		if filters.WithExamGroups {
+			debt.GroupList = make([]models.Group, len(groupIDs))
+			for i := range groupIDs {
+				debt.GroupList[i] = models.Group{ID: groupIDs[i], Name: groupNames[i]}
+			}
+		}
 
 		result = append(result, debt)
 	}
@@ -455,26 +662,223 @@ func (this examRepo) GetDebts(ctx context.Context, filters query.GetDebtsFilters
 }
 
 func (this examRepo) UpdateDebt(ctx context.Context, setCommand commands.UpdateDebtByID) error {
-	query := sq.Update("debts").
-		Set("date", setCommand.Date).
+	// zero date unschedules the debt
+	var date any = setCommand.Date
+	if setCommand.Date.IsZero() {
+		date = nil
+	}
+	var roomID any = setCommand.RoomID
+	if setCommand.RoomID == 0 {
+		roomID = nil
+	}
+
+	update := sq.Update("debts").
+		Set("date", date).
+		Set("room_id", roomID).
 		Set("teacher_uuid", setCommand.TeacherUUID).
 		Set("student_uuid", setCommand.StudentUUID).
-		Set("address", setCommand.Address).
-		Where(sq.Eq{"id": setCommand.DebtID}).
-		PlaceholderFormat(sq.Dollar)
+		Set("address", setCommand.Address)
 
-	sql, args, err := query.ToSql()
+	return updateVersion(ctx, this.db, update, "debts", sq.Eq{"id": setCommand.DebtID}, setCommand.Version)
+}
+
+// CreateDebtResult implements repositories.ExamRepository.
+func (this examRepo) CreateDebtResult(ctx context.Context, result commands.CreateDebtResult) (int64, error) {
+	if err := result.Validate(); err != nil {
+		return 0, err
+	}
+
+	var (
+		sessionID any = result.SessionID
+		grade     any = result.Grade
+	)
+	if result.SessionID == 0 {
+		sessionID = nil
+	}
+	if result.Grade == 0 {
+		grade = nil
+	}
+
+	sql, args, err := sq.
+		Insert("debt_results").
+		SetMap(sq.Eq{
+			"debt_id":      result.DebtID,
+			"session_id":   sessionID,
+			"teacher_uuid": result.TeacherUUID,
+			"passed":       result.Passed,
+			"grade":        grade,
+			"attempted_at": result.AttemptedAt,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		if tools.IsUniqueViolation(err) {
+			return 0, log.ErrorWrapper(errors.ErrResultAlreadyRecorded, errors.ERR_INFRASTRUCTURE, "", "debt", result.DebtID)
+		}
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// GetDebtResults implements repositories.ExamRepository.
+func (this examRepo) GetDebtResults(ctx context.Context, filters query.GetDebtResultsFilters) ([]models.DebtResult, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"r.id",
+		"r.debt_id",
+		"COALESCE(r.session_id, 0)",
+		"r.teacher_uuid",
+		"r.passed",
+		"COALESCE(r.grade, 0)",
+		"r.attempted_at",
+		"r.recorded_at",
+	).
+		From("debt_results r").
+		Join("debts d ON r.debt_id = d.id").
+		OrderBy("r.attempted_at", "r.id")
+
+	if len(filters.DebtIDs) > 0 {
+		query = query.Where(sq.Eq{"r.debt_id": filters.DebtIDs})
+	}
+	if len(filters.SessionIDs) > 0 {
+		query = query.Where(sq.Eq{"r.session_id": filters.SessionIDs})
+	}
+	if len(filters.StudentUUIDs) > 0 {
+		query = query.Where(sq.Eq{"d.student_uuid": filters.StudentUUIDs})
+	}
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.DebtResult
+	for rows.Next() {
+		var item models.DebtResult
+		if err := rows.Scan(
+			&item.ID,
+			&item.DebtID,
+			&item.SessionID,
+			&item.TeacherUUID,
+			&item.Passed,
+			&item.Grade,
+			&item.AttemptedAt,
+			&item.RecordedAt,
+		); err != nil {
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return result, nil
+}
+
+// CloseDebt implements repositories.ExamRepository.
+func (this examRepo) CloseDebt(ctx context.Context, command commands.CloseDebt) error {
+	sql, args, err := sq.Update("debts").
+		Set("closed_at", sq.Expr("now()")).
+		Set("version", sq.Expr("version + 1")).
+		Where(sq.Eq{"id": command.DebtID}).
+		Where("closed_at IS NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
+	var tag pgconn.CommandTag
 	if tx, ok := tools.GetTransaction(ctx); ok {
-		_, err = tx.Exec(ctx, sql, args...)
+		tag, err = tx.Exec(ctx, sql, args...)
 	} else {
-		_, err = this.db.Exec(ctx, sql, args...)
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if tag.RowsAffected() != 0 {
+		return nil
+	}
+
+	// the debt is either gone or closed already
+	sql, args, err = sq.Select("count(*)").
+		From("debts").
+		Where(sq.Eq{"id": command.DebtID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		err = tx.QueryRow(ctx, sql, args...).Scan(&count)
+	} else {
+		err = this.db.QueryRow(ctx, sql, args...).Scan(&count)
+	}
+	switch {
+	case err != nil:
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	case count == 0:
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "debt", command.DebtID)
+	default:
+		return log.ErrorWrapper(errors.ErrDebtClosed, errors.ERR_INFRASTRUCTURE, "", "debt", command.DebtID)
 	}
+}
 
+// LockDebt implements repositories.ExamRepository.
+func (this examRepo) LockDebt(ctx context.Context, id int64) error {
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "debt can be locked inside a transaction only")
+	}
+
+	sql, args, err := sq.Select("id").
+		From("debts").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var locked int64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&locked); err != nil {
+		if err == pgx.ErrNoRows {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+		}
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
@@ -23,7 +24,8 @@ type groupRepo struct {
 // SearchGroups implements repositories.GroupRepository.
 func (g *groupRepo) SearchGroups(ctx context.Context, filters query.SearchGroupFilters) ([]models.Group, error) {
 	query := sq.Select("id", "name").
-		From("groups")
+		From("groups").
+		Where(notDeleted(filters.Deleted))
 
 	if len(filters.IDs) > 0 {
 		query = query.Where(sq.Eq{"id": filters.IDs})
@@ -39,7 +41,12 @@ func (g *groupRepo) SearchGroups(ctx context.Context, filters query.SearchGroupF
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := g.db.Query(ctx, sqlQuery, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sqlQuery, args...)
+	} else {
+		rows, err = g.db.Query(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -80,7 +87,12 @@ func (g *groupRepo) CreateGroup(ctx context.Context, group commands.CreateGroup)
 		return 0, err
 	}
 
-	row := g.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = g.db.QueryRow(ctx, sql, args...)
+	}
 
 	var id int64
 	if err := row.Scan(&id); err != nil {
@@ -93,29 +105,25 @@ func (g *groupRepo) CreateGroup(ctx context.Context, group commands.CreateGroup)
 
 // DeleteGroup implements repositories.GroupRepository.
 func (g *groupRepo) DeleteGroup(ctx context.Context, group commands.DeleteGroup) error {
-	sql, args, err := sq.Delete("groups").Where(sq.Eq{"id": group.ID}).PlaceholderFormat(sq.Dollar).ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if _, err = g.db.Exec(ctx, sql, args...); err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	return nil
+	return moveToTrash(ctx, g.db, "groups", sq.Eq{"id": group.ID})
 }
 
 // GetGroupByID implements repositories.GroupRepository.
 func (g *groupRepo) GetGroupByID(ctx context.Context, id int64) (*models.Group, error) {
-	sql, args, err := sq.Select("id", "name").From("groups").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	sql, args, err := sq.Select("id", "name", "version").From("groups").Where(sq.Eq{"id": id, "deleted_at": nil}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	row := g.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = g.db.QueryRow(ctx, sql, args...)
+	}
 
 	result := new(models.Group)
-	if err := row.Scan(&result.ID, &result.Name); err != nil {
+	if err := row.Scan(&result.ID, &result.Name, &result.Version); err != nil {
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
@@ -124,34 +132,42 @@ func (g *groupRepo) GetGroupByID(ctx context.Context, id int64) (*models.Group,
 
 // GetGroups implements repositories.GroupRepository.
 func (g *groupRepo) GetGroups(ctx context.Context, filters query.GetGroupsFilters) ([]models.Group, error) {
-	query := sq.Select("id", "name")
+	query := sq.Select("id", "name", "version")
 	query = query.From("groups")
+	query = query.Where(groupConditions(filters))
 	query = query.PlaceholderFormat(sq.Dollar)
 
-	if len(filters.IDs) != 0 {
-		query = query.Where(sq.Eq{"id": filters.IDs})
-	}
 	if filters.Offset != 0 {
 		query = query.Offset(uint64(filters.Offset))
 	}
 	if filters.Limit != 0 {
 		query = query.Limit(uint64(filters.Limit))
 	}
+	query, err := orderByKeyset(query, groupSortColumns, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := g.db.Query(ctx, sql, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = g.db.Query(ctx, sql, args...)
+	}
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var result []models.Group
 	for rows.Next() {
 		var exam models.Group
-		if err := rows.Scan(&exam.ID, &exam.Name); err != nil {
+		if err := rows.Scan(&exam.ID, &exam.Name, &exam.Version); err != nil {
 			return nil, err
 		}
 
@@ -161,29 +177,47 @@ func (g *groupRepo) GetGroups(ctx context.Context, filters query.GetGroupsFilter
 	return result, nil
 }
 
-// UpdateGroup implements repositories.GroupRepository.
-func (g *groupRepo) UpdateGroup(ctx context.Context, group commands.UpdateGroup) error {
-	query := sq.Update("groups").
-		Set("name", group.Name).
-		Where(sq.Eq{"id": group.ID}).
-		PlaceholderFormat(sq.Dollar)
-
-	sql, args, err := query.ToSql()
+// CountGroups implements repositories.GroupRepository.
+func (g *groupRepo) CountGroups(ctx context.Context, filters query.GetGroupsFilters) (int64, error) {
+	sql, args, err := sq.Select("count(*)").
+		From("groups").
+		Where(groupConditions(filters)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
 	}
 
+	var count int64
+	var row pgx.Row
 	if tx, ok := tools.GetTransaction(ctx); ok {
-		_, err = tx.Exec(ctx, sql, args...)
+		row = tx.QueryRow(ctx, sql, args...)
 	} else {
-		_, err = g.db.Exec(ctx, sql, args...)
+		row = g.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(&count); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	return count, nil
+}
+
+func groupConditions(filters query.GetGroupsFilters) sq.And {
+	conditions := sq.And{sq.Eq{"deleted_at": nil}}
+	if len(filters.IDs) != 0 {
+		conditions = append(conditions, sq.Eq{"id": filters.IDs})
 	}
+	if len(filters.Names) != 0 {
+		conditions = append(conditions, sq.Eq{"name": filters.Names})
+	}
+	return conditions
+}
+
+// UpdateGroup implements repositories.GroupRepository.
+func (g *groupRepo) UpdateGroup(ctx context.Context, group commands.UpdateGroup) error {
+	update := sq.Update("groups").Set("name", group.Name)
 
-	return nil
+	return updateVersion(ctx, g.db, update, "groups", sq.Eq{"id": group.ID, "deleted_at": nil}, group.Version)
 }
 
 func NewGroupRepo(conn *pgxpool.Pool) repositories.GroupRepository {
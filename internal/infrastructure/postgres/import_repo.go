@@ -0,0 +1,976 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type importRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewImportRepo(conn *pgxpool.Pool) repositories.ImportRepository {
+	return &importRepo{
+		db: conn,
+	}
+}
+
+// CreateImportPreview implements repositories.ImportRepository.
+// Records and issues are stored as jsonb, pgx marshals them itself.
+func (this *importRepo) CreateImportPreview(ctx context.Context, preview commands.CreateImportPreview) error {
+	if err := preview.Validate(); err != nil {
+		return err
+	}
+
+	var profileID any
+	if preview.ProfileID != 0 {
+		profileID = preview.ProfileID
+	}
+	records, issues := preview.Records, preview.Issues
+	if records == nil {
+		records = []models.ImportRecord{}
+	}
+	if issues == nil {
+		issues = []models.ImportIssue{}
+	}
+
+	sql, args, err := sq.
+		Insert("import_previews").
+		SetMap(sq.Eq{
+			"token":      preview.Token,
+			"created_by": preview.CreatedBy,
+			"profile_id": profileID,
+			"records":    records,
+			"issues":     issues,
+			"expires_at": preview.ExpiresAt,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// GetImportPreview implements repositories.ImportRepository.
+func (this *importRepo) GetImportPreview(ctx context.Context, token string) (*models.ImportPreview, error) {
+	sql, args, err := sq.Select(
+		"token",
+		"created_by",
+		"profile_id",
+		"records",
+		"issues",
+		"created_at",
+		"expires_at",
+		"confirmed_at",
+	).
+		From("import_previews").
+		Where(sq.Eq{"token": token}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var preview models.ImportPreview
+	if err := row.Scan(
+		&preview.Token,
+		&preview.CreatedBy,
+		&preview.ProfileID,
+		&preview.Records,
+		&preview.Issues,
+		&preview.CreatedAt,
+		&preview.ExpiresAt,
+		&preview.ConfirmedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+		}
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+	}
+
+	return &preview, nil
+}
+
+// ConfirmImportPreview implements repositories.ImportRepository.
+func (this *importRepo) ConfirmImportPreview(ctx context.Context, confirm commands.ConfirmImportPreview) error {
+	sql, args, err := sq.Update("import_previews").
+		Set("confirmed_at", sq.Expr("now()")).
+		Where(sq.Eq{"token": confirm.Token, "confirmed_at": nil}).
+		Where(sq.Expr("expires_at > now()")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var affected int64
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, execErr := tx.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	} else {
+		tag, execErr := this.db.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if affected == 0 {
+		return log.ErrorWrapper(errors.ErrImportPreviewClosed, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// GetImportProfiles implements repositories.ImportRepository.
+func (this *importRepo) GetImportProfiles(ctx context.Context, filters query.GetImportProfilesFilters) ([]models.ImportProfile, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"id",
+		"name",
+		"layout",
+		"sheets",
+		"header_row",
+		"separator",
+		"student_fields",
+		"teacher_fields",
+		"columns",
+		"created_at",
+	).From("import_profiles")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": filters.IDs})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("name")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.ImportProfile
+	for rows.Next() {
+		var profile models.ImportProfile
+		if err := rows.Scan(
+			&profile.ID,
+			&profile.Name,
+			&profile.Layout,
+			&profile.Sheets,
+			&profile.HeaderRow,
+			&profile.Separator,
+			&profile.StudentFields,
+			&profile.TeacherFields,
+			&profile.Columns,
+			&profile.CreatedAt,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// CreateImportProfile implements repositories.ImportRepository.
+func (this *importRepo) CreateImportProfile(ctx context.Context, profile commands.CreateImportProfile) (int64, error) {
+	if err := profile.Validate(); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := sq.
+		Insert("import_profiles").
+		SetMap(importProfileColumns(profile.Name, profile.Layout, profile.Sheets, profile.HeaderRow, profile.Separator, profile.StudentFields, profile.TeacherFields, profile.Columns)).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		if tools.IsUniqueViolation(err) {
+			return 0, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "import profile already exists")
+		}
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateImportProfile implements repositories.ImportRepository.
+func (this *importRepo) UpdateImportProfile(ctx context.Context, profile commands.UpdateImportProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	sql, args, err := sq.Update("import_profiles").
+		SetMap(importProfileColumns(profile.Name, profile.Layout, profile.Sheets, profile.HeaderRow, profile.Separator, profile.StudentFields, profile.TeacherFields, profile.Columns)).
+		Where(sq.Eq{"id": profile.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		if tools.IsUniqueViolation(err) {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "import profile already exists")
+		}
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// DeleteImportProfile implements repositories.ImportRepository.
+// Previews made with the profile keep their records and lose the reference.
+func (this *importRepo) DeleteImportProfile(ctx context.Context, profile commands.DeleteImportProfile) error {
+	sql, args, err := sq.Delete("import_profiles").Where(sq.Eq{"id": profile.ID}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// importProfileColumns keeps the arrays and the map not null, the columns
+// are not null in the table.
+func importProfileColumns(name, layout string, sheets []string, headerRow int64, separator string, studentFields, teacherFields []string, columns map[string]string) sq.Eq {
+	if sheets == nil {
+		sheets = []string{}
+	}
+	if studentFields == nil {
+		studentFields = []string{}
+	}
+	if teacherFields == nil {
+		teacherFields = []string{}
+	}
+	if columns == nil {
+		columns = map[string]string{}
+	}
+
+	return sq.Eq{
+		"name":           name,
+		"layout":         layout,
+		"sheets":         sheets,
+		"header_row":     headerRow,
+		"separator":      separator,
+		"student_fields": studentFields,
+		"teacher_fields": teacherFields,
+		"columns":        columns,
+	}
+}
+
+// CreateImportJob implements repositories.ImportRepository.
+func (this *importRepo) CreateImportJob(ctx context.Context, job commands.CreateImportJob) (int64, error) {
+	if err := job.Validate(); err != nil {
+		return 0, err
+	}
+
+	var profileID, previewToken any
+	if job.ProfileID != 0 {
+		profileID = job.ProfileID
+	}
+	if job.PreviewToken != "" {
+		previewToken = job.PreviewToken
+	}
+	records, issues := job.Records, job.Issues
+	if records == nil {
+		records = []models.ImportRecord{}
+	}
+	if issues == nil {
+		issues = []models.ImportIssue{}
+	}
+	values := sq.Eq{
+		"source":        job.Source,
+		"status":        valueobjects.ImportQueuedStatus,
+		"actor":         job.Actor,
+		"file_name":     job.FileName,
+		"checksum":      job.Checksum,
+		"profile_id":    profileID,
+		"preview_token": previewToken,
+		"records":       records,
+		"issues":        issues,
+		"total":         len(records),
+	}
+	// the request creating a bulk job runs it
+	if job.Source == valueobjects.ImportBulkSource {
+		values["status"] = valueobjects.ImportRunningStatus
+		values["total"] = job.Total
+		values["attempts"] = 1
+		values["started_at"] = sq.Expr("now()")
+	}
+
+	sql, args, err := sq.
+		Insert("import_jobs").
+		SetMap(values).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// GetImportJobs implements repositories.ImportRepository.
+// The newest jobs go first.
+func (this *importRepo) GetImportJobs(ctx context.Context, filters query.GetImportJobsFilters) ([]models.ImportJob, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	records := "'[]'::jsonb"
+	if filters.WithRecords {
+		records = "records"
+	}
+	query := sq.Select(
+		"id",
+		"source",
+		"status",
+		"actor",
+		"file_name",
+		"checksum",
+		"profile_id",
+		"preview_token",
+		records,
+		"issues",
+		"errors",
+		"total",
+		"processed",
+		"created",
+		"existing",
+		"failed",
+		"attempts",
+		"created_at",
+		"updated_at",
+		"started_at",
+		"finished_at",
+		"rolled_back_at",
+	).From("import_jobs")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": filters.IDs})
+	}
+	if len(filters.Statuses) > 0 {
+		query = query.Where(sq.Eq{"status": filters.Statuses})
+	}
+	if len(filters.Actors) > 0 {
+		query = query.Where(sq.Eq{"actor": filters.Actors})
+	}
+	if len(filters.Checksums) > 0 {
+		query = query.Where(sq.Eq{"checksum": filters.Checksums})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("id DESC")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.ImportJob
+	for rows.Next() {
+		var job models.ImportJob
+		if err := rows.Scan(
+			&job.ID,
+			&job.Source,
+			&job.Status,
+			&job.Actor,
+			&job.FileName,
+			&job.Checksum,
+			&job.ProfileID,
+			&job.PreviewToken,
+			&job.Records,
+			&job.Issues,
+			&job.Errors,
+			&job.Total,
+			&job.Processed,
+			&job.Created,
+			&job.Existing,
+			&job.Failed,
+			&job.Attempts,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+			&job.StartedAt,
+			&job.FinishedAt,
+			&job.RolledBackAt,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// ClaimImportJob implements repositories.ImportRepository.
+// SKIP LOCKED lets several workers claim jobs at once without taking the
+// same one. A claimed job starts over with zeroed counters.
+func (this *importRepo) ClaimImportJob(ctx context.Context, lease time.Duration) (int64, error) {
+	claimable := sq.Select("id").
+		From("import_jobs").
+		Where(sq.Or{
+			sq.Eq{"status": valueobjects.ImportQueuedStatus},
+			sq.And{
+				sq.Eq{"status": valueobjects.ImportRunningStatus},
+				sq.Expr("updated_at < now() - make_interval(secs => ?)", lease.Seconds()),
+			},
+		}).
+		OrderBy("id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, err := sq.Update("import_jobs").
+		Set("status", valueobjects.ImportRunningStatus).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("processed", 0).
+		Set("created", 0).
+		Set("existing", 0).
+		Set("failed", 0).
+		Set("errors", sq.Expr("'[]'::jsonb")).
+		Set("started_at", sq.Expr("now()")).
+		Set("updated_at", sq.Expr("now()")).
+		Set("finished_at", nil).
+		Where(sq.Expr("id = (?)", claimable)).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+		}
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateImportJob implements repositories.ImportRepository.
+func (this *importRepo) UpdateImportJob(ctx context.Context, job commands.UpdateImportJob) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	jobErrors := job.Errors
+	if jobErrors == nil {
+		jobErrors = []models.ImportIssue{}
+	}
+
+	query := sq.Update("import_jobs").
+		Set("status", job.Status).
+		Set("processed", job.Processed).
+		Set("created", job.Created).
+		Set("existing", job.Existing).
+		Set("failed", job.Failed).
+		Set("errors", jobErrors).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": job.ID}).
+		PlaceholderFormat(sq.Dollar)
+	if job.Status == valueobjects.ImportCompletedStatus || job.Status == valueobjects.ImportFailedStatus {
+		query = query.Set("finished_at", sq.Expr("now()"))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// RenewImportJob implements repositories.ImportRepository.
+func (this *importRepo) RenewImportJob(ctx context.Context, job commands.RenewImportJob) error {
+	sql, args, err := sq.Update("import_jobs").
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": job.ID, "status": valueobjects.ImportRunningStatus}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// RequeueImportJob implements repositories.ImportRepository.
+func (this *importRepo) RequeueImportJob(ctx context.Context, job commands.RequeueImportJob) error {
+	sql, args, err := sq.Update("import_jobs").
+		Set("status", valueobjects.ImportQueuedStatus).
+		Set("updated_at", sq.Expr("now()")).
+		Set("rolled_back_at", nil).
+		Where(sq.Eq{"id": job.ID}).
+		Where(sq.NotEq{"status": []string{valueobjects.ImportQueuedStatus, valueobjects.ImportRunningStatus}}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var affected int64
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, execErr := tx.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	} else {
+		tag, execErr := this.db.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if affected == 0 {
+		return log.ErrorWrapper(errors.ErrImportJobActive, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// AddImportJobEntities implements repositories.ImportRepository.
+func (this *importRepo) AddImportJobEntities(ctx context.Context, add commands.AddImportJobEntities) error {
+	if err := add.Validate(); err != nil {
+		return err
+	}
+	if len(add.Entities) == 0 {
+		return nil
+	}
+
+	query := sq.Insert("import_job_entities").
+		Columns("job_id", "entity", "entity_id").
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+	for _, entity := range add.Entities {
+		query = query.Values(add.JobID, entity.Entity, entity.EntityID)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// GetImportJobEntities implements repositories.ImportRepository.
+func (this *importRepo) GetImportJobEntities(ctx context.Context, jobID int64) ([]models.ImportJobEntity, error) {
+	sql, args, err := sq.Select("job_id", "entity", "entity_id", "created_at").
+		From("import_job_entities").
+		Where(sq.Eq{"job_id": jobID}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.ImportJobEntity
+	for rows.Next() {
+		var entity models.ImportJobEntity
+		if err := rows.Scan(&entity.JobID, &entity.Entity, &entity.EntityID, &entity.CreatedAt); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, entity)
+	}
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+type importEntityTable struct {
+	table string
+	key   string
+	// conditions by the name the rollback report shows, each takes the key
+	// of the entity as its only argument
+	references []importEntityReference
+}
+
+type importEntityReference struct {
+	name      string
+	condition string
+}
+
+// importEntityTables lists what keeps an imported entity in place. Debts
+// are also kept once they got a room, results, requests or bookings, the
+// cascades would delete those with the debt otherwise.
+var importEntityTables = map[string]importEntityTable{
+	valueobjects.ImportDebtEntity: {
+		table: "debts",
+		key:   "id",
+		references: []importEntityReference{
+			{"rooms", "exists (select 1 from debts where id = ? and room_id is not null)"},
+			{"debt_results", "exists (select 1 from debt_results where debt_id = ?)"},
+			{"retake_requests", "exists (select 1 from retake_requests where debt_id = ?)"},
+			{"retake_bookings", "exists (select 1 from retake_bookings where debt_id = ?)"},
+			{"timetable_draft_items", "exists (select 1 from timetable_draft_items where debt_id = ?)"},
+		},
+	},
+	valueobjects.ImportStudentEntity: {
+		table: "students",
+		key:   "uuid",
+		references: []importEntityReference{
+			{"debts", "exists (select 1 from debts where student_uuid = ?)"},
+			{"retake_requests", "exists (select 1 from retake_requests where student_uuid = ?)"},
+			{"retake_bookings", "exists (select 1 from retake_bookings where student_uuid = ?)"},
+		},
+	},
+	valueobjects.ImportTeacherEntity: {
+		table: "teachers",
+		key:   "uuid",
+		references: []importEntityReference{
+			{"debts", "exists (select 1 from debts where teacher_uuid = ?)"},
+			{"debt_results", "exists (select 1 from debt_results where teacher_uuid = ?)"},
+			{"retake_requests", "exists (select 1 from retake_requests where teacher_uuid = ?)"},
+			{"retake_sessions", "exists (select 1 from retake_sessions where teacher_uuid = ?)"},
+			{"teacher_availability", "exists (select 1 from teacher_availability where teacher_uuid = ?)"},
+		},
+	},
+	valueobjects.ImportExamEntity: {
+		table: "exams",
+		key:   "id",
+		references: []importEntityReference{
+			{"debts", "exists (select 1 from debts where exam_id = ?)"},
+			{"retake_sessions", "exists (select 1 from retake_sessions where exam_id = ?)"},
+		},
+	},
+	valueobjects.ImportGroupEntity: {
+		table: "groups",
+		key:   "id",
+		references: []importEntityReference{
+			{"students", "exists (select 1 from students where group_id = ?)"},
+		},
+	},
+}
+
+// RollbackImportJobEntity implements repositories.ImportRepository.
+// The entity row is locked first, so nothing can start referencing it
+// between the check and the delete. Run it in a transaction.
+func (this *importRepo) RollbackImportJobEntity(ctx context.Context, rollback commands.RollbackImportJobEntity) ([]string, error) {
+	if err := rollback.Validate(); err != nil {
+		return nil, err
+	}
+
+	table := importEntityTables[rollback.Entity]
+	var key any = rollback.EntityID
+	if table.key == "id" {
+		id, err := strconv.ParseInt(rollback.EntityID, 10, 64)
+		if err != nil {
+			return nil, log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, err.Error())
+		}
+		key = id
+	}
+
+	sql, args, err := sq.Select(table.key).
+		From(table.table).
+		Where(sq.Eq{table.key: key}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var locked any
+	if err := row.Scan(&locked); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, this.untrackImportJobEntity(ctx, rollback)
+		}
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	check := sq.Select().PlaceholderFormat(sq.Dollar)
+	found := make([]bool, len(table.references))
+	dest := make([]any, len(table.references))
+	for i, reference := range table.references {
+		check = check.Column(sq.Expr(reference.condition, key))
+		dest[i] = &found[i]
+	}
+	sql, args, err = check.ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(dest...); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var references []string
+	for i, reference := range table.references {
+		if found[i] {
+			references = append(references, reference.name)
+		}
+	}
+	if len(references) > 0 {
+		return references, nil
+	}
+
+	sql, args, err = sq.Delete(table.table).Where(sq.Eq{table.key: key}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil, this.untrackImportJobEntity(ctx, rollback)
+}
+
+func (this *importRepo) untrackImportJobEntity(ctx context.Context, rollback commands.RollbackImportJobEntity) error {
+	sql, args, err := sq.Delete("import_job_entities").
+		Where(sq.Eq{"entity": rollback.Entity, "entity_id": rollback.EntityID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// FinishImportJobRollback implements repositories.ImportRepository.
+func (this *importRepo) FinishImportJobRollback(ctx context.Context, finish commands.FinishImportJobRollback) error {
+	sql, args, err := sq.Update("import_jobs").
+		Set("rolled_back_at", sq.Expr("now()")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": finish.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
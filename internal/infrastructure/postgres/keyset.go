@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	sq "github.com/Masterminds/squirrel"
+
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// sortColumn is what a sort field of a listing orders by. Cursor values are
+// passed as text and cast to typ, expr never is null so that the rows
+// compare the way they are ordered.
+type sortColumn struct {
+	expr string
+	typ  string
+}
+
+var (
+	examSortColumns = map[string]sortColumn{
+		"id":              {expr: "id", typ: "bigint"},
+		"name":            {expr: "COALESCE(name, '')", typ: "text"},
+		"assessment_type": {expr: "COALESCE(assessment_type, '')", typ: "text"},
+	}
+	groupSortColumns = map[string]sortColumn{
+		"id":   {expr: "id", typ: "bigint"},
+		"name": {expr: "COALESCE(name, '')", typ: "text"},
+	}
+	studentSortColumns = map[string]sortColumn{
+		"uuid":        {expr: "s.uuid", typ: "text"},
+		"last_name":   {expr: "COALESCE(s.last_name, '')", typ: "text"},
+		"first_name":  {expr: "COALESCE(s.first_name, '')", typ: "text"},
+		"middle_name": {expr: "COALESCE(s.middle_name, '')", typ: "text"},
+		"email":       {expr: "COALESCE(s.email, '')", typ: "text"},
+		"group":       {expr: "COALESCE(g.name, '')", typ: "text"},
+	}
+	teacherSortColumns = map[string]sortColumn{
+		"uuid":        {expr: "uuid", typ: "text"},
+		"last_name":   {expr: "COALESCE(last_name, '')", typ: "text"},
+		"first_name":  {expr: "COALESCE(first_name, '')", typ: "text"},
+		"middle_name": {expr: "COALESCE(middle_name, '')", typ: "text"},
+		"email":       {expr: "COALESCE(email, '')", typ: "text"},
+	}
+	debtSortColumns = map[string]sortColumn{
+		"id":        {expr: "d.id", typ: "bigint"},
+		"date":      {expr: "COALESCE(d.date, 'infinity')", typ: "timestamp"},
+		"closed_at": {expr: "COALESCE(d.closed_at, 'infinity')", typ: "timestamptz"},
+		"exam":      {expr: "COALESCE(e.name, '')", typ: "text"},
+		"student":   {expr: "COALESCE(s.last_name, '')", typ: "text"},
+		"teacher":   {expr: "COALESCE(t.last_name, '')", typ: "text"},
+		"group":     {expr: "COALESCE(g.name, '')", typ: "text"},
+	}
+)
+
+// orderByKeyset orders the query by the sort of the keyset and keeps the
+// rows after its cursor: for sort fields a, b and values x, y that is
+// a > x OR (a = x AND b > y), with < for descending fields.
+func orderByKeyset(builder sq.SelectBuilder, columns map[string]sortColumn, keyset query.Keyset) (sq.SelectBuilder, error) {
+	if err := keyset.Validate(); err != nil {
+		return builder, err
+	}
+
+	after := sq.Or{}
+	for i, sort := range keyset.Sort {
+		column, ok := columns[sort.Field]
+		if !ok {
+			return builder, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_INFRASTRUCTURE, "", "sort", sort.Field)
+		}
+
+		direction, operator := "ASC", ">"
+		if sort.Desc {
+			direction, operator = "DESC", "<"
+		}
+		builder = builder.OrderBy(column.expr + " " + direction)
+
+		if len(keyset.After) == 0 {
+			continue
+		}
+		step := sq.And{}
+		for j, previous := range keyset.Sort[:i] {
+			step = append(step, columns[previous.Field].compare("=", keyset.After[j]))
+		}
+		after = append(after, append(step, column.compare(operator, keyset.After[i])))
+	}
+	if len(after) != 0 {
+		builder = builder.Where(after)
+	}
+
+	return builder, nil
+}
+
+func (this sortColumn) compare(operator, value string) sq.Sqlizer {
+	return sq.Expr(this.expr+" "+operator+" CAST(CAST(? AS text) AS "+this.typ+")", value)
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	e "errors"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/VanLavr/Diploma-fin/migrations"
+	"github.com/VanLavr/Diploma-fin/utils/config"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// Commands of Migrate, named as the goose commands they replace.
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+	MigrateRedo   = "redo"
+)
+
+// newMigrationProvider returns the provider of the embedded migrations.
+// Every command holds a postgres advisory lock, so replicas migrating on
+// start wait for each other instead of applying the same migration twice.
+func newMigrationProvider(db *sql.DB) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return provider, nil
+}
+
+// Migrate runs a command against the migrations embedded in the binary.
+func Migrate(ctx context.Context, cfg *config.Config, command string) error {
+	db, err := sql.Open("pgx", cfg.DbString)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	defer db.Close()
+
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		return err
+	}
+
+	var results []*goose.MigrationResult
+	switch command {
+	case MigrateUp:
+		results, err = provider.Up(ctx)
+	case MigrateDown:
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+	case MigrateRedo:
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+		if err == nil {
+			// another replica may have applied it again in between
+			if result, err = provider.UpByOne(ctx); result != nil {
+				results = append(results, result)
+			}
+			if e.Is(err, goose.ErrNoNextVersion) {
+				err = nil
+			}
+		}
+	case MigrateStatus:
+		var statuses []*goose.MigrationStatus
+		statuses, err = provider.Status(ctx)
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			log.Logger.Info(status.Source.Path, "version", status.Source.Version, "applied_at", appliedAt)
+		}
+	default:
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "unknown migrate command "+command)
+	}
+
+	for _, result := range results {
+		log.Logger.Info(result.Source.Path, "direction", result.Direction, "duration", result.Duration.String())
+	}
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "migrate "+command)
+	}
+
+	return nil
+}
@@ -0,0 +1,416 @@
+package postgres
+
+import (
+	"context"
+	e "errors"
+	"maps"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type personalDataRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPersonalDataRepo(conn *pgxpool.Pool) repositories.PersonalDataRepository {
+	return &personalDataRepo{
+		db: conn,
+	}
+}
+
+// GetStudentStatus implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetStudentStatus(ctx context.Context, uuid string) (*models.StudentStatus, error) {
+	sql, args, err := sq.Select("uuid", "status", "left_at", "anonymized_at", "deleted_at").
+		From("students").
+		Where(sq.Eq{"uuid": uuid}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var result models.StudentStatus
+	switch err := row.Scan(&result.UUID, &result.Status, &result.LeftAt, &result.AnonymizedAt, &result.DeletedAt); {
+	case err == nil:
+	case e.Is(err, pgx.ErrNoRows):
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", uuid)
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return &result, nil
+}
+
+// GetStudentPersonalData implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetStudentPersonalData(ctx context.Context, uuid string) (*models.Student, error) {
+	sql, args, err := sq.Select(
+		"s.uuid",
+		"s.first_name",
+		"s.last_name",
+		"s.middle_name",
+		"s.group_id",
+		"s.email",
+		"s.password",
+		"g.name",
+		"s.version",
+	).
+		From("students s").
+		LeftJoin("groups g ON s.group_id = g.id").
+		Where(sq.Eq{"s.uuid": uuid}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	result := models.Student{Group: &models.Group{}}
+	switch err := row.Scan(
+		&result.UUID,
+		&result.FirstName,
+		&result.LastName,
+		&result.MiddleName,
+		&result.Group.ID,
+		&result.Email,
+		&result.Password,
+		&result.Group.Name,
+		&result.Version,
+	); {
+	case err == nil:
+	case e.Is(err, pgx.ErrNoRows):
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", uuid)
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return &result, nil
+}
+
+// SetStudentStatus implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) SetStudentStatus(ctx context.Context, status commands.SetStudentStatus) error {
+	if err := status.Validate(); err != nil {
+		return err
+	}
+
+	var leftAt any
+	if !status.LeftAt.IsZero() {
+		leftAt = status.LeftAt
+	}
+	sql, args, err := sq.Update("students").
+		Set("status", status.Status).
+		Set("left_at", leftAt).
+		Set("version", sq.Expr("version + 1")).
+		Where(sq.Eq{"uuid": status.UUID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var tag pgconn.CommandTag
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if tag.RowsAffected() == 0 {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "uuid", status.UUID)
+	}
+
+	return nil
+}
+
+// AnonymizeStudents implements repositories.PersonalDataRepository. The
+// students are anonymized in the import previews and jobs in the same
+// transaction, the old emails come from the locked rows.
+func (this *personalDataRepo) AnonymizeStudents(ctx context.Context, anonymize commands.AnonymizeStudents) ([]string, error) {
+	if err := anonymize.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		var err error
+		if tx, err = this.db.Begin(ctx); err != nil {
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		defer tx.Rollback(ctx)
+	}
+
+	conditions := sq.And{sq.Eq{"anonymized_at": nil}}
+	if len(anonymize.UUIDs) != 0 {
+		conditions = append(conditions, sq.Eq{"uuid": anonymize.UUIDs})
+	} else {
+		conditions = append(conditions,
+			sq.NotEq{"status": valueobjects.StudentActiveStatus},
+			sq.Lt{"left_at": anonymize.LeftBefore},
+		)
+	}
+	old := sq.Select("uuid AS old_uuid", "email AS old_email").
+		From("students").
+		Where(conditions).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := sq.Update("students").
+		Set("first_name", "").
+		Set("last_name", "").
+		Set("middle_name", "").
+		Set("email", sq.Expr("? || uuid", valueobjects.AnonymizedEmailPrefix)).
+		Set("password", "").
+		Set("anonymized_at", sq.Expr("now()")).
+		Set("version", sq.Expr("version + 1")).
+		FromSelect(old, "old").
+		Where("uuid = old.old_uuid").
+		Suffix("RETURNING uuid, coalesce(old.old_email, ''), email").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var uuids []string
+	emails := make(map[string]string)
+	for rows.Next() {
+		var uuid, email, anonymized string
+		if err := rows.Scan(&uuid, &email, &anonymized); err != nil {
+			rows.Close()
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		uuids = append(uuids, uuid)
+		if email != "" {
+			emails[email] = anonymized
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if len(emails) != 0 {
+		if err := anonymizeImports(ctx, tx, emails); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ok {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	return uuids, nil
+}
+
+// anonymizeImports anonymizes the students of emails in the records,
+// issues and errors of the import previews and jobs that mention them.
+func anonymizeImports(ctx context.Context, tx pgx.Tx, emails map[string]string) error {
+	mentioned := slices.Collect(maps.Keys(emails))
+
+	sql, args, err := sq.Select("token", "records", "issues").
+		From("import_previews").
+		Where("EXISTS (SELECT 1 FROM unnest(?::text[]) m WHERE strpos(records::text || issues::text, m) > 0)", mentioned).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	previews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ImportPreview, error) {
+		var preview models.ImportPreview
+		err := row.Scan(&preview.Token, &preview.Records, &preview.Issues)
+		return preview, err
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+	}
+	for _, preview := range previews {
+		anonymizeImport(preview.Records, emails, preview.Issues)
+		if _, err := tx.Exec(ctx,
+			"UPDATE import_previews SET records = $1, issues = $2 WHERE token = $3",
+			preview.Records, preview.Issues, preview.Token,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "", "preview", preview.Token)
+		}
+	}
+
+	sql, args, err = sq.Select("id", "records", "issues", "errors").
+		From("import_jobs").
+		Where("EXISTS (SELECT 1 FROM unnest(?::text[]) m WHERE strpos(records::text || issues::text || errors::text, m) > 0)", mentioned).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+	rows, err = tx.Query(ctx, sql, args...)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ImportJob, error) {
+		var job models.ImportJob
+		err := row.Scan(&job.ID, &job.Records, &job.Issues, &job.Errors)
+		return job, err
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+	}
+	for _, job := range jobs {
+		anonymizeImport(job.Records, emails, job.Issues, job.Errors)
+		if _, err := tx.Exec(ctx,
+			"UPDATE import_jobs SET records = $1, issues = $2, errors = $3 WHERE id = $4",
+			job.Records, job.Issues, job.Errors, job.ID,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "", "job", job.ID)
+		}
+	}
+
+	return nil
+}
+
+func anonymizeImport(records []models.ImportRecord, emails map[string]string, issues ...[]models.ImportIssue) {
+	for i := range records {
+		records[i].Anonymize(emails)
+	}
+	for _, issues := range issues {
+		for i := range issues {
+			issues[i].Anonymize(emails)
+		}
+	}
+}
+
+// CreatePersonalDataAudit implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) CreatePersonalDataAudit(ctx context.Context, audit commands.CreatePersonalDataAudit) (int64, error) {
+	if err := audit.Validate(); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := sq.Insert("personal_data_audit").
+		SetMap(sq.Eq{
+			"student_uuid": audit.StudentUUID,
+			"action":       audit.Action,
+			"actor_uuid":   audit.ActorUUID,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// GetPersonalDataAudit implements repositories.PersonalDataRepository.
+func (this *personalDataRepo) GetPersonalDataAudit(ctx context.Context, filters query.GetPersonalDataAuditFilters) ([]models.PersonalDataAudit, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	conditions := sq.And{}
+	if len(filters.StudentUUIDs) != 0 {
+		conditions = append(conditions, sq.Eq{"student_uuid": filters.StudentUUIDs})
+	}
+	if len(filters.Actions) != 0 {
+		conditions = append(conditions, sq.Eq{"action": filters.Actions})
+	}
+
+	sql, args, err := sq.Select("id", "student_uuid", "action", "actor_uuid", "created_at").
+		From("personal_data_audit").
+		Where(conditions).
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.PersonalDataAudit
+	for rows.Next() {
+		var audit models.PersonalDataAudit
+		if err := rows.Scan(&audit.ID, &audit.StudentUUID, &audit.Action, &audit.ActorUUID, &audit.CreatedAt); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		result = append(result, audit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return result, nil
+}
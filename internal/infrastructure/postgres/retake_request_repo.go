@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type retakeRequestRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRetakeRequestRepo(conn *pgxpool.Pool) repositories.RetakeRequestRepository {
+	return &retakeRequestRepo{
+		db: conn,
+	}
+}
+
+// GetRetakeRequests implements repositories.RetakeRequestRepository.
+func (this *retakeRequestRepo) GetRetakeRequests(ctx context.Context, filters query.GetRetakeRequestsFilters) ([]models.RetakeRequest, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"r.id",
+		"r.status",
+		"r.created_at",
+		"r.updated_at",
+		"d.id",
+		"d.date",
+		"COALESCE(d.address, '')",
+		"e.id",
+		"e.name",
+		"s.uuid",
+		"s.first_name",
+		"s.last_name",
+		"s.middle_name",
+		"s.email",
+		"g.id",
+		"g.name",
+		"COALESCE(t.uuid, '')",
+		"COALESCE(t.first_name, '')",
+		"COALESCE(t.last_name, '')",
+		"COALESCE(t.middle_name, '')",
+		"COALESCE(t.email, '')",
+	)
+	query = query.From("retake_requests r")
+	query = query.Join("debts d ON r.debt_id = d.id")
+	query = query.LeftJoin("exams e ON d.exam_id = e.id")
+	query = query.LeftJoin("students s ON r.student_uuid = s.uuid")
+	query = query.LeftJoin("groups g ON s.group_id = g.id")
+	query = query.LeftJoin("teachers t ON r.teacher_uuid = t.uuid")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"r.id": filters.IDs})
+	}
+	if len(filters.DebtIDs) > 0 {
+		query = query.Where(sq.Eq{"r.debt_id": filters.DebtIDs})
+	}
+	if len(filters.StudentUUIDs) > 0 {
+		query = query.Where(sq.Eq{"r.student_uuid": filters.StudentUUIDs})
+	}
+	if len(filters.TeacherUUIDs) > 0 {
+		query = query.Where(sq.Eq{"r.teacher_uuid": filters.TeacherUUIDs})
+	}
+	if len(filters.ExamIDs) > 0 {
+		query = query.Where(sq.Eq{"d.exam_id": filters.ExamIDs})
+	}
+	if len(filters.Statuses) > 0 {
+		query = query.Where(sq.Eq{"r.status": filters.Statuses})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("r.created_at DESC", "r.id DESC")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.RetakeRequest
+	for rows.Next() {
+		request := models.RetakeRequest{
+			Debt: &models.Debt{
+				Exam: &models.Exam{},
+				Student: &models.Student{
+					Group: &models.Group{},
+				},
+				Teacher: &models.Teacher{},
+			},
+		}
+		if err := rows.Scan(
+			&request.ID,
+			&request.Status,
+			&request.CreatedAt,
+			&request.UpdatedAt,
+			&request.Debt.ID,
+			&request.Debt.Date,
+			&request.Debt.Address,
+			&request.Debt.Exam.ID,
+			&request.Debt.Exam.Name,
+			&request.Debt.Student.UUID,
+			&request.Debt.Student.FirstName,
+			&request.Debt.Student.LastName,
+			&request.Debt.Student.MiddleName,
+			&request.Debt.Student.Email,
+			&request.Debt.Student.Group.ID,
+			&request.Debt.Student.Group.Name,
+			&request.Debt.Teacher.UUID,
+			&request.Debt.Teacher.FirstName,
+			&request.Debt.Teacher.LastName,
+			&request.Debt.Teacher.MiddleName,
+			&request.Debt.Teacher.Email,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+	rows.Close()
+
+	if err := this.attachPreferredSlots(ctx, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (this *retakeRequestRepo) attachPreferredSlots(ctx context.Context, requests []models.RetakeRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(requests))
+	byID := make(map[int64]*models.RetakeRequest, len(requests))
+	for i := range requests {
+		ids[i] = requests[i].ID
+		byID[requests[i].ID] = &requests[i]
+	}
+
+	sql, args, err := sq.Select("request_id", "starts_at", "ends_at").
+		From("retake_request_slots").
+		Where(sq.Eq{"request_id": ids}).
+		OrderBy("starts_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			requestID int64
+			slot      models.TimeSlot
+		)
+		if err := rows.Scan(&requestID, &slot.Start, &slot.End); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		request := byID[requestID]
+		request.PreferredSlots = append(request.PreferredSlots, slot)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return nil
+}
+
+// CreateRetakeRequest implements repositories.RetakeRequestRepository.
+func (this *retakeRequestRepo) CreateRetakeRequest(ctx context.Context, request commands.CreateRetakeRequest) (int64, error) {
+	if err := request.Validate(); err != nil {
+		return 0, err
+	}
+
+	values := sq.Eq{
+		"debt_id":      request.DebtID,
+		"student_uuid": request.StudentUUID,
+		"status":       valueobjects.RetakeRequestPending,
+	}
+	if request.TeacherUUID != "" {
+		values["teacher_uuid"] = request.TeacherUUID
+	}
+
+	sql, args, err := sq.
+		Insert("retake_requests").
+		SetMap(values).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		if tools.IsUniqueViolation(err) {
+			return 0, log.ErrorWrapper(errors.ErrRetakeRequestAlreadyExists, errors.ERR_INFRASTRUCTURE, "")
+		}
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if len(request.PreferredSlots) == 0 {
+		return id, nil
+	}
+
+	slots := sq.Insert("retake_request_slots").Columns("request_id", "starts_at", "ends_at")
+	for _, slot := range request.PreferredSlots {
+		slots = slots.Values(id, slot.Start, slot.End)
+	}
+
+	sql, args, err = slots.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateRetakeRequestStatus implements repositories.RetakeRequestRepository.
+func (this *retakeRequestRepo) UpdateRetakeRequestStatus(ctx context.Context, update commands.UpdateRetakeRequestStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	query := sq.Update("retake_requests").
+		Set("status", update.Status).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"status": valueobjects.OpenRetakeRequestStatuses}).
+		PlaceholderFormat(sq.Dollar)
+
+	if len(update.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": update.IDs})
+	}
+	if len(update.DebtIDs) > 0 {
+		query = query.Where(sq.Eq{"debt_id": update.DebtIDs})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
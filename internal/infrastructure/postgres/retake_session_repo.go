@@ -0,0 +1,420 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type retakeSessionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRetakeSessionRepo(conn *pgxpool.Pool) repositories.RetakeSessionRepository {
+	return &retakeSessionRepo{
+		db: conn,
+	}
+}
+
+// CreateRetakeSession implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) CreateRetakeSession(ctx context.Context, session commands.CreateRetakeSession) (int64, error) {
+	if err := session.Validate(); err != nil {
+		return 0, err
+	}
+
+	var roomID any = session.RoomID
+	if session.RoomID == 0 {
+		roomID = nil
+	}
+
+	sql, args, err := sq.
+		Insert("retake_sessions").
+		SetMap(sq.Eq{
+			"exam_id":         session.ExamID,
+			"teacher_uuid":    session.TeacherUUID,
+			"date":            session.Date,
+			"address":         session.Address,
+			"room_id":         roomID,
+			"capacity":        session.Capacity,
+			"signup_deadline": session.SignupDeadline,
+			"cancel_deadline": session.CancelDeadline,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// GetRetakeSessions implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) GetRetakeSessions(ctx context.Context, filters query.GetRetakeSessionsFilters) ([]models.RetakeSession, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"rs.id",
+		"rs.date",
+		"rs.address",
+		"rs.capacity",
+		"rs.signup_deadline",
+		"rs.cancel_deadline",
+		"rs.created_at",
+		"e.id",
+		"e.name",
+		"e.assessment_type",
+		"t.uuid",
+		"t.first_name",
+		"t.last_name",
+		"t.middle_name",
+		"t.email",
+		"(SELECT count(*) FROM retake_bookings b WHERE b.session_id = rs.id AND b.status = 'booked')",
+		"(SELECT count(*) FROM retake_bookings b WHERE b.session_id = rs.id AND b.status = 'waitlisted')",
+		"COALESCE(r.id, 0)",
+		"COALESCE(r.building, '')",
+		"COALESCE(r.number, '')",
+		"COALESCE(r.capacity, 0)",
+		"COALESCE(r.accessible, false)",
+	)
+	query = query.From("retake_sessions rs")
+	query = query.LeftJoin("rooms r ON rs.room_id = r.id")
+	query = query.Join("exams e ON rs.exam_id = e.id")
+	query = query.Join("teachers t ON rs.teacher_uuid = t.uuid")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"rs.id": filters.IDs})
+	}
+	if len(filters.ExamIDs) > 0 {
+		query = query.Where(sq.Eq{"rs.exam_id": filters.ExamIDs})
+	}
+	if len(filters.TeacherUUIDs) > 0 {
+		query = query.Where(sq.Eq{"rs.teacher_uuid": filters.TeacherUUIDs})
+	}
+	if filters.OnlyOpen {
+		query = query.Where("rs.signup_deadline > (now() AT TIME ZONE 'UTC')")
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("rs.date", "rs.id")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.RetakeSession
+	for rows.Next() {
+		session := models.RetakeSession{
+			Exam:    &models.Exam{},
+			Teacher: &models.Teacher{},
+			Room:    &models.Room{},
+		}
+		if err := rows.Scan(
+			&session.ID,
+			&session.Date,
+			&session.Address,
+			&session.Capacity,
+			&session.SignupDeadline,
+			&session.CancelDeadline,
+			&session.CreatedAt,
+			&session.Exam.ID,
+			&session.Exam.Name,
+			&session.Exam.AssessmentType,
+			&session.Teacher.UUID,
+			&session.Teacher.FirstName,
+			&session.Teacher.LastName,
+			&session.Teacher.MiddleName,
+			&session.Teacher.Email,
+			&session.BookedCount,
+			&session.WaitlistedCount,
+			&session.Room.ID,
+			&session.Room.Building,
+			&session.Room.Number,
+			&session.Room.Capacity,
+			&session.Room.Accessible,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		if session.Room.ID == 0 {
+			session.Room = nil
+		}
+
+		result = append(result, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// LockRetakeSession implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) LockRetakeSession(ctx context.Context, id int64) error {
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "session can be locked inside a transaction only")
+	}
+
+	sql, args, err := sq.Select("id").
+		From("retake_sessions").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var locked int64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&locked); err != nil {
+		if err == pgx.ErrNoRows {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+		}
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// GetRetakeBookings implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) GetRetakeBookings(ctx context.Context, filters query.GetRetakeBookingsFilters) ([]models.RetakeBooking, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"b.id",
+		"b.session_id",
+		"b.status",
+		"b.created_at",
+		"b.updated_at",
+		"d.id",
+		"e.id",
+		"e.name",
+		"s.uuid",
+		"s.first_name",
+		"s.last_name",
+		"s.middle_name",
+		"s.email",
+		"g.id",
+		"g.name",
+		"t.uuid",
+		"t.first_name",
+		"t.last_name",
+		"t.middle_name",
+		"t.email",
+	)
+	query = query.From("retake_bookings b")
+	query = query.Join("debts d ON b.debt_id = d.id")
+	query = query.LeftJoin("exams e ON d.exam_id = e.id")
+	query = query.LeftJoin("students s ON b.student_uuid = s.uuid")
+	query = query.LeftJoin("groups g ON s.group_id = g.id")
+	query = query.LeftJoin("teachers t ON d.teacher_uuid = t.uuid")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"b.id": filters.IDs})
+	}
+	if len(filters.SessionIDs) > 0 {
+		query = query.Where(sq.Eq{"b.session_id": filters.SessionIDs})
+	}
+	if len(filters.DebtIDs) > 0 {
+		query = query.Where(sq.Eq{"b.debt_id": filters.DebtIDs})
+	}
+	if len(filters.StudentUUIDs) > 0 {
+		query = query.Where(sq.Eq{"b.student_uuid": filters.StudentUUIDs})
+	}
+	if len(filters.Statuses) > 0 {
+		query = query.Where(sq.Eq{"b.status": filters.Statuses})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	query = query.OrderBy("b.created_at", "b.id")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.RetakeBooking
+	for rows.Next() {
+		booking := models.RetakeBooking{
+			Debt: &models.Debt{
+				Exam: &models.Exam{},
+				Student: &models.Student{
+					Group: &models.Group{},
+				},
+				Teacher: &models.Teacher{},
+			},
+		}
+		if err := rows.Scan(
+			&booking.ID,
+			&booking.SessionID,
+			&booking.Status,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+			&booking.Debt.ID,
+			&booking.Debt.Exam.ID,
+			&booking.Debt.Exam.Name,
+			&booking.Debt.Student.UUID,
+			&booking.Debt.Student.FirstName,
+			&booking.Debt.Student.LastName,
+			&booking.Debt.Student.MiddleName,
+			&booking.Debt.Student.Email,
+			&booking.Debt.Student.Group.ID,
+			&booking.Debt.Student.Group.Name,
+			&booking.Debt.Teacher.UUID,
+			&booking.Debt.Teacher.FirstName,
+			&booking.Debt.Teacher.LastName,
+			&booking.Debt.Teacher.MiddleName,
+			&booking.Debt.Teacher.Email,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, booking)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// CreateRetakeBooking implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) CreateRetakeBooking(ctx context.Context, booking commands.CreateRetakeBooking) (int64, error) {
+	if err := booking.Validate(); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := sq.
+		Insert("retake_bookings").
+		SetMap(sq.Eq{
+			"session_id":   booking.SessionID,
+			"debt_id":      booking.DebtID,
+			"student_uuid": booking.StudentUUID,
+			"status":       booking.Status,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		if tools.IsUniqueViolation(err) {
+			return 0, log.ErrorWrapper(errors.ErrRetakeAlreadyBooked, errors.ERR_INFRASTRUCTURE, "")
+		}
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateRetakeBookingStatus implements repositories.RetakeSessionRepository.
+func (this *retakeSessionRepo) UpdateRetakeBookingStatus(ctx context.Context, update commands.UpdateRetakeBookingStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	query := sq.Update("retake_bookings").
+		Set("status", update.Status).
+		Set("updated_at", sq.Expr("clock_timestamp()")).
+		Where(sq.Eq{"id": update.ID}).
+		Where(sq.NotEq{"status": valueobjects.RetakeBookingCancelled}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type roomRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRoomRepo(conn *pgxpool.Pool) repositories.RoomRepository {
+	return &roomRepo{
+		db: conn,
+	}
+}
+
+// GetRooms implements repositories.RoomRepository.
+func (this *roomRepo) GetRooms(ctx context.Context, filters query.GetRoomsFilters) ([]models.Room, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select("id", "building", "number", "capacity", "accessible").
+		From("rooms")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": filters.IDs})
+	}
+	if filters.MinCapacity != 0 {
+		query = query.Where(sq.GtOrEq{"capacity": filters.MinCapacity})
+	}
+	if filters.OnlyAccessible {
+		query = query.Where(sq.Eq{"accessible": true})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("building", "number")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.Room
+	for rows.Next() {
+		var room models.Room
+		if err := rows.Scan(&room.ID, &room.Building, &room.Number, &room.Capacity, &room.Accessible); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, room)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// CreateRoom implements repositories.RoomRepository.
+func (this *roomRepo) CreateRoom(ctx context.Context, room commands.CreateRoom) (int64, error) {
+	if err := room.Validate(); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := sq.
+		Insert("rooms").
+		SetMap(sq.Eq{
+			"building":   room.Building,
+			"number":     room.Number,
+			"capacity":   room.Capacity,
+			"accessible": room.Accessible,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		if tools.IsUniqueViolation(err) {
+			return 0, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "room already exists")
+		}
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateRoom implements repositories.RoomRepository.
+func (this *roomRepo) UpdateRoom(ctx context.Context, room commands.UpdateRoom) error {
+	if err := room.Validate(); err != nil {
+		return err
+	}
+
+	sql, args, err := sq.Update("rooms").
+		Set("building", room.Building).
+		Set("number", room.Number).
+		Set("capacity", room.Capacity).
+		Set("accessible", room.Accessible).
+		Where(sq.Eq{"id": room.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		if tools.IsUniqueViolation(err) {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_INFRASTRUCTURE, "room already exists")
+		}
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// DeleteRoom implements repositories.RoomRepository.
+func (this *roomRepo) DeleteRoom(ctx context.Context, room commands.DeleteRoom) error {
+	sql, args, err := sq.Delete("rooms").Where(sq.Eq{"id": room.ID}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		if tools.IsForeignKeyViolation(err) {
+			return log.ErrorWrapper(errors.ErrRoomInUse, errors.ERR_INFRASTRUCTURE, "")
+		}
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
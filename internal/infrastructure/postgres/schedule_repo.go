@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+// scheduleLockKey is the advisory lock taken by LockSchedule, it differs
+// from the one goose holds while migrating.
+const scheduleLockKey int64 = 0x5363686564756c65
+
+type scheduleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewScheduleRepo(conn *pgxpool.Pool) repositories.ScheduleRepository {
+	return &scheduleRepo{
+		db: conn,
+	}
+}
+
+// GetScheduledRetakes implements repositories.ScheduleRepository.
+// Scheduled debts and retake sessions are read with a single UNION ALL query.
+func (this *scheduleRepo) GetScheduledRetakes(ctx context.Context, filters query.GetScheduledRetakesFilters) ([]models.ScheduledRetake, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	debtsUsing := sq.Or{}
+	sessionsUsing := sq.Or{}
+	if len(filters.RoomIDs) > 0 {
+		debtsUsing = append(debtsUsing, sq.Eq{"d.room_id": filters.RoomIDs})
+		sessionsUsing = append(sessionsUsing, sq.Eq{"rs.room_id": filters.RoomIDs})
+	}
+	if len(filters.TeacherUUIDs) > 0 {
+		debtsUsing = append(debtsUsing, sq.Eq{"d.teacher_uuid": filters.TeacherUUIDs})
+		sessionsUsing = append(sessionsUsing, sq.Eq{"rs.teacher_uuid": filters.TeacherUUIDs})
+	}
+	if len(filters.StudentUUIDs) > 0 {
+		debtsUsing = append(debtsUsing, sq.Eq{"d.student_uuid": filters.StudentUUIDs})
+	}
+
+	parts := make([]string, 0, 2)
+	args := make([]any, 0)
+
+	debtsSQL, debtsArgs, err := sq.Select(
+		"d.id",
+		"0",
+		"d.exam_id",
+		"d.teacher_uuid",
+		"d.student_uuid",
+		"COALESCE(d.room_id, 0)",
+		"d.date",
+	).
+		From("debts d").
+		Where(sq.Gt{"d.date": filters.From}).
+		Where(sq.Lt{"d.date": filters.To}).
+		Where(debtsUsing).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+	parts = append(parts, debtsSQL)
+	args = append(args, debtsArgs...)
+
+	// sessions have no student, a student-only lookup skips them
+	if len(sessionsUsing) > 0 {
+		sessionsSQL, sessionsArgs, err := sq.Select(
+			"0",
+			"rs.id",
+			"rs.exam_id",
+			"rs.teacher_uuid",
+			"''",
+			"COALESCE(rs.room_id, 0)",
+			"rs.date",
+		).
+			From("retake_sessions rs").
+			Where(sq.Gt{"rs.date": filters.From}).
+			Where(sq.Lt{"rs.date": filters.To}).
+			Where(sessionsUsing).
+			ToSql()
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+		}
+		parts = append(parts, sessionsSQL)
+		args = append(args, sessionsArgs...)
+	}
+
+	sql, err := sq.Dollar.ReplacePlaceholders(strings.Join(parts, " UNION ALL ") + " ORDER BY 7")
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.ScheduledRetake
+	for rows.Next() {
+		var retake models.ScheduledRetake
+		if err := rows.Scan(
+			&retake.DebtID,
+			&retake.SessionID,
+			&retake.ExamID,
+			&retake.TeacherUUID,
+			&retake.StudentUUID,
+			&retake.RoomID,
+			&retake.Date,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, retake)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// LockSchedule implements repositories.ScheduleRepository.
+// Retakes are written into rows of several tables, so a transaction level
+// advisory lock stands in for a row lock.
+func (this *scheduleRepo) LockSchedule(ctx context.Context) error {
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "schedule can be locked inside a transaction only")
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", scheduleLockKey); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+
+	return nil
+}
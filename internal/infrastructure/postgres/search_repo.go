@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+// searchSelects are the selects of every kind. A row matches when the query
+// is close to a word of the document ($1), the words of the query are
+// prefixes of its words in any form ($2) or the query is a part of it ($3).
+// Deleted rows never match.
+var searchSelects = map[string]string{
+	valueobjects.SearchStudentKind: searchSelect(valueobjects.SearchStudentKind, personSearchDocument("s"),
+		"s.uuid", searchTitle("s.last_name", "s.first_name", "s.middle_name"), "concat_ws(', ', nullif(g.name, ''), nullif(s.email, ''))",
+		"students s LEFT JOIN groups g ON s.group_id = g.id", "s"),
+	valueobjects.SearchTeacherKind: searchSelect(valueobjects.SearchTeacherKind, personSearchDocument("t"),
+		"t.uuid", searchTitle("t.last_name", "t.first_name", "t.middle_name"), "coalesce(t.email, '')",
+		"teachers t", "t"),
+	valueobjects.SearchGroupKind: searchSelect(valueobjects.SearchGroupKind, nameSearchDocument("g"),
+		"g.id::text", "coalesce(g.name, '')", "''",
+		"groups g", "g"),
+	valueobjects.SearchExamKind: searchSelect(valueobjects.SearchExamKind, nameSearchDocument("e"),
+		"e.id::text", "coalesce(e.name, '')", "coalesce(e.assessment_type, '')",
+		"exams e", "e"),
+}
+
+// personSearchDocument and nameSearchDocument are what a search matches, the
+// add_search_indexes migration indexes the same expressions.
+func personSearchDocument(alias string) string {
+	return "(coalesce(" + alias + ".last_name, '') || ' ' || coalesce(" + alias + ".first_name, '') || ' ' || " +
+		"coalesce(" + alias + ".middle_name, '') || ' ' || coalesce(" + alias + ".email, ''))"
+}
+
+func nameSearchDocument(alias string) string {
+	return "coalesce(" + alias + ".name, '')"
+}
+
+// searchTitle joins the names that are set with spaces.
+func searchTitle(columns ...string) string {
+	for i, column := range columns {
+		columns[i] = "nullif(" + column + ", '')"
+	}
+	return "concat_ws(' ', " + strings.Join(columns, ", ") + ")"
+}
+
+func searchSelect(kind, document, id, title, subtitle, from, alias string) string {
+	vector := "to_tsvector('russian', " + document + ")"
+	tsquery := "to_tsquery('russian', $2)"
+
+	return "SELECT '" + kind + "' AS kind, " + id + " AS id, " + title + " AS title, " + subtitle + " AS subtitle, " +
+		"greatest(word_similarity($1, " + document + "), ts_rank(" + vector + ", " + tsquery + "))::float8 AS rank " +
+		"FROM " + from + " " +
+		"WHERE ($1 <% " + document + " OR " + vector + " @@ " + tsquery + " OR " + document + " ILIKE $3) " +
+		"AND " + alias + ".deleted_at IS NULL"
+}
+
+type searchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepo(conn *pgxpool.Pool) repositories.SearchRepository {
+	return &searchRepo{
+		db: conn,
+	}
+}
+
+// Search implements repositories.SearchRepository.
+func (this *searchRepo) Search(ctx context.Context, search query.Search) ([]models.SearchHit, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	var selects []string
+	for _, kind := range valueobjects.SearchKinds {
+		if search.Searches(kind) {
+			selects = append(selects, searchSelects[kind])
+		}
+	}
+	sql := "SELECT kind, id, title, subtitle, rank FROM (" + strings.Join(selects, " UNION ALL ") + ") hits " +
+		"ORDER BY rank DESC, kind, title, id"
+	if search.Limit != 0 {
+		sql += " LIMIT " + strconv.FormatInt(search.Limit, 10)
+	}
+
+	text := strings.TrimSpace(search.Query)
+	args := []any{text, prefixTSQuery(text), "%" + escapeLike(text) + "%"}
+
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.SearchHit
+	for rows.Next() {
+		var hit models.SearchHit
+		if err := rows.Scan(&hit.Kind, &hit.ID, &hit.Title, &hit.Subtitle, &hit.Rank); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		result = append(result, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return result, nil
+}
+
+// prefixTSQuery makes "лавр ив" into "лавр:* & ив:*". Everything but letters
+// and digits splits the words, so the query can not break the tsquery syntax.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
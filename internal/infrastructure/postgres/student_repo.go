@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
@@ -27,7 +28,12 @@ func (this *studentRepo) GetAmountOfDebtsForStudent(ctx context.Context, uuid st
 		return -1, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var result int64
 	if err := row.Scan(&result); err != nil {
@@ -41,7 +47,7 @@ func (this *studentRepo) GetAmountOfDebtsForStudent(ctx context.Context, uuid st
 func (this *studentRepo) ChangeStudentPassword(ctx context.Context, uuid, password string) error {
 	query := sq.Update("students").
 		Set("password", password).
-		Where(sq.Eq{"uuid": uuid}).
+		Where(sq.Eq{"uuid": uuid, "deleted_at": nil}).
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := query.ToSql()
@@ -74,7 +80,7 @@ func (this *studentRepo) SearchStudents(ctx context.Context, filters query.Searc
 	).
 		From("students")
 
-	conditions := sq.And{}
+	conditions := sq.And{notDeleted(filters.Deleted)}
 	if len(filters.UUIDs) > 0 {
 		conditions = append(conditions, sq.Eq{"uuid": filters.UUIDs})
 	}
@@ -101,7 +107,12 @@ func (this *studentRepo) SearchStudents(ctx context.Context, filters query.Searc
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := this.db.Query(ctx, sqlQuery, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sqlQuery, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -153,17 +164,23 @@ func (this *studentRepo) GetStudentByUUID(ctx context.Context, uuid string) (*mo
 		"s.email",
 		"s.password",
 		"g.name",
+		"s.version",
 	).From("students s")
 
 	query = query.LeftJoin("groups g ON s.group_id = g.id")
-	query = query.Where(sq.Eq{"s.uuid": uuid})
+	query = query.Where(sq.Eq{"s.uuid": uuid, "s.deleted_at": nil})
 
 	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var result models.Student
 	result.Group = &models.Group{}
@@ -177,6 +194,7 @@ func (this *studentRepo) GetStudentByUUID(ctx context.Context, uuid string) (*mo
 		&result.Email,
 		&result.Password,
 		&result.Group.Name,
+		&result.Version,
 	); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
@@ -187,45 +205,19 @@ func (this *studentRepo) GetStudentByUUID(ctx context.Context, uuid string) (*mo
 
 // DeleteStudent implements repositories.StudentRepository.
 func (this *studentRepo) DeleteStudent(ctx context.Context, student commands.DeleteStudent) error {
-	sql, args, err := sq.Delete("students").Where(sq.Eq{"uuid": student.UUID}).PlaceholderFormat(sq.Dollar).ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if _, err = this.db.Exec(ctx, sql, args...); err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	return nil
+	return moveToTrash(ctx, this.db, "students", sq.Eq{"uuid": student.UUID})
 }
 
 // UpdateStudent implements repositories.StudentRepository.
 func (this *studentRepo) UpdateStudent(ctx context.Context, student commands.UpdateStudent) error {
-	query := sq.Update("students").
+	update := sq.Update("students").
 		Set("first_name", student.FirstName).
 		Set("last_name", student.LastName).
 		Set("middle_name", student.MiddleName).
 		Set("email", student.Email).
-		Set("group_id", student.GroupID).
-		Where(sq.Eq{"uuid": student.UUID}).
-		PlaceholderFormat(sq.Dollar)
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if tx, ok := tools.GetTransaction(ctx); ok {
-		_, err = tx.Exec(ctx, sql, args...)
-	} else {
-		_, err = this.db.Exec(ctx, sql, args...)
-	}
-
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
+		Set("group_id", student.GroupID)
 
-	return nil
+	return updateVersion(ctx, this.db, update, "students", sq.Eq{"uuid": student.UUID, "deleted_at": nil}, student.Version)
 }
 
 // CreateStudent implements repositories.StudentRepository.
@@ -250,7 +242,12 @@ func (this *studentRepo) CreateStudent(ctx context.Context, student commands.Cre
 	}
 	fmt.Println("DEBUG", sql, args)
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var id string
 	if err := row.Scan(&id); err != nil {
@@ -275,15 +272,21 @@ func (this studentRepo) GetStudents(ctx context.Context, filters query.GetStuden
 		"s.email",
 		"g.name",
 		"s.password",
+		"s.version",
 	).From("students s")
 
 	query = query.LeftJoin("groups g ON s.group_id = g.id")
+	query = query.Where(studentConditions(filters))
 
-	if len(filters.IDs) != 0 {
-		query = query.Where(sq.Eq{"uuid": filters.IDs})
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
 	}
-	if len(filters.Emails) != 0 {
-		query = query.Where(sq.Eq{"email": filters.Emails})
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query, err := orderByKeyset(query, studentSortColumns, filters.Keyset)
+	if err != nil {
+		return nil, err
 	}
 
 	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
@@ -291,11 +294,16 @@ func (this studentRepo) GetStudents(ctx context.Context, filters query.GetStuden
 		return nil, err
 	}
 
-	rows, err := this.db.Query(ctx, sql, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
 	if err != nil {
-		fmt.Println("here", err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	var result []models.Student
 	for rows.Next() {
@@ -309,6 +317,7 @@ func (this studentRepo) GetStudents(ctx context.Context, filters query.GetStuden
 			&stdnt.Email,
 			&stdnt.Group.Name,
 			&stdnt.Password,
+			&stdnt.Version,
 		); err != nil {
 			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 			return nil, err
@@ -319,3 +328,44 @@ func (this studentRepo) GetStudents(ctx context.Context, filters query.GetStuden
 
 	return result, nil
 }
+
+func (this studentRepo) CountStudents(ctx context.Context, filters query.GetStudentsFilters) (int64, error) {
+	sql, args, err := sq.Select("count(*)").
+		From("students s").
+		Where(studentConditions(filters)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(&count); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return count, nil
+}
+
+func studentConditions(filters query.GetStudentsFilters) sq.And {
+	conditions := sq.And{sq.Eq{"s.deleted_at": nil}}
+	if len(filters.IDs) != 0 {
+		conditions = append(conditions, sq.Eq{"s.uuid": filters.IDs})
+	}
+	if len(filters.Emails) != 0 {
+		conditions = append(conditions, sq.Eq{"s.email": filters.Emails})
+	}
+	if len(filters.GroupIDs) != 0 {
+		conditions = append(conditions, sq.Eq{"s.group_id": filters.GroupIDs})
+	}
+	if len(filters.LastNames) != 0 {
+		conditions = append(conditions, sq.Eq{"s.last_name": filters.LastNames})
+	}
+	return conditions
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type teacherAvailabilityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTeacherAvailabilityRepo(conn *pgxpool.Pool) repositories.TeacherAvailabilityRepository {
+	return &teacherAvailabilityRepo{
+		db: conn,
+	}
+}
+
+// availabilityColumns keeps only the columns that make sense for the kind,
+// the rest are stored as NULL.
+func availabilityColumns(kind string, weekday time.Weekday, startMinute, endMinute int64, startsAt, endsAt time.Time, note string) sq.Eq {
+	columns := sq.Eq{
+		"kind":         kind,
+		"weekday":      nil,
+		"start_minute": nil,
+		"end_minute":   nil,
+		"starts_at":    nil,
+		"ends_at":      nil,
+		"note":         note,
+	}
+	if kind == valueobjects.AvailabilityWeekly {
+		columns["weekday"] = int64(weekday)
+		columns["start_minute"] = startMinute
+		columns["end_minute"] = endMinute
+	} else {
+		columns["starts_at"] = startsAt
+		columns["ends_at"] = endsAt
+	}
+
+	return columns
+}
+
+// GetTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) GetTeacherAvailability(ctx context.Context, filters query.GetTeacherAvailabilityFilters) ([]models.TeacherAvailability, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"id",
+		"teacher_uuid",
+		"kind",
+		"COALESCE(weekday, 0)",
+		"COALESCE(start_minute, 0)",
+		"COALESCE(end_minute, 0)",
+		"starts_at",
+		"ends_at",
+		"note",
+	).From("teacher_availability")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": filters.IDs})
+	}
+	if len(filters.TeacherUUIDs) > 0 {
+		query = query.Where(sq.Eq{"teacher_uuid": filters.TeacherUUIDs})
+	}
+	if len(filters.Kinds) > 0 {
+		query = query.Where(sq.Eq{"kind": filters.Kinds})
+	}
+	query = query.OrderBy("kind", "weekday", "start_minute", "starts_at", "id")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.TeacherAvailability
+	for rows.Next() {
+		var (
+			entry            models.TeacherAvailability
+			weekday          int64
+			startsAt, endsAt *time.Time
+		)
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.TeacherUUID,
+			&entry.Kind,
+			&weekday,
+			&entry.StartMinute,
+			&entry.EndMinute,
+			&startsAt,
+			&endsAt,
+			&entry.Note,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		entry.Weekday = time.Weekday(weekday)
+		if startsAt != nil {
+			entry.StartsAt = *startsAt
+		}
+		if endsAt != nil {
+			entry.EndsAt = *endsAt
+		}
+
+		result = append(result, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// CreateTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) CreateTeacherAvailability(ctx context.Context, entry commands.CreateTeacherAvailability) (int64, error) {
+	if err := entry.Validate(); err != nil {
+		return 0, err
+	}
+
+	columns := availabilityColumns(entry.Kind, entry.Weekday, entry.StartMinute, entry.EndMinute, entry.StartsAt, entry.EndsAt, entry.Note)
+	columns["teacher_uuid"] = entry.TeacherUUID
+
+	sql, args, err := sq.
+		Insert("teacher_availability").
+		SetMap(columns).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return id, nil
+}
+
+// UpdateTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) UpdateTeacherAvailability(ctx context.Context, entry commands.UpdateTeacherAvailability) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	sql, args, err := sq.Update("teacher_availability").
+		SetMap(availabilityColumns(entry.Kind, entry.Weekday, entry.StartMinute, entry.EndMinute, entry.StartsAt, entry.EndsAt, entry.Note)).
+		Where(sq.Eq{"id": entry.ID, "teacher_uuid": entry.TeacherUUID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var tag pgconn.CommandTag
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if tag.RowsAffected() == 0 {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// DeleteTeacherAvailability implements repositories.TeacherAvailabilityRepository.
+func (this *teacherAvailabilityRepo) DeleteTeacherAvailability(ctx context.Context, entry commands.DeleteTeacherAvailability) error {
+	sql, args, err := sq.Delete("teacher_availability").
+		Where(sq.Eq{"id": entry.ID, "teacher_uuid": entry.TeacherUUID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var tag pgconn.CommandTag
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if tag.RowsAffected() == 0 {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
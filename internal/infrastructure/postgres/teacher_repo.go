@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
@@ -24,7 +25,7 @@ type teacherRepo struct {
 func (this *teacherRepo) ChangeTeacherPassword(ctx context.Context, uuid, password string) error {
 	query := sq.Update("teachers").
 		Set("password", password).
-		Where(sq.Eq{"uuid": uuid}).
+		Where(sq.Eq{"uuid": uuid, "deleted_at": nil}).
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := query.ToSql()
@@ -56,7 +57,7 @@ func (this *teacherRepo) SearchTeachers(ctx context.Context, filters query.Searc
 	).
 		From("teachers")
 
-	conditions := sq.And{}
+	conditions := sq.And{notDeleted(filters.Deleted)}
 	if len(filters.UUIDs) > 0 {
 		conditions = append(conditions, sq.Eq{"uuid": filters.UUIDs})
 	}
@@ -83,7 +84,12 @@ func (this *teacherRepo) SearchTeachers(ctx context.Context, filters query.Searc
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := this.db.Query(ctx, sqlQuery, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sqlQuery, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -140,7 +146,12 @@ func (this *teacherRepo) CreateTeacher(ctx context.Context, teacher commands.Cre
 		return "", err
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var id string
 	if err := row.Scan(&id); err != nil {
@@ -153,16 +164,7 @@ func (this *teacherRepo) CreateTeacher(ctx context.Context, teacher commands.Cre
 
 // DeleteTeacher implements repositories.TeacherRepository.
 func (this *teacherRepo) DeleteTeacher(ctx context.Context, teacher commands.DeleteTeacher) error {
-	sql, args, err := sq.Delete("teachers").Where(sq.Eq{"uuid": teacher.UUID}).PlaceholderFormat(sq.Dollar).ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if _, err = this.db.Exec(ctx, sql, args...); err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	return nil
+	return moveToTrash(ctx, this.db, "teachers", sq.Eq{"uuid": teacher.UUID})
 }
 
 // GetTeacherByUUID implements repositories.TeacherRepository.
@@ -174,16 +176,22 @@ func (this *teacherRepo) GetTeacherByUUID(ctx context.Context, uuid string) (*mo
 		"s.middle_name",
 		"s.email",
 		"s.password",
+		"s.version",
 	).From("teachers s")
 
-	query = query.Where(sq.Eq{"s.uuid": uuid})
+	query = query.Where(sq.Eq{"s.uuid": uuid, "s.deleted_at": nil})
 
 	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	row := this.db.QueryRow(ctx, sql, args...)
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
 
 	var result models.Teacher
 
@@ -194,6 +202,7 @@ func (this *teacherRepo) GetTeacherByUUID(ctx context.Context, uuid string) (*mo
 		&result.MiddleName,
 		&result.Email,
 		&result.Password,
+		&result.Version,
 	); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
@@ -204,30 +213,13 @@ func (this *teacherRepo) GetTeacherByUUID(ctx context.Context, uuid string) (*mo
 
 // UpdateTeacher implements repositories.TeacherRepository.
 func (this *teacherRepo) UpdateTeacher(ctx context.Context, teacher commands.UpdateTeacher) error {
-	query := sq.Update("teachers").
+	update := sq.Update("teachers").
 		Set("first_name", teacher.FirstName).
 		Set("last_name", teacher.LastName).
 		Set("middle_name", teacher.MiddleName).
-		Set("email", teacher.Email).
-		Where(sq.Eq{"uuid": teacher.UUID}).
-		PlaceholderFormat(sq.Dollar)
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	if tx, ok := tools.GetTransaction(ctx); ok {
-		_, err = tx.Exec(ctx, sql, args...)
-	} else {
-		_, err = this.db.Exec(ctx, sql, args...)
-	}
-
-	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
+		Set("email", teacher.Email)
 
-	return nil
+	return updateVersion(ctx, this.db, update, "teachers", sq.Eq{"uuid": teacher.UUID, "deleted_at": nil}, teacher.Version)
 }
 
 func (this teacherRepo) GetTeachers(ctx context.Context, filters query.GetTeachersFilters) ([]models.Teacher, error) {
@@ -242,30 +234,36 @@ func (this teacherRepo) GetTeachers(ctx context.Context, filters query.GetTeache
 		"middle_name",
 		"email",
 		"password",
+		"version",
 	).From("teachers")
 
-	if len(filters.UUIDs) != 0 {
-		query = query.Where(sq.Eq{"uuid": filters.UUIDs})
-	}
-	if len(filters.Emails) != 0 {
-		query = query.Where(sq.Eq{"email": filters.Emails})
-	}
+	query = query.Where(teacherConditions(filters))
 	if filters.Limit != 0 {
 		query = query.Limit(uint64(filters.Limit))
 	}
 	if filters.Offset != 0 {
 		query = query.Offset(uint64(filters.Offset))
 	}
+	query, err := orderByKeyset(query, teacherSortColumns, filters.Keyset)
+	if err != nil {
+		return nil, err
+	}
 
 	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := this.db.Query(ctx, sql, args...)
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var result []models.Teacher
 	for rows.Next() {
@@ -277,6 +275,7 @@ func (this teacherRepo) GetTeachers(ctx context.Context, filters query.GetTeache
 			&teacher.MiddleName,
 			&teacher.Email,
 			&teacher.Password,
+			&teacher.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -286,3 +285,41 @@ func (this teacherRepo) GetTeachers(ctx context.Context, filters query.GetTeache
 
 	return result, nil
 }
+
+func (this teacherRepo) CountTeachers(ctx context.Context, filters query.GetTeachersFilters) (int64, error) {
+	sql, args, err := sq.Select("count(*)").
+		From("teachers").
+		Where(teacherConditions(filters)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	var row pgx.Row
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		row = tx.QueryRow(ctx, sql, args...)
+	} else {
+		row = this.db.QueryRow(ctx, sql, args...)
+	}
+	if err := row.Scan(&count); err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return count, nil
+}
+
+func teacherConditions(filters query.GetTeachersFilters) sq.And {
+	conditions := sq.And{sq.Eq{"deleted_at": nil}}
+	if len(filters.UUIDs) != 0 {
+		conditions = append(conditions, sq.Eq{"uuid": filters.UUIDs})
+	}
+	if len(filters.Emails) != 0 {
+		conditions = append(conditions, sq.Eq{"email": filters.Emails})
+	}
+	if len(filters.LastNames) != 0 {
+		conditions = append(conditions, sq.Eq{"last_name": filters.LastNames})
+	}
+	return conditions
+}
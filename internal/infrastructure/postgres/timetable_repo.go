@@ -0,0 +1,346 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type timetableRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTimetableRepo(conn *pgxpool.Pool) repositories.TimetableRepository {
+	return &timetableRepo{
+		db: conn,
+	}
+}
+
+// CreateTimetableDraft implements repositories.TimetableRepository.
+func (this *timetableRepo) CreateTimetableDraft(ctx context.Context, draft commands.CreateTimetableDraft) (int64, error) {
+	if err := draft.Validate(); err != nil {
+		return 0, err
+	}
+
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		var err error
+		if tx, err = this.db.Begin(ctx); err != nil {
+			return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		defer tx.Rollback(ctx)
+	}
+
+	sql, args, err := sq.
+		Insert("timetable_drafts").
+		SetMap(sq.Eq{
+			"status":       valueobjects.TimetableDraftStatus,
+			"period_start": draft.PeriodStart,
+			"deadline":     draft.Deadline,
+			"created_by":   draft.CreatedBy,
+		}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var id int64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if len(draft.Items) > 0 {
+		insert := sq.Insert("timetable_draft_items").Columns("draft_id", "debt_id", "room_id", "date", "reason")
+		for _, item := range draft.Items {
+			var roomID, date any
+			if item.RoomID != 0 {
+				roomID, date = item.RoomID, item.Date
+			}
+			insert = insert.Values(id, item.DebtID, roomID, date, item.Reason)
+		}
+
+		sql, args, err := insert.PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	if !ok {
+		if err := tx.Commit(ctx); err != nil {
+			return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+
+	return id, nil
+}
+
+// GetTimetableDrafts implements repositories.TimetableRepository.
+func (this *timetableRepo) GetTimetableDrafts(ctx context.Context, filters query.GetTimetableDraftsFilters) ([]models.TimetableDraft, error) {
+	if err := filters.Validate(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "invalid filters")
+	}
+
+	query := sq.Select(
+		"id",
+		"status",
+		"period_start",
+		"deadline",
+		"created_by",
+		"created_at",
+		"applied_at",
+	).From("timetable_drafts")
+
+	if len(filters.IDs) > 0 {
+		query = query.Where(sq.Eq{"id": filters.IDs})
+	}
+	if len(filters.Statuses) > 0 {
+		query = query.Where(sq.Eq{"status": filters.Statuses})
+	}
+	if filters.Limit != 0 {
+		query = query.Limit(uint64(filters.Limit))
+	}
+	if filters.Offset != 0 {
+		query = query.Offset(uint64(filters.Offset))
+	}
+	query = query.OrderBy("created_at DESC", "id DESC")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.TimetableDraft
+	for rows.Next() {
+		var draft models.TimetableDraft
+		if err := rows.Scan(
+			&draft.ID,
+			&draft.Status,
+			&draft.PeriodStart,
+			&draft.Deadline,
+			&draft.CreatedBy,
+			&draft.CreatedAt,
+			&draft.AppliedAt,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+
+		result = append(result, draft)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// GetTimetableDraftItems implements repositories.TimetableRepository.
+func (this *timetableRepo) GetTimetableDraftItems(ctx context.Context, draftID int64) ([]models.TimetableDraftItem, error) {
+	query := sq.Select(
+		"i.id",
+		"i.draft_id",
+		"i.date",
+		"i.reason",
+		"d.id",
+		"d.date",
+		"e.id",
+		"e.name",
+		"s.uuid",
+		"s.first_name",
+		"s.last_name",
+		"s.middle_name",
+		"s.email",
+		"g.id",
+		"g.name",
+		"t.uuid",
+		"t.first_name",
+		"t.last_name",
+		"t.middle_name",
+		"t.email",
+		"COALESCE(r.id, 0)",
+		"COALESCE(r.building, '')",
+		"COALESCE(r.number, '')",
+		"COALESCE(r.capacity, 0)",
+		"COALESCE(r.accessible, false)",
+	)
+	query = query.From("timetable_draft_items i")
+	query = query.Join("debts d ON i.debt_id = d.id")
+	query = query.LeftJoin("exams e ON d.exam_id = e.id")
+	query = query.LeftJoin("students s ON d.student_uuid = s.uuid")
+	query = query.LeftJoin("groups g ON s.group_id = g.id")
+	query = query.LeftJoin("teachers t ON d.teacher_uuid = t.uuid")
+	query = query.LeftJoin("rooms r ON i.room_id = r.id")
+	query = query.Where(sq.Eq{"i.draft_id": draftID})
+	query = query.OrderBy("i.date NULLS LAST", "r.building", "r.number", "i.id")
+
+	sql, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var rows pgx.Rows
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = this.db.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.TimetableDraftItem
+	for rows.Next() {
+		item := models.TimetableDraftItem{
+			Debt: &models.Debt{
+				Exam: &models.Exam{},
+				Student: &models.Student{
+					Group: &models.Group{},
+				},
+				Teacher: &models.Teacher{},
+			},
+			Room: &models.Room{},
+		}
+		if err := rows.Scan(
+			&item.ID,
+			&item.DraftID,
+			&item.Date,
+			&item.Reason,
+			&item.Debt.ID,
+			&item.Debt.Date,
+			&item.Debt.Exam.ID,
+			&item.Debt.Exam.Name,
+			&item.Debt.Student.UUID,
+			&item.Debt.Student.FirstName,
+			&item.Debt.Student.LastName,
+			&item.Debt.Student.MiddleName,
+			&item.Debt.Student.Email,
+			&item.Debt.Student.Group.ID,
+			&item.Debt.Student.Group.Name,
+			&item.Debt.Teacher.UUID,
+			&item.Debt.Teacher.FirstName,
+			&item.Debt.Teacher.LastName,
+			&item.Debt.Teacher.MiddleName,
+			&item.Debt.Teacher.Email,
+			&item.Room.ID,
+			&item.Room.Building,
+			&item.Room.Number,
+			&item.Room.Capacity,
+			&item.Room.Accessible,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		if item.Room.ID == 0 {
+			item.Room = nil
+		}
+
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "rows error")
+	}
+
+	return result, nil
+}
+
+// LockTimetableDraft implements repositories.TimetableRepository.
+func (this *timetableRepo) LockTimetableDraft(ctx context.Context, id int64) error {
+	tx, ok := tools.GetTransaction(ctx)
+	if !ok {
+		return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, "draft can be locked inside a transaction only")
+	}
+
+	sql, args, err := sq.Select("id").
+		From("timetable_drafts").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var locked int64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&locked); err != nil {
+		if err == pgx.ErrNoRows {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "")
+		}
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+// UpdateTimetableDraftStatus implements repositories.TimetableRepository.
+// Only drafts are moved, applied and discarded drafts stay as they are.
+func (this *timetableRepo) UpdateTimetableDraftStatus(ctx context.Context, update commands.UpdateTimetableDraftStatus) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+
+	query := sq.Update("timetable_drafts").
+		Set("status", update.Status).
+		Where(sq.Eq{"id": update.ID, "status": valueobjects.TimetableDraftStatus}).
+		PlaceholderFormat(sq.Dollar)
+	if update.Status == valueobjects.TimetableAppliedStatus {
+		query = query.Set("applied_at", sq.Expr("now()"))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = this.db.Exec(ctx, sql, args...)
+	}
+
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+type trashTable struct {
+	importEntityTable
+	title string
+}
+
+// trashTables are the tables of every kind. An expired row is only purged
+// once nothing an import rollback looks for references it.
+var trashTables = map[string]trashTable{
+	valueobjects.TrashStudentKind: {
+		importEntityTable: importEntityTables[valueobjects.ImportStudentEntity],
+		title:             searchTitle("last_name", "first_name", "middle_name"),
+	},
+	valueobjects.TrashTeacherKind: {
+		importEntityTable: importEntityTables[valueobjects.ImportTeacherEntity],
+		title:             searchTitle("last_name", "first_name", "middle_name"),
+	},
+	valueobjects.TrashGroupKind: {
+		importEntityTable: importEntityTables[valueobjects.ImportGroupEntity],
+		title:             "coalesce(name, '')",
+	},
+	valueobjects.TrashExamKind: {
+		importEntityTable: importEntityTables[valueobjects.ImportExamEntity],
+		title:             "coalesce(name, '')",
+	},
+}
+
+// notDeleted keeps the live rows of a search, or the trashed ones when
+// deleted is set.
+func notDeleted(deleted bool) sq.Sqlizer {
+	if deleted {
+		return sq.NotEq{"deleted_at": nil}
+	}
+	return sq.Eq{"deleted_at": nil}
+}
+
+// moveToTrash is the delete of the trashed tables, deleting a deleted row
+// keeps the time it was deleted first.
+func moveToTrash(ctx context.Context, db *pgxpool.Pool, table string, where sq.Eq) error {
+	where["deleted_at"] = nil
+	sql, args, err := sq.Update(table).
+		Set("deleted_at", sq.Expr("now()")).
+		Where(where).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		_, err = db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return nil
+}
+
+type trashRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTrashRepo(conn *pgxpool.Pool) repositories.TrashRepository {
+	return &trashRepo{
+		db: conn,
+	}
+}
+
+// GetTrash implements repositories.TrashRepository.
+func (this *trashRepo) GetTrash(ctx context.Context, filters query.GetTrashFilters) ([]models.TrashItem, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	var selects []string
+	for _, kind := range valueobjects.TrashKinds {
+		if !filters.Lists(kind) {
+			continue
+		}
+		table := trashTables[kind]
+		selects = append(selects, "SELECT '"+kind+"' AS kind, "+table.key+"::text AS id, "+table.title+" AS title, deleted_at "+
+			"FROM "+table.table+" WHERE deleted_at IS NOT NULL")
+	}
+	sql := "SELECT kind, id, title, deleted_at FROM (" + strings.Join(selects, " UNION ALL ") + ") trash " +
+		"ORDER BY deleted_at DESC, kind, id"
+	if filters.Limit != 0 {
+		sql += " LIMIT " + strconv.FormatInt(filters.Limit, 10)
+	}
+	if filters.Offset != 0 {
+		sql += " OFFSET " + strconv.FormatInt(filters.Offset, 10)
+	}
+
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		rows, err = tx.Query(ctx, sql)
+	} else {
+		rows, err = this.db.Query(ctx, sql)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not perform query")
+	}
+	defer rows.Close()
+
+	var result []models.TrashItem
+	for rows.Next() {
+		var item models.TrashItem
+		if err := rows.Scan(&item.Kind, &item.ID, &item.Title, &item.DeletedAt); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not scan result")
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return result, nil
+}
+
+// RestoreDeleted implements repositories.TrashRepository.
+func (this *trashRepo) RestoreDeleted(ctx context.Context, restore commands.RestoreDeleted) error {
+	if err := restore.Validate(); err != nil {
+		return err
+	}
+
+	table := trashTables[restore.Kind]
+	var key any = restore.ID
+	if table.key == "id" {
+		id, err := strconv.ParseInt(restore.ID, 10, 64)
+		if err != nil {
+			return log.ErrorWrapper(errors.ErrInvalidCommand, errors.ERR_INFRASTRUCTURE, err.Error())
+		}
+		key = id
+	}
+
+	sql, args, err := sq.Update(table.table).
+		Set("deleted_at", nil).
+		Where(sq.Eq{table.key: key}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var tag pgconn.CommandTag
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if tag.RowsAffected() == 0 {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "not in the trash", "kind", restore.Kind, "id", restore.ID)
+	}
+
+	return nil
+}
+
+// PurgeDeleted implements repositories.TrashRepository.
+func (this *trashRepo) PurgeDeleted(ctx context.Context, purge commands.PurgeDeleted) (int64, error) {
+	if err := purge.Validate(); err != nil {
+		return 0, err
+	}
+
+	table := trashTables[purge.Kind]
+	conditions := sq.And{sq.Lt{"deleted_at": purge.Before}}
+	for _, reference := range table.references {
+		conditions = append(conditions, sq.Expr("NOT "+strings.ReplaceAll(reference.condition, "?", table.table+"."+table.key)))
+	}
+
+	sql, args, err := sq.Delete(table.table).
+		Where(conditions).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var tag pgconn.CommandTag
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = this.db.Exec(ctx, sql, args...)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return tag.RowsAffected(), nil
+}
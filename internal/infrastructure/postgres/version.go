@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+// updateVersion is the update of the versioned tables. It bumps the version
+// of the row, an update with a version only goes through when it is the
+// current one. where leaves out the trashed rows of the tables with a trash.
+func updateVersion(ctx context.Context, db *pgxpool.Pool, update sq.UpdateBuilder, table string, where sq.Eq, version int64) error {
+	update = update.
+		Set("version", sq.Expr("version + 1")).
+		Where(where)
+	if version != 0 {
+		update = update.Where(sq.Eq{"version": version})
+	}
+
+	sql, args, err := update.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var affected int64
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		tag, execErr := tx.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	} else {
+		tag, execErr := db.Exec(ctx, sql, args...)
+		affected, err = tag.RowsAffected(), execErr
+	}
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if affected != 0 || version == 0 {
+		return nil
+	}
+
+	// the row is either gone or of another version
+	sql, args, err = sq.Select("count(*)").
+		From(table).
+		Where(where).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "can not build sql")
+	}
+
+	var count int64
+	if tx, ok := tools.GetTransaction(ctx); ok {
+		err = tx.QueryRow(ctx, sql, args...).Scan(&count)
+	} else {
+		err = db.QueryRow(ctx, sql, args...).Scan(&count)
+	}
+	switch {
+	case err != nil:
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	case count == 0:
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_INFRASTRUCTURE, "", "table", table)
+	default:
+		return log.ErrorWrapper(errors.ErrVersionMismatch, errors.ERR_INFRASTRUCTURE, "", "table", table, "version", version)
+	}
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type documentUsecase struct {
+	repo repositories.Repository
+}
+
+func NewDocumentUsecase(repo repositories.Repository) logic.DocumentUsecase {
+	return &documentUsecase{
+		repo: repo,
+	}
+}
+
+// RetakeSessionSheet implements logic.DocumentUsecase.
+// The sheet lists students holding a seat, waitlisted ones are left out.
+func (d *documentUsecase) RetakeSessionSheet(ctx context.Context, sessionID int64, teacherUUID string) ([]byte, error) {
+	filters := query.GetRetakeSessionsFilters{IDs: []int64{sessionID}}
+	if teacherUUID != "" {
+		filters.TeacherUUIDs = []string{teacherUUID}
+	}
+	sessions, err := d.repo.GetRetakeSessions(ctx, filters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(sessions) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	session := sessions[0]
+
+	bookings, err := d.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+		SessionIDs: []int64{sessionID},
+		Statuses:   []string{valueobjects.RetakeBookingBooked},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var debts []models.Debt
+	if len(bookings) > 0 {
+		debtIDs := make([]int64, len(bookings))
+		for i, booking := range bookings {
+			debtIDs[i] = booking.Debt.ID
+		}
+		// closed debts stay on the sheet, their grades are already in it
+		debts, err = d.repo.GetDebts(ctx, query.GetDebtsFilters{
+			DebtIDs:       debtIDs,
+			IncludeClosed: true,
+		})
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+	}
+	sort.Slice(debts, func(i, j int) bool {
+		return studentSortKey(debts[i]) < studentSortKey(debts[j])
+	})
+
+	sheet, err := d.repo.RenderRetakeSheet(ctx, models.RetakeSheet{
+		SessionID: session.ID,
+		Exam:      *session.Exam,
+		Teacher:   *session.Teacher,
+		Date:      session.Date,
+		Address:   session.Address,
+		Room:      session.Room,
+		Debts:     debts,
+		IssuedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return sheet, nil
+}
+
+// ReferralSlip implements logic.DocumentUsecase.
+func (d *documentUsecase) ReferralSlip(ctx context.Context, debtID int64, teacherUUID, studentUUID string) ([]byte, error) {
+	filters := query.GetDebtsFilters{DebtIDs: []int64{debtID}}
+	if teacherUUID != "" {
+		filters.TeacherUUIDs = []string{teacherUUID}
+	}
+	if studentUUID != "" {
+		filters.StudentUUIDs = []string{studentUUID}
+	}
+	debts, err := d.repo.GetDebts(ctx, filters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	if debts[0].Date == nil {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "debt has no retake date")
+	}
+
+	slip, err := d.repo.RenderReferralSlip(ctx, models.ReferralSlip{
+		Debt:     debts[0],
+		IssuedAt: time.Now(),
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return slip, nil
+}
+
+func studentSortKey(debt models.Debt) string {
+	if debt.Student == nil {
+		return ""
+	}
+
+	return debt.Student.LastName + " " + debt.Student.FirstName + " " + debt.Student.MiddleName
+}
@@ -0,0 +1,101 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func TestDocumentUsecaseRetakeSessionSheet(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewDocumentUsecase(f.repo)
+	other, otherDebt := f.addDebtor(t, "ivanova@example.com")
+
+	sessionID := f.addSession(t, f.examID, 1, time.Now().Add(24*time.Hour), time.Hour, time.Hour)
+	for _, booking := range []commands.CreateRetakeBooking{
+		{SessionID: sessionID, DebtID: f.debtID, StudentUUID: f.student, Status: valueobjects.RetakeBookingBooked},
+		{SessionID: sessionID, DebtID: otherDebt, StudentUUID: other, Status: valueobjects.RetakeBookingWaitlisted},
+	} {
+		if _, err := f.repo.CreateRetakeBooking(ctx, booking); err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		session int64
+		teacher string
+		want    error
+	}{
+		{"teacher of the session", sessionID, f.teacher, nil},
+		{"admin", sessionID, "", nil},
+		{"another teacher", sessionID, f.student, errors.ErroNoItemsFound},
+		{"unknown session", 1 << 40, "", errors.ErroNoItemsFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sheet, err := usecase.RetakeSessionSheet(ctx, tc.session, tc.teacher)
+			if tc.want != nil {
+				if !e.Is(err, tc.want) {
+					t.Errorf("RetakeSessionSheet: err = %v, want %v", err, tc.want)
+				}
+				return
+			}
+			if err != nil || !bytes.HasPrefix(sheet, []byte("%PDF-")) {
+				t.Errorf("RetakeSessionSheet = %.8q, %v, want a pdf", sheet, err)
+			}
+		})
+	}
+}
+
+func TestDocumentUsecaseReferralSlip(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewDocumentUsecase(f.repo)
+	_, unscheduled := f.addDebtor(t, "ivanova@example.com")
+
+	if err := f.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+		DebtID:      f.debtID,
+		Date:        time.Now().Add(24 * time.Hour),
+		Address:     "ауд. 101",
+		TeacherUUID: f.teacher,
+		StudentUUID: f.student,
+	}); err != nil {
+		t.Fatalf("UpdateDebt: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		debtID  int64
+		teacher string
+		student string
+		want    error
+	}{
+		{"student of the debt", f.debtID, "", f.student, nil},
+		{"teacher of the debt", f.debtID, f.teacher, "", nil},
+		{"admin", f.debtID, "", "", nil},
+		{"another student", f.debtID, "", f.teacher, errors.ErroNoItemsFound},
+		{"another teacher", f.debtID, f.student, "", errors.ErroNoItemsFound},
+		{"unknown debt", 1 << 40, "", "", errors.ErroNoItemsFound},
+		{"debt without a retake date", unscheduled, "", "", errors.ErrInvalidData},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			slip, err := usecase.ReferralSlip(ctx, tc.debtID, tc.teacher, tc.student)
+			if tc.want != nil {
+				if !e.Is(err, tc.want) {
+					t.Errorf("ReferralSlip: err = %v, want %v", err, tc.want)
+				}
+				return
+			}
+			if err != nil || !bytes.HasPrefix(slip, []byte("%PDF-")) {
+				t.Errorf("ReferralSlip = %.8q, %v, want a pdf", slip, err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
 	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
 	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
 	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
@@ -19,8 +20,13 @@ type examUsecase struct {
 
 // CreateExam implements logic.ExamUsecase.
 func (e *examUsecase) CreateExam(ctx context.Context, exam types.Exam) (int64, error) {
+	if exam.AssessmentType == "" {
+		exam.AssessmentType = valueobjects.AssessmentExam
+	}
+
 	id, err := e.repo.CreateExam(ctx, commands.CreateExam{
-		Name: exam.Name,
+		Name:           exam.Name,
+		AssessmentType: exam.AssessmentType,
 	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -36,50 +42,30 @@ func NewExamUsecase(repo repositories.Repository) logic.ExamUsecase {
 	}
 }
 
-// GetDebts implements logic.ExamUsecase.
-func (e *examUsecase) GetDebts(ctx context.Context, limit int64, offset int64) ([]types.Debt, error) {
-	debts, err := e.repo.GetDebts(ctx, query.GetDebtsFilters{})
+// GetDebts implements logic.ExamUsecase. Limit and offset of the filters
+// are left to exports, the page is made by the params.
+func (e *examUsecase) GetDebts(ctx context.Context, filters types.DebtFilters, params types.ListParams) (*types.Page[types.Debt], error) {
+	keyset, limit, err := listKeyset(query.DebtSortFields, params)
 	if err != nil {
 		return nil, err
 	}
-	if len(debts) == 0 {
-		return nil, errors.ErroNoItemsFound
-	}
 
-	result := make([]types.Debt, 0, len(debts))
+	debtFilters := debtsQuery(filters)
+	debtFilters.Limit, debtFilters.Offset = limit+1, 0
+	debtFilters.Keyset = keyset
 
-	for _, debt := range debts {
-		var group types.Group
-		if debt.Student.Group != nil {
-			group.ID = debt.Student.Group.ID
-			group.Name = debt.Student.Group.Name
-		}
-		result = append(result, types.Debt{
-			ID:   debt.ID,
-			Date: debt.Date,
-			Exam: &types.Exam{
-				ID:   debt.Exam.ID,
-				Name: debt.Exam.Name,
-			},
-			Student: &types.Student{
-				UUID:       debt.Student.UUID,
-				FirstName:  debt.Student.FirstName,
-				LastName:   debt.Student.LastName,
-				MiddleName: debt.Student.MiddleName,
-				Email:      debt.Student.Email,
-				Group:      &group,
-			},
-			Teacher: &types.Teacher{
-				UUID:       debt.Teacher.UUID,
-				FirstName:  debt.Teacher.FirstName,
-				LastName:   debt.Teacher.LastName,
-				MiddleName: debt.Teacher.MiddleName,
-				Email:      debt.Teacher.Email,
-			},
-		})
+	debts, err := e.repo.GetDebts(ctx, debtFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	total, err := e.repo.CountDebts(ctx, debtFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
 	}
 
-	return result, nil
+	return listPage(debts, query.DebtSortFields, keyset, limit, total, types.DebtFromDomain), nil
 }
 
 func (e *examUsecase) DeleteDebt(ctx context.Context, id int64) error {
@@ -132,6 +118,7 @@ func (e *examUsecase) UpdateDebt(ctx context.Context, debt types.Debt) error {
 		Date:        *debt.Date,
 		TeacherUUID: debt.Teacher.UUID,
 		StudentUUID: debt.Student.UUID,
+		Version:     debt.Version,
 	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
@@ -204,7 +191,7 @@ func (e *examUsecase) CreateDebt(ctx context.Context, debt types.Debt) (int64, e
 }
 
 func (e *examUsecase) GetDebt(ctx context.Context, id int64) (*types.Debt, error) {
-	debts, err := e.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{id}})
+	debts, err := e.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{id}, IncludeClosed: true})
 	if err != nil {
 		return nil, err
 	}
@@ -219,11 +206,13 @@ func (e *examUsecase) GetDebt(ctx context.Context, id int64) (*types.Debt, error
 	}
 
 	return &types.Debt{
-		ID:   id,
-		Date: debts[0].Date,
+		ID:       id,
+		Date:     debts[0].Date,
+		ClosedAt: debts[0].ClosedAt,
 		Exam: &types.Exam{
-			ID:   debts[0].Exam.ID,
-			Name: debts[0].Exam.Name,
+			ID:             debts[0].Exam.ID,
+			Name:           debts[0].Exam.Name,
+			AssessmentType: debts[0].Exam.AssessmentType,
 		},
 		Student: &types.Student{
 			UUID:       debts[0].Student.UUID,
@@ -269,31 +258,41 @@ func (e *examUsecase) GetExam(ctx context.Context, id int64) (*types.Exam, error
 }
 
 // GetExams implements logic.ExamUsecase.
-func (e *examUsecase) GetExams(ctx context.Context, limit int64, offset int64) ([]types.Exam, error) {
-	exams, err := e.repo.GetExams(ctx, query.GetExamsFilters{
-		Limit:  limit,
-		Offset: offset,
-	})
+func (e *examUsecase) GetExams(ctx context.Context, filters types.ExamFilters, params types.ListParams) (*types.Page[types.Exam], error) {
+	keyset, limit, err := listKeyset(query.ExamSortFields, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
 	}
 
-	result := make([]types.Exam, len(exams))
-	for i, exam := range exams {
-		result[i] = types.ExamFromDomain(&exam)
+	examFilters := query.GetExamsFilters{
+		IDs:             filters.IDs,
+		Names:           filters.Names,
+		AssessmentTypes: filters.AssessmentTypes,
+		Limit:           limit + 1,
+		Keyset:          keyset,
 	}
-
+	exams, err := e.repo.GetExams(ctx, examFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	total, err := e.repo.CountExams(ctx, examFilters)
 	if err != nil {
-		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
 	}
 
-	return result, nil
+	return listPage(exams, query.ExamSortFields, keyset, limit, total, types.ExamFromDomain), nil
 }
 
 // UpdateExam implements logic.ExamUsecase.
 func (e *examUsecase) UpdateExam(ctx context.Context, exam types.Exam) error {
-	err := e.repo.UpdateExam(ctx, commands.UpdateExamByID{ID: exam.ID, Name: exam.Name})
+	err := e.repo.UpdateExam(ctx, commands.UpdateExamByID{
+		ID:             exam.ID,
+		Name:           exam.Name,
+		AssessmentType: exam.AssessmentType,
+		Version:        exam.Version,
+	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return err
@@ -0,0 +1,239 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func TestExamUsecaseCreateExam(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewExamUsecase(repo)
+
+	id, err := usecase.CreateExam(ctx, types.Exam{Name: "Сети"})
+	if err != nil {
+		t.Fatalf("CreateExam: %v", err)
+	}
+
+	exam, err := usecase.GetExam(ctx, id)
+	if err != nil {
+		t.Fatalf("GetExam: %v", err)
+	}
+	if exam.Name != "Сети" || exam.AssessmentType != valueobjects.AssessmentExam {
+		t.Errorf("exam = %+v, want Сети with the default assessment type", exam)
+	}
+
+	if _, err := usecase.CreateExam(ctx, types.Exam{Name: "Сети", AssessmentType: "oral"}); err == nil {
+		t.Error("CreateExam with an unknown assessment type: want an error")
+	}
+}
+
+func TestExamUsecaseGetExams(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewExamUsecase(repo)
+
+	for _, name := range []string{"Алгебра", "Геометрия", "Физика"} {
+		if _, err := usecase.CreateExam(ctx, types.Exam{Name: name}); err != nil {
+			t.Fatalf("CreateExam(%s): %v", name, err)
+		}
+	}
+
+	page, err := usecase.GetExams(ctx, types.ExamFilters{}, types.ListParams{Sort: "-name", Limit: 2})
+	if err != nil {
+		t.Fatalf("GetExams: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Name != "Физика" || page.Items[1].Name != "Геометрия" || page.Total != 3 {
+		t.Errorf("GetExams sorted by -name = %+v, want Физика and Геометрия of 3", page)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("GetExams: want a next cursor")
+	}
+
+	next, err := usecase.GetExams(ctx, types.ExamFilters{}, types.ListParams{Sort: "-name", Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("GetExams of the next page: %v", err)
+	}
+	if len(next.Items) != 1 || next.Items[0].Name != "Алгебра" || next.NextCursor != "" || next.Total != 3 {
+		t.Errorf("GetExams of the next page = %+v, want the last page with Алгебра", next)
+	}
+
+	filtered, err := usecase.GetExams(ctx, types.ExamFilters{Names: []string{"Алгебра", "Физика"}}, types.ListParams{Sort: "name"})
+	if err != nil {
+		t.Fatalf("GetExams by names: %v", err)
+	}
+	if len(filtered.Items) != 2 || filtered.Items[0].Name != "Алгебра" || filtered.Total != 2 || filtered.NextCursor != "" {
+		t.Errorf("GetExams by names = %+v, want Алгебра and Физика", filtered)
+	}
+
+	for _, params := range []types.ListParams{
+		{Sort: "room"},
+		{Sort: "name,name"},
+		{Sort: "name", Cursor: page.NextCursor},
+		{Sort: "-name", Cursor: "not a cursor"},
+		{Limit: -1},
+	} {
+		if _, err := usecase.GetExams(ctx, types.ExamFilters{}, params); !e.Is(err, errors.ErrInvalidFilters) {
+			t.Errorf("GetExams(%+v) = %v, want %v", params, err, errors.ErrInvalidFilters)
+		}
+	}
+}
+
+func TestExamUsecaseUpdateExam(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewExamUsecase(f.repo)
+
+	if err := usecase.UpdateExam(ctx, types.Exam{ID: f.examID, Name: "СУБД"}); err != nil {
+		t.Fatalf("UpdateExam: %v", err)
+	}
+
+	exam, err := usecase.GetExam(ctx, f.examID)
+	if err != nil {
+		t.Fatalf("GetExam: %v", err)
+	}
+	// an empty assessment type keeps the stored one
+	if exam.Name != "СУБД" || exam.AssessmentType != valueobjects.AssessmentExam {
+		t.Errorf("exam = %+v, want СУБД with the stored assessment type", exam)
+	}
+}
+
+func TestExamUsecaseDeleteExam(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewExamUsecase(f.repo)
+
+	if err := usecase.DeleteExam(ctx, f.examID); err != nil {
+		t.Fatalf("DeleteExam of an exam with debts: %v", err)
+	}
+	if _, err := usecase.GetExam(ctx, f.examID); err == nil {
+		t.Error("GetExam of a deleted exam: want an error")
+	}
+
+	// the debt keeps the deleted exam, it is only listed on request
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}})
+	if err != nil || len(debts) != 0 {
+		t.Errorf("GetDebts = %+v, %v, want the debt of the deleted exam left out", debts, err)
+	}
+	debts, err = f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}, IncludeDeleted: true})
+	if err != nil || len(debts) != 1 || debts[0].Exam.Name != "Базы данных" {
+		t.Errorf("GetDebts with deleted = %+v, %v, want the debt of the deleted exam", debts, err)
+	}
+}
+
+func TestExamUsecaseCreateDebt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewExamUsecase(f.repo)
+
+	id, err := usecase.CreateDebt(ctx, types.Debt{
+		Exam:    &types.Exam{ID: f.examID},
+		Student: &types.Student{UUID: f.student},
+		Teacher: &types.Teacher{UUID: f.teacher},
+	})
+	if err != nil {
+		t.Fatalf("CreateDebt: %v", err)
+	}
+
+	debt, err := usecase.GetDebt(ctx, id)
+	if err != nil {
+		t.Fatalf("GetDebt: %v", err)
+	}
+	if debt.Exam.Name != "Базы данных" || debt.Student.Email != "petrov@example.com" || debt.Teacher.Email != "sidorova@example.com" {
+		t.Errorf("debt = %+v, want the fixture exam, student and teacher", debt)
+	}
+	if debt.Student.Group.ID != f.groupID || debt.Student.Group.Name != "ИВТ-41" {
+		t.Errorf("debt group = %+v, want the fixture group", debt.Student.Group)
+	}
+	// a new debt is not scheduled, the date is left for SetDate
+	if debt.Date != nil {
+		t.Errorf("debt date = %v, want none", debt.Date)
+	}
+
+	_, err = usecase.CreateDebt(ctx, types.Debt{
+		Exam:    &types.Exam{ID: f.examID},
+		Student: &types.Student{UUID: "00000000-0000-4000-8000-000000000000"},
+		Teacher: &types.Teacher{UUID: f.teacher},
+	})
+	if !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("CreateDebt for an unknown student = %v, want %v", err, errors.ErroNoItemsFound)
+	}
+}
+
+func TestExamUsecaseGetDebts(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewExamUsecase(repo)
+
+	page, err := usecase.GetDebts(ctx, types.DebtFilters{}, types.ListParams{})
+	if err != nil || len(page.Items) != 0 || page.Total != 0 {
+		t.Errorf("GetDebts of an empty repository = %+v, %v, want an empty page", page, err)
+	}
+
+	f := newFixture(t)
+	usecase = NewExamUsecase(f.repo)
+	if err := f.repo.CloseDebt(ctx, commands.CloseDebt{DebtID: f.debtID}); err != nil {
+		t.Fatalf("CloseDebt: %v", err)
+	}
+	page, err = usecase.GetDebts(ctx, types.DebtFilters{}, types.ListParams{})
+	if err != nil || len(page.Items) != 0 || page.Total != 0 {
+		t.Errorf("GetDebts with a closed debt only = %+v, %v, want an empty page", page, err)
+	}
+	page, err = usecase.GetDebts(ctx, types.DebtFilters{IncludeClosed: true}, types.ListParams{Sort: "-closed_at"})
+	if err != nil {
+		t.Fatalf("GetDebts with closed debts: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != f.debtID || page.Total != 1 {
+		t.Errorf("GetDebts with closed debts = %+v, want the closed debt", page)
+	}
+
+	// a closed debt is still found by id
+	debt, err := usecase.GetDebt(ctx, f.debtID)
+	if err != nil {
+		t.Fatalf("GetDebt: %v", err)
+	}
+	if debt.ClosedAt == nil {
+		t.Error("GetDebt of a closed debt: want ClosedAt set")
+	}
+}
+
+func TestExamUsecaseUpdateDebt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewExamUsecase(f.repo)
+
+	other, err := f.repo.CreateTeacher(ctx, commands.CreateTeacher{
+		FirstName: "Олег",
+		LastName:  "Орлов",
+		Email:     "orlov@example.com",
+	})
+	if err != nil {
+		t.Fatalf("CreateTeacher: %v", err)
+	}
+
+	if err := usecase.UpdateDebt(ctx, types.Debt{
+		ID:      f.debtID,
+		Student: &types.Student{UUID: f.student},
+		Teacher: &types.Teacher{UUID: other},
+	}); err != nil {
+		t.Fatalf("UpdateDebt: %v", err)
+	}
+
+	debt, err := usecase.GetDebt(ctx, f.debtID)
+	if err != nil {
+		t.Fatalf("GetDebt: %v", err)
+	}
+	if debt.Teacher.UUID != other {
+		t.Errorf("debt teacher = %s, want %s", debt.Teacher.UUID, other)
+	}
+	if debt.Date == nil {
+		t.Error("UpdateDebt without a date: want the current date set")
+	}
+}
@@ -0,0 +1,382 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// BulkImport implements logic.FileUsecase.
+// Records are created the same way the workbook import creates them and
+// each one carries its own error, so a bad record does not stop the batch.
+// Sending the batch again creates nothing new. The batch is an import job
+// run by the request: it is in the history, failed records are its errors
+// named by the list and the position in it, and RollbackImportJob undoes
+// what it created.
+func (fu *fileUsecase) BulkImport(ctx context.Context, batch types.BulkImport) (*types.BulkImportResult, error) {
+	total := len(batch.Groups) + len(batch.Teachers) + len(batch.Students) + len(batch.Debts)
+	id, err := fu.repo.CreateImportJob(ctx, commands.CreateImportJob{
+		Source: valueobjects.ImportBulkSource,
+		Actor:  batch.Actor,
+		Total:  int64(total),
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	renewing, stop := context.WithCancel(ctx)
+	defer stop()
+	go fu.renewImportJob(renewing, id)
+
+	result := &types.BulkImportResult{
+		JobID:    id,
+		Groups:   make([]types.BulkRecordResult, len(batch.Groups)),
+		Teachers: make([]types.BulkRecordResult, len(batch.Teachers)),
+		Students: make([]types.BulkRecordResult, len(batch.Students)),
+		Debts:    make([]types.BulkRecordResult, len(batch.Debts)),
+	}
+
+	// the job is finished even when the client has gone
+	finishing := context.WithoutCancel(ctx)
+	if err := fu.bulkImport(ctx, id, batch, result); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName(), "job", id)
+		fu.updateImportJob(finishing, commands.UpdateImportJob{
+			ID:     id,
+			Status: valueobjects.ImportFailedStatus,
+			Errors: []models.ImportIssue{{Message: err.Error()}},
+		})
+		return nil, err
+	}
+
+	update := commands.UpdateImportJob{
+		ID:        id,
+		Status:    valueobjects.ImportCompletedStatus,
+		Processed: int64(total),
+		Errors:    make([]models.ImportIssue, 0),
+	}
+	for _, list := range []struct {
+		name    string
+		records []types.BulkRecordResult
+	}{
+		{"groups", result.Groups},
+		{"teachers", result.Teachers},
+		{"students", result.Students},
+		{"debts", result.Debts},
+	} {
+		for i, record := range list.records {
+			switch {
+			case record.Err != nil:
+				result.Failed++
+				update.Errors = append(update.Errors, models.ImportIssue{
+					Sheet:   list.name,
+					Row:     int64(i + 1),
+					Message: record.Err.Error(),
+				})
+			case record.Created:
+				result.Created++
+			default:
+				result.Existing++
+			}
+		}
+	}
+	update.Created = int64(result.Created)
+	update.Existing = int64(result.Existing)
+	update.Failed = int64(result.Failed)
+	fu.updateImportJob(finishing, update)
+
+	return result, nil
+}
+
+func (fu *fileUsecase) bulkImport(ctx context.Context, jobID int64, batch types.BulkImport, result *types.BulkImportResult) error {
+	if err := fu.bulkImportGroups(ctx, jobID, batch.Groups, result.Groups); err != nil {
+		return err
+	}
+	if err := fu.bulkImportTeachers(ctx, jobID, batch.Teachers, result.Teachers); err != nil {
+		return err
+	}
+	if err := fu.bulkImportStudents(ctx, jobID, batch.Students, result.Students); err != nil {
+		return err
+	}
+
+	return fu.bulkImportDebts(ctx, jobID, batch.Debts, result.Debts)
+}
+
+func (fu *fileUsecase) bulkImportGroups(ctx context.Context, jobID int64, groups []types.Group, results []types.BulkRecordResult) error {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if name := strings.TrimSpace(group.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	stored := make(map[string]int64)
+	if len(names) > 0 {
+		found, err := fu.repo.SearchGroups(ctx, query.SearchGroupFilters{Names: names})
+		if err != nil {
+			return err
+		}
+		for _, group := range found {
+			stored[group.Name] = group.ID
+		}
+	}
+
+	for i, group := range groups {
+		name := strings.TrimSpace(group.Name)
+		switch id, ok := stored[name]; {
+		case name == "":
+			results[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "missing group name")
+		case ok:
+			results[i].ID = id
+		default:
+			results[i].Err = fu.trackImport(ctx, jobID, func(ctx context.Context, created *importEntities) error {
+				var err error
+				results[i].ID, err = fu.CreateGroupIfNotExists(ctx, types.Group{Name: name}, created)
+				return err
+			})
+			results[i].Created = results[i].Err == nil
+			if results[i].Created {
+				stored[name] = results[i].ID
+			}
+		}
+	}
+
+	return nil
+}
+
+func (fu *fileUsecase) bulkImportTeachers(ctx context.Context, jobID int64, teachers []types.Teacher, results []types.BulkRecordResult) error {
+	emails := make([]string, 0, len(teachers))
+	for _, teacher := range teachers {
+		if email := strings.TrimSpace(teacher.Email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	stored := make(map[string]string)
+	if len(emails) > 0 {
+		found, err := fu.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: emails})
+		if err != nil {
+			return err
+		}
+		for _, teacher := range found {
+			stored[teacher.Email] = teacher.UUID
+		}
+	}
+
+	for i, teacher := range teachers {
+		person := importPerson{
+			lastName:   strings.TrimSpace(teacher.LastName),
+			firstName:  strings.TrimSpace(teacher.FirstName),
+			middleName: strings.TrimSpace(teacher.MiddleName),
+			email:      strings.TrimSpace(teacher.Email),
+		}
+		_, msg := checkImportPerson(person, false)
+		switch uuid, ok := stored[person.email]; {
+		case msg != "":
+			results[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, msg)
+		case ok:
+			results[i].UUID = uuid
+		default:
+			results[i].Err = fu.trackImport(ctx, jobID, func(ctx context.Context, created *importEntities) error {
+				var err error
+				results[i].UUID, err = fu.CreateTeacherIfNotExists(ctx, types.Teacher{
+					LastName:   person.lastName,
+					FirstName:  person.firstName,
+					MiddleName: person.middleName,
+					Email:      person.email,
+				}, created)
+				return err
+			})
+			results[i].Created = results[i].Err == nil
+			if results[i].Created {
+				stored[person.email] = results[i].UUID
+			}
+		}
+	}
+
+	return nil
+}
+
+func (fu *fileUsecase) bulkImportStudents(ctx context.Context, jobID int64, students []types.Student, results []types.BulkRecordResult) error {
+	emails := make([]string, 0, len(students))
+	for _, student := range students {
+		if email := strings.TrimSpace(student.Email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	stored := make(map[string]string)
+	if len(emails) > 0 {
+		found, err := fu.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: emails})
+		if err != nil {
+			return err
+		}
+		for _, student := range found {
+			stored[student.Email] = student.UUID
+		}
+	}
+
+	for i, student := range students {
+		person := importPerson{
+			lastName:   strings.TrimSpace(student.LastName),
+			firstName:  strings.TrimSpace(student.FirstName),
+			middleName: strings.TrimSpace(student.MiddleName),
+			email:      strings.TrimSpace(student.Email),
+		}
+		if student.Group != nil {
+			person.group = strings.TrimSpace(student.Group.Name)
+		}
+		_, msg := checkImportPerson(person, true)
+		switch uuid, ok := stored[person.email]; {
+		case msg != "":
+			results[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, msg)
+		case ok:
+			results[i].UUID = uuid
+		default:
+			results[i].Err = fu.trackImport(ctx, jobID, func(ctx context.Context, created *importEntities) error {
+				var err error
+				results[i].UUID, err = fu.CreateStudentIfNotExists(ctx, types.Student{
+					LastName:   person.lastName,
+					FirstName:  person.firstName,
+					MiddleName: person.middleName,
+					Email:      person.email,
+					Group:      &types.Group{Name: person.group},
+				}, created)
+				return err
+			})
+			results[i].Created = results[i].Err == nil
+			if results[i].Created {
+				stored[person.email] = results[i].UUID
+			}
+		}
+	}
+
+	return nil
+}
+
+// bulkImportDebts needs the students and the teachers to be stored, a debt
+// does not carry enough to create them.
+func (fu *fileUsecase) bulkImportDebts(ctx context.Context, jobID int64, debts []types.Debt, results []types.BulkRecordResult) error {
+	exams, studentEmails, teacherEmails := newNameSet(), newNameSet(), newNameSet()
+	keys := make([]string, len(debts))
+	for i, debt := range debts {
+		exam, student, teacher := bulkDebtKeys(debt)
+		if exam == "" || student == "" || teacher == "" {
+			continue
+		}
+		exams.add(exam)
+		studentEmails.add(student)
+		teacherEmails.add(teacher)
+		keys[i] = debtKey(exam, student, teacher)
+	}
+	if len(exams.names) == 0 {
+		for i := range debts {
+			results[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "missing exam, student email or teacher email")
+		}
+		return nil
+	}
+
+	students, err := fu.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: studentEmails.names})
+	if err != nil {
+		return err
+	}
+	studentUUIDs := make(map[string]string)
+	for _, student := range students {
+		studentUUIDs[student.Email] = student.UUID
+	}
+
+	teachers, err := fu.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: teacherEmails.names})
+	if err != nil {
+		return err
+	}
+	teacherUUIDs := make(map[string]string)
+	for _, teacher := range teachers {
+		teacherUUIDs[teacher.Email] = teacher.UUID
+	}
+
+	// the filters match every combination of the lists, the exact triples
+	// are picked by the key
+	found, err := fu.repo.SearchDebts(ctx, query.SearchDebtsFilters{
+		ExamNames:     exams.names,
+		StudentEmails: studentEmails.names,
+		TeacherEmails: teacherEmails.names,
+	})
+	if err != nil {
+		return err
+	}
+	stored := make(map[string]int64)
+	for _, debt := range found {
+		stored[debtKey(debt.Exam.Name, debt.Student.Email, debt.Teacher.Email)] = debt.ID
+	}
+
+	for i, debt := range debts {
+		exam, student, teacher := bulkDebtKeys(debt)
+		id, ok := stored[keys[i]]
+		switch {
+		case keys[i] == "":
+			results[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "missing exam, student email or teacher email")
+		case ok:
+			results[i].ID = id
+		case studentUUIDs[student] == "":
+			results[i].Err = log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, fmt.Sprintf("student %s is not found", student))
+		case teacherUUIDs[teacher] == "":
+			results[i].Err = log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, fmt.Sprintf("teacher %s is not found", teacher))
+		default:
+			results[i].Err = fu.trackImport(ctx, jobID, func(ctx context.Context, created *importEntities) error {
+				var err error
+				results[i].ID, err = fu.createDebt(ctx, exam, studentUUIDs[student], teacherUUIDs[teacher], created)
+				return err
+			})
+			results[i].Created = results[i].Err == nil
+			if results[i].Created {
+				stored[keys[i]] = results[i].ID
+			}
+		}
+	}
+
+	return nil
+}
+
+func (fu *fileUsecase) createDebt(ctx context.Context, examName, studentUUID, teacherUUID string, created *importEntities) (int64, error) {
+	examID, err := fu.CreateExamIfNotExists(ctx, types.Exam{Name: examName}, created)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+
+	id, err := fu.repo.CreateDebt(ctx, commands.CreateDebt{
+		ExamID:      examID,
+		StudentUUID: studentUUID,
+		TeacherUUID: teacherUUID,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+	created.add(valueobjects.ImportDebtEntity, strconv.FormatInt(id, 10))
+
+	return id, nil
+}
+
+// bulkDebtKeys returns the exam name and the emails a debt of a batch
+// refers to.
+func bulkDebtKeys(debt types.Debt) (string, string, string) {
+	var exam, student, teacher string
+	if debt.Exam != nil {
+		exam = strings.TrimSpace(debt.Exam.Name)
+	}
+	if debt.Student != nil {
+		student = strings.TrimSpace(debt.Student.Email)
+	}
+	if debt.Teacher != nil {
+		teacher = strings.TrimSpace(debt.Teacher.Email)
+	}
+
+	return exam, student, teacher
+}
@@ -0,0 +1,432 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// noGroupSheet collects students that are not in any group.
+const noGroupSheet = "Без группы"
+
+// ExportDebts implements logic.FileUsecase.
+func (fu *fileUsecase) ExportDebts(ctx context.Context, filters types.DebtFilters, layout string) (*excelize.File, error) {
+	if layout != types.ExportListLayout && layout != types.ExportMatrixLayout {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "unknown layout", "layout", layout)
+	}
+
+	debts, err := fu.getDebts(ctx, filters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	book, err := newWorkbook()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	for _, group := range debtsByGroup(debts) {
+		if layout == types.ExportMatrixLayout {
+			err = book.writeDebtsMatrix(group.name, group.debts)
+		} else {
+			err = book.writeDebtsList(group.name, group.debts)
+		}
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, err
+		}
+	}
+
+	return book.file, nil
+}
+
+// ExportTimetable implements logic.FileUsecase.
+// Only scheduled debts are exported, ordered by the retake date.
+func (fu *fileUsecase) ExportTimetable(ctx context.Context, filters types.DebtFilters) (*excelize.File, error) {
+	debts, err := fu.getDebts(ctx, filters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	scheduled := make([]models.Debt, 0, len(debts))
+	for _, debt := range debts {
+		if debt.Date != nil {
+			scheduled = append(scheduled, debt)
+		}
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].Date.Before(*scheduled[j].Date)
+	})
+
+	book, err := newWorkbook()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	for _, group := range debtsByGroup(scheduled) {
+		rows := make([][]any, len(group.debts))
+		for i, debt := range group.debts {
+			rows[i] = []any{
+				debt.Date.Format("02.01.2006"),
+				debt.Date.Format("15:04"),
+				debt.Exam.Name,
+				debt.Exam.AssessmentType,
+				personName(debt.Teacher.LastName, debt.Teacher.FirstName, debt.Teacher.MiddleName),
+				personName(debt.Student.LastName, debt.Student.FirstName, debt.Student.MiddleName),
+				debtPlace(debt),
+			}
+		}
+		header := []any{"Дата", "Время", "Дисциплина", "Форма контроля", "Преподаватель", "Студент", "Место"}
+		if err := book.writeTable(group.name, header, rows); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, err
+		}
+	}
+
+	return book.file, nil
+}
+
+// ExportStudents implements logic.FileUsecase.
+// Empty groupIDs export every group.
+func (fu *fileUsecase) ExportStudents(ctx context.Context, groupIDs []int64) (*excelize.File, error) {
+	if len(groupIDs) == 0 {
+		groups, err := fu.repo.GetGroups(ctx, query.GetGroupsFilters{})
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	book, err := newWorkbook()
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if len(groupIDs) == 0 {
+		return book.file, nil
+	}
+
+	students, err := fu.repo.GetStudents(ctx, query.GetStudentsFilters{GroupIDs: groupIDs})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	debts, err := fu.repo.GetDebts(ctx, query.GetDebtsFilters{GroupIDs: groupIDs})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	debtCount := make(map[string]int, len(students))
+	for _, debt := range debts {
+		debtCount[debt.Student.UUID]++
+	}
+
+	byGroup := make(map[string][]models.Student)
+	for _, student := range students {
+		name := noGroupSheet
+		if student.Group != nil && student.Group.Name != "" {
+			name = student.Group.Name
+		}
+		byGroup[name] = append(byGroup[name], student)
+	}
+
+	for _, name := range sortedKeys(byGroup) {
+		group := byGroup[name]
+		sort.SliceStable(group, func(i, j int) bool {
+			return personName(group[i].LastName, group[i].FirstName, group[i].MiddleName) <
+				personName(group[j].LastName, group[j].FirstName, group[j].MiddleName)
+		})
+
+		rows := make([][]any, len(group))
+		for i, student := range group {
+			rows[i] = []any{
+				personName(student.LastName, student.FirstName, student.MiddleName),
+				student.Email,
+				name,
+				debtCount[student.UUID],
+			}
+		}
+		if err := book.writeTable(name, []any{"Студент", "Email", "Группа", "Долгов"}, rows); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, err
+		}
+	}
+
+	return book.file, nil
+}
+
+func (fu *fileUsecase) getDebts(ctx context.Context, filters types.DebtFilters) ([]models.Debt, error) {
+	debts, err := fu.repo.GetDebts(ctx, debtsQuery(filters))
+	if err != nil {
+		if e.Is(err, errors.ErrInvalidFilters) {
+			return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, err.Error())
+		}
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return debts, nil
+}
+
+type debtGroup struct {
+	name  string
+	debts []models.Debt
+}
+
+// debtsByGroup splits debts by the group of the student, groups are ordered
+// by name and keep the order of their debts.
+func debtsByGroup(debts []models.Debt) []debtGroup {
+	byName := make(map[string][]models.Debt)
+	for _, debt := range debts {
+		name := noGroupSheet
+		if debt.Student != nil && debt.Student.Group != nil && debt.Student.Group.Name != "" {
+			name = debt.Student.Group.Name
+		}
+		byName[name] = append(byName[name], debt)
+	}
+
+	result := make([]debtGroup, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		result = append(result, debtGroup{name: name, debts: byName[name]})
+	}
+
+	return result
+}
+
+// workbook writes sheets into an excelize file, the first sheet replaces the
+// default empty one.
+type workbook struct {
+	file   *excelize.File
+	sheets map[string]bool
+	header int
+}
+
+func newWorkbook() (*workbook, error) {
+	file := excelize.NewFile()
+	header, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_APPLICATION, "can not create style")
+	}
+
+	return &workbook{
+		file:   file,
+		sheets: make(map[string]bool),
+		header: header,
+	}, nil
+}
+
+// addSheet returns the name the sheet got, excel limits names to 31
+// characters and forbids some of them.
+func (w *workbook) addSheet(title string) (string, error) {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if name == "" {
+		name = noGroupSheet
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	for i := 2; w.sheets[name]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		runes := []rune(name)
+		if len(runes)+len(suffix) > 31 {
+			runes = runes[:31-len(suffix)]
+		}
+		name = string(runes) + suffix
+	}
+
+	var err error
+	if len(w.sheets) == 0 {
+		err = w.file.SetSheetName(w.file.GetSheetName(0), name)
+	} else {
+		_, err = w.file.NewSheet(name)
+	}
+	if err != nil {
+		return "", log.ErrorWrapper(err, errors.ERR_APPLICATION, "can not create sheet", "sheet", name)
+	}
+	w.sheets[name] = true
+
+	return name, nil
+}
+
+func (w *workbook) writeTable(title string, header []any, rows [][]any) error {
+	sheet, err := w.addSheet(title)
+	if err != nil {
+		return err
+	}
+
+	if err := w.file.SetSheetRow(sheet, "A1", &header); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+	}
+	last, err := excelize.CoordinatesToCellName(len(header), 1)
+	if err != nil {
+		return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+	}
+	if err := w.file.SetCellStyle(sheet, "A1", last, w.header); err != nil {
+		return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+		}
+		if err := w.file.SetSheetRow(sheet, cell, &row); err != nil {
+			return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+		}
+	}
+
+	return nil
+}
+
+func (w *workbook) writeDebtsList(title string, debts []models.Debt) error {
+	rows := make([][]any, len(debts))
+	for i, debt := range debts {
+		var date, closed string
+		if debt.Date != nil {
+			date = debt.Date.Format(valueobjects.DateLayout)
+		}
+		if debt.ClosedAt != nil {
+			closed = debt.ClosedAt.Format(valueobjects.DateLayout)
+		}
+		rows[i] = []any{
+			debt.ID,
+			personName(debt.Student.LastName, debt.Student.FirstName, debt.Student.MiddleName),
+			debt.Student.Email,
+			title,
+			debt.Exam.Name,
+			debt.Exam.AssessmentType,
+			personName(debt.Teacher.LastName, debt.Teacher.FirstName, debt.Teacher.MiddleName),
+			debt.Teacher.Email,
+			date,
+			debtPlace(debt),
+			closed,
+		}
+	}
+
+	return w.writeTable(title, []any{
+		"ID",
+		"Студент",
+		"Email студента",
+		"Группа",
+		"Дисциплина",
+		"Форма контроля",
+		"Преподаватель",
+		"Email преподавателя",
+		"Дата пересдачи",
+		"Место",
+		"Закрыт",
+	}, rows)
+}
+
+// writeDebtsMatrix writes debts in the import format. Headers are the space
+// separated fields of the default import profile, a teacher owed several
+// exams by one student gets one more row for every extra exam.
+func (w *workbook) writeDebtsMatrix(title string, debts []models.Debt) error {
+	var (
+		studentIDs = make([]string, 0)
+		teacherIDs = make([]string, 0)
+		students   = make(map[string]string)
+		teachers   = make(map[string]string)
+		exams      = make(map[string]map[string][]string)
+	)
+	for _, debt := range debts {
+		if _, ok := students[debt.Student.UUID]; !ok {
+			var group string
+			if debt.Student.Group != nil {
+				group = debt.Student.Group.Name
+			}
+			students[debt.Student.UUID] = strings.Join([]string{
+				debt.Student.LastName,
+				debt.Student.FirstName,
+				debt.Student.MiddleName,
+				group,
+				debt.Student.Email,
+			}, " ")
+			studentIDs = append(studentIDs, debt.Student.UUID)
+		}
+		if _, ok := teachers[debt.Teacher.UUID]; !ok {
+			teachers[debt.Teacher.UUID] = strings.Join([]string{
+				debt.Teacher.LastName,
+				debt.Teacher.FirstName,
+				debt.Teacher.MiddleName,
+				debt.Teacher.Email,
+			}, " ")
+			teacherIDs = append(teacherIDs, debt.Teacher.UUID)
+			exams[debt.Teacher.UUID] = make(map[string][]string)
+		}
+		exams[debt.Teacher.UUID][debt.Student.UUID] = append(exams[debt.Teacher.UUID][debt.Student.UUID], debt.Exam.Name)
+	}
+	sort.SliceStable(studentIDs, func(i, j int) bool { return students[studentIDs[i]] < students[studentIDs[j]] })
+	sort.SliceStable(teacherIDs, func(i, j int) bool { return teachers[teacherIDs[i]] < teachers[teacherIDs[j]] })
+
+	header := make([]any, 0, len(studentIDs)+1)
+	header = append(header, "Преподаватель \\ Студент")
+	for _, id := range studentIDs {
+		header = append(header, students[id])
+	}
+
+	rows := make([][]any, 0, len(teacherIDs))
+	for _, teacherID := range teacherIDs {
+		var lines int
+		for _, names := range exams[teacherID] {
+			sort.Strings(names)
+			lines = max(lines, len(names))
+		}
+
+		for line := 0; line < lines; line++ {
+			row := make([]any, len(studentIDs)+1)
+			row[0] = teachers[teacherID]
+			for i, studentID := range studentIDs {
+				row[i+1] = ""
+				if names := exams[teacherID][studentID]; line < len(names) {
+					row[i+1] = names[line]
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return w.writeTable(title, header, rows)
+}
+
+func personName(lastName, firstName, middleName string) string {
+	return strings.Join(strings.Fields(lastName+" "+firstName+" "+middleName), " ")
+}
+
+func debtPlace(debt models.Debt) string {
+	if debt.Address != "" || debt.Room == nil {
+		return debt.Address
+	}
+
+	return roomAddress(debt.Room)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,387 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/generator"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// PreviewImport implements logic.FileUsecase.
+// Nothing is written except the preview itself, the records are imported
+// by ConfirmImport with the returned token. profileID 0 is the default
+// matrix profile.
+func (fu *fileUsecase) PreviewImport(ctx context.Context, adminUUID string, profileID int64, f *excelize.File) (*types.ImportReport, error) {
+	profile, err := fu.importProfile(ctx, profileID)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	records, issues, err := parseWorkbook(f, *profile)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	token, err := generator.GenerateToken(generator.TOKENLEN)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_APPLICATION, "can not generate token")
+	}
+
+	if err := fu.repo.CreateImportPreview(ctx, commands.CreateImportPreview{
+		Token:     token,
+		CreatedBy: adminUUID,
+		ProfileID: profileID,
+		Records:   records,
+		Issues:    issues,
+		ExpiresAt: time.Now().Add(valueobjects.ImportPreviewTTL),
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return fu.GetImportPreview(ctx, token)
+}
+
+// GetImportPreview implements logic.FileUsecase.
+// The diff is built against the current state, so it shows what a
+// confirmation would do now rather than at the time of the upload.
+func (fu *fileUsecase) GetImportPreview(ctx context.Context, token string) (*types.ImportReport, error) {
+	preview, err := fu.repo.GetImportPreview(ctx, token)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	diff, err := fu.diffImport(ctx, preview.Records)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	report := types.ImportReport{
+		Token:         preview.Token,
+		CreatedBy:     preview.CreatedBy,
+		ProfileID:     preview.ProfileID,
+		CreatedAt:     preview.CreatedAt,
+		ExpiresAt:     preview.ExpiresAt,
+		ConfirmedAt:   preview.ConfirmedAt,
+		Groups:        diff.groups,
+		Students:      diff.students,
+		Teachers:      diff.teachers,
+		Exams:         diff.exams,
+		NewDebts:      make([]types.ImportRecord, 0),
+		ExistingDebts: make([]types.ImportRecord, 0),
+		Issues:        make([]types.ImportIssue, len(preview.Issues)),
+	}
+	for _, record := range preview.Records {
+		if _, ok := diff.debts[importDebtKey(record)]; ok {
+			report.ExistingDebts = append(report.ExistingDebts, types.ImportRecordFromDomain(&record))
+		} else {
+			report.NewDebts = append(report.NewDebts, types.ImportRecordFromDomain(&record))
+		}
+	}
+	for i, issue := range preview.Issues {
+		report.Issues[i] = types.ImportIssueFromDomain(&issue)
+	}
+
+	return &report, nil
+}
+
+// ConfirmImport implements logic.FileUsecase.
+// The preview is closed together with queueing the job, so a token is
+// imported once.
+func (fu *fileUsecase) ConfirmImport(ctx context.Context, adminUUID, token string) (*types.ImportJob, error) {
+	var id int64
+	err := fu.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		preview, err := fu.repo.GetImportPreview(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if err := fu.repo.ConfirmImportPreview(ctx, commands.ConfirmImportPreview{Token: token}); err != nil {
+			return err
+		}
+
+		var profileID int64
+		if preview.ProfileID != nil {
+			profileID = *preview.ProfileID
+		}
+		id, err = fu.repo.CreateImportJob(ctx, commands.CreateImportJob{
+			Source:       valueobjects.ImportPreviewSource,
+			Actor:        adminUUID,
+			ProfileID:    profileID,
+			PreviewToken: token,
+			Records:      preview.Records,
+			Issues:       preview.Issues,
+		})
+		return err
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	fu.wakeImportJobs()
+
+	return fu.GetImportJob(ctx, id)
+}
+
+type importDiff struct {
+	groups   types.ImportDiff
+	students types.ImportDiff
+	teachers types.ImportDiff
+	exams    types.ImportDiff
+	// ids of stored debts by importDebtKey
+	debts map[string]int64
+}
+
+// diffImport looks up every group, student, teacher, exam and debt of the
+// records with one query per kind.
+func (fu *fileUsecase) diffImport(ctx context.Context, records []models.ImportRecord) (*importDiff, error) {
+	groups, students, teachers, exams := newNameSet(), newNameSet(), newNameSet(), newNameSet()
+	for _, record := range records {
+		groups.add(record.GroupName)
+		students.add(record.StudentEmail)
+		teachers.add(record.TeacherEmail)
+		exams.add(record.ExamName)
+	}
+
+	diff := &importDiff{debts: make(map[string]int64)}
+	if len(records) == 0 {
+		return diff, nil
+	}
+
+	storedGroups, err := fu.repo.SearchGroups(ctx, query.SearchGroupFilters{Names: groups.names})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	stored := make(map[string]bool)
+	for _, group := range storedGroups {
+		stored[group.Name] = true
+	}
+	diff.groups = groups.split(stored)
+
+	storedStudents, err := fu.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: students.names})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	stored = make(map[string]bool)
+	for _, student := range storedStudents {
+		stored[student.Email] = true
+	}
+	diff.students = students.split(stored)
+
+	storedTeachers, err := fu.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: teachers.names})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	stored = make(map[string]bool)
+	for _, teacher := range storedTeachers {
+		stored[teacher.Email] = true
+	}
+	diff.teachers = teachers.split(stored)
+
+	storedExams, err := fu.repo.SearchExams(ctx, query.SearchExamFilters{Names: exams.names})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	stored = make(map[string]bool)
+	for _, exam := range storedExams {
+		stored[exam.Name] = true
+	}
+	diff.exams = exams.split(stored)
+
+	// the filters match every combination of the lists, the exact triples
+	// are picked by the key
+	storedDebts, err := fu.repo.SearchDebts(ctx, query.SearchDebtsFilters{
+		ExamNames:     exams.names,
+		StudentEmails: students.names,
+		TeacherEmails: teachers.names,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	for _, debt := range storedDebts {
+		diff.debts[debtKey(debt.Exam.Name, debt.Student.Email, debt.Teacher.Email)] = debt.ID
+	}
+
+	return diff, nil
+}
+
+// nameSet keeps unique names in the order they were added.
+type nameSet struct {
+	names []string
+	seen  map[string]bool
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{names: make([]string, 0), seen: make(map[string]bool)}
+}
+
+func (s *nameSet) add(name string) {
+	if !s.seen[name] {
+		s.seen[name] = true
+		s.names = append(s.names, name)
+	}
+}
+
+func (s *nameSet) split(stored map[string]bool) types.ImportDiff {
+	diff := types.ImportDiff{New: make([]string, 0), Existing: make([]string, 0)}
+	for _, name := range s.names {
+		if stored[name] {
+			diff.Existing = append(diff.Existing, name)
+		} else {
+			diff.New = append(diff.New, name)
+		}
+	}
+
+	return diff
+}
+
+func debtKey(examName, studentEmail, teacherEmail string) string {
+	return examName + "\x00" + studentEmail + "\x00" + teacherEmail
+}
+
+func importDebtKey(record models.ImportRecord) string {
+	return debtKey(record.ExamName, record.StudentEmail, record.TeacherEmail)
+}
+
+func importRecordDebt(record models.ImportRecord) types.Debt {
+	return types.Debt{
+		Exam: &types.Exam{Name: record.ExamName},
+		Student: &types.Student{
+			LastName:   record.StudentLastName,
+			FirstName:  record.StudentFirstName,
+			MiddleName: record.StudentMiddleName,
+			Email:      record.StudentEmail,
+			Group:      &types.Group{Name: record.GroupName},
+		},
+		Teacher: &types.Teacher{
+			LastName:   record.TeacherLastName,
+			FirstName:  record.TeacherFirstName,
+			MiddleName: record.TeacherMiddleName,
+			Email:      record.TeacherEmail,
+		},
+	}
+}
+
+// importProfile returns the stored profile, id 0 is the default one.
+func (fu *fileUsecase) importProfile(ctx context.Context, id int64) (*models.ImportProfile, error) {
+	if id == 0 {
+		profile := defaultImportProfile
+		return &profile, nil
+	}
+
+	profiles, err := fu.repo.GetImportProfiles(ctx, query.GetImportProfilesFilters{IDs: []int64{id}})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "import profile is not found")
+	}
+
+	return &profiles[0], nil
+}
+
+// CreateImportProfile implements logic.FileUsecase.
+func (fu *fileUsecase) CreateImportProfile(ctx context.Context, profile types.ImportProfile) (int64, error) {
+	id, err := fu.repo.CreateImportProfile(ctx, commands.CreateImportProfile{
+		Name:          profile.Name,
+		Layout:        profile.Layout,
+		Sheets:        profile.Sheets,
+		HeaderRow:     profile.HeaderRow,
+		Separator:     profile.Separator,
+		StudentFields: profile.StudentFields,
+		TeacherFields: profile.TeacherFields,
+		Columns:       profile.Columns,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateImportProfile implements logic.FileUsecase.
+func (fu *fileUsecase) UpdateImportProfile(ctx context.Context, profile types.ImportProfile) error {
+	if err := fu.repo.UpdateImportProfile(ctx, commands.UpdateImportProfile{
+		ID:            profile.ID,
+		Name:          profile.Name,
+		Layout:        profile.Layout,
+		Sheets:        profile.Sheets,
+		HeaderRow:     profile.HeaderRow,
+		Separator:     profile.Separator,
+		StudentFields: profile.StudentFields,
+		TeacherFields: profile.TeacherFields,
+		Columns:       profile.Columns,
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteImportProfile implements logic.FileUsecase.
+func (fu *fileUsecase) DeleteImportProfile(ctx context.Context, id int64) error {
+	if err := fu.repo.DeleteImportProfile(ctx, commands.DeleteImportProfile{ID: id}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// GetImportProfile implements logic.FileUsecase.
+func (fu *fileUsecase) GetImportProfile(ctx context.Context, id int64) (*types.ImportProfile, error) {
+	if id == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+
+	profile, err := fu.importProfile(ctx, id)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := types.ImportProfileFromDomain(profile)
+
+	return &result, nil
+}
+
+// GetImportProfiles implements logic.FileUsecase.
+func (fu *fileUsecase) GetImportProfiles(ctx context.Context, limit, offset int64) ([]types.ImportProfile, error) {
+	profiles, err := fu.repo.GetImportProfiles(ctx, query.GetImportProfilesFilters{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := make([]types.ImportProfile, len(profiles))
+	for i, profile := range profiles {
+		result[i] = types.ImportProfileFromDomain(&profile)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,352 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// importJobBatch is how many records a running job processes between
+// progress updates.
+const importJobBatch = 50
+
+// ImportFile implements logic.FileUsecase.
+// Every sheet of the profile is read, so exports with a sheet per group can
+// be imported back. Cells with issues are kept in the job and skipped,
+// PreviewImport reports them before anything is queued.
+func (fu *fileUsecase) ImportFile(ctx context.Context, upload types.ImportUpload, f *excelize.File) (*types.ImportJob, error) {
+	profile, err := fu.importProfile(ctx, upload.ProfileID)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	records, issues, err := parseWorkbook(f, *profile)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "the file has no debts to import")
+	}
+
+	id, err := fu.repo.CreateImportJob(ctx, commands.CreateImportJob{
+		Source:    valueobjects.ImportFileSource,
+		Actor:     upload.Actor,
+		FileName:  upload.FileName,
+		Checksum:  upload.Checksum,
+		ProfileID: upload.ProfileID,
+		Records:   records,
+		Issues:    issues,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	fu.wakeImportJobs()
+
+	return fu.GetImportJob(ctx, id)
+}
+
+// GetImportJob implements logic.FileUsecase.
+func (fu *fileUsecase) GetImportJob(ctx context.Context, id int64) (*types.ImportJob, error) {
+	jobs, err := fu.repo.GetImportJobs(ctx, query.GetImportJobsFilters{IDs: []int64{id}})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+
+	result := types.ImportJobFromDomain(&jobs[0])
+
+	return &result, nil
+}
+
+// GetImportJobs implements logic.FileUsecase.
+func (fu *fileUsecase) GetImportJobs(ctx context.Context, filters types.ImportJobFilters) ([]types.ImportJob, error) {
+	for _, status := range filters.Statuses {
+		if !valueobjects.IsValidImportStatus(status) {
+			return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "unknown status "+status)
+		}
+	}
+
+	jobs, err := fu.repo.GetImportJobs(ctx, query.GetImportJobsFilters{
+		Statuses: filters.Statuses,
+		Limit:    filters.Limit,
+		Offset:   filters.Offset,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := make([]types.ImportJob, len(jobs))
+	for i, job := range jobs {
+		result[i] = types.ImportJobFromDomain(&job)
+	}
+
+	return result, nil
+}
+
+// RerunImportJob implements logic.FileUsecase.
+// The job runs its records again, those imported before are found and
+// counted as existing, so a rerun only adds what the previous run missed.
+// Bulk jobs have no records to run again.
+func (fu *fileUsecase) RerunImportJob(ctx context.Context, id int64) (*types.ImportJob, error) {
+	job, err := fu.GetImportJob(ctx, id)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if job.Source == valueobjects.ImportBulkSource {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "a bulk import can not be rerun, send the batch again")
+	}
+
+	if err := fu.repo.RequeueImportJob(ctx, commands.RequeueImportJob{ID: id}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	fu.wakeImportJobs()
+
+	return fu.GetImportJob(ctx, id)
+}
+
+// RollbackImportJob implements logic.FileUsecase.
+// Entities go in valueobjects.ImportRollbackOrder, each in a transaction of
+// its own, so one that is referenced keeps only itself and what it needs.
+func (fu *fileUsecase) RollbackImportJob(ctx context.Context, id int64) (*types.ImportRollback, error) {
+	job, err := fu.GetImportJob(ctx, id)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if job.Status == valueobjects.ImportQueuedStatus || job.Status == valueobjects.ImportRunningStatus {
+		return nil, log.ErrorWrapper(errors.ErrImportJobActive, errors.ERR_APPLICATION, "")
+	}
+
+	entities, err := fu.repo.GetImportJobEntities(ctx, id)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	rank := make(map[string]int, len(valueobjects.ImportRollbackOrder))
+	for i, entity := range valueobjects.ImportRollbackOrder {
+		rank[entity] = i
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		return rank[entities[i].Entity] < rank[entities[j].Entity]
+	})
+
+	result := &types.ImportRollback{
+		JobID: id,
+		Kept:  make([]types.ImportRollbackItem, 0),
+	}
+	for _, entity := range entities {
+		var references []string
+		err := fu.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			references, err = fu.repo.RollbackImportJobEntity(ctx, commands.RollbackImportJobEntity{
+				JobID:    id,
+				Entity:   entity.Entity,
+				EntityID: entity.EntityID,
+			})
+			return err
+		})
+		switch {
+		case err != nil:
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName(), "job", id)
+			result.Kept = append(result.Kept, types.ImportRollbackItem{
+				Entity:   entity.Entity,
+				EntityID: entity.EntityID,
+				Message:  err.Error(),
+			})
+		case len(references) > 0:
+			result.Kept = append(result.Kept, types.ImportRollbackItem{
+				Entity:     entity.Entity,
+				EntityID:   entity.EntityID,
+				References: references,
+				Message:    "referenced by " + strings.Join(references, ", "),
+			})
+		default:
+			result.Deleted++
+		}
+	}
+
+	if err := fu.repo.FinishImportJobRollback(ctx, commands.FinishImportJobRollback{ID: id}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RunImportJobs implements logic.FileUsecase.
+// Jobs are claimed in the database, so every replica can run a worker, and
+// a job left running by a stopped worker is claimed again after
+// valueobjects.ImportJobLease.
+func (fu *fileUsecase) RunImportJobs(ctx context.Context) {
+	ticker := time.NewTicker(valueobjects.ImportJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for fu.runImportJob(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-fu.jobs:
+		}
+	}
+}
+
+func (fu *fileUsecase) wakeImportJobs() {
+	select {
+	case fu.jobs <- struct{}{}:
+	default:
+	}
+}
+
+// runImportJob runs one claimed job and reports whether there was one.
+func (fu *fileUsecase) runImportJob(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	id, err := fu.repo.ClaimImportJob(ctx, valueobjects.ImportJobLease)
+	switch {
+	case err == nil:
+	case e.Is(err, errors.ErroNoItemsFound):
+		return false
+	default:
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return false
+	}
+
+	renewing, stop := context.WithCancel(ctx)
+	defer stop()
+	go fu.renewImportJob(renewing, id)
+
+	update := commands.UpdateImportJob{
+		ID:     id,
+		Status: valueobjects.ImportRunningStatus,
+		Errors: make([]models.ImportIssue, 0),
+	}
+
+	jobs, err := fu.repo.GetImportJobs(ctx, query.GetImportJobsFilters{IDs: []int64{id}, WithRecords: true})
+	if err == nil && len(jobs) == 0 {
+		err = log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	// a bulk job is claimed only when the request running it has stopped
+	if err == nil && jobs[0].Source == valueobjects.ImportBulkSource {
+		err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "the request importing the batch stopped")
+	}
+	var diff *importDiff
+	if err == nil {
+		diff, err = fu.diffImport(ctx, jobs[0].Records)
+	}
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName(), "job", id)
+		update.Status = valueobjects.ImportFailedStatus
+		update.Errors = append(update.Errors, models.ImportIssue{Message: err.Error()})
+		fu.updateImportJob(ctx, update)
+		return true
+	}
+
+	for _, record := range jobs[0].Records {
+		// a stopped worker leaves the job running, the lease hands it over
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if _, ok := diff.debts[importDebtKey(record)]; ok {
+			update.Existing++
+		} else if err := fu.importRecord(ctx, id, record); err != nil {
+			update.Failed++
+			update.Errors = append(update.Errors, models.ImportIssue{
+				Sheet:   record.Sheet,
+				Row:     record.Row,
+				Column:  record.Column,
+				Value:   record.ExamName,
+				Message: err.Error(),
+			})
+		} else {
+			update.Created++
+		}
+
+		update.Processed++
+		if update.Processed%importJobBatch == 0 {
+			fu.updateImportJob(ctx, update)
+		}
+	}
+
+	update.Status = valueobjects.ImportCompletedStatus
+	fu.updateImportJob(ctx, update)
+
+	return true
+}
+
+// renewImportJob renews the lease of the job every
+// valueobjects.ImportJobRenewInterval until ctx is done.
+func (fu *fileUsecase) renewImportJob(ctx context.Context, id int64) {
+	ticker := time.NewTicker(valueobjects.ImportJobRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := fu.repo.RenewImportJob(ctx, commands.RenewImportJob{ID: id}); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName(), "job", id)
+		}
+	}
+}
+
+// importRecord creates the debt of record with whatever it is missing.
+func (fu *fileUsecase) importRecord(ctx context.Context, jobID int64, record models.ImportRecord) error {
+	return fu.trackImport(ctx, jobID, func(ctx context.Context, created *importEntities) error {
+		_, err := fu.CreateDebtIfNotExists(ctx, importRecordDebt(record), created)
+		return err
+	})
+}
+
+// trackImport runs create and stores what it created for
+// RollbackImportJob in the same transaction.
+func (fu *fileUsecase) trackImport(ctx context.Context, jobID int64, create func(context.Context, *importEntities) error) error {
+	return fu.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		var created importEntities
+		if err := create(ctx, &created); err != nil {
+			return err
+		}
+
+		return fu.repo.AddImportJobEntities(ctx, commands.AddImportJobEntities{
+			JobID:    jobID,
+			Entities: created,
+		})
+	})
+}
+
+func (fu *fileUsecase) updateImportJob(ctx context.Context, update commands.UpdateImportJob) {
+	if err := fu.repo.UpdateImportJob(ctx, update); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName(), "job", update.ID)
+	}
+}
@@ -0,0 +1,395 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+// defaultImportProfile reads the matrix the importer has always read and
+// the matrix export writes: "Фамилия Имя Отчество Группа Email" students and
+// "Фамилия Имя Отчество Email" teachers split by single spaces, so an empty
+// middle name is two spaces in a row.
+var defaultImportProfile = models.ImportProfile{
+	Name:      "default",
+	Layout:    valueobjects.ImportMatrixLayout,
+	HeaderRow: 1,
+	Separator: " ",
+	StudentFields: []string{
+		valueobjects.ImportLastNameField,
+		valueobjects.ImportFirstNameField,
+		valueobjects.ImportMiddleNameField,
+		valueobjects.ImportGroupField,
+		valueobjects.ImportEmailField,
+	},
+	TeacherFields: []string{
+		valueobjects.ImportLastNameField,
+		valueobjects.ImportFirstNameField,
+		valueobjects.ImportMiddleNameField,
+		valueobjects.ImportEmailField,
+	},
+}
+
+var importFieldTitles = map[string]string{
+	valueobjects.ImportLastNameField:   "Фамилия",
+	valueobjects.ImportFirstNameField:  "Имя",
+	valueobjects.ImportMiddleNameField: "Отчество",
+	valueobjects.ImportFullNameField:   "ФИО",
+	valueobjects.ImportGroupField:      "Группа",
+	valueobjects.ImportEmailField:      "Email",
+}
+
+// parseWorkbook reads the sheets of the profile, every sheet when it names
+// none. Cells that can not be imported are returned as issues, Row and
+// Column of records and issues start at 1 as in the spreadsheet.
+func parseWorkbook(f *excelize.File, profile models.ImportProfile) ([]models.ImportRecord, []models.ImportIssue, error) {
+	p := &importParser{
+		profile:  profile,
+		records:  make([]models.ImportRecord, 0),
+		issues:   make([]models.ImportIssue, 0),
+		students: make(map[string]importPerson),
+		teachers: make(map[string]importPerson),
+		debts:    make(map[string]bool),
+	}
+
+	if profile.Layout == valueobjects.ImportListLayout {
+		p.columns = make(map[string]int)
+		for column, letters := range profile.Columns {
+			number, err := excelize.ColumnNameToNumber(strings.ToUpper(letters))
+			if err != nil {
+				return nil, nil, fmt.Errorf("column %s: %w", column, err)
+			}
+			p.columns[column] = number
+		}
+	}
+
+	sheets := profile.Sheets
+	if len(sheets) == 0 {
+		sheets = f.GetSheetList()
+	}
+	for _, sheet := range sheets {
+		if index, err := f.GetSheetIndex(sheet); err != nil || index == -1 {
+			p.issue(sheet, 0, 0, "", "the sheet is not found")
+			continue
+		}
+
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get rows: %w", err)
+		}
+
+		if profile.Layout == valueobjects.ImportListLayout {
+			p.parseList(sheet, rows)
+		} else {
+			p.parseMatrix(sheet, rows)
+		}
+	}
+
+	return p.records, p.issues, nil
+}
+
+type importPerson struct {
+	lastName   string
+	firstName  string
+	middleName string
+	group      string
+	email      string
+}
+
+type importParser struct {
+	profile models.ImportProfile
+	// column numbers of the list layout by valueobjects column name
+	columns map[string]int
+	records []models.ImportRecord
+	issues  []models.ImportIssue
+	// people seen in the workbook by email, to catch one email used for
+	// different people
+	students map[string]importPerson
+	teachers map[string]importPerson
+	debts    map[string]bool
+}
+
+func (p *importParser) issue(sheet string, row, column int, value, message string) {
+	p.issues = append(p.issues, models.ImportIssue{
+		Sheet:   sheet,
+		Row:     int64(row),
+		Column:  int64(column),
+		Value:   value,
+		Message: message,
+	})
+}
+
+// parseMatrix reads students from the header row, teachers from the first
+// column of the rows below it and exams from the cells.
+func (p *importParser) parseMatrix(sheet string, rows [][]string) {
+	headerRow := int(p.profile.HeaderRow)
+	if len(rows) < headerRow {
+		return
+	}
+	header := rows[headerRow-1]
+	if len(header) < 2 {
+		if len(rows) > headerRow {
+			p.issue(sheet, headerRow, 0, "", "the header row has no students, the sheet is skipped")
+		}
+		return
+	}
+
+	students := make(map[int]importPerson)
+	sheetEmails := make(map[string]bool)
+	for col := 2; col <= len(header); col++ {
+		value := strings.TrimSpace(header[col-1])
+		if value == "" {
+			continue
+		}
+
+		student, msg := splitImportPerson(value, p.profile.Separator, p.profile.StudentFields)
+		if msg == "" {
+			_, msg = checkImportPerson(student, true)
+		}
+		if msg == "" && sheetEmails[student.email] {
+			msg = "the student is listed twice"
+		}
+		if msg == "" {
+			msg = p.checkStudent(student)
+		}
+		if msg != "" {
+			p.issue(sheet, headerRow, col, value, msg)
+			continue
+		}
+
+		sheetEmails[student.email] = true
+		students[col] = student
+	}
+
+	for r := headerRow + 1; r <= len(rows); r++ {
+		row := rows[r-1]
+		var value string
+		if len(row) > 0 {
+			value = strings.TrimSpace(row[0])
+		}
+		if value == "" {
+			for col := 2; col <= len(row); col++ {
+				if strings.TrimSpace(row[col-1]) != "" {
+					p.issue(sheet, r, 1, "", "the row has exams but no teacher")
+					break
+				}
+			}
+			continue
+		}
+
+		teacher, msg := splitImportPerson(value, p.profile.Separator, p.profile.TeacherFields)
+		if msg == "" {
+			_, msg = checkImportPerson(teacher, false)
+		}
+		if msg == "" {
+			msg = p.checkTeacher(teacher)
+		}
+		if msg != "" {
+			p.issue(sheet, r, 1, value, msg)
+			continue
+		}
+
+		for col := 2; col <= len(row); col++ {
+			exam := strings.TrimSpace(row[col-1])
+			if exam == "" {
+				continue
+			}
+
+			student, ok := students[col]
+			if !ok {
+				p.issue(sheet, r, col, exam, "the column has no valid student")
+				continue
+			}
+
+			p.addRecord(sheet, r, col, exam, student, teacher)
+		}
+	}
+}
+
+// parseList reads a debt per row below the header row, the columns of the
+// profile point at the cells of the student, the teacher and the exam.
+func (p *importParser) parseList(sheet string, rows [][]string) {
+	for r := int(p.profile.HeaderRow) + 1; r <= len(rows); r++ {
+		row := rows[r-1]
+		cells := make(map[string]string, len(p.columns))
+		empty := true
+		for column, number := range p.columns {
+			if number <= len(row) {
+				cells[column] = strings.TrimSpace(row[number-1])
+				empty = empty && cells[column] == ""
+			}
+		}
+		if empty {
+			continue
+		}
+
+		student, teacher := importPerson{}, importPerson{}
+		for column, value := range cells {
+			switch {
+			case column == valueobjects.ImportGroupField:
+				student.group = value
+			case strings.HasPrefix(column, valueobjects.ImportStudentPrefix):
+				setImportField(&student, strings.TrimPrefix(column, valueobjects.ImportStudentPrefix), value)
+			case strings.HasPrefix(column, valueobjects.ImportTeacherPrefix):
+				setImportField(&teacher, strings.TrimPrefix(column, valueobjects.ImportTeacherPrefix), value)
+			}
+		}
+
+		if field, msg := checkImportPerson(student, true); msg != "" {
+			column := valueobjects.ImportGroupField
+			if field != valueobjects.ImportGroupField {
+				column = p.personColumn(valueobjects.ImportStudentPrefix, field)
+			}
+			p.issue(sheet, r, p.columns[column], cells[column], msg)
+			continue
+		}
+		if msg := p.checkStudent(student); msg != "" {
+			column := valueobjects.ImportStudentPrefix + valueobjects.ImportEmailField
+			p.issue(sheet, r, p.columns[column], cells[column], msg)
+			continue
+		}
+		if field, msg := checkImportPerson(teacher, false); msg != "" {
+			column := p.personColumn(valueobjects.ImportTeacherPrefix, field)
+			p.issue(sheet, r, p.columns[column], cells[column], msg)
+			continue
+		}
+		if msg := p.checkTeacher(teacher); msg != "" {
+			column := valueobjects.ImportTeacherPrefix + valueobjects.ImportEmailField
+			p.issue(sheet, r, p.columns[column], cells[column], msg)
+			continue
+		}
+
+		examColumn := p.columns[valueobjects.ImportExamColumn]
+		exam := cells[valueobjects.ImportExamColumn]
+		if exam == "" {
+			p.issue(sheet, r, examColumn, "", "missing exam")
+			continue
+		}
+
+		p.addRecord(sheet, r, examColumn, exam, student, teacher)
+	}
+}
+
+// personColumn is the column holding field, the full name column when the
+// profile does not split names.
+func (p *importParser) personColumn(prefix, field string) string {
+	if _, ok := p.columns[prefix+field]; !ok {
+		return prefix + valueobjects.ImportFullNameField
+	}
+
+	return prefix + field
+}
+
+func (p *importParser) checkStudent(student importPerson) string {
+	if seen, ok := p.students[student.email]; ok && seen != student {
+		return "the email belongs to another student in the file"
+	}
+	p.students[student.email] = student
+
+	return ""
+}
+
+func (p *importParser) checkTeacher(teacher importPerson) string {
+	if seen, ok := p.teachers[teacher.email]; ok && seen != teacher {
+		return "the email belongs to another teacher in the file"
+	}
+	p.teachers[teacher.email] = teacher
+
+	return ""
+}
+
+func (p *importParser) addRecord(sheet string, row, column int, exam string, student, teacher importPerson) {
+	key := debtKey(exam, student.email, teacher.email)
+	if p.debts[key] {
+		p.issue(sheet, row, column, exam, "the debt is listed twice")
+		return
+	}
+	p.debts[key] = true
+
+	p.records = append(p.records, models.ImportRecord{
+		Sheet:             sheet,
+		Row:               int64(row),
+		Column:            int64(column),
+		GroupName:         student.group,
+		StudentLastName:   student.lastName,
+		StudentFirstName:  student.firstName,
+		StudentMiddleName: student.middleName,
+		StudentEmail:      student.email,
+		TeacherLastName:   teacher.lastName,
+		TeacherFirstName:  teacher.firstName,
+		TeacherMiddleName: teacher.middleName,
+		TeacherEmail:      teacher.email,
+		ExamName:          exam,
+	})
+}
+
+// splitImportPerson splits a matrix cell by separator into fields. It
+// returns a message describing what is wrong with the cell.
+func splitImportPerson(value, separator string, fields []string) (importPerson, string) {
+	var person importPerson
+
+	parts := strings.Split(value, separator)
+	if len(parts) != len(fields) {
+		titles := make([]string, len(fields))
+		for i, field := range fields {
+			titles[i] = importFieldTitles[field]
+		}
+		return person, fmt.Sprintf("expected %q", strings.Join(titles, separator))
+	}
+
+	for i, field := range fields {
+		setImportField(&person, field, strings.TrimSpace(parts[i]))
+	}
+
+	return person, ""
+}
+
+func setImportField(person *importPerson, field, value string) {
+	switch field {
+	case valueobjects.ImportLastNameField:
+		person.lastName = value
+	case valueobjects.ImportFirstNameField:
+		person.firstName = value
+	case valueobjects.ImportMiddleNameField:
+		person.middleName = value
+	case valueobjects.ImportGroupField:
+		person.group = value
+	case valueobjects.ImportEmailField:
+		person.email = value
+	case valueobjects.ImportFullNameField:
+		// the rest after the last and the first names is the middle name,
+		// which is empty for people without one
+		names := strings.Fields(value)
+		if len(names) > 0 {
+			person.lastName = names[0]
+		}
+		if len(names) > 1 {
+			person.firstName = names[1]
+		}
+		if len(names) > 2 {
+			person.middleName = strings.Join(names[2:], " ")
+		}
+	}
+}
+
+// checkImportPerson returns the field that is wrong and a message about it.
+func checkImportPerson(person importPerson, withGroup bool) (string, string) {
+	switch {
+	case person.lastName == "":
+		return valueobjects.ImportLastNameField, "missing last name"
+	case person.firstName == "":
+		return valueobjects.ImportFirstNameField, "missing first name"
+	case withGroup && person.group == "":
+		return valueobjects.ImportGroupField, "missing group"
+	case person.email == "":
+		return valueobjects.ImportEmailField, "missing email"
+	case strings.Count(person.email, "@") != 1 || strings.HasPrefix(person.email, "@") || strings.HasSuffix(person.email, "@"):
+		return valueobjects.ImportEmailField, "malformed email"
+	}
+
+	return "", ""
+}
@@ -3,15 +3,15 @@ package application
 import (
 	"context"
 	e "errors"
-	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/xuri/excelize/v2"
-
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
 	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
 	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
 	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
@@ -22,123 +22,22 @@ import (
 
 type fileUsecase struct {
 	repo repositories.Repository
+	// wakes RunImportJobs up when a job is queued
+	jobs chan struct{}
 }
 
-// ParseFile implements logic.FileUsecase.
-func (fu *fileUsecase) ParseFile(ctx context.Context, f *excelize.File) error {
-	rows, err := f.GetRows("Sheet1")
-	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		return fmt.Errorf("get rows: %w", err)
-	}
+// importEntities collects what the CreateXIfNotExists methods create for an
+// import job, a nil one collects nothing. The methods do not create what is
+// in the trash again, they return errors.ErrInTrash.
+type importEntities []models.ImportJobEntity
 
-	if len(rows) < 2 {
-		log.Logger.Error("not enough rows in the table", errors.MethodKey, log.GetMethodName())
-		return fmt.Errorf("not enough rows in the table")
+func (ie *importEntities) add(entity, id string) {
+	if ie != nil {
+		*ie = append(*ie, models.ImportJobEntity{Entity: entity, EntityID: id})
 	}
-
-	// 1. Парсим студентов из заголовка
-	studentHeaders := rows[0][1:]
-	students := make([]*types.Student, 0, len(studentHeaders))
-	studentMap := make(map[int]*types.Student) // Для связи по индексу
-
-	for i, header := range studentHeaders {
-		parts := strings.Split(header, " ")
-		fmt.Println(parts)
-		if len(parts) < 5 {
-			continue
-		}
-
-		student := &types.Student{
-			LastName:   parts[0],
-			FirstName:  parts[1],
-			MiddleName: parts[2],
-			Group:      &types.Group{Name: parts[3]},
-			Email:      parts[4],
-		}
-
-		students = append(students, student)
-		studentMap[i] = student
-	}
-
-	// 2. Парсим преподавателей и долги
-	teachers := make([]*types.Teacher, 0)
-	teacherMap := make(map[string]*types.Teacher) // Для избежания дубликатов
-	debts := make([]*types.Debt, 0)
-
-	for _, row := range rows[1:] {
-		if len(row) == 0 {
-			continue
-		}
-
-		teacherName := strings.TrimSpace(row[0])
-		if teacherName == "" {
-			continue
-		}
-
-		// Обрабатываем преподавателя
-		teacherParts := strings.Split(teacherName, " ")
-		if len(teacherParts) < 4 {
-			continue
-		}
-
-		teacherKey := teacherName
-		teacher, exists := teacherMap[teacherKey]
-		if !exists {
-			teacher = &types.Teacher{
-				LastName:   teacherParts[0],
-				FirstName:  teacherParts[1],
-				MiddleName: teacherParts[2],
-				Email:      teacherParts[3],
-			}
-			teachers = append(teachers, teacher)
-			teacherMap[teacherKey] = teacher
-		}
-
-		// Обрабатываем долги для каждого студента
-		for colIdx, cell := range row[1:] {
-			if colIdx >= len(studentMap) {
-				break
-			}
-
-			examName := strings.TrimSpace(cell)
-			if examName == "" {
-				continue
-			}
-
-			student := studentMap[colIdx]
-			if student == nil {
-				continue
-			}
-
-			debts = append(debts, &types.Debt{
-				Exam:    &types.Exam{Name: examName},
-				Student: student,
-				Teacher: teacher,
-			})
-		}
-	}
-
-	// fmt.Println("STUDENTS:")
-	// for _, stud := range students {
-	// }
-
-	// fmt.Println("TEACHERS:")
-	// for _, teac := range teachers {
-	// }
-
-	fmt.Println("DEBTS:")
-	for _, debt := range debts {
-		_, err := fu.CreateDebtIfNotExists(ctx, *debt)
-		if err != nil {
-			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-			return err
-		}
-	}
-	return nil
 }
 
-func (fu *fileUsecase) CreateGroupIfNotExists(ctx context.Context, group types.Group) (int64, error) {
+func (fu *fileUsecase) CreateGroupIfNotExists(ctx context.Context, group types.Group, created *importEntities) (int64, error) {
 	groupsFound, err := fu.repo.SearchGroups(ctx, query.SearchGroupFilters{
 		Names: []string{group.Name},
 	})
@@ -149,6 +48,17 @@ func (fu *fileUsecase) CreateGroupIfNotExists(ctx context.Context, group types.G
 	if len(groupsFound) != 0 {
 		return groupsFound[0].ID, nil
 	}
+	groupsFound, err = fu.repo.SearchGroups(ctx, query.SearchGroupFilters{
+		Names:   []string{group.Name},
+		Deleted: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+	if len(groupsFound) != 0 {
+		return 0, log.ErrorWrapper(errors.ErrInTrash, errors.ERR_APPLICATION, "restore the group or purge it", "group", group.Name)
+	}
 
 	id, err := fu.repo.CreateGroup(ctx, commands.CreateGroup{
 		Name: group.Name,
@@ -157,11 +67,12 @@ func (fu *fileUsecase) CreateGroupIfNotExists(ctx context.Context, group types.G
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
 	}
+	created.add(valueobjects.ImportGroupEntity, strconv.FormatInt(id, 10))
 
 	return id, nil
 }
 
-func (fu *fileUsecase) CreateStudentIfNotExists(ctx context.Context, student types.Student) (string, error) {
+func (fu *fileUsecase) CreateStudentIfNotExists(ctx context.Context, student types.Student, created *importEntities) (string, error) {
 	studentsFound, err := fu.repo.SearchStudents(ctx, query.SearchStudentFilters{
 		Emails: []string{student.Email},
 	})
@@ -172,10 +83,26 @@ func (fu *fileUsecase) CreateStudentIfNotExists(ctx context.Context, student typ
 	if len(studentsFound) != 0 {
 		return studentsFound[0].UUID, nil
 	}
+	studentsFound, err = fu.repo.SearchStudents(ctx, query.SearchStudentFilters{
+		Emails:  []string{student.Email},
+		Deleted: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return "", err
+	}
+	if len(studentsFound) != 0 {
+		return "", log.ErrorWrapper(errors.ErrInTrash, errors.ERR_APPLICATION, "restore the student or purge it", "email", student.Email)
+	}
+	// records keep the anonymized email of an anonymized student, a rerun
+	// must not bring the student back once the row is purged
+	if strings.HasPrefix(student.Email, valueobjects.AnonymizedEmailPrefix) {
+		return "", log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "the student is anonymized", "email", student.Email)
+	}
 
 	groupID, err := fu.CreateGroupIfNotExists(ctx, types.Group{
 		Name: student.Group.Name,
-	})
+	}, created)
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return "", err
@@ -211,11 +138,12 @@ func (fu *fileUsecase) CreateStudentIfNotExists(ctx context.Context, student typ
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return "", err
 	}
+	created.add(valueobjects.ImportStudentEntity, id)
 
 	return id, nil
 }
 
-func (fu *fileUsecase) CreateTeacherIfNotExists(ctx context.Context, teacher types.Teacher) (string, error) {
+func (fu *fileUsecase) CreateTeacherIfNotExists(ctx context.Context, teacher types.Teacher, created *importEntities) (string, error) {
 	teachersFound, err := fu.repo.SearchTeachers(ctx, query.SearchTeacherFilters{
 		Emails: []string{teacher.Email},
 	})
@@ -226,6 +154,17 @@ func (fu *fileUsecase) CreateTeacherIfNotExists(ctx context.Context, teacher typ
 	if len(teachersFound) != 0 {
 		return teachersFound[0].UUID, nil
 	}
+	teachersFound, err = fu.repo.SearchTeachers(ctx, query.SearchTeacherFilters{
+		Emails:  []string{teacher.Email},
+		Deleted: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return "", err
+	}
+	if len(teachersFound) != 0 {
+		return "", log.ErrorWrapper(errors.ErrInTrash, errors.ERR_APPLICATION, "restore the teacher or purge it", "email", teacher.Email)
+	}
 
 	// 1) generate password
 	pass, err := generator.GeneratePassword(generator.DEFAULTLEN)
@@ -261,11 +200,12 @@ func (fu *fileUsecase) CreateTeacherIfNotExists(ctx context.Context, teacher typ
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return "", err
 	}
+	created.add(valueobjects.ImportTeacherEntity, id)
 
 	return id, nil
 }
 
-func (fu *fileUsecase) CreateExamIfNotExists(ctx context.Context, exam types.Exam) (int64, error) {
+func (fu *fileUsecase) CreateExamIfNotExists(ctx context.Context, exam types.Exam, created *importEntities) (int64, error) {
 	examsFound, err := fu.repo.SearchExams(ctx, query.SearchExamFilters{
 		Names: []string{exam.Name},
 	})
@@ -276,19 +216,32 @@ func (fu *fileUsecase) CreateExamIfNotExists(ctx context.Context, exam types.Exa
 	if len(examsFound) != 0 {
 		return examsFound[0].ID, nil
 	}
+	examsFound, err = fu.repo.SearchExams(ctx, query.SearchExamFilters{
+		Names:   []string{exam.Name},
+		Deleted: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+	if len(examsFound) != 0 {
+		return 0, log.ErrorWrapper(errors.ErrInTrash, errors.ERR_APPLICATION, "restore the exam or purge it", "exam", exam.Name)
+	}
 
 	id, err := fu.repo.CreateExam(ctx, commands.CreateExam{
-		Name: exam.Name,
+		Name:           exam.Name,
+		AssessmentType: valueobjects.AssessmentExam,
 	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
 	}
+	created.add(valueobjects.ImportExamEntity, strconv.FormatInt(id, 10))
 
 	return id, nil
 }
 
-func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Debt) (int64, error) {
+func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Debt, created *importEntities) (int64, error) {
 	debtsFound, err := fu.repo.SearchDebts(ctx, query.SearchDebtsFilters{
 		ExamNames:     []string{debt.Exam.Name},
 		StudentEmails: []string{debt.Student.Email},
@@ -304,7 +257,7 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 
 	eid, err := fu.CreateExamIfNotExists(ctx, types.Exam{
 		Name: debt.Exam.Name,
-	})
+	}, created)
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
@@ -312,7 +265,7 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 
 	gid, err := fu.CreateGroupIfNotExists(ctx, types.Group{
 		Name: debt.Student.Group.Name,
-	})
+	}, created)
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
@@ -327,7 +280,7 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 			ID:   gid,
 			Name: debt.Student.Group.Name,
 		},
-	})
+	}, created)
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
@@ -338,7 +291,7 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 		LastName:   debt.Teacher.LastName,
 		MiddleName: debt.Teacher.MiddleName,
 		Email:      debt.Teacher.Email,
-	})
+	}, created)
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
@@ -354,6 +307,7 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return 0, err
 	}
+	created.add(valueobjects.ImportDebtEntity, strconv.FormatInt(id, 10))
 
 	return id, nil
 }
@@ -361,5 +315,6 @@ func (fu *fileUsecase) CreateDebtIfNotExists(ctx context.Context, debt types.Deb
 func NewFileUsecase(repo repositories.Repository) logic.FileUsecase {
 	return &fileUsecase{
 		repo: repo,
+		jobs: make(chan struct{}, 1),
 	}
 }
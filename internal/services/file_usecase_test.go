@@ -0,0 +1,768 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func newFileUsecase(t *testing.T) (*fileUsecase, *fixture) {
+	t.Helper()
+
+	f := newFixture(t)
+	return NewFileUsecase(f.repo).(*fileUsecase), f
+}
+
+func importRecord(exam, studentEmail, teacherEmail string) models.ImportRecord {
+	return models.ImportRecord{
+		Sheet:            "ИВТ-43",
+		GroupName:        "ИВТ-43",
+		StudentLastName:  "Смирнов",
+		StudentFirstName: "Алексей",
+		StudentEmail:     studentEmail,
+		TeacherLastName:  "Волков",
+		TeacherFirstName: "Сергей",
+		TeacherEmail:     teacherEmail,
+		ExamName:         exam,
+	}
+}
+
+func TestFileUsecaseCreateDebtIfNotExists(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	// everything of the fixture debt exists already
+	var created importEntities
+	id, err := usecase.CreateDebtIfNotExists(ctx, types.Debt{
+		Exam:    &types.Exam{Name: "Базы данных"},
+		Student: &types.Student{Email: "petrov@example.com", Group: &types.Group{Name: "ИВТ-41"}},
+		Teacher: &types.Teacher{Email: "sidorova@example.com"},
+	}, &created)
+	if err != nil {
+		t.Fatalf("CreateDebtIfNotExists: %v", err)
+	}
+	if id != f.debtID || len(created) != 0 {
+		t.Errorf("CreateDebtIfNotExists of the stored debt = %d with %+v created, want %d and nothing created", id, created, f.debtID)
+	}
+
+	// a new student of a new group for the stored exam and teacher
+	id, err = usecase.CreateDebtIfNotExists(ctx, importRecordDebt(importRecord("Базы данных", "smirnov@example.com", "sidorova@example.com")), &created)
+	if err != nil {
+		t.Fatalf("CreateDebtIfNotExists: %v", err)
+	}
+	entities := make([]string, len(created))
+	for i, entity := range created {
+		entities[i] = entity.Entity
+	}
+	want := []string{valueobjects.ImportGroupEntity, valueobjects.ImportStudentEntity, valueobjects.ImportDebtEntity}
+	if len(entities) != len(want) {
+		t.Fatalf("created = %v, want %v", entities, want)
+	}
+	for i := range want {
+		if entities[i] != want[i] {
+			t.Fatalf("created = %v, want %v", entities, want)
+		}
+	}
+
+	// the new student got a password by mail
+	mails := f.mailer.Mails()
+	if len(mails) != 1 || mails[0].To != "smirnov@example.com" || mails[0].Body == "" {
+		t.Errorf("mails = %+v, want the password sent to the student", mails)
+	}
+
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{id}})
+	if err != nil || len(debts) != 1 {
+		t.Fatalf("GetDebts = %+v, %v, want the new debt", debts, err)
+	}
+	if debts[0].Student.Group.Name != "ИВТ-43" || debts[0].Teacher.UUID != f.teacher || debts[0].Exam.ID != f.examID {
+		t.Errorf("debt = %+v, want the new student with the stored exam and teacher", debts[0])
+	}
+}
+
+func TestFileUsecaseCreateDebtIfNotExistsInTrash(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	if err := f.repo.DeleteTeacher(ctx, commands.DeleteTeacher{UUID: f.teacher}); err != nil {
+		t.Fatalf("DeleteTeacher: %v", err)
+	}
+	if err := f.repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: f.student}); err != nil {
+		t.Fatalf("DeleteStudent: %v", err)
+	}
+
+	for name, record := range map[string]models.ImportRecord{
+		"student": importRecord("Сети", "petrov@example.com", "volkov@example.com"),
+		"teacher": importRecord("Сети", "smirnov@example.com", "sidorova@example.com"),
+	} {
+		var created importEntities
+		err := f.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			_, err := usecase.CreateDebtIfNotExists(ctx, importRecordDebt(record), &created)
+			return err
+		})
+		if !e.Is(err, errors.ErrInTrash) {
+			t.Errorf("CreateDebtIfNotExists of a trashed %s: err = %v, want %v", name, err, errors.ErrInTrash)
+		}
+	}
+
+	// nothing was created next to the trashed ones
+	for _, email := range []string{"petrov@example.com", "smirnov@example.com"} {
+		students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{email}})
+		if err != nil || len(students) != 0 {
+			t.Errorf("SearchStudents(%s) = %+v, %v, want none", email, students, err)
+		}
+	}
+	teachers, err := f.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"sidorova@example.com"}})
+	if err != nil || len(teachers) != 0 {
+		t.Errorf("SearchTeachers = %+v, %v, want none", teachers, err)
+	}
+	teachers, err = f.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"sidorova@example.com"}, Deleted: true})
+	if err != nil || len(teachers) != 1 || teachers[0].UUID != f.teacher {
+		t.Errorf("SearchTeachers in the trash = %+v, %v, want the deleted teacher", teachers, err)
+	}
+}
+
+func TestFileUsecaseBulkImport(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	batch := types.BulkImport{
+		Actor:  adminUUID,
+		Groups: []types.Group{{Name: "ИВТ-41"}, {Name: "ИВТ-43"}, {Name: " "}},
+		Teachers: []types.Teacher{
+			{LastName: "Волков", FirstName: "Сергей", Email: "volkov@example.com"},
+			{LastName: "Волков", FirstName: "Сергей", Email: "volkov"},
+		},
+		Students: []types.Student{
+			{LastName: "Смирнов", FirstName: "Алексей", Email: "smirnov@example.com", Group: &types.Group{Name: "ИВТ-43"}},
+		},
+		Debts: []types.Debt{
+			{Exam: &types.Exam{Name: "Сети"}, Student: &types.Student{Email: "smirnov@example.com"}, Teacher: &types.Teacher{Email: "volkov@example.com"}},
+			{Exam: &types.Exam{Name: "Базы данных"}, Student: &types.Student{Email: "petrov@example.com"}, Teacher: &types.Teacher{Email: "sidorova@example.com"}},
+			{Exam: &types.Exam{Name: "Сети"}, Student: &types.Student{Email: "nobody@example.com"}, Teacher: &types.Teacher{Email: "volkov@example.com"}},
+		},
+	}
+
+	result, err := usecase.BulkImport(ctx, batch)
+	if err != nil {
+		t.Fatalf("BulkImport: %v", err)
+	}
+	// created: ИВТ-43, Волков, Смирнов, the debt for Сети
+	// existing: ИВТ-41, the fixture debt
+	// failed: the blank group, the malformed email, the unknown student
+	if result.Created != 4 || result.Existing != 2 || result.Failed != 3 {
+		t.Errorf("BulkImport = %d created, %d existing, %d failed, want 4, 2, 3", result.Created, result.Existing, result.Failed)
+	}
+	if result.Groups[0].ID != f.groupID || result.Groups[0].Created {
+		t.Errorf("group result = %+v, want the stored group", result.Groups[0])
+	}
+	if result.Debts[1].ID != f.debtID {
+		t.Errorf("debt result = %+v, want the fixture debt", result.Debts[1])
+	}
+	if !e.Is(result.Debts[2].Err, errors.ErroNoItemsFound) {
+		t.Errorf("debt of an unknown student = %v, want %v", result.Debts[2].Err, errors.ErroNoItemsFound)
+	}
+
+	// the batch is a finished job of the history
+	job, err := usecase.GetImportJob(ctx, result.JobID)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if job.Source != valueobjects.ImportBulkSource || job.Status != valueobjects.ImportCompletedStatus || job.Actor != adminUUID {
+		t.Errorf("job = %s %s by %s, want a completed bulk job by the admin", job.Source, job.Status, job.Actor)
+	}
+	if job.Total != 9 || job.Processed != 9 || job.Created != 4 || job.Existing != 2 || job.Failed != 3 || len(job.Errors) != 3 {
+		t.Errorf("job counters = %+v, want the counters of the result", job)
+	}
+	if job.Errors[0].Sheet != "groups" || job.Errors[0].Row != 3 {
+		t.Errorf("job error = %+v, want the third group", job.Errors[0])
+	}
+	if _, err := usecase.RerunImportJob(ctx, result.JobID); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("RerunImportJob of a bulk job = %v, want %v", err, errors.ErrInvalidData)
+	}
+	if usecase.runImportJob(ctx) {
+		t.Error("runImportJob: want the bulk job left to the request")
+	}
+
+	// the same batch again creates nothing
+	again, err := usecase.BulkImport(ctx, batch)
+	if err != nil {
+		t.Fatalf("BulkImport: %v", err)
+	}
+	if again.Created != 0 || again.Existing != 6 || again.Failed != 3 {
+		t.Errorf("BulkImport again = %d created, %d existing, %d failed, want 0, 6, 3", again.Created, again.Existing, again.Failed)
+	}
+
+	// the group, the teacher, the student, the exam and the debt go back
+	rollback, err := usecase.RollbackImportJob(ctx, result.JobID)
+	if err != nil {
+		t.Fatalf("RollbackImportJob: %v", err)
+	}
+	if rollback.Deleted != 5 || len(rollback.Kept) != 0 {
+		t.Errorf("RollbackImportJob = %+v, want 5 deleted", rollback)
+	}
+	if students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"smirnov@example.com"}}); err != nil || len(students) != 0 {
+		t.Errorf("SearchStudents after the rollback = %+v, %v, want the student deleted", students, err)
+	}
+}
+
+// queueImportJob stores a job the way ImportFile does after parsing.
+func queueImportJob(t *testing.T, f *fixture, records ...models.ImportRecord) int64 {
+	t.Helper()
+
+	id, err := f.repo.CreateImportJob(context.Background(), commands.CreateImportJob{
+		Source:  valueobjects.ImportFileSource,
+		Actor:   "admin",
+		Records: records,
+	})
+	if err != nil {
+		t.Fatalf("CreateImportJob: %v", err)
+	}
+
+	return id
+}
+
+func TestFileUsecaseRunImportJob(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	id := queueImportJob(t, f,
+		importRecord("Сети", "smirnov@example.com", "volkov@example.com"),
+		importRecord("Базы данных", "petrov@example.com", "sidorova@example.com"),
+	)
+
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the queued job run")
+	}
+	if usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want nothing left to run")
+	}
+
+	job, err := usecase.GetImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if job.Status != valueobjects.ImportCompletedStatus || job.Attempts != 1 {
+		t.Errorf("job = %s after %d attempts, want completed after 1", job.Status, job.Attempts)
+	}
+	// the second record is the fixture debt
+	if job.Processed != 2 || job.Created != 1 || job.Existing != 1 || job.Failed != 0 {
+		t.Errorf("job counters = %+v, want 1 created and 1 existing", job)
+	}
+
+	if _, err := usecase.RerunImportJob(ctx, id); err != nil {
+		t.Fatalf("RerunImportJob: %v", err)
+	}
+	if _, err := usecase.RerunImportJob(ctx, id); !e.Is(err, errors.ErrImportJobActive) {
+		t.Errorf("RerunImportJob of a queued job = %v, want %v", err, errors.ErrImportJobActive)
+	}
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the requeued job run")
+	}
+
+	job, err = usecase.GetImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	// the rerun finds what the first run imported
+	if job.Attempts != 2 || job.Created != 0 || job.Existing != 2 {
+		t.Errorf("rerun job = %+v, want nothing created on the second attempt", job)
+	}
+}
+
+func TestFileUsecaseRerunImportJobOfAnonymizedStudent(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	id := queueImportJob(t, f, importRecord("Сети", "smirnov@example.com", "sidorova@example.com"))
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the queued job run")
+	}
+	students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"smirnov@example.com"}})
+	if err != nil || len(students) != 1 {
+		t.Fatalf("SearchStudents = %+v, %v, want the imported student", students, err)
+	}
+	if err := NewPersonalDataUsecase(f.repo).ErasePersonalData(ctx, students[0].UUID, adminUUID); err != nil {
+		t.Fatalf("ErasePersonalData: %v", err)
+	}
+
+	// the job keeps the anonymized student, the rerun finds their debt
+	jobs, err := f.repo.GetImportJobs(ctx, query.GetImportJobsFilters{IDs: []int64{id}, WithRecords: true})
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("GetImportJobs = %+v, %v, want the job", jobs, err)
+	}
+	anonymized := valueobjects.AnonymizedEmailPrefix + students[0].UUID
+	if records := jobs[0].Records; len(records) != 1 || records[0].StudentEmail != anonymized || records[0].StudentLastName != "" {
+		t.Errorf("records = %+v, want the student anonymized", records)
+	}
+	if _, err := usecase.RerunImportJob(ctx, id); err != nil {
+		t.Fatalf("RerunImportJob: %v", err)
+	}
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the requeued job run")
+	}
+	if job, err := usecase.GetImportJob(ctx, id); err != nil || job.Created != 0 || job.Existing != 1 {
+		t.Errorf("rerun job = %+v, %v, want the debt of the anonymized student existing", job, err)
+	}
+	found, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"smirnov@example.com"}})
+	if err != nil || len(found) != 0 {
+		t.Errorf("SearchStudents after the rerun = %+v, %v, want the student not created again", found, err)
+	}
+
+	// a purged anonymized student is not created from the records either
+	_, err = usecase.CreateStudentIfNotExists(ctx, types.Student{
+		Email: valueobjects.AnonymizedEmailPrefix + "00000000-0000-0000-0000-000000000000",
+		Group: &types.Group{Name: "ИВТ-41"},
+	}, nil)
+	if !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("CreateStudentIfNotExists of an anonymized email: err = %v, want %v", err, errors.ErrInvalidData)
+	}
+}
+
+func TestFileUsecaseRollbackImportJob(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	id := queueImportJob(t, f,
+		importRecord("Сети", "smirnov@example.com", "volkov@example.com"),
+		importRecord("Базы данных", "kuznetsov@example.com", "sidorova@example.com"),
+	)
+
+	if _, err := usecase.RollbackImportJob(ctx, id); !e.Is(err, errors.ErrImportJobActive) {
+		t.Errorf("RollbackImportJob of a queued job = %v, want %v", err, errors.ErrImportJobActive)
+	}
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the queued job run")
+	}
+
+	students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"kuznetsov@example.com"}})
+	if err != nil || len(students) != 1 {
+		t.Fatalf("SearchStudents = %+v, %v, want the imported student", students, err)
+	}
+	// the second student asks for a retake, so the debt has to stay
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{StudentUUIDs: []string{students[0].UUID}})
+	if err != nil || len(debts) != 1 {
+		t.Fatalf("GetDebts = %+v, %v, want the imported debt", debts, err)
+	}
+	if _, err := NewStudentUsecase(f.repo).RequestRetake(ctx, students[0].UUID, debts[0].ID, nil); err != nil {
+		t.Fatalf("RequestRetake: %v", err)
+	}
+
+	report, err := usecase.RollbackImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("RollbackImportJob: %v", err)
+	}
+	// the first record goes as a whole: its debt, student, teacher and exam,
+	// the group stays with the second student
+	if report.Deleted != 4 {
+		t.Errorf("deleted = %d, want 4", report.Deleted)
+	}
+	kept := make(map[string][]string)
+	for _, item := range report.Kept {
+		kept[item.Entity] = item.References
+	}
+	if len(kept) != 3 {
+		t.Errorf("kept = %+v, want the debt, the student and the group", report.Kept)
+	}
+	if references := kept[valueobjects.ImportDebtEntity]; len(references) != 1 || references[0] != "retake_requests" {
+		t.Errorf("debt kept by %v, want retake_requests", references)
+	}
+
+	if teachers, err := f.repo.SearchTeachers(ctx, query.SearchTeacherFilters{Emails: []string{"volkov@example.com"}}); err != nil || len(teachers) != 0 {
+		t.Errorf("teachers = %+v, %v, want the imported teacher deleted", teachers, err)
+	}
+	if exams, err := f.repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Базы данных"}}); err != nil || len(exams) != 1 {
+		t.Errorf("exams = %+v, %v, want the fixture exam kept", exams, err)
+	}
+
+	job, err := usecase.GetImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if job.RolledBackAt == nil {
+		t.Error("job: want RolledBackAt set")
+	}
+}
+
+// failingTeachers fails CreateTeacher for its emails, so a record fails
+// after the exam, the group and the student of it are created.
+type failingTeachers struct {
+	repositories.Repository
+	emails []string
+}
+
+func (this failingTeachers) CreateTeacher(ctx context.Context, teacher commands.CreateTeacher) (string, error) {
+	if slices.Contains(this.emails, teacher.Email) {
+		return "", errors.ErrInvalidData
+	}
+	return this.Repository.CreateTeacher(ctx, teacher)
+}
+
+func TestFileUsecaseRollbackPartlyImportedJob(t *testing.T) {
+	ctx := context.Background()
+	_, f := newFileUsecase(t)
+	usecase := NewFileUsecase(failingTeachers{Repository: f.repo, emails: []string{"broken@example.com"}}).(*fileUsecase)
+
+	failing := importRecord("Физика", "kuznetsov@example.com", "broken@example.com")
+	failing.GroupName = "ИВТ-44"
+	id := queueImportJob(t, f,
+		importRecord("Сети", "smirnov@example.com", "volkov@example.com"),
+		failing,
+	)
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the queued job run")
+	}
+
+	job, err := usecase.GetImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if job.Created != 1 || job.Failed != 1 {
+		t.Errorf("job counters = %+v, want 1 created and 1 failed", job)
+	}
+
+	// nothing of the failed record is left behind
+	if exams, err := f.repo.SearchExams(ctx, query.SearchExamFilters{Names: []string{"Физика"}}); err != nil || len(exams) != 0 {
+		t.Errorf("exams = %+v, %v, want the exam of the failed record gone", exams, err)
+	}
+	if groups, err := f.repo.SearchGroups(ctx, query.SearchGroupFilters{Names: []string{"ИВТ-44"}}); err != nil || len(groups) != 0 {
+		t.Errorf("groups = %+v, %v, want the group of the failed record gone", groups, err)
+	}
+	if students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"kuznetsov@example.com"}}); err != nil || len(students) != 0 {
+		t.Errorf("students = %+v, %v, want the student of the failed record gone", students, err)
+	}
+
+	// the job tracks the group, student, teacher, exam and debt of the
+	// first record only, the rollback removes all of them
+	report, err := usecase.RollbackImportJob(ctx, id)
+	if err != nil {
+		t.Fatalf("RollbackImportJob: %v", err)
+	}
+	if report.Deleted != 5 || len(report.Kept) != 0 {
+		t.Errorf("RollbackImportJob = %d deleted, %+v kept, want 5 deleted and nothing kept", report.Deleted, report.Kept)
+	}
+	if students, err := f.repo.SearchStudents(ctx, query.SearchStudentFilters{Emails: []string{"smirnov@example.com"}}); err != nil || len(students) != 0 {
+		t.Errorf("students = %+v, %v, want the imported student deleted", students, err)
+	}
+	if debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{}); err != nil || len(debts) != 1 || debts[0].ID != f.debtID {
+		t.Errorf("debts = %+v, %v, want only the fixture debt", debts, err)
+	}
+}
+
+func TestFileUsecaseImportProfiles(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newFileUsecase(t)
+
+	profile := types.ImportProfile{
+		Name:      "деканат",
+		Layout:    valueobjects.ImportListLayout,
+		HeaderRow: 1,
+		Columns: map[string]string{
+			valueobjects.ImportGroupField:                                       "A",
+			valueobjects.ImportStudentPrefix + valueobjects.ImportFullNameField: "B",
+			valueobjects.ImportStudentPrefix + valueobjects.ImportEmailField:    "C",
+			valueobjects.ImportTeacherPrefix + valueobjects.ImportFullNameField: "D",
+			valueobjects.ImportTeacherPrefix + valueobjects.ImportEmailField:    "E",
+			valueobjects.ImportExamColumn:                                       "F",
+		},
+	}
+	id, err := usecase.CreateImportProfile(ctx, profile)
+	if err != nil {
+		t.Fatalf("CreateImportProfile: %v", err)
+	}
+	if _, err := usecase.CreateImportProfile(ctx, profile); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("CreateImportProfile with a taken name = %v, want %v", err, errors.ErrInvalidData)
+	}
+
+	stored, err := usecase.GetImportProfile(ctx, id)
+	if err != nil {
+		t.Fatalf("GetImportProfile: %v", err)
+	}
+	if stored.Name != "деканат" || stored.Columns[valueobjects.ImportExamColumn] != "F" {
+		t.Errorf("GetImportProfile = %+v, want the stored profile", stored)
+	}
+
+	if err := usecase.DeleteImportProfile(ctx, id); err != nil {
+		t.Fatalf("DeleteImportProfile: %v", err)
+	}
+	if _, err := usecase.GetImportProfile(ctx, id); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("GetImportProfile of a deleted profile = %v, want %v", err, errors.ErroNoItemsFound)
+	}
+}
+
+// newMatrixWorkbook returns a workbook of the default profile with the
+// students in the header row and a teacher with the exams per row below it.
+func newMatrixWorkbook(t *testing.T, students []string, rows ...[]string) *excelize.File {
+	t.Helper()
+
+	f := excelize.NewFile()
+	t.Cleanup(func() { f.Close() })
+	sheet := f.GetSheetName(0)
+	header := append([]any{""}, toCells(students)...)
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		t.Fatalf("SetSheetRow: %v", err)
+	}
+	for i, row := range rows {
+		cells := toCells(row)
+		if err := f.SetSheetRow(sheet, "A"+strconv.Itoa(i+2), &cells); err != nil {
+			t.Fatalf("SetSheetRow: %v", err)
+		}
+	}
+	return f
+}
+
+func toCells(values []string) []any {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
+
+func debtEmails(t *testing.T, repo repositories.Repository, exam string) []string {
+	t.Helper()
+
+	debts, err := repo.GetDebts(context.Background(), query.GetDebtsFilters{})
+	if err != nil {
+		t.Fatalf("GetDebts: %v", err)
+	}
+	var emails []string
+	for _, debt := range debts {
+		if debt.Exam.Name == exam {
+			emails = append(emails, debt.Student.Email)
+		}
+	}
+	slices.Sort(emails)
+	return emails
+}
+
+func TestFileUsecaseConfirmImport(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	teacher := "Волков Сергей Петрович volkov@example.com"
+	previewed, err := usecase.PreviewImport(ctx, adminUUID, 0, newMatrixWorkbook(t,
+		[]string{
+			"Смирнов Алексей Игоревич ИВТ-43 smirnov@example.com",
+			"Кузнецова Ольга Павловна ИВТ-43 kuznetsova@example.com",
+			"Орлов",
+		},
+		[]string{teacher, "Сети", "Сети"},
+	))
+	if err != nil {
+		t.Fatalf("PreviewImport: %v", err)
+	}
+	if previewed.Token == "" || len(previewed.NewDebts) != 2 || len(previewed.ExistingDebts) != 0 || len(previewed.Issues) != 1 {
+		t.Fatalf("PreviewImport = %+v, want 2 new debts and the student without an email as an issue", previewed)
+	}
+	if !slices.Equal(previewed.Groups.New, []string{"ИВТ-43"}) || !slices.Equal(previewed.Teachers.New, []string{"volkov@example.com"}) {
+		t.Errorf("PreviewImport diff = %+v, %+v, want the group and the teacher new", previewed.Groups, previewed.Teachers)
+	}
+	// a preview writes nothing but itself
+	if emails := debtEmails(t, f.repo, "Сети"); len(emails) != 0 {
+		t.Errorf("debts after the preview = %v, want none", emails)
+	}
+
+	// another preview of the same teacher is left unconfirmed
+	if _, err := usecase.PreviewImport(ctx, adminUUID, 0, newMatrixWorkbook(t,
+		[]string{"Белова Анна Ильинична ИВТ-43 belova@example.com"},
+		[]string{teacher, "Сети"},
+	)); err != nil {
+		t.Fatalf("PreviewImport: %v", err)
+	}
+
+	job, err := usecase.ConfirmImport(ctx, adminUUID, previewed.Token)
+	if err != nil {
+		t.Fatalf("ConfirmImport: %v", err)
+	}
+	if job.Source != valueobjects.ImportPreviewSource || job.Status != valueobjects.ImportQueuedStatus ||
+		job.Total != 2 || len(job.Issues) != 1 || job.PreviewToken == nil || *job.PreviewToken != previewed.Token {
+		t.Errorf("ConfirmImport = %+v, want a queued job of the previewed records", job)
+	}
+	if _, err := usecase.ConfirmImport(ctx, adminUUID, previewed.Token); !e.Is(err, errors.ErrImportPreviewClosed) {
+		t.Errorf("ConfirmImport twice: err = %v, want ErrImportPreviewClosed", err)
+	}
+	if report, err := usecase.GetImportPreview(ctx, previewed.Token); err != nil || report.ConfirmedAt == nil {
+		t.Errorf("GetImportPreview = %+v, %v, want the preview confirmed", report, err)
+	}
+
+	if !usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want the confirmed job run")
+	}
+	if usecase.runImportJob(ctx) {
+		t.Fatal("runImportJob: want one job for one confirmation")
+	}
+	if job, err = usecase.GetImportJob(ctx, job.ID); err != nil || job.Status != valueobjects.ImportCompletedStatus || job.Created != 2 {
+		t.Errorf("GetImportJob = %+v, %v, want 2 debts created", job, err)
+	}
+	if emails := debtEmails(t, f.repo, "Сети"); !slices.Equal(emails, []string{"kuznetsova@example.com", "smirnov@example.com"}) {
+		t.Errorf("debts = %v, want the previewed ones only", emails)
+	}
+}
+
+func TestFileUsecaseConfirmImportRejects(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+
+	expired := "expired-token"
+	if err := f.repo.CreateImportPreview(ctx, commands.CreateImportPreview{
+		Token:     expired,
+		CreatedBy: adminUUID,
+		Records:   []models.ImportRecord{importRecord("Сети", "smirnov@example.com", "volkov@example.com")},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatalf("CreateImportPreview: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"expired token", expired, errors.ErrImportPreviewClosed},
+		{"unknown token", "unknown-token", errors.ErroNoItemsFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := usecase.ConfirmImport(ctx, adminUUID, tc.token); !e.Is(err, tc.want) {
+				t.Errorf("ConfirmImport: err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	if usecase.runImportJob(ctx) {
+		t.Error("runImportJob: want no job of a rejected confirmation")
+	}
+	if emails := debtEmails(t, f.repo, "Сети"); len(emails) != 0 {
+		t.Errorf("debts = %v, want none", emails)
+	}
+}
+
+// addExportDebts adds a second exam of the fixture student and a student
+// with a middle name in another group, both owing the fixture teacher.
+func addExportDebts(t *testing.T, f *fixture) {
+	t.Helper()
+
+	ctx := context.Background()
+	examID, err := f.repo.CreateExam(ctx, commands.CreateExam{Name: "Сети", AssessmentType: valueobjects.AssessmentCredit})
+	if err != nil {
+		t.Fatalf("create exam: %v", err)
+	}
+	groupID, err := f.repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-42"})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	student, err := f.repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName:  "Ольга",
+		LastName:   "Кузнецова",
+		MiddleName: "Павловна",
+		Email:      "kuznetsova@example.com",
+		GroupID:    groupID,
+	})
+	if err != nil {
+		t.Fatalf("create student: %v", err)
+	}
+	for _, debt := range []commands.CreateDebt{
+		{ExamID: examID, StudentUUID: f.student, TeacherUUID: f.teacher},
+		{ExamID: f.examID, StudentUUID: student, TeacherUUID: f.teacher},
+	} {
+		if _, err := f.repo.CreateDebt(ctx, debt); err != nil {
+			t.Fatalf("create debt: %v", err)
+		}
+	}
+}
+
+func TestFileUsecaseExportDebts(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+	addExportDebts(t, f)
+
+	book, err := usecase.ExportDebts(ctx, types.DebtFilters{}, types.ExportListLayout)
+	if err != nil {
+		t.Fatalf("ExportDebts: %v", err)
+	}
+	defer book.Close()
+	if sheets := book.GetSheetList(); !slices.Equal(sheets, []string{"ИВТ-41", "ИВТ-42"}) {
+		t.Fatalf("sheets = %v, want a sheet per group", sheets)
+	}
+	rows, err := book.GetRows("ИВТ-41")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	if len(rows) != 3 || rows[0][1] != "Студент" || rows[1][1] != "Петров Иван" || rows[1][2] != "petrov@example.com" {
+		t.Errorf("rows = %v, want the header and the 2 debts of the student", rows)
+	}
+
+	matrix, err := usecase.ExportDebts(ctx, types.DebtFilters{}, types.ExportMatrixLayout)
+	if err != nil {
+		t.Fatalf("ExportDebts: %v", err)
+	}
+	defer matrix.Close()
+	rows, err = matrix.GetRows("ИВТ-41")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	// the second exam of the student goes to a row of its own
+	want := [][]string{
+		{"Преподаватель \\ Студент", "Петров Иван  ИВТ-41 petrov@example.com"},
+		{"Сидорова Анна  sidorova@example.com", "Базы данных"},
+		{"Сидорова Анна  sidorova@example.com", "Сети"},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("matrix rows = %q, want %q", rows, want)
+	}
+
+	if _, err := usecase.ExportDebts(ctx, types.DebtFilters{}, "pdf"); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("ExportDebts of an unknown layout: err = %v, want ErrInvalidData", err)
+	}
+}
+
+func TestFileUsecaseExportDebtsMatrixReimports(t *testing.T) {
+	ctx := context.Background()
+	usecase, f := newFileUsecase(t)
+	addExportDebts(t, f)
+
+	book, err := usecase.ExportDebts(ctx, types.DebtFilters{}, types.ExportMatrixLayout)
+	if err != nil {
+		t.Fatalf("ExportDebts: %v", err)
+	}
+	defer book.Close()
+
+	records, issues, err := parseWorkbook(book, defaultImportProfile)
+	if err != nil {
+		t.Fatalf("parseWorkbook: %v", err)
+	}
+	if len(issues) != 0 || len(records) != 3 {
+		t.Fatalf("parseWorkbook = %+v, %+v, want the 3 debts without issues", records, issues)
+	}
+	// the empty middle names keep their place among the fields
+	petrov := records[0]
+	if petrov.StudentLastName != "Петров" || petrov.StudentFirstName != "Иван" || petrov.StudentMiddleName != "" ||
+		petrov.GroupName != "ИВТ-41" || petrov.StudentEmail != "petrov@example.com" ||
+		petrov.TeacherMiddleName != "" || petrov.TeacherEmail != "sidorova@example.com" {
+		t.Errorf("record = %+v, want the fixture student and teacher", petrov)
+	}
+	if kuznetsova := records[2]; kuznetsova.StudentMiddleName != "Павловна" || kuznetsova.GroupName != "ИВТ-42" {
+		t.Errorf("record = %+v, want the student of the second group", kuznetsova)
+	}
+
+	report, err := usecase.PreviewImport(ctx, adminUUID, 0, book)
+	if err != nil {
+		t.Fatalf("PreviewImport: %v", err)
+	}
+	if len(report.NewDebts) != 0 || len(report.ExistingDebts) != 3 || len(report.Issues) != 0 ||
+		len(report.Students.New) != 0 || len(report.Teachers.New) != 0 || len(report.Groups.New) != 0 {
+		t.Errorf("PreviewImport = %+v, want every debt found", report)
+	}
+}
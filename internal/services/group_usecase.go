@@ -59,31 +59,35 @@ func (g *groupUsecase) GetGroupByID(ctx context.Context, id int64) (*types.Group
 }
 
 // GetGroups implements logic.GroupUsecase.
-func (g *groupUsecase) GetGroups(ctx context.Context, limit int64, offset int64) ([]types.Group, error) {
-	groups, err := g.repo.GetGroups(ctx, query.GetGroupsFilters{
-		Limit:  limit,
-		Offset: offset,
-	})
+func (g *groupUsecase) GetGroups(ctx context.Context, filters types.GroupFilters, params types.ListParams) (*types.Page[types.Group], error) {
+	keyset, limit, err := listKeyset(query.GroupSortFields, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
 	}
 
-	result := make([]types.Group, len(groups))
-	for i, group := range groups {
-		result[i] = types.GroupFromDomain(&group)
+	groupFilters := query.GetGroupsFilters{
+		IDs:    filters.IDs,
+		Names:  filters.Names,
+		Limit:  limit + 1,
+		Keyset: keyset,
 	}
-
+	groups, err := g.repo.GetGroups(ctx, groupFilters)
 	if err != nil {
-		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	total, err := g.repo.CountGroups(ctx, groupFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
 	}
 
-	return result, nil
+	return listPage(groups, query.GroupSortFields, keyset, limit, total, types.GroupFromDomain), nil
 }
 
 // UpdateGroup implements logic.GroupUsecase.
 func (g *groupUsecase) UpdateGroup(ctx context.Context, group types.Group) error {
-	err := g.repo.UpdateGroup(ctx, commands.UpdateGroup{ID: group.ID, Name: group.Name})
+	err := g.repo.UpdateGroup(ctx, commands.UpdateGroup{ID: group.ID, Name: group.Name, Version: group.Version})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return err
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+const (
+	defaultListLimit int64 = 20
+	maxListLimit     int64 = 100
+)
+
+// listCursor is what an opaque cursor encodes: the sort it was made for and
+// the sort values of the last item of the page.
+type listCursor struct {
+	Sort  string   `json:"sort"`
+	After []string `json:"after"`
+}
+
+// listKeyset reads the sort, the cursor and the page size of the params. The
+// first of the fields is unique, it is appended to every sort so that items
+// with equal values never straddle two pages.
+func listKeyset[T any](fields []query.SortField[T], params types.ListParams) (query.Keyset, int64, error) {
+	limit := params.Limit
+	switch {
+	case limit < 0:
+		return query.Keyset{}, 0, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "negative limit")
+	case limit == 0:
+		limit = defaultListLimit
+	case limit > maxListLimit:
+		limit = maxListLimit
+	}
+
+	var keyset query.Keyset
+	if params.Sort != "" {
+		for _, field := range strings.Split(params.Sort, ",") {
+			sort := query.Sort{Field: strings.TrimSpace(field)}
+			if name, ok := strings.CutPrefix(sort.Field, "-"); ok {
+				sort = query.Sort{Field: name, Desc: true}
+			}
+			known := slices.ContainsFunc(fields, func(field query.SortField[T]) bool { return field.Name == sort.Field })
+			taken := slices.ContainsFunc(keyset.Sort, func(taken query.Sort) bool { return taken.Field == sort.Field })
+			if !known || taken {
+				return query.Keyset{}, 0, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "invalid sort", "field", field)
+			}
+			keyset.Sort = append(keyset.Sort, sort)
+		}
+	}
+	if key := fields[0].Name; !slices.ContainsFunc(keyset.Sort, func(sort query.Sort) bool { return sort.Field == key }) {
+		keyset.Sort = append(keyset.Sort, query.Sort{Field: key})
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeCursor(params.Cursor)
+		if err != nil || cursor.Sort != formatSort(keyset.Sort) || len(cursor.After) != len(keyset.Sort) {
+			return query.Keyset{}, 0, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "cursor does not match the sort")
+		}
+		keyset.After = cursor.After
+	}
+
+	return keyset, limit, nil
+}
+
+// listPage makes a page of the items fetched with a limit of one more than
+// the page size, the extra item only tells that there is a next page.
+func listPage[T, R any](items []T, fields []query.SortField[T], keyset query.Keyset, limit, total int64, convert func(*T) R) *types.Page[R] {
+	result := &types.Page[R]{Items: make([]R, 0, len(items)), Total: total}
+
+	if int64(len(items)) > limit {
+		items = items[:limit]
+
+		last := items[len(items)-1]
+		cursor := listCursor{Sort: formatSort(keyset.Sort)}
+		for _, sort := range keyset.Sort {
+			index := slices.IndexFunc(fields, func(field query.SortField[T]) bool { return field.Name == sort.Field })
+			cursor.After = append(cursor.After, fields[index].Value(last))
+		}
+		result.NextCursor = encodeCursor(cursor)
+	}
+	for i := range items {
+		result.Items = append(result.Items, convert(&items[i]))
+	}
+
+	return result
+}
+
+func formatSort(sorts []query.Sort) string {
+	fields := make([]string, len(sorts))
+	for i, sort := range sorts {
+		fields[i] = sort.Field
+		if sort.Desc {
+			fields[i] = "-" + sort.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+func encodeCursor(cursor listCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (listCursor, error) {
+	var cursor listCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+// debtsQuery turns the debt filters of the usecases into the filters of the
+// repository.
+func debtsQuery(filters types.DebtFilters) query.GetDebtsFilters {
+	return query.GetDebtsFilters{
+		StudentUUIDs:    filters.StudentUUIDs,
+		TeacherUUIDs:    filters.TeacherUUIDs,
+		ExamIDs:         filters.ExamIDs,
+		DebtIDs:         filters.DebtIDs,
+		GroupIDs:        filters.GroupIDs,
+		OnlyUnscheduled: filters.OnlyUnscheduled,
+		IncludeClosed:   filters.IncludeClosed,
+		ScheduledFrom:   filters.ScheduledFrom,
+		ScheduledTo:     filters.ScheduledTo,
+		Limit:           filters.Limit,
+		Offset:          filters.Offset,
+	}
+}
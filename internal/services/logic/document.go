@@ -0,0 +1,10 @@
+package logic
+
+import "context"
+
+type DocumentUsecase interface {
+	// RetakeSessionSheet takes the teacher of the session, empty for admins.
+	RetakeSessionSheet(context.Context, int64, string) ([]byte, error)
+	// ReferralSlip takes the teacher or the student of the debt, both empty for admins.
+	ReferralSlip(context.Context, int64, string, string) ([]byte, error)
+}
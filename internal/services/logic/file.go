@@ -4,8 +4,32 @@ import (
 	"context"
 
 	"github.com/xuri/excelize/v2"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
 )
 
 type FileUsecase interface {
-	ParseFile(context.Context, *excelize.File) error
+	// ImportFile parses the workbook and queues an import job, profile 0
+	// reads it with the default matrix profile
+	ImportFile(context.Context, types.ImportUpload, *excelize.File) (*types.ImportJob, error)
+	PreviewImport(ctx context.Context, adminUUID string, profileID int64, f *excelize.File) (*types.ImportReport, error)
+	GetImportPreview(ctx context.Context, token string) (*types.ImportReport, error)
+	ConfirmImport(ctx context.Context, adminUUID, token string) (*types.ImportJob, error)
+	GetImportJob(context.Context, int64) (*types.ImportJob, error)
+	GetImportJobs(context.Context, types.ImportJobFilters) ([]types.ImportJob, error)
+	RerunImportJob(context.Context, int64) (*types.ImportJob, error)
+	// RollbackImportJob deletes what the job created unless something
+	// references it since, the report lists what was kept.
+	RollbackImportJob(context.Context, int64) (*types.ImportRollback, error)
+	// RunImportJobs runs queued import jobs until the context is done.
+	RunImportJobs(context.Context)
+	BulkImport(context.Context, types.BulkImport) (*types.BulkImportResult, error)
+	CreateImportProfile(context.Context, types.ImportProfile) (int64, error)
+	UpdateImportProfile(context.Context, types.ImportProfile) error
+	DeleteImportProfile(context.Context, int64) error
+	GetImportProfile(context.Context, int64) (*types.ImportProfile, error)
+	GetImportProfiles(ctx context.Context, limit, offset int64) ([]types.ImportProfile, error)
+	ExportDebts(context.Context, types.DebtFilters, string) (*excelize.File, error)
+	ExportTimetable(context.Context, types.DebtFilters) (*excelize.File, error)
+	ExportStudents(context.Context, []int64) (*excelize.File, error)
 }
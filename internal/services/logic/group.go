@@ -11,5 +11,5 @@ type GroupUsecase interface {
 	DeleteGroup(context.Context, int64) error
 	UpdateGroup(context.Context, types.Group) error
 	GetGroupByID(context.Context, int64) (*types.Group, error)
-	GetGroups(context.Context, int64, int64) ([]types.Group, error)
+	GetGroups(context.Context, types.GroupFilters, types.ListParams) (*types.Page[types.Group], error)
 }
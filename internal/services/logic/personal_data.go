@@ -0,0 +1,26 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type PersonalDataUsecase interface {
+	// SetStudentStatus marks a student graduated or expelled at leftAt, or
+	// active again with zero leftAt.
+	SetStudentStatus(ctx context.Context, uuid, status string, leftAt time.Time) error
+	// ExportPersonalData returns everything kept about the student and
+	// audits the export as done by the actor.
+	ExportPersonalData(ctx context.Context, uuid, actorUUID string) (*types.PersonalData, error)
+	// ErasePersonalData anonymizes the student at once. The debts and the
+	// results stay for the statistics, the audit keeps who erased it.
+	ErasePersonalData(ctx context.Context, uuid, actorUUID string) error
+	// AnonymizeLeftStudents anonymizes the students who left before the
+	// time and returns how many were anonymized.
+	AnonymizeLeftStudents(ctx context.Context, before time.Time) (int, error)
+	// RunPersonalDataRetention anonymizes the students who left more than
+	// the years ago until the context is done.
+	RunPersonalDataRetention(ctx context.Context, years int)
+}
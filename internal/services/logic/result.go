@@ -0,0 +1,14 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type ResultUsecase interface {
+	RecordResult(context.Context, string, types.DebtResultEntry) (*types.DebtResult, error)
+	RecordSessionResults(context.Context, string, int64, []types.DebtResultEntry) ([]types.DebtResultReport, error)
+	GetDebtResults(context.Context, int64) ([]types.DebtResult, error)
+	GetStudentResults(context.Context, string) ([]types.DebtResult, error)
+}
@@ -0,0 +1,17 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type RetakeSessionUsecase interface {
+	CreateSession(context.Context, string, types.RetakeSession, bool) (int64, []types.ScheduleConflict, error)
+	GetTeacherSessions(context.Context, string) ([]types.RetakeSession, error)
+	GetSessionBookings(context.Context, string, int64) ([]types.RetakeBooking, error)
+	GetAvailableSessions(context.Context, string) ([]types.RetakeSession, error)
+	GetStudentBookings(context.Context, string) ([]types.RetakeBooking, error)
+	Book(context.Context, string, int64, int64) (*types.RetakeBooking, error)
+	CancelBooking(context.Context, string, int64) error
+}
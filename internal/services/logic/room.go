@@ -0,0 +1,15 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type RoomUsecase interface {
+	CreateRoom(context.Context, types.Room) (int64, error)
+	UpdateRoom(context.Context, types.Room) error
+	DeleteRoom(context.Context, int64) error
+	GetRoom(context.Context, int64) (*types.Room, error)
+	GetRooms(context.Context, types.RoomFilters) ([]types.Room, error)
+}
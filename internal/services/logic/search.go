@@ -0,0 +1,11 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type SearchUsecase interface {
+	Search(context.Context, types.SearchRequest) ([]types.SearchHit, error)
+}
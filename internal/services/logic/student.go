@@ -8,12 +8,12 @@ import (
 
 type StudentUsecase interface {
 	GetAllDebts(context.Context, string) ([]types.Debt, error)
-	SendNotification(context.Context, string, int64) error
+	RequestRetake(context.Context, string, int64, []types.TimeSlot) (int64, error)
 	GetStudentByEmail(context.Context, string) ([]types.Student, error)
 	DeleteStudent(context.Context, string) error
 	UpdateStudent(context.Context, types.Student) error
 	CreateStudent(context.Context, types.Student) (string, error)
-	GetStudents(context.Context, int64, int64) ([]types.Student, error)
+	GetStudents(context.Context, types.StudentFilters, types.ListParams) (*types.Page[types.Student], error)
 	GetStudent(context.Context, string) (*types.Student, error)
 	ChangePassword(context.Context, string, string) error
 	GetAmountOfDebts(context.Context, string) (int64, error)
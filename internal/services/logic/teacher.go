@@ -7,13 +7,16 @@ import (
 )
 
 type TeacherUsecase interface {
-	SetDate(context.Context, string, string, string, int64) error
+	SetDate(context.Context, string, types.SetDateRequest) (*types.SetDateReport, error)
 	GetAllDebts(context.Context, string) ([]types.Debt, error)
 	GetTeacherByEmail(context.Context, string) ([]types.Teacher, error)
 	DeleteTeacher(context.Context, string) error
 	UpdateTeacher(context.Context, types.Teacher) error
 	CreateTeacher(context.Context, types.Teacher) (string, error)
-	GetTeachers(context.Context, int64, int64) ([]types.Teacher, error)
+	GetTeachers(context.Context, types.TeacherFilters, types.ListParams) (*types.Page[types.Teacher], error)
 	GetTeacher(context.Context, string) (types.Teacher, error)
 	ChangePassword(context.Context, string, string) error
+	GetRetakeRequests(context.Context, string, []string) ([]types.RetakeRequest, error)
+	UpdateRetakeRequestStatus(context.Context, string, int64, string) error
+	SuggestRetakeSlot(context.Context, string, int64) (*types.SlotSuggestion, error)
 }
@@ -0,0 +1,14 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type TeacherAvailabilityUsecase interface {
+	GetAvailability(context.Context, string) ([]types.TeacherAvailability, error)
+	CreateAvailability(context.Context, string, types.TeacherAvailability) (int64, error)
+	UpdateAvailability(context.Context, string, types.TeacherAvailability) error
+	DeleteAvailability(context.Context, string, int64) error
+}
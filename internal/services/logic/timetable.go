@@ -0,0 +1,15 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type TimetableUsecase interface {
+	GenerateDraft(context.Context, string, types.TimetableRequest) (*types.TimetableDraft, error)
+	GetDrafts(context.Context, []string) ([]types.TimetableDraft, error)
+	GetDraft(context.Context, int64) (*types.TimetableDraft, error)
+	ApplyDraft(context.Context, int64) ([]types.ScheduleConflict, error)
+	DiscardDraft(context.Context, int64) error
+}
@@ -0,0 +1,19 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+type TrashUsecase interface {
+	GetTrash(context.Context, types.TrashFilters) ([]types.TrashItem, error)
+	RestoreDeleted(ctx context.Context, kind, id string) error
+	// PurgeTrash drops the entities deleted before the time and returns how
+	// many were dropped.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	// RunTrashPurge purges the entities kept in the trash longer than the
+	// retention until the context is done.
+	RunTrashPurge(ctx context.Context, retention time.Duration)
+}
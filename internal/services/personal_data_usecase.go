@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type personalDataUsecase struct {
+	repo repositories.Repository
+}
+
+func NewPersonalDataUsecase(repo repositories.Repository) logic.PersonalDataUsecase {
+	return &personalDataUsecase{
+		repo: repo,
+	}
+}
+
+// SetStudentStatus implements logic.PersonalDataUsecase.
+func (p *personalDataUsecase) SetStudentStatus(ctx context.Context, uuid, status string, leftAt time.Time) error {
+	err := p.repo.SetStudentStatus(ctx, commands.SetStudentStatus{
+		UUID:   uuid,
+		Status: status,
+		LeftAt: leftAt,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// ExportPersonalData implements logic.PersonalDataUsecase. The export is
+// audited in the same transaction it is read in. A student in the trash is
+// exported too.
+func (p *personalDataUsecase) ExportPersonalData(ctx context.Context, uuid, actorUUID string) (*types.PersonalData, error) {
+	var result types.PersonalData
+	err := p.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		status, err := p.repo.GetStudentStatus(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		result.Status = types.StudentStatusFromDomain(status)
+
+		student, err := p.repo.GetStudentPersonalData(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		result.Student = types.StudentFromDomain(student)
+		// the hash is not personal data the student gave
+		result.Student.Password = ""
+
+		debts, err := p.repo.GetDebts(ctx, query.GetDebtsFilters{
+			StudentUUIDs:   []string{uuid},
+			IncludeClosed:  true,
+			IncludeDeleted: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, debt := range debts {
+			result.Debts = append(result.Debts, types.DebtFromDomain(&debt))
+		}
+
+		results, err := p.repo.GetDebtResults(ctx, query.GetDebtResultsFilters{StudentUUIDs: []string{uuid}})
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			result.Results = append(result.Results, types.DebtResultFromDomain(&r))
+		}
+
+		requests, err := p.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{StudentUUIDs: []string{uuid}})
+		if err != nil {
+			return err
+		}
+		for _, request := range requests {
+			result.RetakeRequests = append(result.RetakeRequests, types.RetakeRequestFromDomain(&request))
+		}
+
+		bookings, err := p.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{StudentUUIDs: []string{uuid}})
+		if err != nil {
+			return err
+		}
+		for _, booking := range bookings {
+			result.RetakeBookings = append(result.RetakeBookings, types.RetakeBookingFromDomain(&booking))
+		}
+
+		if _, err := p.repo.CreatePersonalDataAudit(ctx, commands.CreatePersonalDataAudit{
+			StudentUUID: uuid,
+			Action:      valueobjects.PersonalDataExportAction,
+			ActorUUID:   actorUUID,
+		}); err != nil {
+			return err
+		}
+
+		audit, err := p.repo.GetPersonalDataAudit(ctx, query.GetPersonalDataAuditFilters{StudentUUIDs: []string{uuid}})
+		if err != nil {
+			return err
+		}
+		for _, a := range audit {
+			result.Audit = append(result.Audit, types.PersonalDataAuditFromDomain(&a))
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ErasePersonalData implements logic.PersonalDataUsecase. Erasing an
+// anonymized student is audited as well, it changes nothing else. A student
+// in the trash is erased too.
+func (p *personalDataUsecase) ErasePersonalData(ctx context.Context, uuid, actorUUID string) error {
+	err := p.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if _, err := p.repo.GetStudentStatus(ctx, uuid); err != nil {
+			return err
+		}
+		if _, err := p.repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{UUIDs: []string{uuid}}); err != nil {
+			return err
+		}
+
+		_, err := p.repo.CreatePersonalDataAudit(ctx, commands.CreatePersonalDataAudit{
+			StudentUUID: uuid,
+			Action:      valueobjects.PersonalDataEraseAction,
+			ActorUUID:   actorUUID,
+		})
+		return err
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	log.Logger.Info("erased personal data", "student", uuid, "actor", actorUUID)
+	return nil
+}
+
+// AnonymizeLeftStudents implements logic.PersonalDataUsecase. The audit
+// of the retention has no actor.
+func (p *personalDataUsecase) AnonymizeLeftStudents(ctx context.Context, before time.Time) (int, error) {
+	var uuids []string
+	err := p.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		uuids, err = p.repo.AnonymizeStudents(ctx, commands.AnonymizeStudents{LeftBefore: before})
+		if err != nil {
+			return err
+		}
+
+		for _, uuid := range uuids {
+			if _, err := p.repo.CreatePersonalDataAudit(ctx, commands.CreatePersonalDataAudit{
+				StudentUUID: uuid,
+				Action:      valueobjects.PersonalDataAnonymizeAction,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+
+	if len(uuids) != 0 {
+		log.Logger.Info("anonymized students", "count", len(uuids))
+	}
+	return len(uuids), nil
+}
+
+// RunPersonalDataRetention implements logic.PersonalDataUsecase. Zero
+// years is valueobjects.DefaultPersonalDataRetentionYears, negative ones
+// keep the personal data forever.
+func (p *personalDataUsecase) RunPersonalDataRetention(ctx context.Context, years int) {
+	if years < 0 {
+		return
+	}
+	if years == 0 {
+		years = valueobjects.DefaultPersonalDataRetentionYears
+	}
+
+	ticker := time.NewTicker(valueobjects.PersonalDataRetentionInterval)
+	defer ticker.Stop()
+
+	for {
+		// errors are logged, the next tick tries again
+		p.AnonymizeLeftStudents(ctx, time.Now().AddDate(-years, 0, 0))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+const adminUUID = "11111111-1111-1111-1111-111111111111"
+
+func TestPersonalDataUsecaseExportPersonalData(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewPersonalDataUsecase(f.repo)
+
+	data, err := usecase.ExportPersonalData(ctx, f.student, adminUUID)
+	if err != nil {
+		t.Fatalf("ExportPersonalData: %v", err)
+	}
+	if data.Student.Email != "petrov@example.com" || data.Student.Password != "" {
+		t.Errorf("student = %+v, want the email and no password", data.Student)
+	}
+	if data.Status.Status != valueobjects.StudentActiveStatus {
+		t.Errorf("status = %+v, want active", data.Status)
+	}
+	if len(data.Debts) != 1 || data.Debts[0].ID != f.debtID {
+		t.Errorf("debts = %+v, want the debt of the fixture", data.Debts)
+	}
+	if len(data.Audit) != 1 || data.Audit[0].Action != valueobjects.PersonalDataExportAction || data.Audit[0].ActorUUID != adminUUID {
+		t.Errorf("audit = %+v, want the export by the admin", data.Audit)
+	}
+
+	if _, err := usecase.ExportPersonalData(ctx, "00000000-0000-0000-0000-000000000000", adminUUID); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("ExportPersonalData of a missing student: err = %v, want ErroNoItemsFound", err)
+	}
+}
+
+func TestPersonalDataUsecaseErasePersonalData(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewPersonalDataUsecase(f.repo)
+
+	if err := usecase.ErasePersonalData(ctx, f.student, adminUUID); err != nil {
+		t.Fatalf("ErasePersonalData: %v", err)
+	}
+
+	data, err := usecase.ExportPersonalData(ctx, f.student, adminUUID)
+	if err != nil {
+		t.Fatalf("ExportPersonalData: %v", err)
+	}
+	if data.Student.LastName != "" || data.Student.Email != valueobjects.AnonymizedEmailPrefix+f.student {
+		t.Errorf("student = %+v, want anonymized", data.Student)
+	}
+	if data.Status.AnonymizedAt == nil {
+		t.Errorf("status = %+v, want the time of the anonymization", data.Status)
+	}
+	// the debt still counts in the statistics
+	if len(data.Debts) != 1 {
+		t.Errorf("debts = %+v, want the debt kept", data.Debts)
+	}
+	if len(data.Audit) != 2 || data.Audit[0].Action != valueobjects.PersonalDataEraseAction {
+		t.Errorf("audit = %+v, want the erase before the export", data.Audit)
+	}
+	if students, err := f.repo.GetStudents(ctx, query.GetStudentsFilters{Emails: []string{"petrov@example.com"}}); err != nil || len(students) != 0 {
+		t.Errorf("GetStudents by the old email = %+v, %v, want none", students, err)
+	}
+}
+
+func TestPersonalDataUsecaseTrashedStudent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewPersonalDataUsecase(f.repo)
+
+	// the purge keeps a student with debts in the trash
+	if err := f.repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: f.student}); err != nil {
+		t.Fatalf("DeleteStudent: %v", err)
+	}
+	if err := usecase.SetStudentStatus(ctx, f.student, valueobjects.StudentExpelledStatus, time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("SetStudentStatus: %v", err)
+	}
+
+	data, err := usecase.ExportPersonalData(ctx, f.student, adminUUID)
+	if err != nil {
+		t.Fatalf("ExportPersonalData: %v", err)
+	}
+	if data.Student.Email != "petrov@example.com" || data.Status.DeletedAt == nil || data.Status.Status != valueobjects.StudentExpelledStatus {
+		t.Errorf("export = %+v, %+v, want the expelled student in the trash", data.Student, data.Status)
+	}
+	if len(data.Debts) != 1 || data.Debts[0].ID != f.debtID {
+		t.Errorf("debts = %+v, want the debt of the fixture", data.Debts)
+	}
+
+	if err := usecase.ErasePersonalData(ctx, f.student, adminUUID); err != nil {
+		t.Fatalf("ErasePersonalData: %v", err)
+	}
+	data, err = usecase.ExportPersonalData(ctx, f.student, adminUUID)
+	if err != nil {
+		t.Fatalf("ExportPersonalData: %v", err)
+	}
+	if data.Student.Email != valueobjects.AnonymizedEmailPrefix+f.student || data.Status.AnonymizedAt == nil {
+		t.Errorf("export = %+v, %+v, want the student anonymized", data.Student, data.Status)
+	}
+	if len(data.Audit) != 3 || data.Audit[1].Action != valueobjects.PersonalDataEraseAction {
+		t.Errorf("audit = %+v, want the export, the erase and the export", data.Audit)
+	}
+}
+
+func TestPersonalDataUsecaseAnonymizeLeftStudents(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewPersonalDataUsecase(f.repo)
+
+	leftAt := time.Date(2018, 6, 30, 0, 0, 0, 0, time.UTC)
+	if err := usecase.SetStudentStatus(ctx, f.student, valueobjects.StudentGraduatedStatus, leftAt); err != nil {
+		t.Fatalf("SetStudentStatus: %v", err)
+	}
+	if err := usecase.SetStudentStatus(ctx, f.student, valueobjects.StudentExpelledStatus, time.Time{}); !e.Is(err, errors.ErrInvalidCommand) {
+		t.Errorf("SetStudentStatus without left at: err = %v, want ErrInvalidCommand", err)
+	}
+
+	if n, err := usecase.AnonymizeLeftStudents(ctx, leftAt); err != nil || n != 0 {
+		t.Errorf("AnonymizeLeftStudents at the left at = %d, %v, want 0", n, err)
+	}
+	if n, err := usecase.AnonymizeLeftStudents(ctx, leftAt.AddDate(5, 0, 0)); err != nil || n != 1 {
+		t.Errorf("AnonymizeLeftStudents = %d, %v, want 1", n, err)
+	}
+	if n, err := usecase.AnonymizeLeftStudents(ctx, leftAt.AddDate(5, 0, 0)); err != nil || n != 0 {
+		t.Errorf("AnonymizeLeftStudents twice = %d, %v, want 0", n, err)
+	}
+
+	data, err := usecase.ExportPersonalData(ctx, f.student, adminUUID)
+	if err != nil {
+		t.Fatalf("ExportPersonalData: %v", err)
+	}
+	if len(data.Audit) == 0 || data.Audit[0].Action != valueobjects.PersonalDataAnonymizeAction || data.Audit[0].ActorUUID != "" {
+		t.Errorf("audit = %+v, want the anonymization without an actor", data.Audit)
+	}
+}
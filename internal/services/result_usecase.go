@@ -0,0 +1,242 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type resultUsecase struct {
+	repo repositories.Repository
+}
+
+func NewResultUsecase(repo repositories.Repository) logic.ResultUsecase {
+	return &resultUsecase{
+		repo: repo,
+	}
+}
+
+// RecordResult implements logic.ResultUsecase.
+// The result belongs to the retake the debt is scheduled for, so the debt
+// must have a date that has already come.
+func (r *resultUsecase) RecordResult(ctx context.Context, teacherUUID string, entry types.DebtResultEntry) (*types.DebtResult, error) {
+	debts, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+		DebtIDs:       []int64{entry.DebtID},
+		IncludeClosed: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	debt := debts[0]
+	if debt.Teacher.UUID != teacherUUID {
+		return nil, log.ErrorWrapper(errors.ErrUserDoesNotHaveRights, errors.ERR_APPLICATION, "debt belongs to another teacher")
+	}
+	if debt.Date == nil {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "debt has no retake date")
+	}
+
+	result, _, err := r.recordResult(ctx, teacherUUID, debt, 0, *debt.Date, entry)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RecordSessionResults implements logic.ResultUsecase.
+// Every entry is recorded on its own, so a wrong grade of one student does
+// not hold back the rest of the session.
+func (r *resultUsecase) RecordSessionResults(ctx context.Context, teacherUUID string, sessionID int64, entries []types.DebtResultEntry) ([]types.DebtResultReport, error) {
+	sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+		IDs:          []int64{sessionID},
+		TeacherUUIDs: []string{teacherUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(sessions) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	session := sessions[0]
+	if session.Date.After(time.Now()) {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "retake session has not taken place yet")
+	}
+
+	bookings, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+		SessionIDs: []int64{sessionID},
+		Statuses:   []string{valueobjects.RetakeBookingBooked},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	debtIDs := make([]int64, len(bookings))
+	for i, booking := range bookings {
+		debtIDs[i] = booking.Debt.ID
+	}
+
+	debts := make(map[int64]models.Debt, len(debtIDs))
+	if len(debtIDs) > 0 {
+		found, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+			DebtIDs:       debtIDs,
+			IncludeClosed: true,
+		})
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		for _, debt := range found {
+			debts[debt.ID] = debt
+		}
+	}
+
+	reports := make([]types.DebtResultReport, len(entries))
+	for i, entry := range entries {
+		reports[i].DebtID = entry.DebtID
+
+		debt, ok := debts[entry.DebtID]
+		if !ok {
+			reports[i].Err = log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "debt is not booked for the session")
+			continue
+		}
+		reports[i].StudentUUID = debt.Student.UUID
+
+		_, closed, err := r.recordResult(ctx, teacherUUID, debt, session.ID, session.Date, entry)
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			reports[i].Err = err
+			continue
+		}
+		reports[i].Recorded = true
+		reports[i].Closed = closed
+	}
+
+	return reports, nil
+}
+
+// GetDebtResults implements logic.ResultUsecase.
+func (r *resultUsecase) GetDebtResults(ctx context.Context, debtID int64) ([]types.DebtResult, error) {
+	return r.getResults(ctx, query.GetDebtResultsFilters{DebtIDs: []int64{debtID}})
+}
+
+// GetStudentResults implements logic.ResultUsecase.
+func (r *resultUsecase) GetStudentResults(ctx context.Context, studentUUID string) ([]types.DebtResult, error) {
+	return r.getResults(ctx, query.GetDebtResultsFilters{StudentUUIDs: []string{studentUUID}})
+}
+
+func (r *resultUsecase) getResults(ctx context.Context, filters query.GetDebtResultsFilters) ([]types.DebtResult, error) {
+	results, err := r.repo.GetDebtResults(ctx, filters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	converted := make([]types.DebtResult, len(results))
+	for i, result := range results {
+		converted[i] = types.DebtResultFromDomain(&result)
+	}
+
+	return converted, nil
+}
+
+// recordResult stores the result of one attempt and closes the debt when the
+// student passed. The debt is locked and read again in the transaction, so a
+// concurrent result can not close it in between.
+func (r *resultUsecase) recordResult(ctx context.Context, teacherUUID string, debt models.Debt, sessionID int64, attemptedAt time.Time, entry types.DebtResultEntry) (*types.DebtResult, bool, error) {
+	if debt.ClosedAt != nil {
+		return nil, false, log.ErrorWrapper(errors.ErrDebtClosed, errors.ERR_APPLICATION, "", "debt", debt.ID)
+	}
+	if attemptedAt.After(time.Now()) {
+		return nil, false, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "retake has not taken place yet")
+	}
+
+	passed, err := assessResult(debt.Exam.AssessmentType, entry)
+	if err != nil {
+		return nil, false, err
+	}
+
+	command := commands.CreateDebtResult{
+		DebtID:      debt.ID,
+		SessionID:   sessionID,
+		TeacherUUID: teacherUUID,
+		Passed:      passed,
+		Grade:       entry.Grade,
+		AttemptedAt: attemptedAt,
+	}
+	var id int64
+	err = r.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := r.repo.LockDebt(ctx, debt.ID); err != nil {
+			return err
+		}
+		debts, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+			DebtIDs:       []int64{debt.ID},
+			IncludeClosed: true,
+		})
+		if err != nil {
+			return err
+		}
+		if len(debts) == 0 {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		}
+		if debts[0].ClosedAt != nil {
+			return log.ErrorWrapper(errors.ErrDebtClosed, errors.ERR_APPLICATION, "", "debt", debt.ID)
+		}
+
+		id, err = r.repo.CreateDebtResult(ctx, command)
+		if err != nil {
+			return err
+		}
+		if !passed {
+			return nil
+		}
+
+		return r.repo.CloseDebt(ctx, commands.CloseDebt{DebtID: debt.ID})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &types.DebtResult{
+		ID:          id,
+		DebtID:      command.DebtID,
+		SessionID:   command.SessionID,
+		TeacherUUID: command.TeacherUUID,
+		Passed:      command.Passed,
+		Grade:       command.Grade,
+		AttemptedAt: command.AttemptedAt,
+		RecordedAt:  time.Now(),
+	}, passed, nil
+}
+
+// assessResult checks the entry against the assessment type of the exam and
+// tells whether the student passed.
+func assessResult(assessmentType string, entry types.DebtResultEntry) (bool, error) {
+	if !valueobjects.IsGradedAssessment(assessmentType) {
+		if entry.Grade != 0 {
+			return false, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "pass/fail assessment takes no grade")
+		}
+
+		return entry.Passed, nil
+	}
+
+	if entry.Grade < valueobjects.MinGrade || entry.Grade > valueobjects.MaxGrade {
+		return false, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "grade must be from 2 to 5", "grade", entry.Grade)
+	}
+
+	return entry.Grade >= valueobjects.PassingGrade, nil
+}
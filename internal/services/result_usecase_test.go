@@ -0,0 +1,204 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func TestAssessResult(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		assessment string
+		entry      types.DebtResultEntry
+		passed     bool
+		wantErr    bool
+	}{
+		{"lowest grade", valueobjects.AssessmentExam, types.DebtResultEntry{Grade: valueobjects.MinGrade}, false, false},
+		{"passing grade", valueobjects.AssessmentExam, types.DebtResultEntry{Grade: valueobjects.PassingGrade}, true, false},
+		{"highest grade", valueobjects.AssessmentGradedCredit, types.DebtResultEntry{Grade: valueobjects.MaxGrade}, true, false},
+		{"grade below the scale", valueobjects.AssessmentExam, types.DebtResultEntry{Grade: valueobjects.MinGrade - 1}, false, true},
+		{"grade above the scale", valueobjects.AssessmentCoursework, types.DebtResultEntry{Grade: valueobjects.MaxGrade + 1}, false, true},
+		{"graded exam passed without a grade", valueobjects.AssessmentExam, types.DebtResultEntry{Passed: true}, false, true},
+		{"credit passed", valueobjects.AssessmentCredit, types.DebtResultEntry{Passed: true}, true, false},
+		{"credit failed", valueobjects.AssessmentCredit, types.DebtResultEntry{}, false, false},
+		{"credit with a grade", valueobjects.AssessmentCredit, types.DebtResultEntry{Passed: true, Grade: 4}, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			passed, err := assessResult(tc.assessment, tc.entry)
+			if tc.wantErr {
+				if !e.Is(err, errors.ErrInvalidData) {
+					t.Errorf("assessResult: err = %v, want ErrInvalidData", err)
+				}
+				return
+			}
+			if err != nil || passed != tc.passed {
+				t.Errorf("assessResult = %v, %v, want %v", passed, err, tc.passed)
+			}
+		})
+	}
+}
+
+func TestResultUsecaseRecordResult(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewResultUsecase(f.repo)
+	other, err := f.repo.CreateTeacher(ctx, commands.CreateTeacher{FirstName: "Олег", LastName: "Орлов", Email: "orlov@example.com"})
+	if err != nil {
+		t.Fatalf("create teacher: %v", err)
+	}
+
+	// the cases run in order on the debt of the fixture
+	for _, tc := range []struct {
+		name    string
+		teacher string
+		date    time.Duration
+		entry   types.DebtResultEntry
+		want    error
+		closed  bool
+	}{
+		{"no retake date", f.teacher, 0, types.DebtResultEntry{Grade: 4}, errors.ErrInvalidData, false},
+		{"another teacher", other, -time.Hour, types.DebtResultEntry{Grade: 4}, errors.ErrUserDoesNotHaveRights, false},
+		{"retake to come", f.teacher, time.Hour, types.DebtResultEntry{Grade: 4}, errors.ErrInvalidData, false},
+		{"grade out of bounds", f.teacher, -time.Hour, types.DebtResultEntry{Grade: 6}, errors.ErrInvalidData, false},
+		{"failed", f.teacher, -2 * time.Hour, types.DebtResultEntry{Grade: 2}, nil, false},
+		{"same attempt", f.teacher, -2 * time.Hour, types.DebtResultEntry{Grade: 4}, errors.ErrResultAlreadyRecorded, false},
+		{"passed", f.teacher, -time.Hour, types.DebtResultEntry{Grade: 4}, nil, true},
+		{"closed debt", f.teacher, -time.Minute, types.DebtResultEntry{Grade: 5}, errors.ErrDebtClosed, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.date != 0 {
+				if err := f.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+					DebtID:      f.debtID,
+					Date:        time.Now().Add(tc.date).Truncate(time.Second),
+					TeacherUUID: f.teacher,
+					StudentUUID: f.student,
+				}); err != nil {
+					t.Fatalf("UpdateDebt: %v", err)
+				}
+			}
+
+			tc.entry.DebtID = f.debtID
+			result, err := usecase.RecordResult(ctx, tc.teacher, tc.entry)
+			if tc.want != nil {
+				if !e.Is(err, tc.want) {
+					t.Errorf("RecordResult: err = %v, want %v", err, tc.want)
+				}
+			} else if err != nil || result.Passed != tc.closed || result.Grade != tc.entry.Grade {
+				t.Errorf("RecordResult = %+v, %v, want grade %d", result, err, tc.entry.Grade)
+			}
+
+			debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}, IncludeClosed: true})
+			if err != nil || len(debts) != 1 {
+				t.Fatalf("GetDebts = %+v, %v", debts, err)
+			}
+			if closed := debts[0].ClosedAt != nil; closed != tc.closed {
+				t.Errorf("debt closed = %v, want %v", closed, tc.closed)
+			}
+		})
+	}
+
+	results, err := usecase.GetDebtResults(ctx, f.debtID)
+	if err != nil || len(results) != 2 || results[0].Passed || !results[1].Passed {
+		t.Errorf("GetDebtResults = %+v, %v, want the failed and the passed attempt", results, err)
+	}
+}
+
+func TestResultUsecaseRecordResultOfStaleDebt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := &resultUsecase{repo: f.repo}
+
+	// the debt is closed by another result after it was read
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}})
+	if err != nil || len(debts) != 1 {
+		t.Fatalf("GetDebts = %+v, %v", debts, err)
+	}
+	if err := f.repo.CloseDebt(ctx, commands.CloseDebt{DebtID: f.debtID}); err != nil {
+		t.Fatalf("CloseDebt: %v", err)
+	}
+
+	attemptedAt := time.Now().Add(-time.Hour)
+	if _, _, err := usecase.recordResult(ctx, f.teacher, debts[0], 0, attemptedAt, types.DebtResultEntry{DebtID: f.debtID, Grade: 2}); !e.Is(err, errors.ErrDebtClosed) {
+		t.Errorf("recordResult: err = %v, want ErrDebtClosed", err)
+	}
+	if results, err := usecase.GetDebtResults(ctx, f.debtID); err != nil || len(results) != 0 {
+		t.Errorf("GetDebtResults = %+v, %v, want none", results, err)
+	}
+}
+
+func TestResultUsecaseRecordSessionResults(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewResultUsecase(f.repo)
+	second, secondDebt := f.addDebtor(t, "ivanova@example.com")
+	third, thirdDebt := f.addDebtor(t, "smirnova@example.com")
+	_, unbookedDebt := f.addDebtor(t, "kuznetsova@example.com")
+
+	held := f.addSession(t, f.examID, 5, time.Now().Add(-time.Hour), -2*time.Hour, -2*time.Hour)
+	for _, booking := range []struct {
+		student string
+		debtID  int64
+	}{{f.student, f.debtID}, {second, secondDebt}, {third, thirdDebt}} {
+		if _, err := f.repo.CreateRetakeBooking(ctx, commands.CreateRetakeBooking{
+			SessionID:   held,
+			DebtID:      booking.debtID,
+			StudentUUID: booking.student,
+			Status:      valueobjects.RetakeBookingBooked,
+		}); err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+	}
+
+	reports, err := usecase.RecordSessionResults(ctx, f.teacher, held, []types.DebtResultEntry{
+		{DebtID: f.debtID, Grade: 5},
+		{DebtID: secondDebt, Grade: 2},
+		{DebtID: thirdDebt, Grade: 7},
+		{DebtID: unbookedDebt, Grade: 4},
+	})
+	if err != nil {
+		t.Fatalf("RecordSessionResults: %v", err)
+	}
+	for i, want := range []struct {
+		recorded bool
+		closed   bool
+	}{{true, true}, {true, false}, {false, false}, {false, false}} {
+		report := reports[i]
+		if report.Recorded != want.recorded || report.Closed != want.closed || (report.Err == nil) != want.recorded {
+			t.Errorf("report %d = %+v, want recorded %v, closed %v", i, report, want.recorded, want.closed)
+		}
+	}
+	if !e.Is(reports[2].Err, errors.ErrInvalidData) || !e.Is(reports[3].Err, errors.ErrInvalidData) {
+		t.Errorf("errors = %v, %v, want ErrInvalidData", reports[2].Err, reports[3].Err)
+	}
+
+	results, err := usecase.GetDebtResults(ctx, f.debtID)
+	if err != nil || len(results) != 1 || results[0].SessionID != held {
+		t.Errorf("GetDebtResults = %+v, %v, want the result of the session", results, err)
+	}
+
+	upcoming := f.addSession(t, f.examID, 5, time.Now().Add(time.Hour), time.Minute, time.Minute)
+	for _, tc := range []struct {
+		name    string
+		teacher string
+		session int64
+		want    error
+	}{
+		{"session to come", f.teacher, upcoming, errors.ErrInvalidData},
+		{"session of another teacher", second, held, errors.ErroNoItemsFound},
+		{"unknown session", f.teacher, 1 << 40, errors.ErroNoItemsFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := usecase.RecordSessionResults(ctx, tc.teacher, tc.session, nil); !e.Is(err, tc.want) {
+				t.Errorf("RecordSessionResults: err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+// retakeSlot is a retake that is about to be put into the timetable.
+type retakeSlot struct {
+	ExamID      int64
+	TeacherUUID string
+	Date        time.Time
+	Room        *models.Room
+	// StudentUUIDs are the students who will sit the retake
+	StudentUUIDs []string
+	// Seats is the number of places the retake needs in the room, it is
+	// used by sessions whose students are not known yet
+	Seats int64
+	// DebtIDs are moved by the scheduling, their current dates are ignored
+	DebtIDs []int64
+}
+
+// sameRetake tells whether the scheduled retake is the very retake of the
+// slot: a group sitting the same exam with the same teacher at the same
+// time and place is one retake, not a clash.
+func (this retakeSlot) sameRetake(retake models.ScheduledRetake) bool {
+	var roomID int64
+	if this.Room != nil {
+		roomID = this.Room.ID
+	}
+
+	return retake.ExamID == this.ExamID &&
+		retake.TeacherUUID == this.TeacherUUID &&
+		retake.RoomID == roomID &&
+		retake.Date.Equal(this.Date)
+}
+
+// getRoom loads a room of the catalogue, zero id means no room.
+func getRoom(ctx context.Context, repo repositories.Repository, id int64) (*models.Room, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	rooms, err := repo.GetRooms(ctx, query.GetRoomsFilters{IDs: []int64{id}})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(rooms) == 0 {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "unknown room", "room", id)
+	}
+
+	return &rooms[0], nil
+}
+
+// roomAddress is the address written to debts scheduled into the room.
+func roomAddress(room *models.Room) string {
+	return fmt.Sprintf("%s, %s", room.Building, room.Number)
+}
+
+// findScheduleConflicts lists retakes that overlap with the slot and share
+// its room, teacher or one of its students. Retakes overlap when they start
+// less than valueobjects.RetakeDuration apart. A slot outside of the teacher
+// availability is reported as a conflict too.
+func findScheduleConflicts(ctx context.Context, repo repositories.Repository, slot retakeSlot) ([]types.ScheduleConflict, error) {
+	filters := query.GetScheduledRetakesFilters{
+		From:         slot.Date.Add(-valueobjects.RetakeDuration),
+		To:           slot.Date.Add(valueobjects.RetakeDuration),
+		TeacherUUIDs: []string{slot.TeacherUUID},
+		StudentUUIDs: slot.StudentUUIDs,
+	}
+	if slot.Room != nil {
+		filters.RoomIDs = []int64{slot.Room.ID}
+	}
+
+	retakes, err := repo.GetScheduledRetakes(ctx, filters)
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var (
+		conflicts = make([]types.ScheduleConflict, 0)
+		reported  = make(map[string]bool)
+		// students already sitting the same retake, they share the room with the slot
+		seated = make(map[string]bool)
+	)
+	report := func(kind string, retake models.ScheduledRetake, studentUUID string) {
+		// every debt of a group retake would clash the same way, one report per retake is enough
+		key := fmt.Sprintf("%s|%d|%d|%s|%d|%s|%s", kind, retake.SessionID, retake.ExamID, retake.TeacherUUID, retake.RoomID, retake.Date, studentUUID)
+		if reported[key] {
+			return
+		}
+		reported[key] = true
+
+		conflicts = append(conflicts, types.ScheduleConflict{
+			Kind:        kind,
+			Date:        retake.Date,
+			ExamID:      retake.ExamID,
+			DebtID:      retake.DebtID,
+			SessionID:   retake.SessionID,
+			RoomID:      retake.RoomID,
+			TeacherUUID: retake.TeacherUUID,
+			StudentUUID: studentUUID,
+		})
+	}
+
+	for _, retake := range retakes {
+		if retake.DebtID != 0 && slices.Contains(slot.DebtIDs, retake.DebtID) {
+			continue
+		}
+		if slot.sameRetake(retake) {
+			if retake.StudentUUID != "" {
+				seated[retake.StudentUUID] = true
+			}
+			continue
+		}
+
+		if slot.Room != nil && retake.RoomID == slot.Room.ID {
+			report(valueobjects.ScheduleConflictRoom, retake, "")
+		}
+		if retake.TeacherUUID == slot.TeacherUUID {
+			report(valueobjects.ScheduleConflictTeacher, retake, "")
+		}
+		if retake.StudentUUID != "" && slices.Contains(slot.StudentUUIDs, retake.StudentUUID) {
+			report(valueobjects.ScheduleConflictStudent, retake, retake.StudentUUID)
+		}
+	}
+
+	availability, err := repo.GetTeacherAvailability(ctx, query.GetTeacherAvailabilityFilters{
+		TeacherUUIDs: []string{slot.TeacherUUID},
+	})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if !isTeacherAvailable(availability, slot.Date, slot.Date.Add(valueobjects.RetakeDuration)) {
+		conflicts = append(conflicts, types.ScheduleConflict{
+			Kind:        valueobjects.ScheduleConflictAvailability,
+			Date:        slot.Date,
+			ExamID:      slot.ExamID,
+			TeacherUUID: slot.TeacherUUID,
+		})
+	}
+
+	if slot.Room != nil {
+		for _, uuid := range slot.StudentUUIDs {
+			seated[uuid] = true
+		}
+		seats := max(int64(len(seated)), slot.Seats)
+		if seats > slot.Room.Capacity {
+			conflicts = append(conflicts, types.ScheduleConflict{
+				Kind:   valueobjects.ScheduleConflictCapacity,
+				Date:   slot.Date,
+				ExamID: slot.ExamID,
+				RoomID: slot.Room.ID,
+			})
+		}
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+func TestFindScheduleConflicts(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	// the debt of the fixture is sat at 10:00 in a room for two
+	date := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	roomID, err := f.repo.CreateRoom(ctx, commands.CreateRoom{Building: "Главный корпус", Number: "101", Capacity: 2})
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	otherRoomID, err := f.repo.CreateRoom(ctx, commands.CreateRoom{Building: "Главный корпус", Number: "102", Capacity: 30})
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	room := &models.Room{ID: roomID, Capacity: 2}
+	otherRoom := &models.Room{ID: otherRoomID, Capacity: 30}
+	if err := f.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+		DebtID:      f.debtID,
+		Date:        date,
+		RoomID:      roomID,
+		TeacherUUID: f.teacher,
+		StudentUUID: f.student,
+	}); err != nil {
+		t.Fatalf("schedule debt: %v", err)
+	}
+
+	otherStudent, err := f.repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Олег",
+		LastName:  "Смирнов",
+		Email:     "smirnov@example.com",
+		GroupID:   f.groupID,
+	})
+	if err != nil {
+		t.Fatalf("create student: %v", err)
+	}
+	otherTeacher, err := f.repo.CreateTeacher(ctx, commands.CreateTeacher{
+		FirstName: "Павел",
+		LastName:  "Кузнецов",
+		Email:     "kuznetsov@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create teacher: %v", err)
+	}
+	// the other teacher is away the next day
+	if _, err := f.repo.CreateTeacherAvailability(ctx, commands.CreateTeacherAvailability{
+		TeacherUUID: otherTeacher,
+		Kind:        valueobjects.AvailabilityBlackout,
+		StartsAt:    date.AddDate(0, 0, 1).Add(-time.Hour),
+		EndsAt:      date.AddDate(0, 0, 1).Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create availability: %v", err)
+	}
+	otherExamID := f.examID + 1
+
+	for _, tc := range []struct {
+		name string
+		slot retakeSlot
+		want []string
+	}{
+		{
+			name: "nothing shared",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date, Room: otherRoom, StudentUUIDs: []string{otherStudent}},
+		},
+		{
+			name: "same room",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date.Add(30 * time.Minute), Room: room, StudentUUIDs: []string{otherStudent}},
+			want: []string{valueobjects.ScheduleConflictRoom},
+		},
+		{
+			name: "same teacher just before the retake ends",
+			slot: retakeSlot{ExamID: f.examID, TeacherUUID: f.teacher, Date: date.Add(valueobjects.RetakeDuration - time.Minute), Room: otherRoom, StudentUUIDs: []string{otherStudent}},
+			want: []string{valueobjects.ScheduleConflictTeacher},
+		},
+		{
+			name: "same teacher once the retake is over",
+			slot: retakeSlot{ExamID: f.examID, TeacherUUID: f.teacher, Date: date.Add(valueobjects.RetakeDuration), Room: room, StudentUUIDs: []string{otherStudent}},
+		},
+		{
+			name: "same teacher earlier",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: f.teacher, Date: date.Add(-time.Hour), Room: otherRoom},
+			want: []string{valueobjects.ScheduleConflictTeacher},
+		},
+		{
+			name: "same student",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date, Room: otherRoom, StudentUUIDs: []string{f.student}},
+			want: []string{valueobjects.ScheduleConflictStudent},
+		},
+		{
+			name: "same room, teacher and student",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: f.teacher, Date: date.Add(time.Hour), Room: room, StudentUUIDs: []string{f.student}},
+			want: []string{valueobjects.ScheduleConflictRoom, valueobjects.ScheduleConflictTeacher, valueobjects.ScheduleConflictStudent},
+		},
+		{
+			name: "joining the retake",
+			slot: retakeSlot{ExamID: f.examID, TeacherUUID: f.teacher, Date: date, Room: room, StudentUUIDs: []string{otherStudent}},
+		},
+		{
+			name: "joining the retake over the capacity",
+			slot: retakeSlot{ExamID: f.examID, TeacherUUID: f.teacher, Date: date, Room: room, StudentUUIDs: []string{otherStudent, "third"}},
+			want: []string{valueobjects.ScheduleConflictCapacity},
+		},
+		{
+			name: "session seats over the capacity",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date.AddDate(0, 0, 2), Room: room, Seats: 3},
+			want: []string{valueobjects.ScheduleConflictCapacity},
+		},
+		{
+			name: "moving the debt itself",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: f.teacher, Date: date.Add(time.Hour), Room: room, StudentUUIDs: []string{f.student}, DebtIDs: []int64{f.debtID}},
+		},
+		{
+			name: "teacher is away",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date.AddDate(0, 0, 1), Room: otherRoom},
+			want: []string{valueobjects.ScheduleConflictAvailability},
+		},
+		{
+			name: "no room",
+			slot: retakeSlot{ExamID: otherExamID, TeacherUUID: otherTeacher, Date: date, StudentUUIDs: []string{otherStudent, "third", "fourth"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conflicts, err := findScheduleConflicts(ctx, f.repo, tc.slot)
+			if err != nil {
+				t.Fatalf("findScheduleConflicts: %v", err)
+			}
+
+			kinds := make([]string, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				kinds = append(kinds, conflict.Kind)
+				if conflict.Kind == valueobjects.ScheduleConflictStudent && conflict.StudentUUID != f.student {
+					t.Errorf("student conflict = %+v, want the student of the fixture", conflict)
+				}
+			}
+			if !slices.Equal(kinds, tc.want) && len(kinds)+len(tc.want) != 0 {
+				t.Errorf("findScheduleConflicts = %+v, want kinds %v", conflicts, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,503 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type retakeSessionUsecase struct {
+	repo repositories.Repository
+}
+
+func NewRetakeSessionUsecase(repo repositories.Repository) logic.RetakeSessionUsecase {
+	return &retakeSessionUsecase{
+		repo: repo,
+	}
+}
+
+// CreateSession implements logic.RetakeSessionUsecase.
+// Conflicts with other retakes reject the session unless force is set, then
+// they are returned as warnings. The schedule is locked from the check until
+// the session is created.
+func (r *retakeSessionUsecase) CreateSession(ctx context.Context, teacherUUID string, session types.RetakeSession, force bool) (int64, []types.ScheduleConflict, error) {
+	if session.Capacity <= 0 {
+		return 0, nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "capacity must be positive")
+	}
+	if !session.SignupDeadline.After(time.Now()) {
+		return 0, nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "sign-up deadline has already passed")
+	}
+	if session.SignupDeadline.After(session.Date) || session.CancelDeadline.After(session.Date) {
+		return 0, nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "deadline is after the retake")
+	}
+
+	// teachers publish sessions only for exams they have debtors in
+	debts, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+		TeacherUUIDs: []string{teacherUUID},
+		ExamIDs:      []int64{session.Exam.ID},
+		Limit:        1,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return 0, nil, log.ErrorWrapper(errors.ErrUserDoesNotHaveRights, errors.ERR_APPLICATION, "teacher has no debts for the exam")
+	}
+
+	var roomID int64
+	if session.Room != nil {
+		roomID = session.Room.ID
+	}
+	room, err := getRoom(ctx, r.repo, roomID)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, nil, err
+	}
+	if room != nil && session.Address == "" {
+		session.Address = roomAddress(room)
+	}
+
+	var (
+		id        int64
+		conflicts []types.ScheduleConflict
+	)
+	err = r.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := r.repo.LockSchedule(ctx); err != nil {
+			return err
+		}
+
+		conflicts, err = findScheduleConflicts(ctx, r.repo, retakeSlot{
+			ExamID:      session.Exam.ID,
+			TeacherUUID: teacherUUID,
+			Date:        session.Date,
+			Room:        room,
+			Seats:       session.Capacity,
+		})
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 && !force {
+			return log.ErrorWrapper(errors.ErrScheduleConflict, errors.ERR_APPLICATION, "", "conflicts", len(conflicts))
+		}
+
+		id, err = r.repo.CreateRetakeSession(ctx, commands.CreateRetakeSession{
+			ExamID:         session.Exam.ID,
+			TeacherUUID:    teacherUUID,
+			Date:           session.Date,
+			Address:        session.Address,
+			RoomID:         roomID,
+			Capacity:       session.Capacity,
+			SignupDeadline: session.SignupDeadline,
+			CancelDeadline: session.CancelDeadline,
+		})
+		return err
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, conflicts, err
+	}
+
+	return id, conflicts, nil
+}
+
+// GetTeacherSessions implements logic.RetakeSessionUsecase.
+func (r *retakeSessionUsecase) GetTeacherSessions(ctx context.Context, teacherUUID string) ([]types.RetakeSession, error) {
+	sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+		TeacherUUIDs: []string{teacherUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := make([]types.RetakeSession, len(sessions))
+	for i, session := range sessions {
+		result[i] = types.RetakeSessionFromDomain(&session)
+	}
+
+	return result, nil
+}
+
+// GetSessionBookings implements logic.RetakeSessionUsecase.
+func (r *retakeSessionUsecase) GetSessionBookings(ctx context.Context, teacherUUID string, sessionID int64) ([]types.RetakeBooking, error) {
+	sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+		IDs:          []int64{sessionID},
+		TeacherUUIDs: []string{teacherUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(sessions) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+
+	bookings, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+		SessionIDs: []int64{sessionID},
+		Statuses:   valueobjects.ActiveRetakeBookingStatuses,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return withWaitlistPositions(bookings), nil
+}
+
+// GetAvailableSessions implements logic.RetakeSessionUsecase.
+func (r *retakeSessionUsecase) GetAvailableSessions(ctx context.Context, studentUUID string) ([]types.RetakeSession, error) {
+	debts, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+		StudentUUIDs: []string{studentUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return []types.RetakeSession{}, nil
+	}
+
+	// a session is available if it is held by the teacher of one of the student's debts
+	owned := make(map[int64]map[string]bool, len(debts))
+	examIDs := make([]int64, 0, len(debts))
+	for _, debt := range debts {
+		if owned[debt.Exam.ID] == nil {
+			owned[debt.Exam.ID] = make(map[string]bool)
+			examIDs = append(examIDs, debt.Exam.ID)
+		}
+		owned[debt.Exam.ID][debt.Teacher.UUID] = true
+	}
+
+	sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+		ExamIDs:  examIDs,
+		OnlyOpen: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := make([]types.RetakeSession, 0, len(sessions))
+	for _, session := range sessions {
+		if owned[session.Exam.ID][session.Teacher.UUID] {
+			result = append(result, types.RetakeSessionFromDomain(&session))
+		}
+	}
+
+	return result, nil
+}
+
+// GetStudentBookings implements logic.RetakeSessionUsecase.
+func (r *retakeSessionUsecase) GetStudentBookings(ctx context.Context, studentUUID string) ([]types.RetakeBooking, error) {
+	bookings, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+		StudentUUIDs: []string{studentUUID},
+		Statuses:     valueobjects.ActiveRetakeBookingStatuses,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := make([]types.RetakeBooking, len(bookings))
+	for i, booking := range bookings {
+		result[i] = types.RetakeBookingFromDomain(&booking)
+		if booking.Status != valueobjects.RetakeBookingWaitlisted {
+			continue
+		}
+
+		waitlist, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+			SessionIDs: []int64{booking.SessionID},
+			Statuses:   []string{valueobjects.RetakeBookingWaitlisted},
+		})
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		}
+		for position, waiting := range waitlist {
+			if waiting.ID == booking.ID {
+				result[i].Position = int64(position + 1)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Book implements logic.RetakeSessionUsecase.
+// Bookings of one session are serialized by a row lock on the session, so
+// the seats are given away strictly in the order the transactions get the lock.
+// The schedule is locked before the session, so the student is still free
+// when the debt is scheduled.
+func (r *retakeSessionUsecase) Book(ctx context.Context, studentUUID string, sessionID, debtID int64) (*types.RetakeBooking, error) {
+	debts, err := r.repo.GetDebts(ctx, query.GetDebtsFilters{
+		DebtIDs: []int64{debtID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	debt := debts[0]
+	if debt.Student.UUID != studentUUID {
+		return nil, log.ErrorWrapper(errors.ErrUserDoesNotHaveRights, errors.ERR_APPLICATION, "debt belongs to another student")
+	}
+
+	var result types.RetakeBooking
+	err = r.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := r.repo.LockSchedule(ctx); err != nil {
+			return err
+		}
+		if err := r.repo.LockRetakeSession(ctx, sessionID); err != nil {
+			return err
+		}
+
+		sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+			IDs: []int64{sessionID},
+		})
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		}
+		session := sessions[0]
+
+		if !time.Now().Before(session.SignupDeadline) {
+			return log.ErrorWrapper(errors.ErrRetakeSignupClosed, errors.ERR_APPLICATION, "")
+		}
+		if debt.Exam.ID != session.Exam.ID || debt.Teacher.UUID != session.Teacher.UUID {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "debt is not retaken at this session")
+		}
+		if err := r.checkStudentIsFree(ctx, studentUUID, debtID, session); err != nil {
+			return err
+		}
+
+		status := valueobjects.RetakeBookingWaitlisted
+		if session.BookedCount < session.Capacity {
+			status = valueobjects.RetakeBookingBooked
+		}
+
+		id, err := r.repo.CreateRetakeBooking(ctx, commands.CreateRetakeBooking{
+			SessionID:   sessionID,
+			DebtID:      debtID,
+			StudentUUID: studentUUID,
+			Status:      status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = types.RetakeBooking{
+			ID:        id,
+			SessionID: sessionID,
+			Status:    status,
+		}
+		if status == valueobjects.RetakeBookingWaitlisted {
+			result.Position = session.WaitlistedCount + 1
+			return nil
+		}
+
+		return r.scheduleDebt(ctx, *debt.Student, *debt.Teacher, debtID, session)
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	converted := types.DebtFromDomain(&debt)
+	result.Debt = &converted
+
+	return &result, nil
+}
+
+// CancelBooking implements logic.RetakeSessionUsecase.
+// A freed seat goes to the first student in the waitlist of the session.
+func (r *retakeSessionUsecase) CancelBooking(ctx context.Context, studentUUID string, bookingID int64) error {
+	bookings, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+		IDs:          []int64{bookingID},
+		StudentUUIDs: []string{studentUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(bookings) == 0 {
+		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	sessionID := bookings[0].SessionID
+
+	var (
+		promoted *models.RetakeBooking
+		session  models.RetakeSession
+	)
+	err = r.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := r.repo.LockRetakeSession(ctx, sessionID); err != nil {
+			return err
+		}
+
+		sessions, err := r.repo.GetRetakeSessions(ctx, query.GetRetakeSessionsFilters{
+			IDs: []int64{sessionID},
+		})
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		}
+		session = sessions[0]
+
+		if !time.Now().Before(session.CancelDeadline) {
+			return log.ErrorWrapper(errors.ErrRetakeCancellationClosed, errors.ERR_APPLICATION, "")
+		}
+
+		// the status is read again under the lock, a concurrent cancel could have changed it
+		bookings, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+			IDs: []int64{bookingID},
+		})
+		if err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		}
+		booking := bookings[0]
+		if booking.Status == valueobjects.RetakeBookingCancelled {
+			return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "booking is already cancelled")
+		}
+
+		if err := r.repo.UpdateRetakeBookingStatus(ctx, commands.UpdateRetakeBookingStatus{
+			ID:     bookingID,
+			Status: valueobjects.RetakeBookingCancelled,
+		}); err != nil {
+			return err
+		}
+		if booking.Status != valueobjects.RetakeBookingBooked {
+			return nil
+		}
+
+		if err := r.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+			DebtID:      booking.Debt.ID,
+			TeacherUUID: booking.Debt.Teacher.UUID,
+			StudentUUID: booking.Debt.Student.UUID,
+		}); err != nil {
+			return err
+		}
+
+		waitlist, err := r.repo.GetRetakeBookings(ctx, query.GetRetakeBookingsFilters{
+			SessionIDs: []int64{sessionID},
+			Statuses:   []string{valueobjects.RetakeBookingWaitlisted},
+			Limit:      1,
+		})
+		if err != nil {
+			return err
+		}
+		if len(waitlist) == 0 {
+			return nil
+		}
+		promoted = &waitlist[0]
+
+		if err := r.repo.UpdateRetakeBookingStatus(ctx, commands.UpdateRetakeBookingStatus{
+			ID:     promoted.ID,
+			Status: valueobjects.RetakeBookingBooked,
+		}); err != nil {
+			return err
+		}
+
+		return r.scheduleDebt(ctx, *promoted.Debt.Student, *promoted.Debt.Teacher, promoted.Debt.ID, session)
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	if promoted != nil {
+		// the seat is already given, a failed email must not undo it
+		if err := r.repo.NotifyNewDateAndPlace(
+			ctx,
+			promoted.Debt.Student.Email,
+			session.Exam.Name,
+			session.Date.Format(valueobjects.DateLayout),
+			session.Address,
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+	}
+
+	return nil
+}
+
+// checkStudentIsFree rejects a booking that clashes with another retake of the student.
+func (r *retakeSessionUsecase) checkStudentIsFree(ctx context.Context, studentUUID string, debtID int64, session models.RetakeSession) error {
+	conflicts, err := findScheduleConflicts(ctx, r.repo, retakeSlot{
+		ExamID:       session.Exam.ID,
+		TeacherUUID:  session.Teacher.UUID,
+		Date:         session.Date,
+		Room:         session.Room,
+		StudentUUIDs: []string{studentUUID},
+		DebtIDs:      []int64{debtID},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the room and the teacher of the session were checked when it was published
+	for _, conflict := range conflicts {
+		if conflict.Kind == valueobjects.ScheduleConflictStudent {
+			return log.ErrorWrapper(errors.ErrScheduleConflict, errors.ERR_APPLICATION, "student has another retake at this time")
+		}
+	}
+
+	return nil
+}
+
+// scheduleDebt copies date and place of the session into the debt.
+func (r *retakeSessionUsecase) scheduleDebt(ctx context.Context, student models.Student, teacher models.Teacher, debtID int64, session models.RetakeSession) error {
+	var roomID int64
+	if session.Room != nil {
+		roomID = session.Room.ID
+	}
+
+	if err := r.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+		DebtID:      debtID,
+		Date:        session.Date,
+		Address:     session.Address,
+		RoomID:      roomID,
+		TeacherUUID: teacher.UUID,
+		StudentUUID: student.UUID,
+	}); err != nil {
+		return err
+	}
+
+	return r.repo.UpdateRetakeRequestStatus(ctx, commands.UpdateRetakeRequestStatus{
+		DebtIDs: []int64{debtID},
+		Status:  valueobjects.RetakeRequestScheduled,
+	})
+}
+
+// withWaitlistPositions expects bookings of a single session ordered by booking time.
+func withWaitlistPositions(bookings []models.RetakeBooking) []types.RetakeBooking {
+	result := make([]types.RetakeBooking, len(bookings))
+
+	var position int64
+	for i, booking := range bookings {
+		result[i] = types.RetakeBookingFromDomain(&booking)
+		if booking.Status == valueobjects.RetakeBookingWaitlisted {
+			position++
+			result[i].Position = position
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+// addDebtor adds another student of the group with a debt for the exam of
+// the fixture and returns the student and the debt.
+func (f *fixture) addDebtor(t *testing.T, email string) (string, int64) {
+	t.Helper()
+
+	ctx := context.Background()
+	student, err := f.repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Мария",
+		LastName:  "Иванова",
+		Email:     email,
+		GroupID:   f.groupID,
+	})
+	if err != nil {
+		t.Fatalf("create student: %v", err)
+	}
+	debtID, err := f.repo.CreateDebt(ctx, commands.CreateDebt{
+		ExamID:      f.examID,
+		StudentUUID: student,
+		TeacherUUID: f.teacher,
+	})
+	if err != nil {
+		t.Fatalf("create debt: %v", err)
+	}
+
+	return student, debtID
+}
+
+// addSession adds a retake session of the teacher of the fixture for an
+// exam, with the deadlines relative to now.
+func (f *fixture) addSession(t *testing.T, examID, capacity int64, date time.Time, signup, cancel time.Duration) int64 {
+	t.Helper()
+
+	id, err := f.repo.CreateRetakeSession(context.Background(), commands.CreateRetakeSession{
+		ExamID:         examID,
+		TeacherUUID:    f.teacher,
+		Date:           date,
+		Address:        "ауд. 101",
+		Capacity:       capacity,
+		SignupDeadline: time.Now().Add(signup),
+		CancelDeadline: time.Now().Add(cancel),
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return id
+}
+
+func TestRetakeSessionUsecaseCreateSession(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewRetakeSessionUsecase(f.repo)
+	date := time.Now().Add(14 * 24 * time.Hour).Truncate(time.Minute)
+	f.addSession(t, f.examID, 5, date.Add(30*time.Minute), time.Hour, time.Hour)
+
+	session := types.RetakeSession{
+		Exam:           &types.Exam{ID: f.examID},
+		Date:           date,
+		Address:        "ауд. 202",
+		Capacity:       5,
+		SignupDeadline: date.Add(-24 * time.Hour),
+		CancelDeadline: date.Add(-24 * time.Hour),
+	}
+	if _, conflicts, err := usecase.CreateSession(ctx, f.teacher, session, false); !e.Is(err, errors.ErrScheduleConflict) || len(conflicts) != 1 {
+		t.Fatalf("CreateSession over another retake = %+v, %v, want the teacher conflict", conflicts, err)
+	}
+	if sessions, err := usecase.GetTeacherSessions(ctx, f.teacher); err != nil || len(sessions) != 1 {
+		t.Errorf("GetTeacherSessions = %+v, %v, want the rejected session not created", sessions, err)
+	}
+
+	id, conflicts, err := usecase.CreateSession(ctx, f.teacher, session, true)
+	if err != nil || id == 0 || len(conflicts) != 1 || conflicts[0].Kind != valueobjects.ScheduleConflictTeacher {
+		t.Fatalf("CreateSession with force = %d, %+v, %v, want the session created with the warning", id, conflicts, err)
+	}
+	if sessions, err := usecase.GetTeacherSessions(ctx, f.teacher); err != nil || len(sessions) != 2 {
+		t.Errorf("GetTeacherSessions = %+v, %v, want the forced session created", sessions, err)
+	}
+}
+
+func TestRetakeSessionUsecaseWaitlist(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewRetakeSessionUsecase(f.repo)
+	other, otherDebt := f.addDebtor(t, "ivanova@example.com")
+	third, thirdDebt := f.addDebtor(t, "smirnova@example.com")
+	date := time.Now().Add(14 * 24 * time.Hour).Truncate(time.Minute)
+	sessionID := f.addSession(t, f.examID, 1, date, time.Hour, time.Hour)
+
+	booked, err := usecase.Book(ctx, f.student, sessionID, f.debtID)
+	if err != nil {
+		t.Fatalf("Book: %v", err)
+	}
+	if booked.Status != valueobjects.RetakeBookingBooked || booked.Position != 0 {
+		t.Errorf("Book = %+v, want a seat", booked)
+	}
+	for i, student := range []struct {
+		uuid   string
+		debtID int64
+	}{{other, otherDebt}, {third, thirdDebt}} {
+		waiting, err := usecase.Book(ctx, student.uuid, sessionID, student.debtID)
+		if err != nil {
+			t.Fatalf("Book of a full session: %v", err)
+		}
+		if waiting.Status != valueobjects.RetakeBookingWaitlisted || waiting.Position != int64(i+1) {
+			t.Errorf("Book of a full session = %+v, want position %d of the waitlist", waiting, i+1)
+		}
+	}
+
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID, otherDebt}})
+	if err != nil {
+		t.Fatalf("GetDebts: %v", err)
+	}
+	for _, debt := range debts {
+		if scheduled := debt.Date != nil && debt.Date.Equal(date); scheduled != (debt.ID == f.debtID) {
+			t.Errorf("debt %d date = %v, want only the booked debt scheduled", debt.ID, debt.Date)
+		}
+	}
+
+	// the seat goes to the first student of the waitlist
+	if err := usecase.CancelBooking(ctx, f.student, booked.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	bookings, err := usecase.GetSessionBookings(ctx, f.teacher, sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionBookings: %v", err)
+	}
+	if len(bookings) != 2 ||
+		bookings[0].Debt.ID != otherDebt || bookings[0].Status != valueobjects.RetakeBookingBooked ||
+		bookings[1].Debt.ID != thirdDebt || bookings[1].Position != 1 {
+		t.Errorf("GetSessionBookings = %+v, want the first waitlisted student booked", bookings)
+	}
+	if mails := f.mailer.Mails(); len(mails) != 1 || mails[0].To != "ivanova@example.com" {
+		t.Errorf("mails = %+v, want the promoted student notified", mails)
+	}
+	debts, err = f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID, otherDebt}})
+	if err != nil {
+		t.Fatalf("GetDebts: %v", err)
+	}
+	for _, debt := range debts {
+		if scheduled := debt.Date != nil && debt.Date.Equal(date); scheduled != (debt.ID == otherDebt) {
+			t.Errorf("debt %d date = %v after the cancel, want only the promoted debt scheduled", debt.ID, debt.Date)
+		}
+	}
+
+	if err := usecase.CancelBooking(ctx, f.student, booked.ID); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("CancelBooking twice: err = %v, want ErrInvalidData", err)
+	}
+}
+
+func TestRetakeSessionUsecaseBookRejects(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewRetakeSessionUsecase(f.repo)
+	other, otherDebt := f.addDebtor(t, "ivanova@example.com")
+	date := time.Now().Add(14 * 24 * time.Hour)
+
+	otherExam, err := f.repo.CreateExam(ctx, commands.CreateExam{Name: "Сети", AssessmentType: valueobjects.AssessmentExam})
+	if err != nil {
+		t.Fatalf("create exam: %v", err)
+	}
+	otherExamDebt, err := f.repo.CreateDebt(ctx, commands.CreateDebt{ExamID: otherExam, StudentUUID: f.student, TeacherUUID: f.teacher})
+	if err != nil {
+		t.Fatalf("create debt: %v", err)
+	}
+	open := f.addSession(t, f.examID, 5, date, time.Hour, time.Hour)
+	if _, err := usecase.Book(ctx, f.student, open, f.debtID); err != nil {
+		t.Fatalf("Book: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		student string
+		session int64
+		debtID  int64
+		want    error
+	}{
+		{"debt of another student", f.student, open, otherDebt, errors.ErrUserDoesNotHaveRights},
+		{"unknown debt", f.student, open, 1 << 40, errors.ErroNoItemsFound},
+		{"unknown session", other, 1 << 40, otherDebt, errors.ErroNoItemsFound},
+		{"sign-up closed", other, f.addSession(t, f.examID, 5, date, -time.Hour, -time.Hour), otherDebt, errors.ErrRetakeSignupClosed},
+		{"session of another exam", other, f.addSession(t, otherExam, 5, date, time.Hour, time.Hour), otherDebt, errors.ErrInvalidData},
+		{"student has a retake at the time", f.student, f.addSession(t, otherExam, 5, date, time.Hour, time.Hour), otherExamDebt, errors.ErrScheduleConflict},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := usecase.Book(ctx, tc.student, tc.session, tc.debtID); !e.Is(err, tc.want) {
+				t.Errorf("Book: err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetakeSessionUsecaseCancelRejects(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewRetakeSessionUsecase(f.repo)
+	other, _ := f.addDebtor(t, "ivanova@example.com")
+
+	// sign-up is open, the booking can not be cancelled anymore
+	sessionID := f.addSession(t, f.examID, 1, time.Now().Add(14*24*time.Hour), time.Hour, -time.Hour)
+	booking, err := usecase.Book(ctx, f.student, sessionID, f.debtID)
+	if err != nil {
+		t.Fatalf("Book: %v", err)
+	}
+
+	if err := usecase.CancelBooking(ctx, other, booking.ID); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("CancelBooking of another student: err = %v, want ErroNoItemsFound", err)
+	}
+	if err := usecase.CancelBooking(ctx, f.student, booking.ID); !e.Is(err, errors.ErrRetakeCancellationClosed) {
+		t.Errorf("CancelBooking after the deadline: err = %v, want ErrRetakeCancellationClosed", err)
+	}
+	bookings, err := usecase.GetStudentBookings(ctx, f.student)
+	if err != nil || len(bookings) != 1 || bookings[0].Status != valueobjects.RetakeBookingBooked {
+		t.Errorf("GetStudentBookings = %+v, %v, want the booking kept", bookings, err)
+	}
+}
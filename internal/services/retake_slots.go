@@ -0,0 +1,67 @@
+package application
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+// mostCoveredSlot finds the window that lies inside preferences of the
+// largest number of students. Keys of preferences are student uuids. Of the
+// intervals covered by most students the earliest one is taken, and it is
+// extended over the following intervals while they are covered by the very
+// same students.
+func mostCoveredSlot(preferences map[string][]types.TimeSlot) (*types.TimeSlot, []string) {
+	points := make([]time.Time, 0)
+	for _, slots := range preferences {
+		for _, slot := range slots {
+			points = append(points, slot.Start, slot.End)
+		}
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	slices.SortFunc(points, func(a, b time.Time) int { return a.Compare(b) })
+	points = slices.CompactFunc(points, func(a, b time.Time) bool { return a.Equal(b) })
+
+	// students available during every elementary interval [points[i], points[i+1])
+	covered := make([][]string, len(points)-1)
+	for i := 0; i < len(points)-1; i++ {
+		for uuid, slots := range preferences {
+			for _, slot := range slots {
+				if !slot.Start.After(points[i]) && !slot.End.Before(points[i+1]) {
+					covered[i] = append(covered[i], uuid)
+					break
+				}
+			}
+		}
+		slices.Sort(covered[i])
+	}
+
+	best := -1
+	for i := range covered {
+		if len(covered[i]) == 0 {
+			continue
+		}
+		if best == -1 || len(covered[i]) > len(covered[best]) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return nil, nil
+	}
+
+	// neighbouring intervals with the same students make one continuous window
+	last := best
+	for last+1 < len(covered) && strings.Join(covered[last+1], ",") == strings.Join(covered[best], ",") {
+		last++
+	}
+
+	return &types.TimeSlot{
+		Start: points[best],
+		End:   points[last+1],
+	}, covered[best]
+}
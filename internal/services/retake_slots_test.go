@@ -0,0 +1,103 @@
+package application
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+)
+
+func TestMostCoveredSlot(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, time.June, 2, hour, 0, 0, 0, time.UTC) }
+	slot := func(start, end int) types.TimeSlot { return types.TimeSlot{Start: at(start), End: at(end)} }
+
+	for _, tc := range []struct {
+		name         string
+		preferences  map[string][]types.TimeSlot
+		want         *types.TimeSlot
+		wantStudents []string
+	}{
+		{
+			name:        "no preferences",
+			preferences: map[string][]types.TimeSlot{},
+		},
+		{
+			name:        "students without slots",
+			preferences: map[string][]types.TimeSlot{"a": nil},
+		},
+		{
+			name:         "single slot",
+			preferences:  map[string][]types.TimeSlot{"a": {slot(9, 12)}},
+			want:         &types.TimeSlot{Start: at(9), End: at(12)},
+			wantStudents: []string{"a"},
+		},
+		{
+			name: "overlap of everybody",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(9, 13)},
+				"b": {slot(11, 15)},
+				"c": {slot(10, 12)},
+			},
+			want:         &types.TimeSlot{Start: at(11), End: at(12)},
+			wantStudents: []string{"a", "b", "c"},
+		},
+		{
+			name: "most students win over a longer window",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(9, 17)},
+				"b": {slot(15, 16)},
+				"c": {slot(15, 17)},
+			},
+			want:         &types.TimeSlot{Start: at(15), End: at(16)},
+			wantStudents: []string{"a", "b", "c"},
+		},
+		{
+			name: "ties go to the earliest window",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(14, 15)},
+				"b": {slot(9, 10)},
+			},
+			want:         &types.TimeSlot{Start: at(9), End: at(10)},
+			wantStudents: []string{"b"},
+		},
+		{
+			name: "touching slots do not overlap",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(9, 10)},
+				"b": {slot(10, 11)},
+			},
+			want:         &types.TimeSlot{Start: at(9), End: at(10)},
+			wantStudents: []string{"a"},
+		},
+		{
+			name: "neighbouring slots of the same students make one window",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(9, 10), slot(10, 12)},
+				"b": {slot(9, 12)},
+			},
+			want:         &types.TimeSlot{Start: at(9), End: at(12)},
+			wantStudents: []string{"a", "b"},
+		},
+		{
+			name: "window ends where a student leaves",
+			preferences: map[string][]types.TimeSlot{
+				"a": {slot(9, 12)},
+				"b": {slot(9, 11)},
+			},
+			want:         &types.TimeSlot{Start: at(9), End: at(11)},
+			wantStudents: []string{"a", "b"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, students := mostCoveredSlot(tc.preferences)
+			if (got == nil) != (tc.want == nil) ||
+				got != nil && (!got.Start.Equal(tc.want.Start) || !got.End.Equal(tc.want.End)) {
+				t.Errorf("mostCoveredSlot = %+v, want %+v", got, tc.want)
+			}
+			if !slices.Equal(students, tc.wantStudents) {
+				t.Errorf("mostCoveredSlot students = %v, want %v", students, tc.wantStudents)
+			}
+		})
+	}
+}
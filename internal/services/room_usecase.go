@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type roomUsecase struct {
+	repo repositories.Repository
+}
+
+func NewRoomUsecase(repo repositories.Repository) logic.RoomUsecase {
+	return &roomUsecase{
+		repo: repo,
+	}
+}
+
+// CreateRoom implements logic.RoomUsecase.
+func (r *roomUsecase) CreateRoom(ctx context.Context, room types.Room) (int64, error) {
+	id, err := r.repo.CreateRoom(ctx, commands.CreateRoom{
+		Building:   room.Building,
+		Number:     room.Number,
+		Capacity:   room.Capacity,
+		Accessible: room.Accessible,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateRoom implements logic.RoomUsecase.
+func (r *roomUsecase) UpdateRoom(ctx context.Context, room types.Room) error {
+	if err := r.repo.UpdateRoom(ctx, commands.UpdateRoom{
+		ID:         room.ID,
+		Building:   room.Building,
+		Number:     room.Number,
+		Capacity:   room.Capacity,
+		Accessible: room.Accessible,
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRoom implements logic.RoomUsecase.
+func (r *roomUsecase) DeleteRoom(ctx context.Context, id int64) error {
+	if err := r.repo.DeleteRoom(ctx, commands.DeleteRoom{ID: id}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// GetRoom implements logic.RoomUsecase.
+func (r *roomUsecase) GetRoom(ctx context.Context, id int64) (*types.Room, error) {
+	rooms, err := r.repo.GetRooms(ctx, query.GetRoomsFilters{IDs: []int64{id}})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	if len(rooms) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+
+	result := types.RoomFromDomain(&rooms[0])
+
+	return &result, nil
+}
+
+// GetRooms implements logic.RoomUsecase.
+func (r *roomUsecase) GetRooms(ctx context.Context, filters types.RoomFilters) ([]types.Room, error) {
+	rooms, err := r.repo.GetRooms(ctx, query.GetRoomsFilters{
+		MinCapacity:    filters.MinCapacity,
+		OnlyAccessible: filters.OnlyAccessible,
+		Limit:          filters.Limit,
+		Offset:         filters.Offset,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := make([]types.Room, len(rooms))
+	for i, room := range rooms {
+		result[i] = types.RoomFromDomain(&room)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type searchUsecase struct {
+	repo repositories.Repository
+}
+
+func NewSearchUsecase(repo repositories.Repository) logic.SearchUsecase {
+	return &searchUsecase{
+		repo: repo,
+	}
+}
+
+// Search implements logic.SearchUsecase. The hits are ranked, the limit is
+// the one of the list endpoints.
+func (s *searchUsecase) Search(ctx context.Context, request types.SearchRequest) ([]types.SearchHit, error) {
+	limit := request.Limit
+	switch {
+	case limit < 0:
+		return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "negative limit")
+	case limit == 0:
+		limit = defaultListLimit
+	case limit > maxListLimit:
+		limit = maxListLimit
+	}
+
+	hits, err := s.repo.Search(ctx, query.Search{
+		Query: request.Query,
+		Kinds: request.Kinds,
+		Limit: limit,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := make([]types.SearchHit, len(hits))
+	for i, hit := range hits {
+		result[i] = types.SearchHitFromDomain(&hit)
+	}
+
+	return result, nil
+}
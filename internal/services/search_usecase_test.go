@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"strconv"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func TestSearchUsecaseSearch(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewSearchUsecase(f.repo)
+
+	hits, err := usecase.Search(ctx, types.SearchRequest{Query: "петр"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Kind != valueobjects.SearchStudentKind || hits[0].ID != f.student {
+		t.Errorf("Search петр = %+v, want the student", hits)
+	}
+
+	if _, err := usecase.Search(ctx, types.SearchRequest{Query: "петр", Limit: -1}); !e.Is(err, errors.ErrInvalidFilters) {
+		t.Errorf("Search with a negative limit: err = %v, want ErrInvalidFilters", err)
+	}
+	if _, err := usecase.Search(ctx, types.SearchRequest{Query: "п"}); !e.Is(err, errors.ErrInvalidFilters) {
+		t.Errorf("Search with a short query: err = %v, want ErrInvalidFilters", err)
+	}
+}
+
+func TestSearchUsecaseSearchLimit(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewSearchUsecase(repo)
+
+	for i := range maxListLimit + 1 {
+		if _, err := repo.CreateGroup(ctx, commands.CreateGroup{Name: "Группа-" + strconv.FormatInt(i, 10)}); err != nil {
+			t.Fatalf("create group: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		limit int64
+		want  int64
+	}{
+		{0, defaultListLimit},
+		{5, 5},
+		{maxListLimit + 1, maxListLimit},
+	} {
+		hits, err := usecase.Search(ctx, types.SearchRequest{Query: "групп", Limit: tc.limit})
+		if err != nil {
+			t.Fatalf("Search with limit %d: %v", tc.limit, err)
+		}
+		if int64(len(hits)) != tc.want {
+			t.Errorf("Search with limit %d = %d hits, want %d", tc.limit, len(hits), tc.want)
+		}
+	}
+}
@@ -3,11 +3,14 @@ package application
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
 	"github.com/VanLavr/Diploma-fin/internal/domain/models"
 	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
 	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/VanLavr/Diploma-fin/internal/services/logic"
 	"github.com/VanLavr/Diploma-fin/internal/services/types"
 	"github.com/VanLavr/Diploma-fin/utils/errors"
@@ -77,26 +80,32 @@ func (this *studentUsecase) GetStudent(ctx context.Context, uuid string) (*types
 }
 
 // GetStudents implements logic.StudentUsecase.
-func (this *studentUsecase) GetStudents(ctx context.Context, limit int64, offset int64) ([]types.Student, error) {
-	students, err := this.repo.GetStudents(ctx, query.GetStudentsFilters{
-		Limit:  limit,
-		Offset: offset,
-	})
+func (this *studentUsecase) GetStudents(ctx context.Context, filters types.StudentFilters, params types.ListParams) (*types.Page[types.Student], error) {
+	keyset, limit, err := listKeyset(query.StudentSortFields, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
 	}
 
-	result := make([]types.Student, len(students))
-	for i, student := range students {
-		result[i] = types.StudentFromDomain(&student)
+	studentFilters := query.GetStudentsFilters{
+		IDs:       filters.UUIDs,
+		Emails:    filters.Emails,
+		GroupIDs:  filters.GroupIDs,
+		LastNames: filters.LastNames,
+		Limit:     limit + 1,
+		Keyset:    keyset,
 	}
-
+	students, err := this.repo.GetStudents(ctx, studentFilters)
 	if err != nil {
-		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	total, err := this.repo.CountStudents(ctx, studentFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
 	}
 
-	return result, nil
+	return listPage(students, query.StudentSortFields, keyset, limit, total, types.StudentFromDomain), nil
 }
 
 // UpdateStudent implements logic.StudentUsecase.
@@ -108,6 +117,7 @@ func (this *studentUsecase) UpdateStudent(ctx context.Context, student types.Stu
 		MiddleName: student.MiddleName,
 		GroupID:    student.Group.ID,
 		Email:      student.Email,
+		Version:    student.Version,
 	}); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return err
@@ -118,98 +128,113 @@ func (this *studentUsecase) UpdateStudent(ctx context.Context, student types.Stu
 
 func (this studentUsecase) GetAllDebts(ctx context.Context, UUID string) ([]types.Debt, error) {
 	debts, err := this.repo.GetDebts(ctx, query.GetDebtsFilters{
-		StudentUUIDs: []string{UUID},
+		StudentUUIDs:   []string{UUID},
+		WithExamGroups: true,
 	})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
 
 	result := make([]types.Debt, len(debts))
 	for i, debt := range debts {
 		result[i] = types.DebtFromDomain(&debt)
 	}
 
-	if err != nil {
-		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-	}
-
-	for i, debt := range result {
-		debtsWithGroupsByExam, err := this.repo.GetDebts(ctx, query.GetDebtsFilters{
-			ExamIDs: []int64{debt.Exam.ID},
-		})
-		if err != nil {
-			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-		}
+	return result, nil
+}
 
-		uniqueGroups := make(map[int64]models.Group)
-		for _, debt := range debtsWithGroupsByExam {
-			uniqueGroups[debt.Student.Group.ID] = models.Group{
-				ID:   debt.Student.Group.ID,
-				Name: debt.Student.Group.Name,
-			}
+func (this studentUsecase) RequestRetake(ctx context.Context, UUID string, debtID int64, slots []types.TimeSlot) (int64, error) {
+	if len(slots) > valueobjects.MaxPreferredSlots {
+		return 0, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "too many preferred slots", "max", valueobjects.MaxPreferredSlots)
+	}
+	preferredSlots := make([]models.TimeSlot, len(slots))
+	for i, slot := range slots {
+		if !slot.End.After(slot.Start) || slot.Start.Before(time.Now()) {
+			return 0, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "invalid preferred slot", "start", slot.Start, "end", slot.End)
 		}
-		groupList := make([]types.Group, 0, len(uniqueGroups))
-		for _, group := range uniqueGroups {
-			groupList = append(groupList, types.Group{
-				ID:   group.ID,
-				Name: group.Name,
-			})
+		preferredSlots[i] = models.TimeSlot{
+			Start: slot.Start,
+			End:   slot.End,
 		}
-
-		result[i].Groups = groupList
 	}
 
-	return result, nil
-}
-
-func (this studentUsecase) SendNotification(ctx context.Context, UUID string, debtID int64) error {
 	// get student personal data
-	fmt.Println("10")
 	students, err := this.repo.GetStudents(ctx, query.GetStudentsFilters{
 		IDs: []string{UUID},
 	})
-	if len(students) == 0 {
-		fmt.Println("11")
-		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
-	}
 	if err != nil {
-		fmt.Println("12")
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(students) == 0 {
+		return 0, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
 	}
-	fmt.Println("5")
 
 	// get debt by id
 	debts, err := this.repo.GetDebts(ctx, query.GetDebtsFilters{
 		DebtIDs: []int64{debtID},
 	})
+	if err != nil {
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
 	if len(debts) == 0 {
-		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		return 0, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	if debts[0].Student.UUID != UUID {
+		return 0, log.ErrorWrapper(errors.ErrUserDoesNotHaveRights, errors.ERR_APPLICATION, "debt belongs to another student")
 	}
+
+	// only one open request per debt and not more often than the cooldown allows
+	previous, err := this.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{
+		DebtIDs: []int64{debtID},
+		Limit:   1,
+	})
 	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(previous) != 0 {
+		if slices.Contains(valueobjects.OpenRetakeRequestStatuses, previous[0].Status) {
+			return 0, log.ErrorWrapper(errors.ErrRetakeRequestAlreadyExists, errors.ERR_APPLICATION, "")
+		}
+		if time.Since(previous[0].CreatedAt) < valueobjects.RetakeRequestCooldown {
+			return 0, log.ErrorWrapper(errors.ErrRetakeRequestCooldown, errors.ERR_APPLICATION, "")
+		}
 	}
-	fmt.Println("6")
 
 	// get teacher personal data
 	teachers, err := this.repo.GetTeachers(ctx, query.GetTeachersFilters{
 		UUIDs: []string{debts[0].Teacher.UUID},
 	})
-	if len(teachers) == 0 {
-		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
-	}
 	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		return 0, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(teachers) == 0 {
+		return 0, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
 	}
-	fmt.Println("7")
 
-	// send notification
-	err = this.repo.SendNotification(ctx, students[0], teachers[0].Email, models.Exam{
-		ID:   debts[0].Exam.ID,
-		Name: debts[0].Exam.Name,
+	// record the request and notify the teacher, the request is not kept if the email was not sent
+	var id int64
+	err = this.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		id, err = this.repo.CreateRetakeRequest(ctx, commands.CreateRetakeRequest{
+			DebtID:         debtID,
+			StudentUUID:    UUID,
+			TeacherUUID:    teachers[0].UUID,
+			PreferredSlots: preferredSlots,
+		})
+		if err != nil {
+			return err
+		}
+
+		return this.repo.SendNotification(ctx, students[0], teachers[0].Email, models.Exam{
+			ID:   debts[0].Exam.ID,
+			Name: debts[0].Exam.Name,
+		})
 	})
 	if err != nil {
-		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
 	}
-	fmt.Println("8")
 
-	return nil
+	return id, nil
 }
 
 func (s studentUsecase) GetStudentByEmail(ctx context.Context, email string) ([]types.Student, error) {
@@ -0,0 +1,312 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/hasher"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+func TestStudentUsecaseCreateStudent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	uuid, err := usecase.CreateStudent(ctx, types.Student{
+		FirstName: "Мария",
+		LastName:  "Козлова",
+		Email:     "kozlova@example.com",
+		Group:     &types.Group{ID: f.groupID},
+		Password:  "secret",
+	})
+	if err != nil {
+		t.Fatalf("CreateStudent: %v", err)
+	}
+
+	student, err := f.repo.GetStudentByUUID(ctx, uuid)
+	if err != nil {
+		t.Fatalf("GetStudentByUUID: %v", err)
+	}
+	if !hasher.Hshr.Validate(student.Password, "secret") {
+		t.Error("CreateStudent: want the password stored hashed")
+	}
+	if student.Group.Name != "ИВТ-41" {
+		t.Errorf("student group = %+v, want ИВТ-41", student.Group)
+	}
+
+	_, err = usecase.CreateStudent(ctx, types.Student{
+		FirstName: "Мария",
+		LastName:  "Козлова",
+		Email:     "kozlova@example.com",
+		Group:     &types.Group{ID: f.groupID},
+	})
+	if !tools.IsUniqueViolation(err) {
+		t.Errorf("CreateStudent with a taken email = %v, want a unique violation", err)
+	}
+
+	_, err = usecase.CreateStudent(ctx, types.Student{
+		FirstName: "Мария",
+		LastName:  "Козлова",
+		Email:     "kozlova2@example.com",
+		Group:     &types.Group{ID: f.groupID + 1000},
+	})
+	if !tools.IsForeignKeyViolation(err) {
+		t.Errorf("CreateStudent in an unknown group = %v, want a foreign key violation", err)
+	}
+}
+
+func TestStudentUsecaseGetStudents(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	student, err := usecase.GetStudent(ctx, f.student)
+	if err != nil {
+		t.Fatalf("GetStudent: %v", err)
+	}
+	if student.Email != "petrov@example.com" || student.Group.ID != f.groupID {
+		t.Errorf("GetStudent = %+v, want the fixture student", student)
+	}
+
+	students, err := usecase.GetStudentByEmail(ctx, "petrov@example.com")
+	if err != nil {
+		t.Fatalf("GetStudentByEmail: %v", err)
+	}
+	if len(students) != 1 || students[0].UUID != f.student {
+		t.Errorf("GetStudentByEmail = %+v, want the fixture student", students)
+	}
+
+	students, err = usecase.GetStudentByEmail(ctx, "nobody@example.com")
+	if err != nil || len(students) != 0 {
+		t.Errorf("GetStudentByEmail of an unknown email = %+v, %v, want nothing", students, err)
+	}
+
+	page, err := usecase.GetStudents(ctx, types.StudentFilters{GroupIDs: []int64{f.groupID}}, types.ListParams{Sort: "group,last_name"})
+	if err != nil {
+		t.Fatalf("GetStudents: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].UUID != f.student || page.Total != 1 || page.NextCursor != "" {
+		t.Errorf("GetStudents = %+v, want the fixture student", page)
+	}
+}
+
+func TestStudentUsecaseUpdateStudent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	groupID, err := f.repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-42"})
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+
+	if err := usecase.UpdateStudent(ctx, types.Student{
+		UUID:      f.student,
+		FirstName: "Иван",
+		LastName:  "Петров",
+		Email:     "ivan.petrov@example.com",
+		Group:     &types.Group{ID: groupID},
+	}); err != nil {
+		t.Fatalf("UpdateStudent: %v", err)
+	}
+
+	student, err := usecase.GetStudent(ctx, f.student)
+	if err != nil {
+		t.Fatalf("GetStudent: %v", err)
+	}
+	if student.Email != "ivan.petrov@example.com" || student.Group.Name != "ИВТ-42" {
+		t.Errorf("student = %+v, want the new email and group", student)
+	}
+}
+
+func TestStudentUsecaseUpdateStudentVersion(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	// two admins read the same version, the second update is rejected
+	read, err := usecase.GetStudent(ctx, f.student)
+	if err != nil {
+		t.Fatalf("GetStudent: %v", err)
+	}
+	first, second := *read, *read
+	first.FirstName = "Пётр"
+	second.FirstName = "Павел"
+
+	if err := usecase.UpdateStudent(ctx, first); err != nil {
+		t.Fatalf("UpdateStudent: %v", err)
+	}
+	if err := usecase.UpdateStudent(ctx, second); !e.Is(err, errors.ErrVersionMismatch) {
+		t.Errorf("UpdateStudent of the read version: err = %v, want ErrVersionMismatch", err)
+	}
+
+	student, err := usecase.GetStudent(ctx, f.student)
+	if err != nil {
+		t.Fatalf("GetStudent: %v", err)
+	}
+	if student.FirstName != "Пётр" || student.Version != read.Version+1 {
+		t.Errorf("student = %+v, want the first update", student)
+	}
+}
+
+func TestStudentUsecaseDeleteStudent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	if err := usecase.DeleteStudent(ctx, f.student); err != nil {
+		t.Fatalf("DeleteStudent of a student with debts: %v", err)
+	}
+	if _, err := usecase.GetStudent(ctx, f.student); err == nil {
+		t.Error("GetStudent of a deleted student: want an error")
+	}
+
+	// the debt keeps the deleted student, it is only listed on request
+	debts, err := f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}})
+	if err != nil || len(debts) != 0 {
+		t.Errorf("GetDebts = %+v, %v, want the debt of the deleted student left out", debts, err)
+	}
+	debts, err = f.repo.GetDebts(ctx, query.GetDebtsFilters{DebtIDs: []int64{f.debtID}, IncludeDeleted: true})
+	if err != nil || len(debts) != 1 || debts[0].Student.UUID != f.student {
+		t.Errorf("GetDebts with deleted = %+v, %v, want the debt of the deleted student", debts, err)
+	}
+}
+
+func TestStudentUsecaseGetAllDebts(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	// another group has a debt for the same exam
+	groupID, err := f.repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-42"})
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	other, err := f.repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Пётр",
+		LastName:  "Иванов",
+		Email:     "ivanov@example.com",
+		GroupID:   groupID,
+	})
+	if err != nil {
+		t.Fatalf("CreateStudent: %v", err)
+	}
+	if _, err := f.repo.CreateDebt(ctx, commands.CreateDebt{ExamID: f.examID, StudentUUID: other, TeacherUUID: f.teacher}); err != nil {
+		t.Fatalf("CreateDebt: %v", err)
+	}
+
+	amount, err := usecase.GetAmountOfDebts(ctx, f.student)
+	if err != nil || amount != 1 {
+		t.Errorf("GetAmountOfDebts = %d, %v, want 1", amount, err)
+	}
+
+	debts, err := usecase.GetAllDebts(ctx, f.student)
+	if err != nil {
+		t.Fatalf("GetAllDebts: %v", err)
+	}
+	if len(debts) != 1 || debts[0].ID != f.debtID {
+		t.Fatalf("GetAllDebts = %+v, want the fixture debt", debts)
+	}
+	if len(debts[0].Groups) != 2 {
+		t.Errorf("debt groups = %+v, want both groups with a debt for the exam", debts[0].Groups)
+	}
+}
+
+func TestStudentUsecaseRequestRetake(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	slots := []types.TimeSlot{{Start: start, End: start.Add(2 * time.Hour)}}
+
+	id, err := usecase.RequestRetake(ctx, f.student, f.debtID, slots)
+	if err != nil {
+		t.Fatalf("RequestRetake: %v", err)
+	}
+
+	requests, err := f.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{IDs: []int64{id}})
+	if err != nil {
+		t.Fatalf("GetRetakeRequests: %v", err)
+	}
+	if len(requests) != 1 || requests[0].Status != valueobjects.RetakeRequestPending || len(requests[0].PreferredSlots) != 1 {
+		t.Errorf("requests = %+v, want a pending request with the slot", requests)
+	}
+	if mails := f.mailer.Mails(); len(mails) != 1 || mails[0].To != "sidorova@example.com" {
+		t.Errorf("mails = %+v, want a notification to the teacher", mails)
+	}
+
+	if _, err := usecase.RequestRetake(ctx, f.student, f.debtID, slots); !e.Is(err, errors.ErrRetakeRequestAlreadyExists) {
+		t.Errorf("RequestRetake with an open request = %v, want %v", err, errors.ErrRetakeRequestAlreadyExists)
+	}
+
+	// a declined request still holds the next one back for the cooldown
+	if err := f.repo.UpdateRetakeRequestStatus(ctx, commands.UpdateRetakeRequestStatus{
+		IDs:    []int64{id},
+		Status: valueobjects.RetakeRequestDeclined,
+	}); err != nil {
+		t.Fatalf("UpdateRetakeRequestStatus: %v", err)
+	}
+	if _, err := usecase.RequestRetake(ctx, f.student, f.debtID, slots); !e.Is(err, errors.ErrRetakeRequestCooldown) {
+		t.Errorf("RequestRetake during the cooldown = %v, want %v", err, errors.ErrRetakeRequestCooldown)
+	}
+}
+
+func TestStudentUsecaseRequestRetakeRejected(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	other, err := f.repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Пётр",
+		LastName:  "Иванов",
+		Email:     "ivanov@example.com",
+		GroupID:   f.groupID,
+	})
+	if err != nil {
+		t.Fatalf("CreateStudent: %v", err)
+	}
+	if _, err := usecase.RequestRetake(ctx, other, f.debtID, nil); !e.Is(err, errors.ErrUserDoesNotHaveRights) {
+		t.Errorf("RequestRetake for a debt of another student = %v, want %v", err, errors.ErrUserDoesNotHaveRights)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := usecase.RequestRetake(ctx, f.student, f.debtID, []types.TimeSlot{{Start: past, End: past.Add(time.Hour)}}); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("RequestRetake with a past slot = %v, want %v", err, errors.ErrInvalidData)
+	}
+}
+
+func TestStudentUsecaseRequestRetakeNotSent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewStudentUsecase(f.repo)
+
+	// the teacher can not be notified without an email
+	if err := f.repo.UpdateTeacher(ctx, commands.UpdateTeacher{
+		UUID:      f.teacher,
+		FirstName: "Анна",
+		LastName:  "Сидорова",
+	}); err != nil {
+		t.Fatalf("UpdateTeacher: %v", err)
+	}
+
+	if _, err := usecase.RequestRetake(ctx, f.student, f.debtID, nil); err == nil {
+		t.Fatal("RequestRetake without a teacher email: want an error")
+	}
+
+	requests, err := f.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{DebtIDs: []int64{f.debtID}})
+	if err != nil {
+		t.Fatalf("GetRetakeRequests: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests = %+v, want the request rolled back", requests)
+	}
+}
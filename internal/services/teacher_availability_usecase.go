@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type teacherAvailabilityUsecase struct {
+	repo repositories.Repository
+}
+
+func NewTeacherAvailabilityUsecase(repo repositories.Repository) logic.TeacherAvailabilityUsecase {
+	return &teacherAvailabilityUsecase{
+		repo: repo,
+	}
+}
+
+// GetAvailability implements logic.TeacherAvailabilityUsecase.
+func (t *teacherAvailabilityUsecase) GetAvailability(ctx context.Context, teacherUUID string) ([]types.TeacherAvailability, error) {
+	entries, err := t.repo.GetTeacherAvailability(ctx, query.GetTeacherAvailabilityFilters{
+		TeacherUUIDs: []string{teacherUUID},
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := make([]types.TeacherAvailability, len(entries))
+	for i, entry := range entries {
+		result[i] = types.TeacherAvailabilityFromDomain(&entry)
+	}
+
+	return result, nil
+}
+
+// CreateAvailability implements logic.TeacherAvailabilityUsecase.
+func (t *teacherAvailabilityUsecase) CreateAvailability(ctx context.Context, teacherUUID string, entry types.TeacherAvailability) (int64, error) {
+	id, err := t.repo.CreateTeacherAvailability(ctx, commands.CreateTeacherAvailability{
+		TeacherUUID: teacherUUID,
+		Kind:        entry.Kind,
+		Weekday:     entry.Weekday,
+		StartMinute: entry.StartMinute,
+		EndMinute:   entry.EndMinute,
+		StartsAt:    entry.StartsAt,
+		EndsAt:      entry.EndsAt,
+		Note:        entry.Note,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateAvailability implements logic.TeacherAvailabilityUsecase.
+func (t *teacherAvailabilityUsecase) UpdateAvailability(ctx context.Context, teacherUUID string, entry types.TeacherAvailability) error {
+	if err := t.repo.UpdateTeacherAvailability(ctx, commands.UpdateTeacherAvailability{
+		ID:          entry.ID,
+		TeacherUUID: teacherUUID,
+		Kind:        entry.Kind,
+		Weekday:     entry.Weekday,
+		StartMinute: entry.StartMinute,
+		EndMinute:   entry.EndMinute,
+		StartsAt:    entry.StartsAt,
+		EndsAt:      entry.EndsAt,
+		Note:        entry.Note,
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAvailability implements logic.TeacherAvailabilityUsecase.
+func (t *teacherAvailabilityUsecase) DeleteAvailability(ctx context.Context, teacherUUID string, id int64) error {
+	if err := t.repo.DeleteTeacherAvailability(ctx, commands.DeleteTeacherAvailability{
+		ID:          id,
+		TeacherUUID: teacherUUID,
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// isTeacherAvailable tells whether the teacher can run a retake in [start, end).
+// Blackouts always forbid the time. A teacher without any weekly windows or
+// exceptions has not declared the calendar and is available otherwise.
+func isTeacherAvailable(entries []models.TeacherAvailability, start, end time.Time) bool {
+	var declared, inWindow bool
+	for _, entry := range entries {
+		switch entry.Kind {
+		case valueobjects.AvailabilityBlackout:
+			if start.Before(entry.EndsAt) && entry.StartsAt.Before(end) {
+				return false
+			}
+		case valueobjects.AvailabilityException:
+			declared = true
+			if !start.Before(entry.StartsAt) && !end.After(entry.EndsAt) {
+				inWindow = true
+			}
+		case valueobjects.AvailabilityWeekly:
+			declared = true
+			midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+			startMinute := int64(start.Sub(midnight) / time.Minute)
+			endMinute := int64(end.Sub(midnight) / time.Minute)
+			if start.Weekday() == entry.Weekday && startMinute >= entry.StartMinute && endMinute <= entry.EndMinute {
+				inWindow = true
+			}
+		}
+	}
+
+	return !declared || inWindow
+}
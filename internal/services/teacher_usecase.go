@@ -4,10 +4,10 @@ import (
 	"context"
 	e "errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
-	"github.com/VanLavr/Diploma-fin/internal/domain/models"
 	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
 	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
 	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
@@ -75,26 +75,31 @@ func (t *teacherUsecase) GetTeacher(ctx context.Context, uuid string) (types.Tea
 }
 
 // GetTeachers implements logic.TeacherUsecase.
-func (t *teacherUsecase) GetTeachers(ctx context.Context, limit int64, offset int64) ([]types.Teacher, error) {
-	teachers, err := t.repo.GetTeachers(ctx, query.GetTeachersFilters{
-		Limit:  limit,
-		Offset: offset,
-	})
+func (t *teacherUsecase) GetTeachers(ctx context.Context, filters types.TeacherFilters, params types.ListParams) (*types.Page[types.Teacher], error) {
+	keyset, limit, err := listKeyset(query.TeacherSortFields, params)
 	if err != nil {
-		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return nil, err
 	}
 
-	result := make([]types.Teacher, len(teachers))
-	for i, teacher := range teachers {
-		result[i] = types.TeacherFromDomain(&teacher)
+	teacherFilters := query.GetTeachersFilters{
+		UUIDs:     filters.UUIDs,
+		Emails:    filters.Emails,
+		LastNames: filters.LastNames,
+		Limit:     limit + 1,
+		Keyset:    keyset,
 	}
-
+	teachers, err := t.repo.GetTeachers(ctx, teacherFilters)
 	if err != nil {
-		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	total, err := t.repo.CountTeachers(ctx, teacherFilters)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
 	}
 
-	return result, nil
+	return listPage(teachers, query.TeacherSortFields, keyset, limit, total, types.TeacherFromDomain), nil
 }
 
 // UpdateTeacher implements logic.TeacherUsecase.
@@ -105,6 +110,7 @@ func (t *teacherUsecase) UpdateTeacher(ctx context.Context, teacher types.Teache
 		LastName:   teacher.LastName,
 		MiddleName: teacher.MiddleName,
 		Email:      teacher.Email,
+		Version:    teacher.Version,
 	}); err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return err
@@ -115,87 +121,196 @@ func (t *teacherUsecase) UpdateTeacher(ctx context.Context, teacher types.Teache
 
 func (t teacherUsecase) GetAllDebts(ctx context.Context, UUID string) ([]types.Debt, error) {
 	debts, err := t.repo.GetDebts(ctx, query.GetDebtsFilters{
-		TeacherUUIDs: []string{UUID},
+		TeacherUUIDs:   []string{UUID},
+		WithExamGroups: true,
 	})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
 
 	result := make([]types.Debt, len(debts))
 	for i, debt := range debts {
 		result[i] = types.DebtFromDomain(&debt)
 	}
 
+	return result, nil
+}
+
+func (this teacherUsecase) SetDate(ctx context.Context, teacherUUID string, request types.SetDateRequest) (*types.SetDateReport, error) {
+	examDate, err := time.Parse(valueobjects.DateLayout, request.Date)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, err.Error())
+	}
+
+	room, err := getRoom(ctx, this.repo, request.RoomID)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+	address := request.Address
+	if room != nil && address == "" {
+		address = roomAddress(room)
+	}
+
+	// only debts of the caller are touched, other teachers' students stay as they are
+	debts, err := this.repo.GetDebts(ctx, query.GetDebtsFilters{
+		TeacherUUIDs: []string{teacherUUID},
+		ExamIDs:      []int64{request.ExamID},
+		GroupIDs:     request.GroupIDs,
+		StudentUUIDs: request.StudentUUIDs,
+	})
 	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		if e.Is(err, errors.ErrInvalidFilters) {
+			return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "")
+		}
 		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
+	if len(debts) == 0 {
+		log.Logger.Error("no debts", errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
 
-	for i, debt := range result {
-		debtsWithGroupsByExam, err := t.repo.GetDebts(ctx, query.GetDebtsFilters{
-			ExamIDs: []int64{debt.Exam.ID},
-		})
-		if err != nil {
-			return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	slot := retakeSlot{
+		ExamID:      request.ExamID,
+		TeacherUUID: teacherUUID,
+		Date:        examDate,
+		Room:        room,
+	}
+	for _, debt := range debts {
+		slot.StudentUUIDs = append(slot.StudentUUIDs, debt.Student.UUID)
+		slot.DebtIDs = append(slot.DebtIDs, debt.ID)
+	}
+	conflicts, err := findScheduleConflicts(ctx, this.repo, slot)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	report := &types.SetDateReport{
+		Conflicts: conflicts,
+	}
+	if len(conflicts) > 0 && !request.Force {
+		return report, log.ErrorWrapper(errors.ErrScheduleConflict, errors.ERR_APPLICATION, "", "conflicts", len(conflicts))
+	}
+
+	var roomID int64
+	if room != nil {
+		roomID = room.ID
+	}
+
+	// a failure of one debt is reported and does not stop the others
+	report.Results = make([]types.SetDateResult, len(debts))
+	for i, debt := range debts {
+		report.Results[i] = types.SetDateResult{
+			DebtID:      debt.ID,
+			StudentUUID: debt.Student.UUID,
 		}
 
-		uniqueGroups := make(map[int64]models.Group)
-		for _, debt := range debtsWithGroupsByExam {
-			uniqueGroups[debt.Student.Group.ID] = models.Group{
-				ID:   debt.Student.Group.ID,
-				Name: debt.Student.Group.Name,
+		if err := this.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+			// the slot is checked again under the lock, it may have been
+			// taken since the check above
+			if err := this.repo.LockSchedule(ctx); err != nil {
+				return err
 			}
-		}
-		groupList := make([]types.Group, 0, len(uniqueGroups))
-		for _, group := range uniqueGroups {
-			groupList = append(groupList, types.Group{
-				ID:   group.ID,
-				Name: group.Name,
+			if !request.Force {
+				conflicts, err := findScheduleConflicts(ctx, this.repo, slot)
+				if err != nil {
+					return err
+				}
+				if len(conflicts) > 0 {
+					return log.ErrorWrapper(errors.ErrScheduleConflict, errors.ERR_APPLICATION, "", "conflicts", len(conflicts))
+				}
+			}
+
+			if err := this.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+				DebtID:      debt.ID,
+				Date:        examDate,
+				Address:     address,
+				RoomID:      roomID,
+				TeacherUUID: debt.Teacher.UUID,
+				StudentUUID: debt.Student.UUID,
+			}); err != nil {
+				return err
+			}
+
+			// requests for the debt are answered by the new date
+			return this.repo.UpdateRetakeRequestStatus(ctx, commands.UpdateRetakeRequestStatus{
+				DebtIDs: []int64{debt.ID},
+				Status:  valueobjects.RetakeRequestScheduled,
 			})
+		}); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			report.Results[i].Err = err
+			continue
 		}
+		report.Results[i].Scheduled = true
 
-		result[i].Groups = groupList
+		if err := this.repo.NotifyNewDateAndPlace(ctx, debt.Student.Email, debt.Exam.Name, request.Date, address); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			report.Results[i].Err = err
+			continue
+		}
+		report.Results[i].Notified = true
 	}
 
-	return result, nil
+	return report, nil
 }
 
-func (this teacherUsecase) SetDate(ctx context.Context, teacherUUID, date, address string, examID int64) error {
-	examDate, err := time.Parse(valueobjects.DateLayout, date)
+// GetRetakeRequests implements logic.TeacherUsecase.
+func (t teacherUsecase) GetRetakeRequests(ctx context.Context, teacherUUID string, statuses []string) ([]types.RetakeRequest, error) {
+	for _, status := range statuses {
+		if !valueobjects.IsValidRetakeRequestStatus(status) {
+			return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "unknown status", "status", status)
+		}
+	}
+
+	requests, err := t.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{
+		TeacherUUIDs: []string{teacherUUID},
+		Statuses:     statuses,
+	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		return log.ErrorWrapper(err, errors.ERR_APPLICATION, "")
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
-	debts, err := this.repo.GetDebts(ctx, query.GetDebtsFilters{
-		ExamIDs: []int64{examID},
+	result := make([]types.RetakeRequest, len(requests))
+	for i, request := range requests {
+		result[i] = types.RetakeRequestFromDomain(&request)
+	}
+
+	return result, nil
+}
+
+// UpdateRetakeRequestStatus implements logic.TeacherUsecase.
+func (t teacherUsecase) UpdateRetakeRequestStatus(ctx context.Context, teacherUUID string, id int64, status string) error {
+	// scheduled is set by SetDate only
+	if status != valueobjects.RetakeRequestAcknowledged && status != valueobjects.RetakeRequestDeclined {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "status can not be set manually", "status", status)
+	}
+
+	requests, err := t.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{
+		IDs:          []int64{id},
+		TeacherUUIDs: []string{teacherUUID},
 	})
 	if err != nil {
 		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
 		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
-	if len(debts) < 1 || len(debts) == 0 {
-		log.Logger.Error("no debts", errors.MethodKey, log.GetMethodName())
+	if len(requests) == 0 {
 		return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
 	}
+	if !slices.Contains(valueobjects.OpenRetakeRequestStatuses, requests[0].Status) {
+		return log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "request is already closed", "status", requests[0].Status)
+	}
 
-	for _, debt := range debts {
-		if err = this.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
-			DebtID:      debt.ID,
-			Date:        examDate,
-			TeacherUUID: teacherUUID,
-			StudentUUID: debt.Student.UUID,
-			Address:     address,
-		}); err != nil {
-			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-			return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
-		}
-
-		err = this.repo.NotifyNewDateAndPlace(ctx, debt.Student.Email, debt.Exam.Name, date, address)
-		switch {
-		case err == nil:
-		case e.Is(err, errors.ErrInvalidData):
-			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-		default:
-			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
-			return err
-		}
+	if err := t.repo.UpdateRetakeRequestStatus(ctx, commands.UpdateRetakeRequestStatus{
+		IDs:    []int64{id},
+		Status: status,
+	}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
 	}
 
 	return nil
@@ -217,3 +332,44 @@ func (t teacherUsecase) GetTeacherByEmail(ctx context.Context, email string) ([]
 
 	return result, nil
 }
+
+// SuggestRetakeSlot implements logic.TeacherUsecase.
+func (t teacherUsecase) SuggestRetakeSlot(ctx context.Context, teacherUUID string, examID int64) (*types.SlotSuggestion, error) {
+	requests, err := t.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{
+		TeacherUUIDs: []string{teacherUUID},
+		ExamIDs:      []int64{examID},
+		Statuses:     valueobjects.OpenRetakeRequestStatuses,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := &types.SlotSuggestion{
+		ExamID:      examID,
+		Preferences: make([]types.StudentPreferences, 0, len(requests)),
+	}
+	students := make(map[string]types.Student, len(requests))
+	preferences := make(map[string][]types.TimeSlot, len(requests))
+	for _, request := range requests {
+		converted := types.RetakeRequestFromDomain(&request)
+		student := *converted.Debt.Student
+
+		students[student.UUID] = student
+		preferences[student.UUID] = append(preferences[student.UUID], converted.PreferredSlots...)
+		result.Preferences = append(result.Preferences, types.StudentPreferences{
+			RequestID: converted.ID,
+			Student:   student,
+			Slots:     converted.PreferredSlots,
+		})
+	}
+
+	slot, covered := mostCoveredSlot(preferences)
+	result.Slot = slot
+	result.CoveredStudents = make([]types.Student, 0, len(covered))
+	for _, uuid := range covered {
+		result.CoveredStudents = append(result.CoveredStudents, students[uuid])
+	}
+
+	return result, nil
+}
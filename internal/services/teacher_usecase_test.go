@@ -0,0 +1,369 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/hasher"
+	"github.com/VanLavr/Diploma-fin/utils/tools"
+)
+
+func TestTeacherUsecaseCreateTeacher(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewTeacherUsecase(repo)
+
+	uuid, err := usecase.CreateTeacher(ctx, types.Teacher{
+		FirstName: "Олег",
+		LastName:  "Орлов",
+		Email:     "orlov@example.com",
+		Password:  "secret",
+	})
+	if err != nil {
+		t.Fatalf("CreateTeacher: %v", err)
+	}
+
+	teacher, err := usecase.GetTeacher(ctx, uuid)
+	if err != nil {
+		t.Fatalf("GetTeacher: %v", err)
+	}
+	if teacher.Email != "orlov@example.com" || !hasher.Hshr.Validate(teacher.Password, "secret") {
+		t.Errorf("GetTeacher = %+v, want the teacher with the password hashed", teacher)
+	}
+
+	if _, err := usecase.CreateTeacher(ctx, types.Teacher{Email: "orlov@example.com"}); !tools.IsUniqueViolation(err) {
+		t.Errorf("CreateTeacher with a taken email = %v, want a unique violation", err)
+	}
+
+	teachers, err := usecase.GetTeacherByEmail(ctx, "orlov@example.com")
+	if err != nil || len(teachers) != 1 || teachers[0].UUID != uuid {
+		t.Errorf("GetTeacherByEmail = %+v, %v, want the teacher", teachers, err)
+	}
+}
+
+func TestTeacherUsecaseGetTeachers(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewTeacherUsecase(repo)
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := usecase.CreateTeacher(ctx, types.Teacher{FirstName: "Имя", LastName: "Фамилия", Email: email}); err != nil {
+			t.Fatalf("CreateTeacher(%s): %v", email, err)
+		}
+	}
+
+	// the teachers share the last name, the email orders them
+	var emails []string
+	params := types.ListParams{Sort: "last_name,-email", Limit: 2}
+	for pages := 0; ; pages++ {
+		page, err := usecase.GetTeachers(ctx, types.TeacherFilters{}, params)
+		if err != nil {
+			t.Fatalf("GetTeachers: %v", err)
+		}
+		if page.Total != 3 || pages > 2 {
+			t.Fatalf("GetTeachers = %+v, want 3 teachers on 2 pages", page)
+		}
+		for _, teacher := range page.Items {
+			emails = append(emails, teacher.Email)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+	if want := []string{"c@example.com", "b@example.com", "a@example.com"}; !slices.Equal(emails, want) {
+		t.Errorf("GetTeachers sorted by last_name,-email = %v, want %v", emails, want)
+	}
+}
+
+func TestTeacherUsecaseUpdateTeacher(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	other, err := usecase.CreateTeacher(ctx, types.Teacher{FirstName: "Олег", LastName: "Орлов", Email: "orlov@example.com"})
+	if err != nil {
+		t.Fatalf("CreateTeacher: %v", err)
+	}
+
+	err = usecase.UpdateTeacher(ctx, types.Teacher{UUID: other, FirstName: "Олег", LastName: "Орлов", Email: "sidorova@example.com"})
+	if !tools.IsUniqueViolation(err) {
+		t.Errorf("UpdateTeacher to a taken email = %v, want a unique violation", err)
+	}
+
+	if err := usecase.UpdateTeacher(ctx, types.Teacher{UUID: other, FirstName: "Олег", LastName: "Орлов", MiddleName: "Ильич", Email: "o.orlov@example.com"}); err != nil {
+		t.Fatalf("UpdateTeacher: %v", err)
+	}
+	teacher, err := usecase.GetTeacher(ctx, other)
+	if err != nil {
+		t.Fatalf("GetTeacher: %v", err)
+	}
+	if teacher.MiddleName != "Ильич" || teacher.Email != "o.orlov@example.com" {
+		t.Errorf("GetTeacher = %+v, want the update applied", teacher)
+	}
+}
+
+func TestTeacherUsecaseDeleteTeacher(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	if _, err := f.repo.CreateTeacherAvailability(ctx, commands.CreateTeacherAvailability{
+		TeacherUUID: f.teacher,
+		Kind:        valueobjects.AvailabilityWeekly,
+		Weekday:     time.Monday,
+		StartMinute: 9 * 60,
+		EndMinute:   12 * 60,
+	}); err != nil {
+		t.Fatalf("CreateTeacherAvailability: %v", err)
+	}
+	if err := usecase.DeleteTeacher(ctx, f.teacher); err != nil {
+		t.Fatalf("DeleteTeacher of a teacher with debts: %v", err)
+	}
+	if _, err := usecase.GetTeacher(ctx, f.teacher); err == nil {
+		t.Error("GetTeacher of a deleted teacher: want an error")
+	}
+
+	// the availability stays for a restore
+	availability, err := f.repo.GetTeacherAvailability(ctx, query.GetTeacherAvailabilityFilters{TeacherUUIDs: []string{f.teacher}})
+	if err != nil || len(availability) != 1 {
+		t.Errorf("availability of a deleted teacher = %+v, %v, want the entry", availability, err)
+	}
+}
+
+func TestTeacherUsecaseGetAllDebts(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	debts, err := usecase.GetAllDebts(ctx, f.teacher)
+	if err != nil {
+		t.Fatalf("GetAllDebts: %v", err)
+	}
+	if len(debts) != 1 || debts[0].Student.UUID != f.student {
+		t.Fatalf("GetAllDebts = %+v, want the fixture debt", debts)
+	}
+	if len(debts[0].Groups) != 1 || debts[0].Groups[0].Name != "ИВТ-41" {
+		t.Errorf("debt groups = %+v, want ИВТ-41", debts[0].Groups)
+	}
+
+	debts, err = usecase.GetAllDebts(ctx, "00000000-0000-4000-8000-000000000000")
+	if err != nil || len(debts) != 0 {
+		t.Errorf("GetAllDebts of another teacher = %+v, %v, want nothing", debts, err)
+	}
+}
+
+func TestTeacherUsecaseSetDate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	requestID, err := NewStudentUsecase(f.repo).RequestRetake(ctx, f.student, f.debtID, nil)
+	if err != nil {
+		t.Fatalf("RequestRetake: %v", err)
+	}
+	roomID, err := f.repo.CreateRoom(ctx, commands.CreateRoom{Building: "Главный корпус", Number: "101", Capacity: 30})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	date := time.Now().UTC().Add(72 * time.Hour).Truncate(time.Hour)
+	report, err := usecase.SetDate(ctx, f.teacher, types.SetDateRequest{
+		ExamID: f.examID,
+		Date:   date.Format(valueobjects.DateLayout),
+		RoomID: roomID,
+	})
+	if err != nil {
+		t.Fatalf("SetDate: %v", err)
+	}
+	if len(report.Results) != 1 || !report.Results[0].Scheduled || !report.Results[0].Notified {
+		t.Fatalf("SetDate report = %+v, want the debt scheduled and the student notified", report)
+	}
+
+	debt, err := NewExamUsecase(f.repo).GetDebt(ctx, f.debtID)
+	if err != nil {
+		t.Fatalf("GetDebt: %v", err)
+	}
+	if debt.Date == nil || !debt.Date.Equal(date) {
+		t.Errorf("debt date = %v, want %v", debt.Date, date)
+	}
+
+	requests, err := f.repo.GetRetakeRequests(ctx, query.GetRetakeRequestsFilters{IDs: []int64{requestID}})
+	if err != nil {
+		t.Fatalf("GetRetakeRequests: %v", err)
+	}
+	if len(requests) != 1 || requests[0].Status != valueobjects.RetakeRequestScheduled {
+		t.Errorf("requests = %+v, want the request scheduled", requests)
+	}
+
+	// the student can not sit another retake at the same time
+	examID, err := f.repo.CreateExam(ctx, commands.CreateExam{Name: "Сети", AssessmentType: valueobjects.AssessmentExam})
+	if err != nil {
+		t.Fatalf("CreateExam: %v", err)
+	}
+	other, err := f.repo.CreateTeacher(ctx, commands.CreateTeacher{FirstName: "Олег", LastName: "Орлов", Email: "orlov@example.com"})
+	if err != nil {
+		t.Fatalf("CreateTeacher: %v", err)
+	}
+	if _, err := f.repo.CreateDebt(ctx, commands.CreateDebt{ExamID: examID, StudentUUID: f.student, TeacherUUID: other}); err != nil {
+		t.Fatalf("CreateDebt: %v", err)
+	}
+
+	report, err = usecase.SetDate(ctx, other, types.SetDateRequest{
+		ExamID: examID,
+		Date:   date.Add(30 * time.Minute).Format(valueobjects.DateLayout),
+	})
+	if !e.Is(err, errors.ErrScheduleConflict) {
+		t.Fatalf("SetDate over another retake = %v, want %v", err, errors.ErrScheduleConflict)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Kind != valueobjects.ScheduleConflictStudent {
+		t.Errorf("conflicts = %+v, want the student conflict", report.Conflicts)
+	}
+
+	report, err = usecase.SetDate(ctx, other, types.SetDateRequest{
+		ExamID: examID,
+		Date:   date.Add(30 * time.Minute).Format(valueobjects.DateLayout),
+		Force:  true,
+	})
+	if err != nil || len(report.Results) != 1 || !report.Results[0].Scheduled {
+		t.Errorf("SetDate with Force = %+v, %v, want the debt scheduled", report, err)
+	}
+}
+
+// racingSchedule runs race once, in the middle of the first conflict check,
+// as if another request scheduled a retake between the check and the write.
+type racingSchedule struct {
+	repositories.Repository
+	race func()
+}
+
+func (this *racingSchedule) GetTeacherAvailability(ctx context.Context, filters query.GetTeacherAvailabilityFilters) ([]models.TeacherAvailability, error) {
+	if this.race != nil {
+		this.race()
+		this.race = nil
+	}
+	return this.Repository.GetTeacherAvailability(ctx, filters)
+}
+
+func TestTeacherUsecaseSetDateTakenMeanwhile(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name      string
+		force     bool
+		scheduled bool
+	}{
+		{"rejected", false, false},
+		{"forced", true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			date := time.Now().UTC().Add(72 * time.Hour).Truncate(time.Hour)
+			usecase := NewTeacherUsecase(&racingSchedule{
+				Repository: f.repo,
+				race: func() {
+					f.addSession(t, f.examID, 5, date.Add(30*time.Minute), time.Hour, time.Hour)
+				},
+			})
+
+			report, err := usecase.SetDate(ctx, f.teacher, types.SetDateRequest{
+				ExamID: f.examID,
+				Date:   date.Format(valueobjects.DateLayout),
+				Force:  tc.force,
+			})
+			if err != nil || len(report.Results) != 1 {
+				t.Fatalf("SetDate = %+v, %v", report, err)
+			}
+			result := report.Results[0]
+			if result.Scheduled != tc.scheduled || (tc.scheduled != (result.Err == nil)) {
+				t.Errorf("result = %+v, want scheduled %v", result, tc.scheduled)
+			}
+			if !tc.scheduled && !e.Is(result.Err, errors.ErrScheduleConflict) {
+				t.Errorf("result err = %v, want ErrScheduleConflict", result.Err)
+			}
+
+			debt, err := NewExamUsecase(f.repo).GetDebt(ctx, f.debtID)
+			if err != nil {
+				t.Fatalf("GetDebt: %v", err)
+			}
+			if scheduled := debt.Date != nil; scheduled != tc.scheduled {
+				t.Errorf("debt date = %v, want scheduled %v", debt.Date, tc.scheduled)
+			}
+		})
+	}
+}
+
+func TestTeacherUsecaseSetDateNoDebts(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	if _, err := usecase.SetDate(ctx, f.teacher, types.SetDateRequest{ExamID: f.examID, Date: "tomorrow"}); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("SetDate with a malformed date = %v, want %v", err, errors.ErrInvalidData)
+	}
+
+	other, err := f.repo.CreateTeacher(ctx, commands.CreateTeacher{FirstName: "Олег", LastName: "Орлов", Email: "orlov@example.com"})
+	if err != nil {
+		t.Fatalf("CreateTeacher: %v", err)
+	}
+	// debts of other teachers are not touched
+	date := time.Now().UTC().Add(72 * time.Hour).Format(valueobjects.DateLayout)
+	if _, err := usecase.SetDate(ctx, other, types.SetDateRequest{ExamID: f.examID, Date: date}); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("SetDate of another teacher = %v, want %v", err, errors.ErroNoItemsFound)
+	}
+}
+
+func TestTeacherUsecaseRetakeRequests(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTeacherUsecase(f.repo)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	id, err := NewStudentUsecase(f.repo).RequestRetake(ctx, f.student, f.debtID, []types.TimeSlot{
+		{Start: start, End: start.Add(3 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("RequestRetake: %v", err)
+	}
+
+	if _, err := usecase.GetRetakeRequests(ctx, f.teacher, []string{"lost"}); !e.Is(err, errors.ErrInvalidFilters) {
+		t.Errorf("GetRetakeRequests with an unknown status = %v, want %v", err, errors.ErrInvalidFilters)
+	}
+	requests, err := usecase.GetRetakeRequests(ctx, f.teacher, []string{valueobjects.RetakeRequestPending})
+	if err != nil || len(requests) != 1 || requests[0].ID != id {
+		t.Fatalf("GetRetakeRequests = %+v, %v, want the pending request", requests, err)
+	}
+
+	suggestion, err := usecase.SuggestRetakeSlot(ctx, f.teacher, f.examID)
+	if err != nil {
+		t.Fatalf("SuggestRetakeSlot: %v", err)
+	}
+	if suggestion.Slot == nil || !suggestion.Slot.Start.Equal(start) || len(suggestion.CoveredStudents) != 1 {
+		t.Errorf("SuggestRetakeSlot = %+v, want the preferred slot of the student", suggestion)
+	}
+
+	if err := usecase.UpdateRetakeRequestStatus(ctx, f.teacher, id, valueobjects.RetakeRequestScheduled); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("UpdateRetakeRequestStatus to scheduled = %v, want %v", err, errors.ErrInvalidData)
+	}
+	if err := usecase.UpdateRetakeRequestStatus(ctx, f.teacher, id, valueobjects.RetakeRequestDeclined); err != nil {
+		t.Fatalf("UpdateRetakeRequestStatus: %v", err)
+	}
+	if err := usecase.UpdateRetakeRequestStatus(ctx, f.teacher, id, valueobjects.RetakeRequestAcknowledged); !e.Is(err, errors.ErrInvalidData) {
+		t.Errorf("UpdateRetakeRequestStatus of a declined request = %v, want %v", err, errors.ErrInvalidData)
+	}
+
+	requests, err = usecase.GetRetakeRequests(ctx, f.teacher, valueobjects.OpenRetakeRequestStatuses)
+	if err != nil || len(requests) != 0 {
+		t.Errorf("open requests = %+v, %v, want none", requests, err)
+	}
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+// timetableInput is everything the timetable engine needs to know.
+type timetableInput struct {
+	PeriodStart time.Time
+	Deadline    time.Time
+	// Debts are the unscheduled debts to place
+	Debts []models.Debt
+	Rooms []models.Room
+	// Availability is keyed by teacher uuid
+	Availability map[string][]models.TeacherAvailability
+	// Scheduled are retakes already in the timetable, they are kept as they are
+	Scheduled []models.ScheduledRetake
+}
+
+// timetablePlacement is a debt with the retake it got, Room is nil and
+// Reason is set for a debt that was not placed.
+type timetablePlacement struct {
+	Debt   models.Debt
+	Room   *models.Room
+	Date   time.Time
+	Reason string
+}
+
+// timetableSlots lists start times of retakes between the period start and
+// the deadline: RetakeDuration long slots from TimetableDayStart to
+// TimetableDayEnd, Sundays are skipped.
+func timetableSlots(periodStart, deadline time.Time) []time.Time {
+	slots := make([]time.Time, 0)
+
+	day := time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day(), 0, 0, 0, 0, periodStart.Location())
+	for ; day.Before(deadline); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Sunday {
+			continue
+		}
+		for offset := valueobjects.TimetableDayStart; offset+valueobjects.RetakeDuration <= valueobjects.TimetableDayEnd; offset += valueobjects.RetakeDuration {
+			start := day.Add(offset)
+			if start.Before(periodStart) || start.Add(valueobjects.RetakeDuration).After(deadline) {
+				continue
+			}
+			slots = append(slots, start)
+		}
+	}
+
+	return slots
+}
+
+// timetableBusy tracks what is taken in the timetable being built.
+type timetableBusy struct {
+	teachers map[string][]time.Time
+	rooms    map[int64][]time.Time
+	// students keeps days with a retake, a student sits one retake a day
+	students map[string]map[string]bool
+}
+
+func newTimetableBusy(scheduled []models.ScheduledRetake) *timetableBusy {
+	busy := &timetableBusy{
+		teachers: make(map[string][]time.Time),
+		rooms:    make(map[int64][]time.Time),
+		students: make(map[string]map[string]bool),
+	}
+	for _, retake := range scheduled {
+		busy.take(retake.TeacherUUID, retake.RoomID, retake.Date, retake.StudentUUID)
+	}
+
+	return busy
+}
+
+func (this *timetableBusy) take(teacherUUID string, roomID int64, start time.Time, studentUUIDs ...string) {
+	this.teachers[teacherUUID] = append(this.teachers[teacherUUID], start)
+	if roomID != 0 {
+		this.rooms[roomID] = append(this.rooms[roomID], start)
+	}
+	for _, uuid := range studentUUIDs {
+		if uuid == "" {
+			continue
+		}
+		if this.students[uuid] == nil {
+			this.students[uuid] = make(map[string]bool)
+		}
+		this.students[uuid][start.Format(time.DateOnly)] = true
+	}
+}
+
+func overlapsAny(starts []time.Time, start time.Time) bool {
+	for _, other := range starts {
+		diff := other.Sub(start)
+		if diff < valueobjects.RetakeDuration && diff > -valueobjects.RetakeDuration {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this *timetableBusy) teacherFree(uuid string, start time.Time) bool {
+	return !overlapsAny(this.teachers[uuid], start)
+}
+
+func (this *timetableBusy) roomFree(id int64, start time.Time) bool {
+	return !overlapsAny(this.rooms[id], start)
+}
+
+func (this *timetableBusy) studentFree(uuid string, start time.Time) bool {
+	return !this.students[uuid][start.Format(time.DateOnly)]
+}
+
+// planTimetable places debts greedily. Debts of one teacher and exam are
+// sat together when possible: the biggest groups are placed first, each
+// takes the earliest slot where the teacher is available and free, and the
+// smallest free room that seats the students who have no other retake that
+// day. Students who do not fit move on to later slots.
+func planTimetable(input timetableInput) []timetablePlacement {
+	type groupKey struct {
+		teacherUUID string
+		examID      int64
+	}
+	groups := make(map[groupKey][]models.Debt)
+	keys := make([]groupKey, 0)
+	for _, debt := range input.Debts {
+		key := groupKey{teacherUUID: debt.Teacher.UUID, examID: debt.Exam.ID}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], debt)
+	}
+	slices.SortFunc(keys, func(a, b groupKey) int {
+		return cmp.Or(
+			cmp.Compare(len(groups[b]), len(groups[a])),
+			cmp.Compare(a.teacherUUID, b.teacherUUID),
+			cmp.Compare(a.examID, b.examID),
+		)
+	})
+
+	rooms := slices.Clone(input.Rooms)
+	slices.SortFunc(rooms, func(a, b models.Room) int {
+		return cmp.Or(cmp.Compare(a.Capacity, b.Capacity), cmp.Compare(a.ID, b.ID))
+	})
+
+	slots := timetableSlots(input.PeriodStart, input.Deadline)
+	busy := newTimetableBusy(input.Scheduled)
+	result := make([]timetablePlacement, 0, len(input.Debts))
+
+	for _, key := range keys {
+		pending := groups[key]
+		slices.SortFunc(pending, func(a, b models.Debt) int { return cmp.Compare(a.Student.UUID, b.Student.UUID) })
+
+		if len(rooms) == 0 {
+			for _, debt := range pending {
+				result = append(result, timetablePlacement{Debt: debt, Reason: valueobjects.TimetableNoRoomReason})
+			}
+			continue
+		}
+
+		for _, slot := range slots {
+			if len(pending) == 0 {
+				break
+			}
+			if !busy.teacherFree(key.teacherUUID, slot) ||
+				!isTeacherAvailable(input.Availability[key.teacherUUID], slot, slot.Add(valueobjects.RetakeDuration)) {
+				continue
+			}
+
+			seated := make([]models.Debt, 0, len(pending))
+			waiting := make([]models.Debt, 0, len(pending))
+			for _, debt := range pending {
+				if busy.studentFree(debt.Student.UUID, slot) && !slices.ContainsFunc(seated, func(d models.Debt) bool { return d.Student.UUID == debt.Student.UUID }) {
+					seated = append(seated, debt)
+				} else {
+					waiting = append(waiting, debt)
+				}
+			}
+			if len(seated) == 0 {
+				continue
+			}
+
+			// the smallest free room that seats everybody, the largest free one otherwise
+			var room *models.Room
+			for i := range rooms {
+				if !busy.roomFree(rooms[i].ID, slot) {
+					continue
+				}
+				room = &rooms[i]
+				if rooms[i].Capacity >= int64(len(seated)) {
+					break
+				}
+			}
+			if room == nil {
+				continue
+			}
+			if int64(len(seated)) > room.Capacity {
+				waiting = append(waiting, seated[room.Capacity:]...)
+				seated = seated[:room.Capacity]
+			}
+
+			studentUUIDs := make([]string, len(seated))
+			for i, debt := range seated {
+				studentUUIDs[i] = debt.Student.UUID
+				result = append(result, timetablePlacement{Debt: debt, Room: room, Date: slot})
+			}
+			busy.take(key.teacherUUID, room.ID, slot, studentUUIDs...)
+
+			pending = waiting
+			slices.SortFunc(pending, func(a, b models.Debt) int { return cmp.Compare(a.Student.UUID, b.Student.UUID) })
+		}
+
+		for _, debt := range pending {
+			result = append(result, timetablePlacement{Debt: debt, Reason: valueobjects.TimetableNoSlotReason})
+		}
+	}
+
+	return result
+}
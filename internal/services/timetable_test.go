@@ -0,0 +1,185 @@
+package application
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+)
+
+func TestPlanTimetable(t *testing.T) {
+	// Monday, retakes start at 9:00, 10:30, 12:00, 13:30, 15:00 and 16:30
+	monday := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour, minute int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	debt := func(id int64, teacherUUID string, examID int64, studentUUID string) models.Debt {
+		return models.Debt{
+			ID:      id,
+			Teacher: &models.Teacher{UUID: teacherUUID},
+			Exam:    &models.Exam{ID: examID},
+			Student: &models.Student{UUID: studentUUID},
+		}
+	}
+	// placement is debt id, room id, date and reason of a timetablePlacement
+	type placement struct {
+		debtID int64
+		roomID int64
+		date   time.Time
+		reason string
+	}
+	placed := func(debtID, roomID int64, date time.Time) placement {
+		return placement{debtID: debtID, roomID: roomID, date: date}
+	}
+	small := models.Room{ID: 1, Capacity: 2}
+	large := models.Room{ID: 2, Capacity: 10}
+
+	for _, tc := range []struct {
+		name  string
+		input timetableInput
+		want  []placement
+	}{
+		{
+			name: "no rooms",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1")},
+			},
+			want: []placement{{debtID: 1, reason: valueobjects.TimetableNoRoomReason}},
+		},
+		{
+			name: "no slot before the deadline",
+			input: timetableInput{
+				Deadline: at(0, 10, 0),
+				Debts:    []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms:    []models.Room{large},
+			},
+			want: []placement{{debtID: 1, reason: valueobjects.TimetableNoSlotReason}},
+		},
+		{
+			name: "debts of a teacher and exam are sat together in the smallest room that seats them",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s2"), debt(2, "t1", 1, "s1")},
+				Rooms: []models.Room{large, small},
+			},
+			want: []placement{placed(2, 1, at(0, 9, 0)), placed(1, 1, at(0, 9, 0))},
+		},
+		{
+			name: "room capacity",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1"), debt(2, "t1", 1, "s2"), debt(3, "t1", 1, "s3")},
+				Rooms: []models.Room{small},
+			},
+			want: []placement{placed(1, 1, at(0, 9, 0)), placed(2, 1, at(0, 9, 0)), placed(3, 1, at(0, 10, 30))},
+		},
+		{
+			name: "the biggest group goes first",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1"), debt(2, "t2", 2, "s2"), debt(3, "t2", 2, "s3")},
+				Rooms: []models.Room{small},
+			},
+			want: []placement{placed(2, 1, at(0, 9, 0)), placed(3, 1, at(0, 9, 0)), placed(1, 1, at(0, 10, 30))},
+		},
+		{
+			name: "one retake per student per day",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1"), debt(2, "t2", 2, "s1")},
+				Rooms: []models.Room{large},
+			},
+			want: []placement{placed(1, 2, at(0, 9, 0)), placed(2, 2, at(1, 9, 0))},
+		},
+		{
+			name: "one retake per student per day with scheduled retakes",
+			input: timetableInput{
+				Debts:     []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms:     []models.Room{large},
+				Scheduled: []models.ScheduledRetake{{DebtID: 9, TeacherUUID: "t9", StudentUUID: "s1", Date: at(0, 16, 30)}},
+			},
+			want: []placement{placed(1, 2, at(1, 9, 0))},
+		},
+		{
+			name: "sundays are skipped",
+			input: timetableInput{
+				PeriodStart: at(5, 0, 0),
+				Deadline:    at(8, 0, 0),
+				Debts:       []models.Debt{debt(1, "t1", 1, "s1"), debt(2, "t2", 2, "s1")},
+				Rooms:       []models.Room{large},
+			},
+			want: []placement{placed(1, 2, at(5, 9, 0)), placed(2, 2, at(7, 9, 0))},
+		},
+		{
+			name: "teacher overlap",
+			input: timetableInput{
+				Debts:     []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms:     []models.Room{large},
+				Scheduled: []models.ScheduledRetake{{SessionID: 9, TeacherUUID: "t1", Date: at(0, 9, 30)}},
+			},
+			want: []placement{placed(1, 2, at(0, 12, 0))},
+		},
+		{
+			name: "room overlap",
+			input: timetableInput{
+				Debts:     []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms:     []models.Room{large},
+				Scheduled: []models.ScheduledRetake{{SessionID: 9, TeacherUUID: "t9", RoomID: 2, Date: at(0, 9, 0)}},
+			},
+			want: []placement{placed(1, 2, at(0, 10, 30))},
+		},
+		{
+			name: "a busy room gives way to a larger free one",
+			input: timetableInput{
+				Debts:     []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms:     []models.Room{small, large},
+				Scheduled: []models.ScheduledRetake{{SessionID: 9, TeacherUUID: "t9", RoomID: 1, Date: at(0, 9, 0)}},
+			},
+			want: []placement{placed(1, 2, at(0, 9, 0))},
+		},
+		{
+			name: "teacher availability",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms: []models.Room{large},
+				Availability: map[string][]models.TeacherAvailability{
+					"t1": {{Kind: valueobjects.AvailabilityWeekly, Weekday: time.Monday, StartMinute: 13 * 60, EndMinute: 18 * 60}},
+				},
+			},
+			want: []placement{placed(1, 2, at(0, 13, 30))},
+		},
+		{
+			name: "teacher blackout",
+			input: timetableInput{
+				Debts: []models.Debt{debt(1, "t1", 1, "s1")},
+				Rooms: []models.Room{large},
+				Availability: map[string][]models.TeacherAvailability{
+					"t1": {{Kind: valueobjects.AvailabilityBlackout, StartsAt: at(0, 0, 0), EndsAt: at(1, 0, 0)}},
+				},
+			},
+			want: []placement{placed(1, 2, at(1, 9, 0))},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := tc.input
+			if input.PeriodStart.IsZero() {
+				input.PeriodStart = monday
+			}
+			if input.Deadline.IsZero() {
+				input.Deadline = at(7, 0, 0)
+			}
+
+			got := make([]placement, 0)
+			for _, p := range planTimetable(input) {
+				var roomID int64
+				if p.Room != nil {
+					roomID = p.Room.ID
+				}
+				got = append(got, placement{debtID: p.Debt.ID, roomID: roomID, date: p.Date, reason: p.Reason})
+			}
+			if !slices.EqualFunc(got, tc.want, func(a, b placement) bool {
+				return a.debtID == b.debtID && a.roomID == b.roomID && a.date.Equal(b.date) && a.reason == b.reason
+			}) {
+				t.Errorf("planTimetable = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
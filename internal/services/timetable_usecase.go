@@ -0,0 +1,323 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/models"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type timetableUsecase struct {
+	repo repositories.Repository
+}
+
+func NewTimetableUsecase(repo repositories.Repository) logic.TimetableUsecase {
+	return &timetableUsecase{
+		repo: repo,
+	}
+}
+
+// GenerateDraft implements logic.TimetableUsecase.
+// The draft only proposes dates, debts are not touched until it is applied.
+func (t *timetableUsecase) GenerateDraft(ctx context.Context, adminUUID string, request types.TimetableRequest) (*types.TimetableDraft, error) {
+	if !request.PeriodStart.Before(request.Deadline) {
+		return nil, log.ErrorWrapper(errors.ErrInvalidData, errors.ERR_APPLICATION, "deadline must be after the period start")
+	}
+
+	debts, err := t.repo.GetDebts(ctx, query.GetDebtsFilters{
+		ExamIDs:         request.ExamIDs,
+		GroupIDs:        request.GroupIDs,
+		TeacherUUIDs:    request.TeacherUUIDs,
+		OnlyUnscheduled: true,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(debts) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "no unscheduled debts")
+	}
+
+	rooms, err := t.repo.GetRooms(ctx, query.GetRoomsFilters{})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	var (
+		teacherUUIDs = make([]string, 0)
+		studentUUIDs = make([]string, 0, len(debts))
+		roomIDs      = make([]int64, len(rooms))
+		seen         = make(map[string]bool)
+	)
+	for _, debt := range debts {
+		if !seen[debt.Teacher.UUID] {
+			seen[debt.Teacher.UUID] = true
+			teacherUUIDs = append(teacherUUIDs, debt.Teacher.UUID)
+		}
+		studentUUIDs = append(studentUUIDs, debt.Student.UUID)
+	}
+	for i, room := range rooms {
+		roomIDs[i] = room.ID
+	}
+
+	entries, err := t.repo.GetTeacherAvailability(ctx, query.GetTeacherAvailabilityFilters{
+		TeacherUUIDs: teacherUUIDs,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	availability := make(map[string][]models.TeacherAvailability, len(teacherUUIDs))
+	for _, entry := range entries {
+		availability[entry.TeacherUUID] = append(availability[entry.TeacherUUID], entry)
+	}
+
+	// retakes that are already in the timetable keep their rooms, teachers and students busy
+	scheduled, err := t.repo.GetScheduledRetakes(ctx, query.GetScheduledRetakesFilters{
+		From:         request.PeriodStart.Add(-valueobjects.RetakeDuration),
+		To:           request.Deadline.Add(valueobjects.RetakeDuration),
+		RoomIDs:      roomIDs,
+		TeacherUUIDs: teacherUUIDs,
+		StudentUUIDs: studentUUIDs,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	placements := planTimetable(timetableInput{
+		PeriodStart:  request.PeriodStart,
+		Deadline:     request.Deadline,
+		Debts:        debts,
+		Rooms:        rooms,
+		Availability: availability,
+		Scheduled:    scheduled,
+	})
+
+	draft := commands.CreateTimetableDraft{
+		PeriodStart: request.PeriodStart,
+		Deadline:    request.Deadline,
+		CreatedBy:   adminUUID,
+		Items:       make([]commands.CreateTimetableDraftItem, len(placements)),
+	}
+	for i, placement := range placements {
+		draft.Items[i] = commands.CreateTimetableDraftItem{
+			DebtID: placement.Debt.ID,
+			Reason: placement.Reason,
+		}
+		if placement.Room != nil {
+			draft.Items[i].RoomID = placement.Room.ID
+			draft.Items[i].Date = placement.Date
+		}
+	}
+
+	id, err := t.repo.CreateTimetableDraft(ctx, draft)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	return t.GetDraft(ctx, id)
+}
+
+// GetDrafts implements logic.TimetableUsecase.
+func (t *timetableUsecase) GetDrafts(ctx context.Context, statuses []string) ([]types.TimetableDraft, error) {
+	drafts, err := t.repo.GetTimetableDrafts(ctx, query.GetTimetableDraftsFilters{
+		Statuses: statuses,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	result := make([]types.TimetableDraft, len(drafts))
+	for i, draft := range drafts {
+		result[i] = types.TimetableDraftFromDomain(&draft)
+	}
+
+	return result, nil
+}
+
+// GetDraft implements logic.TimetableUsecase.
+func (t *timetableUsecase) GetDraft(ctx context.Context, id int64) (*types.TimetableDraft, error) {
+	draft, err := t.getDraft(ctx, id)
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := types.TimetableDraftFromDomain(draft)
+
+	return &result, nil
+}
+
+// ApplyDraft implements logic.TimetableUsecase.
+// All debts of the draft are scheduled in one transaction. The draft is
+// rejected as a whole when any of its debts was scheduled meanwhile or when
+// the timetable changed so that its retakes now clash with others. The
+// schedule is locked from the check until the debts are written.
+func (t *timetableUsecase) ApplyDraft(ctx context.Context, id int64) ([]types.ScheduleConflict, error) {
+	var (
+		placed    []models.TimetableDraftItem
+		conflicts []types.ScheduleConflict
+	)
+	err := t.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := t.repo.LockSchedule(ctx); err != nil {
+			return err
+		}
+		if err := t.repo.LockTimetableDraft(ctx, id); err != nil {
+			return err
+		}
+
+		draft, err := t.getDraft(ctx, id)
+		if err != nil {
+			return err
+		}
+		if draft.Status != valueobjects.TimetableDraftStatus {
+			return log.ErrorWrapper(errors.ErrTimetableDraftClosed, errors.ERR_APPLICATION, "", "status", draft.Status)
+		}
+
+		// debts sitting one retake are checked together, like SetDate does
+		slots := make(map[string]*retakeSlot)
+		keys := make([]string, 0)
+		for _, item := range draft.Items {
+			if item.Date == nil {
+				continue
+			}
+			if item.Debt.Date != nil {
+				return log.ErrorWrapper(errors.ErrTimetableDraftOutdated, errors.ERR_APPLICATION, "", "debt", item.Debt.ID)
+			}
+			placed = append(placed, item)
+
+			key := fmt.Sprintf("%s|%d|%d|%s", item.Debt.Teacher.UUID, item.Debt.Exam.ID, item.Room.ID, item.Date)
+			if slots[key] == nil {
+				slots[key] = &retakeSlot{
+					ExamID:      item.Debt.Exam.ID,
+					TeacherUUID: item.Debt.Teacher.UUID,
+					Date:        *item.Date,
+					Room:        item.Room,
+				}
+				keys = append(keys, key)
+			}
+			slots[key].StudentUUIDs = append(slots[key].StudentUUIDs, item.Debt.Student.UUID)
+			slots[key].DebtIDs = append(slots[key].DebtIDs, item.Debt.ID)
+		}
+
+		for _, key := range keys {
+			found, err := findScheduleConflicts(ctx, t.repo, *slots[key])
+			if err != nil {
+				return err
+			}
+			conflicts = append(conflicts, found...)
+		}
+		if len(conflicts) > 0 {
+			return log.ErrorWrapper(errors.ErrScheduleConflict, errors.ERR_APPLICATION, "", "conflicts", len(conflicts))
+		}
+
+		debtIDs := make([]int64, len(placed))
+		for i, item := range placed {
+			if err := t.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+				DebtID:      item.Debt.ID,
+				Date:        *item.Date,
+				Address:     roomAddress(item.Room),
+				RoomID:      item.Room.ID,
+				TeacherUUID: item.Debt.Teacher.UUID,
+				StudentUUID: item.Debt.Student.UUID,
+			}); err != nil {
+				return err
+			}
+			debtIDs[i] = item.Debt.ID
+		}
+
+		if len(debtIDs) > 0 {
+			if err := t.repo.UpdateRetakeRequestStatus(ctx, commands.UpdateRetakeRequestStatus{
+				DebtIDs: debtIDs,
+				Status:  valueobjects.RetakeRequestScheduled,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return t.repo.UpdateTimetableDraftStatus(ctx, commands.UpdateTimetableDraftStatus{
+			ID:     id,
+			Status: valueobjects.TimetableAppliedStatus,
+		})
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return conflicts, err
+	}
+
+	// the timetable is already applied, a failed email must not undo it
+	for _, item := range placed {
+		if err := t.repo.NotifyNewDateAndPlace(
+			ctx,
+			item.Debt.Student.Email,
+			item.Debt.Exam.Name,
+			item.Date.Format(valueobjects.DateLayout),
+			roomAddress(item.Room),
+		); err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		}
+	}
+
+	return nil, nil
+}
+
+// DiscardDraft implements logic.TimetableUsecase.
+func (t *timetableUsecase) DiscardDraft(ctx context.Context, id int64) error {
+	err := t.repo.PerformTransaction(ctx, func(ctx context.Context) error {
+		if err := t.repo.LockTimetableDraft(ctx, id); err != nil {
+			return err
+		}
+
+		drafts, err := t.repo.GetTimetableDrafts(ctx, query.GetTimetableDraftsFilters{IDs: []int64{id}})
+		if err != nil {
+			return err
+		}
+		if len(drafts) == 0 {
+			return log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+		}
+		if drafts[0].Status != valueobjects.TimetableDraftStatus {
+			return log.ErrorWrapper(errors.ErrTimetableDraftClosed, errors.ERR_APPLICATION, "", "status", drafts[0].Status)
+		}
+
+		return t.repo.UpdateTimetableDraftStatus(ctx, commands.UpdateTimetableDraftStatus{
+			ID:     id,
+			Status: valueobjects.TimetableDiscardedStatus,
+		})
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+func (t *timetableUsecase) getDraft(ctx context.Context, id int64) (*models.TimetableDraft, error) {
+	drafts, err := t.repo.GetTimetableDrafts(ctx, query.GetTimetableDraftsFilters{IDs: []int64{id}})
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+	if len(drafts) == 0 {
+		return nil, log.ErrorWrapper(errors.ErroNoItemsFound, errors.ERR_APPLICATION, "")
+	}
+	draft := drafts[0]
+
+	draft.Items, err = t.repo.GetTimetableDraftItems(ctx, id)
+	if err != nil {
+		return nil, log.ErrorWrapper(err, errors.ERR_INFRASTRUCTURE, "")
+	}
+
+	return &draft, nil
+}
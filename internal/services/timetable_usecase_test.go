@@ -0,0 +1,166 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+// newDraft adds a room and a second debtor to the fixture and generates a
+// draft of a week, with both debts sat together at its first retake.
+func newDraft(t *testing.T, f *fixture, usecase *timetableUsecase) (*types.TimetableDraft, int64) {
+	t.Helper()
+
+	ctx := context.Background()
+	if _, err := f.repo.CreateRoom(ctx, commands.CreateRoom{Building: "Корпус 1", Number: "101", Capacity: 10}); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	_, otherDebt := f.addDebtor(t, "ivanova@example.com")
+
+	monday := time.Date(2030, time.June, 3, 0, 0, 0, 0, time.UTC)
+	draft, err := usecase.GenerateDraft(ctx, adminUUID, types.TimetableRequest{
+		PeriodStart: monday,
+		Deadline:    monday.AddDate(0, 0, 7),
+	})
+	if err != nil {
+		t.Fatalf("GenerateDraft: %v", err)
+	}
+	if draft.Status != valueobjects.TimetableDraftStatus || len(draft.Items) != 2 {
+		t.Fatalf("GenerateDraft = %+v, want a draft of the 2 debts", draft)
+	}
+	for _, item := range draft.Items {
+		if item.Date == nil || !item.Date.Equal(monday.Add(9*time.Hour)) || item.Room == nil {
+			t.Fatalf("draft item = %+v, want the debt placed at 9:00 on monday", item)
+		}
+	}
+
+	return draft, otherDebt
+}
+
+func scheduledDebts(t *testing.T, f *fixture, debtIDs ...int64) map[int64]*time.Time {
+	t.Helper()
+
+	debts, err := f.repo.GetDebts(context.Background(), query.GetDebtsFilters{DebtIDs: debtIDs})
+	if err != nil {
+		t.Fatalf("GetDebts: %v", err)
+	}
+	dates := make(map[int64]*time.Time, len(debts))
+	for _, debt := range debts {
+		dates[debt.ID] = debt.Date
+	}
+	return dates
+}
+
+func TestTimetableUsecaseApplyDraft(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTimetableUsecase(f.repo).(*timetableUsecase)
+	draft, otherDebt := newDraft(t, f, usecase)
+
+	// the draft only proposes the dates
+	for id, date := range scheduledDebts(t, f, f.debtID, otherDebt) {
+		if date != nil {
+			t.Errorf("debt %d date = %v before the draft is applied, want none", id, date)
+		}
+	}
+
+	if conflicts, err := usecase.ApplyDraft(ctx, draft.ID); err != nil || len(conflicts) != 0 {
+		t.Fatalf("ApplyDraft = %+v, %v", conflicts, err)
+	}
+	for id, date := range scheduledDebts(t, f, f.debtID, otherDebt) {
+		if date == nil || !date.Equal(*draft.Items[0].Date) {
+			t.Errorf("debt %d date = %v, want %v", id, date, *draft.Items[0].Date)
+		}
+	}
+	if applied, err := usecase.GetDraft(ctx, draft.ID); err != nil || applied.Status != valueobjects.TimetableAppliedStatus {
+		t.Errorf("GetDraft = %+v, %v, want the draft applied", applied, err)
+	}
+	if mails := f.mailer.Mails(); len(mails) != 2 {
+		t.Errorf("mails = %+v, want both students notified", mails)
+	}
+
+	if _, err := usecase.ApplyDraft(ctx, draft.ID); !e.Is(err, errors.ErrTimetableDraftClosed) {
+		t.Errorf("ApplyDraft twice: err = %v, want ErrTimetableDraftClosed", err)
+	}
+	if _, err := usecase.GenerateDraft(ctx, adminUUID, types.TimetableRequest{
+		PeriodStart: draft.PeriodStart,
+		Deadline:    draft.Deadline,
+	}); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("GenerateDraft with every debt scheduled: err = %v, want ErroNoItemsFound", err)
+	}
+}
+
+func TestTimetableUsecaseApplyChangedDraft(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name string
+		// change changes the schedule after the draft was generated
+		change    func(t *testing.T, f *fixture, draft *types.TimetableDraft)
+		want      error
+		conflicts bool
+	}{
+		{
+			name: "debt scheduled meanwhile",
+			change: func(t *testing.T, f *fixture, draft *types.TimetableDraft) {
+				if err := f.repo.UpdateDebt(ctx, commands.UpdateDebtByID{
+					DebtID:      f.debtID,
+					Date:        draft.Deadline,
+					Address:     "ауд. 202",
+					TeacherUUID: f.teacher,
+					StudentUUID: f.student,
+				}); err != nil {
+					t.Fatalf("UpdateDebt: %v", err)
+				}
+			},
+			want: errors.ErrTimetableDraftOutdated,
+		},
+		{
+			name: "teacher got another retake at the time",
+			change: func(t *testing.T, f *fixture, draft *types.TimetableDraft) {
+				examID, err := f.repo.CreateExam(ctx, commands.CreateExam{Name: "Сети", AssessmentType: valueobjects.AssessmentExam})
+				if err != nil {
+					t.Fatalf("create exam: %v", err)
+				}
+				f.addSession(t, examID, 5, *draft.Items[0].Date, time.Hour, time.Hour)
+			},
+			want:      errors.ErrScheduleConflict,
+			conflicts: true,
+		},
+		{
+			name: "draft discarded",
+			change: func(t *testing.T, f *fixture, draft *types.TimetableDraft) {
+				if err := NewTimetableUsecase(f.repo).DiscardDraft(ctx, draft.ID); err != nil {
+					t.Fatalf("DiscardDraft: %v", err)
+				}
+			},
+			want: errors.ErrTimetableDraftClosed,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			usecase := NewTimetableUsecase(f.repo).(*timetableUsecase)
+			draft, otherDebt := newDraft(t, f, usecase)
+			tc.change(t, f, draft)
+
+			conflicts, err := usecase.ApplyDraft(ctx, draft.ID)
+			if !e.Is(err, tc.want) || (len(conflicts) > 0) != tc.conflicts {
+				t.Fatalf("ApplyDraft = %+v, %v, want %v", conflicts, err, tc.want)
+			}
+			// the draft is rejected as a whole
+			if dates := scheduledDebts(t, f, otherDebt); dates[otherDebt] != nil {
+				t.Errorf("debt %d date = %v, want none", otherDebt, dates[otherDebt])
+			}
+			if len(f.mailer.Mails()) != 0 {
+				t.Errorf("mails = %+v, want none", f.mailer.Mails())
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	query "github.com/VanLavr/Diploma-fin/internal/domain/queries"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/logic"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+	"github.com/VanLavr/Diploma-fin/utils/log"
+)
+
+type trashUsecase struct {
+	repo repositories.Repository
+}
+
+func NewTrashUsecase(repo repositories.Repository) logic.TrashUsecase {
+	return &trashUsecase{
+		repo: repo,
+	}
+}
+
+// GetTrash implements logic.TrashUsecase.
+func (t *trashUsecase) GetTrash(ctx context.Context, filters types.TrashFilters) ([]types.TrashItem, error) {
+	limit := filters.Limit
+	switch {
+	case limit < 0:
+		return nil, log.ErrorWrapper(errors.ErrInvalidFilters, errors.ERR_APPLICATION, "negative limit")
+	case limit == 0:
+		limit = defaultListLimit
+	case limit > maxListLimit:
+		limit = maxListLimit
+	}
+
+	items, err := t.repo.GetTrash(ctx, query.GetTrashFilters{
+		Kinds:  filters.Kinds,
+		Limit:  limit,
+		Offset: filters.Offset,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return nil, err
+	}
+
+	result := make([]types.TrashItem, len(items))
+	for i, item := range items {
+		result[i] = types.TrashItemFromDomain(&item)
+	}
+
+	return result, nil
+}
+
+// RestoreDeleted implements logic.TrashUsecase.
+func (t *trashUsecase) RestoreDeleted(ctx context.Context, kind, id string) error {
+	if err := t.repo.RestoreDeleted(ctx, commands.RestoreDeleted{Kind: kind, ID: id}); err != nil {
+		log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+		return err
+	}
+
+	return nil
+}
+
+// PurgeTrash implements logic.TrashUsecase. People go first so that the
+// groups they were in can go in the same run.
+func (t *trashUsecase) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for _, kind := range valueobjects.TrashKinds {
+		n, err := t.repo.PurgeDeleted(ctx, commands.PurgeDeleted{Kind: kind, Before: before})
+		if err != nil {
+			log.Logger.Error(err.Error(), errors.MethodKey, log.GetMethodName())
+			return purged, err
+		}
+		if n != 0 {
+			log.Logger.Info("purged the trash", "kind", kind, "count", n)
+		}
+		purged += n
+	}
+
+	return purged, nil
+}
+
+// RunTrashPurge implements logic.TrashUsecase. Zero retention is
+// valueobjects.DefaultTrashRetention, a negative one keeps the trash forever.
+func (t *trashUsecase) RunTrashPurge(ctx context.Context, retention time.Duration) {
+	if retention < 0 {
+		return
+	}
+	if retention == 0 {
+		retention = valueobjects.DefaultTrashRetention
+	}
+
+	ticker := time.NewTicker(valueobjects.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		// errors are logged, the next tick tries again
+		t.PurgeTrash(ctx, time.Now().Add(-retention))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
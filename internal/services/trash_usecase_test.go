@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	e "errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/services/types"
+	"github.com/VanLavr/Diploma-fin/utils/errors"
+)
+
+func TestTrashUsecaseRestoreDeleted(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	usecase := NewTrashUsecase(f.repo)
+
+	if err := f.repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: f.student}); err != nil {
+		t.Fatalf("delete student: %v", err)
+	}
+
+	items, err := usecase.GetTrash(ctx, types.TrashFilters{})
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(items) != 1 || items[0].Kind != valueobjects.TrashStudentKind || items[0].ID != f.student {
+		t.Fatalf("GetTrash = %+v, want the student", items)
+	}
+	if _, err := usecase.GetTrash(ctx, types.TrashFilters{Kinds: []string{"debt"}}); !e.Is(err, errors.ErrInvalidFilters) {
+		t.Errorf("GetTrash of an unknown kind: err = %v, want ErrInvalidFilters", err)
+	}
+
+	if err := usecase.RestoreDeleted(ctx, valueobjects.TrashStudentKind, f.student); err != nil {
+		t.Fatalf("RestoreDeleted: %v", err)
+	}
+	if err := usecase.RestoreDeleted(ctx, valueobjects.TrashStudentKind, f.student); !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("RestoreDeleted twice: err = %v, want ErroNoItemsFound", err)
+	}
+	if _, err := f.repo.GetStudentByUUID(ctx, f.student); err != nil {
+		t.Errorf("restored student: %v", err)
+	}
+}
+
+func TestTrashUsecasePurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepository()
+	usecase := NewTrashUsecase(repo)
+
+	groupID, err := repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-41"})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	student, err := repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Иван",
+		LastName:  "Петров",
+		Email:     "petrov@example.com",
+		GroupID:   groupID,
+	})
+	if err != nil {
+		t.Fatalf("create student: %v", err)
+	}
+	if err := repo.DeleteStudent(ctx, commands.DeleteStudent{UUID: student}); err != nil {
+		t.Fatalf("delete student: %v", err)
+	}
+	if err := repo.DeleteGroup(ctx, commands.DeleteGroup{ID: groupID}); err != nil {
+		t.Fatalf("delete group: %v", err)
+	}
+
+	if purged, err := usecase.PurgeTrash(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("PurgeTrash before the deletes = %d, %v, want 0", purged, err)
+	}
+	// the student goes first and lets the group go in the same run
+	purged, err := usecase.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("PurgeTrash = %d, want 2", purged)
+	}
+
+	err = usecase.RestoreDeleted(ctx, valueobjects.TrashGroupKind, strconv.FormatInt(groupID, 10))
+	if !e.Is(err, errors.ErroNoItemsFound) {
+		t.Errorf("RestoreDeleted of a purged group: err = %v, want ErroNoItemsFound", err)
+	}
+}
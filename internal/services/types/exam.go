@@ -3,16 +3,78 @@ package types
 import "time"
 
 type Exam struct {
-	ID   int64
-	Name string
+	ID             int64
+	Name           string
+	AssessmentType string
+	Version        int64
 }
 
 type Debt struct {
-	ID      int64
-	Address string
-	Date    *time.Time
-	Exam    *Exam
-	Student *Student
-	Teacher *Teacher
-	Groups  []Group
+	ID       int64
+	Address  string
+	Date     *time.Time
+	ClosedAt *time.Time
+	Room     *Room
+	Exam     *Exam
+	Student  *Student
+	Teacher  *Teacher
+	Groups   []Group
+	Version  int64
+}
+
+// SetDateRequest schedules the debts of an exam. Empty GroupIDs and
+// StudentUUIDs select every debt of the teacher for the exam. Conflicts with
+// other retakes reject the request unless Force is set.
+type SetDateRequest struct {
+	ExamID       int64
+	Date         string
+	Address      string
+	RoomID       int64
+	Force        bool
+	GroupIDs     []int64
+	StudentUUIDs []string
+}
+
+// SetDateResult describes what happened to a single debt during SetDate.
+type SetDateResult struct {
+	DebtID      int64
+	StudentUUID string
+	Scheduled   bool
+	Notified    bool
+	Err         error
+}
+
+// SetDateReport Conflicts are warnings when the request was forced.
+type SetDateReport struct {
+	Results   []SetDateResult
+	Conflicts []ScheduleConflict
+}
+
+// DebtResultEntry is a result typed in by a teacher. Grade is required for
+// graded assessments and decides whether the student passed, Passed is only
+// read for pass/fail ones.
+type DebtResultEntry struct {
+	DebtID int64
+	Passed bool
+	Grade  int64
+}
+
+type DebtResult struct {
+	ID          int64
+	DebtID      int64
+	SessionID   int64
+	TeacherUUID string
+	Passed      bool
+	Grade       int64
+	AttemptedAt time.Time
+	RecordedAt  time.Time
+}
+
+// DebtResultReport describes what happened to a single entry of a bulk entry.
+type DebtResultReport struct {
+	DebtID      int64
+	StudentUUID string
+	Recorded    bool
+	Closed      bool
+	Err         error
 }
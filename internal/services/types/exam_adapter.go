@@ -7,15 +7,18 @@ import (
 
 func ExamFromDomain(src *entities.Exam) Exam {
 	return Exam{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:             src.ID,
+		Name:           src.Name,
+		AssessmentType: src.AssessmentType,
+		Version:        src.Version,
 	}
 }
 
 func GroupFromDomain(src *entities.Group) Group {
 	return Group{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:      src.ID,
+		Name:    src.Name,
+		Version: src.Version,
 	}
 }
 
@@ -31,6 +34,7 @@ func DebtFromDomain(src *entities.Debt) Debt {
 	if src.Exam != nil {
 		ex.ID = src.Exam.ID
 		ex.Name = src.Exam.Name
+		ex.AssessmentType = src.Exam.AssessmentType
 	}
 	if src.Teacher != nil {
 		teacher.UUID = src.Teacher.UUID
@@ -58,14 +62,23 @@ func DebtFromDomain(src *entities.Debt) Debt {
 		})
 	}
 
+	var room *Room
+	if src.Room != nil {
+		converted := RoomFromDomain(src.Room)
+		room = &converted
+	}
+
 	return Debt{
-		ID:      src.ID,
-		Address: src.Address,
-		Date:    src.Date,
-		Exam:    &ex,
-		Teacher: &teacher,
-		Student: &student,
-		Groups:  groupList,
+		ID:       src.ID,
+		Address:  src.Address,
+		Date:     src.Date,
+		ClosedAt: src.ClosedAt,
+		Room:     room,
+		Exam:     &ex,
+		Teacher:  &teacher,
+		Student:  &student,
+		Groups:   groupList,
+		Version:  src.Version,
 	}
 }
 
@@ -77,6 +90,7 @@ func TeacherFromDomain(src *entities.Teacher) Teacher {
 		MiddleName: src.MiddleName,
 		Email:      src.Email,
 		Password:   src.Password,
+		Version:    src.Version,
 	}
 }
 func StudentFromDomain(src *entities.Student) Student {
@@ -93,6 +107,7 @@ func StudentFromDomain(src *entities.Student) Student {
 		MiddleName: src.MiddleName,
 		Email:      src.Email,
 		Password:   src.Password,
+		Version:    src.Version,
 		Group: &Group{
 			ID:   groupID,
 			Name: groupName,
@@ -135,7 +150,270 @@ func DomainFromTeacher(src models.Teacher) Teacher {
 
 func DomainFromExam(src models.Exam) Exam {
 	return Exam{
-		ID:   src.ID,
-		Name: src.Name,
+		ID:             src.ID,
+		Name:           src.Name,
+		AssessmentType: src.AssessmentType,
+	}
+}
+
+func RetakeRequestFromDomain(src *entities.RetakeRequest) RetakeRequest {
+	debt := Debt{}
+	if src.Debt != nil {
+		debt = DebtFromDomain(src.Debt)
+	}
+
+	slots := make([]TimeSlot, len(src.PreferredSlots))
+	for i, slot := range src.PreferredSlots {
+		slots[i] = TimeSlot{
+			Start: slot.Start,
+			End:   slot.End,
+		}
+	}
+
+	return RetakeRequest{
+		ID:             src.ID,
+		Status:         src.Status,
+		Debt:           &debt,
+		PreferredSlots: slots,
+		CreatedAt:      src.CreatedAt,
+		UpdatedAt:      src.UpdatedAt,
+	}
+}
+
+func RetakeSessionFromDomain(src *entities.RetakeSession) RetakeSession {
+	var (
+		ex      = Exam{}
+		teacher = Teacher{}
+	)
+	if src.Exam != nil {
+		ex = ExamFromDomain(src.Exam)
+	}
+	if src.Teacher != nil {
+		teacher = DomainFromTeacher(*src.Teacher)
+	}
+	var room *Room
+	if src.Room != nil {
+		converted := RoomFromDomain(src.Room)
+		room = &converted
+	}
+
+	return RetakeSession{
+		ID:              src.ID,
+		Exam:            &ex,
+		Teacher:         &teacher,
+		Date:            src.Date,
+		Address:         src.Address,
+		Room:            room,
+		Capacity:        src.Capacity,
+		SignupDeadline:  src.SignupDeadline,
+		CancelDeadline:  src.CancelDeadline,
+		BookedCount:     src.BookedCount,
+		WaitlistedCount: src.WaitlistedCount,
+	}
+}
+
+func RetakeBookingFromDomain(src *entities.RetakeBooking) RetakeBooking {
+	debt := Debt{}
+	if src.Debt != nil {
+		debt = DebtFromDomain(src.Debt)
+	}
+
+	return RetakeBooking{
+		ID:        src.ID,
+		SessionID: src.SessionID,
+		Status:    src.Status,
+		Debt:      &debt,
+		CreatedAt: src.CreatedAt,
+	}
+}
+
+func RoomFromDomain(src *entities.Room) Room {
+	return Room{
+		ID:         src.ID,
+		Building:   src.Building,
+		Number:     src.Number,
+		Capacity:   src.Capacity,
+		Accessible: src.Accessible,
+	}
+}
+
+func TeacherAvailabilityFromDomain(src *entities.TeacherAvailability) TeacherAvailability {
+	return TeacherAvailability{
+		ID:          src.ID,
+		Kind:        src.Kind,
+		Weekday:     src.Weekday,
+		StartMinute: src.StartMinute,
+		EndMinute:   src.EndMinute,
+		StartsAt:    src.StartsAt,
+		EndsAt:      src.EndsAt,
+		Note:        src.Note,
+	}
+}
+
+func TimetableDraftFromDomain(src *entities.TimetableDraft) TimetableDraft {
+	items := make([]TimetableDraftItem, len(src.Items))
+	for i, item := range src.Items {
+		items[i] = TimetableDraftItemFromDomain(&item)
+	}
+
+	return TimetableDraft{
+		ID:          src.ID,
+		Status:      src.Status,
+		PeriodStart: src.PeriodStart,
+		Deadline:    src.Deadline,
+		CreatedBy:   src.CreatedBy,
+		CreatedAt:   src.CreatedAt,
+		AppliedAt:   src.AppliedAt,
+		Items:       items,
+	}
+}
+
+func TimetableDraftItemFromDomain(src *entities.TimetableDraftItem) TimetableDraftItem {
+	debt := Debt{}
+	if src.Debt != nil {
+		debt = DebtFromDomain(src.Debt)
+	}
+	var room *Room
+	if src.Room != nil {
+		converted := RoomFromDomain(src.Room)
+		room = &converted
+	}
+
+	return TimetableDraftItem{
+		ID:     src.ID,
+		Debt:   &debt,
+		Room:   room,
+		Date:   src.Date,
+		Reason: src.Reason,
+	}
+}
+
+func DebtResultFromDomain(src *entities.DebtResult) DebtResult {
+	return DebtResult{
+		ID:          src.ID,
+		DebtID:      src.DebtID,
+		SessionID:   src.SessionID,
+		TeacherUUID: src.TeacherUUID,
+		Passed:      src.Passed,
+		Grade:       src.Grade,
+		AttemptedAt: src.AttemptedAt,
+		RecordedAt:  src.RecordedAt,
+	}
+}
+
+func ImportRecordFromDomain(src *entities.ImportRecord) ImportRecord {
+	return ImportRecord{
+		Sheet:             src.Sheet,
+		Row:               src.Row,
+		Column:            src.Column,
+		GroupName:         src.GroupName,
+		StudentLastName:   src.StudentLastName,
+		StudentFirstName:  src.StudentFirstName,
+		StudentMiddleName: src.StudentMiddleName,
+		StudentEmail:      src.StudentEmail,
+		TeacherLastName:   src.TeacherLastName,
+		TeacherFirstName:  src.TeacherFirstName,
+		TeacherMiddleName: src.TeacherMiddleName,
+		TeacherEmail:      src.TeacherEmail,
+		ExamName:          src.ExamName,
+	}
+}
+
+func ImportIssueFromDomain(src *entities.ImportIssue) ImportIssue {
+	return ImportIssue{
+		Sheet:   src.Sheet,
+		Row:     src.Row,
+		Column:  src.Column,
+		Value:   src.Value,
+		Message: src.Message,
+	}
+}
+
+func ImportProfileFromDomain(src *entities.ImportProfile) ImportProfile {
+	return ImportProfile{
+		ID:            src.ID,
+		Name:          src.Name,
+		Layout:        src.Layout,
+		Sheets:        src.Sheets,
+		HeaderRow:     src.HeaderRow,
+		Separator:     src.Separator,
+		StudentFields: src.StudentFields,
+		TeacherFields: src.TeacherFields,
+		Columns:       src.Columns,
+		CreatedAt:     src.CreatedAt,
+	}
+}
+
+func ImportJobFromDomain(src *entities.ImportJob) ImportJob {
+	issues := make([]ImportIssue, len(src.Issues))
+	for i, issue := range src.Issues {
+		issues[i] = ImportIssueFromDomain(&issue)
+	}
+	jobErrors := make([]ImportIssue, len(src.Errors))
+	for i, issue := range src.Errors {
+		jobErrors[i] = ImportIssueFromDomain(&issue)
+	}
+
+	return ImportJob{
+		ID:           src.ID,
+		Source:       src.Source,
+		Status:       src.Status,
+		Actor:        src.Actor,
+		FileName:     src.FileName,
+		Checksum:     src.Checksum,
+		ProfileID:    src.ProfileID,
+		PreviewToken: src.PreviewToken,
+		Issues:       issues,
+		Errors:       jobErrors,
+		Total:        src.Total,
+		Processed:    src.Processed,
+		Created:      src.Created,
+		Existing:     src.Existing,
+		Failed:       src.Failed,
+		Attempts:     src.Attempts,
+		CreatedAt:    src.CreatedAt,
+		UpdatedAt:    src.UpdatedAt,
+		StartedAt:    src.StartedAt,
+		FinishedAt:   src.FinishedAt,
+		RolledBackAt: src.RolledBackAt,
+	}
+}
+
+func SearchHitFromDomain(src *entities.SearchHit) SearchHit {
+	return SearchHit{
+		Kind:     src.Kind,
+		ID:       src.ID,
+		Title:    src.Title,
+		Subtitle: src.Subtitle,
+		Rank:     src.Rank,
+	}
+}
+
+func TrashItemFromDomain(src *entities.TrashItem) TrashItem {
+	return TrashItem{
+		Kind:      src.Kind,
+		ID:        src.ID,
+		Title:     src.Title,
+		DeletedAt: src.DeletedAt,
+	}
+}
+
+func StudentStatusFromDomain(src *entities.StudentStatus) StudentStatus {
+	return StudentStatus{
+		UUID:         src.UUID,
+		Status:       src.Status,
+		LeftAt:       src.LeftAt,
+		AnonymizedAt: src.AnonymizedAt,
+		DeletedAt:    src.DeletedAt,
+	}
+}
+
+func PersonalDataAuditFromDomain(src *entities.PersonalDataAudit) PersonalDataAudit {
+	return PersonalDataAudit{
+		ID:          src.ID,
+		StudentUUID: src.StudentUUID,
+		Action:      src.Action,
+		ActorUUID:   src.ActorUUID,
+		CreatedAt:   src.CreatedAt,
 	}
 }
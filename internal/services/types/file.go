@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+// Layouts of the debts export. The matrix layout is the one the default
+// import profile reads: students in the first row, teachers in the first
+// column and exam names in the cells, so an exported workbook can be
+// imported back.
+const (
+	ExportListLayout   string = "list"
+	ExportMatrixLayout string = "matrix"
+)
+
+// DebtFilters mirror query.GetDebtsFilters. Limit and Offset page exports,
+// listings are paged by ListParams.
+type DebtFilters struct {
+	StudentUUIDs    []string
+	TeacherUUIDs    []string
+	ExamIDs         []int64
+	DebtIDs         []int64
+	GroupIDs        []int64
+	OnlyUnscheduled bool
+	IncludeClosed   bool
+	ScheduledFrom   time.Time
+	ScheduledTo     time.Time
+	Limit           int64
+	Offset          int64
+}
@@ -0,0 +1,158 @@
+package types
+
+import "time"
+
+// ImportRecord is a debt read from an imported file, Row and Column point
+// at its cell.
+type ImportRecord struct {
+	Sheet             string
+	Row               int64
+	Column            int64
+	GroupName         string
+	StudentLastName   string
+	StudentFirstName  string
+	StudentMiddleName string
+	StudentEmail      string
+	TeacherLastName   string
+	TeacherFirstName  string
+	TeacherMiddleName string
+	TeacherEmail      string
+	ExamName          string
+}
+
+type ImportIssue struct {
+	Sheet   string
+	Row     int64
+	Column  int64
+	Value   string
+	Message string
+}
+
+// ImportDiff splits the names (emails for people) found in a file into the
+// ones the import creates and the ones already stored.
+type ImportDiff struct {
+	New      []string
+	Existing []string
+}
+
+// ImportReport is the result of a dry-run import. Records with issues are
+// left out of it and are not imported on confirmation.
+type ImportReport struct {
+	Token         string
+	CreatedBy     string
+	ProfileID     *int64
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	ConfirmedAt   *time.Time
+	Groups        ImportDiff
+	Students      ImportDiff
+	Teachers      ImportDiff
+	Exams         ImportDiff
+	NewDebts      []ImportRecord
+	ExistingDebts []ImportRecord
+	Issues        []ImportIssue
+}
+
+// ImportUpload describes an uploaded file, Checksum is the sha256 of it.
+type ImportUpload struct {
+	Actor     string
+	ProfileID int64
+	FileName  string
+	Checksum  string
+}
+
+// ImportJob is a background import, see models.ImportJob. The records are
+// not loaded.
+type ImportJob struct {
+	ID           int64
+	Source       string
+	Status       string
+	Actor        string
+	FileName     string
+	Checksum     string
+	ProfileID    *int64
+	PreviewToken *string
+	Issues       []ImportIssue
+	Errors       []ImportIssue
+	Total        int64
+	Processed    int64
+	Created      int64
+	Existing     int64
+	Failed       int64
+	Attempts     int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	RolledBackAt *time.Time
+}
+
+type ImportJobFilters struct {
+	Statuses []string
+	Limit    int64
+	Offset   int64
+}
+
+// ImportProfile tells the importer how to read a workbook, see
+// models.ImportProfile.
+type ImportProfile struct {
+	ID            int64
+	Name          string
+	Layout        string
+	Sheets        []string
+	HeaderRow     int64
+	Separator     string
+	StudentFields []string
+	TeacherFields []string
+	Columns       map[string]string
+	CreatedAt     time.Time
+}
+
+// BulkImport is a batch of the CSV and JSON imports. Debts refer to the
+// exam by name and to the student and the teacher by email, people of the
+// same batch are created before the debts.
+type BulkImport struct {
+	Actor    string
+	Groups   []Group
+	Teachers []Teacher
+	Students []Student
+	Debts    []Debt
+}
+
+// BulkRecordResult ID is set for groups and debts, UUID for people.
+type BulkRecordResult struct {
+	ID      int64
+	UUID    string
+	Created bool
+	Err     error
+}
+
+// BulkImportResult holds a result per record in the order of the batch.
+// JobID is the import job that can roll the batch back.
+type BulkImportResult struct {
+	JobID    int64
+	Created  int
+	Existing int
+	Failed   int
+	Groups   []BulkRecordResult
+	Teachers []BulkRecordResult
+	Students []BulkRecordResult
+	Debts    []BulkRecordResult
+}
+
+// ImportRollback is the report of RollbackImportJob. Kept are the entities
+// of the job still in place, a later rollback tries them again.
+type ImportRollback struct {
+	JobID   int64
+	Deleted int64
+	Kept    []ImportRollbackItem
+}
+
+// ImportRollbackItem is an entity the rollback could not delete, with the
+// tables referencing it or the error deleting it.
+type ImportRollbackItem struct {
+	Entity     string
+	EntityID   string
+	References []string
+	Message    string
+}
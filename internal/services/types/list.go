@@ -0,0 +1,43 @@
+package types
+
+// ListParams page a listing. Sort is a comma separated list of fields, a
+// leading minus sorts a field descending: "name,-date". Cursor is the
+// NextCursor of the previous page and only goes with the sort it was made
+// for. Zero Limit is the default page size.
+type ListParams struct {
+	Sort   string
+	Cursor string
+	Limit  int64
+}
+
+// Page is a page of a listing. NextCursor is empty on the last page, Total
+// counts the items of every page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      int64
+}
+
+type ExamFilters struct {
+	IDs             []int64
+	Names           []string
+	AssessmentTypes []string
+}
+
+type GroupFilters struct {
+	IDs   []int64
+	Names []string
+}
+
+type StudentFilters struct {
+	UUIDs     []string
+	Emails    []string
+	GroupIDs  []int64
+	LastNames []string
+}
+
+type TeacherFilters struct {
+	UUIDs     []string
+	Emails    []string
+	LastNames []string
+}
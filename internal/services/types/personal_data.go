@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+type StudentStatus struct {
+	UUID         string
+	Status       string
+	LeftAt       *time.Time
+	AnonymizedAt *time.Time
+	DeletedAt    *time.Time
+}
+
+type PersonalDataAudit struct {
+	ID          int64
+	StudentUUID string
+	Action      string
+	ActorUUID   string
+	CreatedAt   time.Time
+}
+
+// PersonalData is everything kept about a student, the closed debts and
+// the audit of its personal data included.
+type PersonalData struct {
+	Student        Student
+	Status         StudentStatus
+	Debts          []Debt
+	Results        []DebtResult
+	RetakeRequests []RetakeRequest
+	RetakeBookings []RetakeBooking
+	Audit          []PersonalDataAudit
+}
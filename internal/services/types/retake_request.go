@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+type RetakeRequest struct {
+	ID             int64
+	Status         string
+	Debt           *Debt
+	PreferredSlots []TimeSlot
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+type StudentPreferences struct {
+	RequestID int64
+	Student   Student
+	Slots     []TimeSlot
+}
+
+// SlotSuggestion is the window preferred by the largest number of students
+// who requested a retake of the exam. Slot is nil when nobody attached
+// preferences.
+type SlotSuggestion struct {
+	ExamID          int64
+	Slot            *TimeSlot
+	CoveredStudents []Student
+	Preferences     []StudentPreferences
+}
@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+type RetakeSession struct {
+	ID              int64
+	Exam            *Exam
+	Teacher         *Teacher
+	Date            time.Time
+	Address         string
+	Room            *Room
+	Capacity        int64
+	SignupDeadline  time.Time
+	CancelDeadline  time.Time
+	BookedCount     int64
+	WaitlistedCount int64
+}
+
+// RetakeBooking Position is the place in the waitlist, it is zero for booked and cancelled seats.
+type RetakeBooking struct {
+	ID        int64
+	SessionID int64
+	Status    string
+	Position  int64
+	Debt      *Debt
+	CreatedAt time.Time
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+type Room struct {
+	ID         int64
+	Building   string
+	Number     string
+	Capacity   int64
+	Accessible bool
+}
+
+type RoomFilters struct {
+	MinCapacity    int64
+	OnlyAccessible bool
+	Limit          int64
+	Offset         int64
+}
+
+// ScheduleConflict is a retake that overlaps with the one being scheduled.
+// Kind tells what is double-booked: the room, the teacher, a student or the
+// seats of the room.
+type ScheduleConflict struct {
+	Kind        string
+	Date        time.Time
+	ExamID      int64
+	DebtID      int64
+	SessionID   int64
+	RoomID      int64
+	TeacherUUID string
+	StudentUUID string
+}
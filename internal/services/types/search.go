@@ -0,0 +1,17 @@
+package types
+
+// SearchRequest is a search of people, groups and exams. Empty Kinds
+// searches all of them, zero Limit is the default page size.
+type SearchRequest struct {
+	Query string
+	Kinds []string
+	Limit int64
+}
+
+type SearchHit struct {
+	Kind     string
+	ID       string
+	Title    string
+	Subtitle string
+	Rank     float64
+}
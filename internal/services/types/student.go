@@ -8,9 +8,11 @@ type Student struct {
 	Email      string
 	Group      *Group
 	Password   string
+	Version    int64
 }
 
 type Group struct {
-	ID   int64
-	Name string
+	ID      int64
+	Name    string
+	Version int64
 }
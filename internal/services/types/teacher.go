@@ -7,4 +7,5 @@ type Teacher struct {
 	MiddleName string
 	Email      string
 	Password   string
+	Version    int64
 }
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// TeacherAvailability StartMinute and EndMinute are minutes since midnight of
+// a weekly window, StartsAt and EndsAt bound exceptions and blackouts.
+type TeacherAvailability struct {
+	ID          int64
+	Kind        string
+	Weekday     time.Weekday
+	StartMinute int64
+	EndMinute   int64
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Note        string
+}
@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// TimetableRequest selects unscheduled debts to place between PeriodStart
+// and Deadline, empty filters select all of them.
+type TimetableRequest struct {
+	PeriodStart  time.Time
+	Deadline     time.Time
+	ExamIDs      []int64
+	GroupIDs     []int64
+	TeacherUUIDs []string
+}
+
+type TimetableDraft struct {
+	ID          int64
+	Status      string
+	PeriodStart time.Time
+	Deadline    time.Time
+	CreatedBy   string
+	CreatedAt   time.Time
+	AppliedAt   *time.Time
+	Items       []TimetableDraftItem
+}
+
+// TimetableDraftItem Date and Room are nil for a debt that was not placed.
+type TimetableDraftItem struct {
+	ID     int64
+	Debt   *Debt
+	Room   *Room
+	Date   *time.Time
+	Reason string
+}
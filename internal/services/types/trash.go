@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// TrashFilters lists the deleted entities. Empty Kinds lists all of them,
+// zero Limit is the default page size.
+type TrashFilters struct {
+	Kinds  []string
+	Limit  int64
+	Offset int64
+}
+
+type TrashItem struct {
+	Kind      string
+	ID        string
+	Title     string
+	DeletedAt time.Time
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VanLavr/Diploma-fin/internal/domain/commands"
+	"github.com/VanLavr/Diploma-fin/internal/domain/repositories"
+	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
+	"github.com/VanLavr/Diploma-fin/internal/infrastructure/memory"
+)
+
+// fixture is a debt of a student of a group to a teacher for an exam,
+// stored in an in-memory repository.
+type fixture struct {
+	repo    repositories.Repository
+	mailer  *memory.StudentMailer
+	groupID int64
+	student string
+	teacher string
+	examID  int64
+	debtID  int64
+}
+
+func newRepository() (repositories.Repository, *memory.StudentMailer) {
+	mailer := memory.NewStudentMailer()
+	return memory.NewRepository(mailer), mailer
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	ctx := context.Background()
+	repo, mailer := newRepository()
+	f := &fixture{repo: repo, mailer: mailer}
+
+	var err error
+	if f.groupID, err = repo.CreateGroup(ctx, commands.CreateGroup{Name: "ИВТ-41"}); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if f.student, err = repo.CreateStudent(ctx, commands.CreateStudent{
+		FirstName: "Иван",
+		LastName:  "Петров",
+		Email:     "petrov@example.com",
+		GroupID:   f.groupID,
+	}); err != nil {
+		t.Fatalf("create student: %v", err)
+	}
+	if f.teacher, err = repo.CreateTeacher(ctx, commands.CreateTeacher{
+		FirstName: "Анна",
+		LastName:  "Сидорова",
+		Email:     "sidorova@example.com",
+	}); err != nil {
+		t.Fatalf("create teacher: %v", err)
+	}
+	if f.examID, err = repo.CreateExam(ctx, commands.CreateExam{
+		Name:           "Базы данных",
+		AssessmentType: valueobjects.AssessmentExam,
+	}); err != nil {
+		t.Fatalf("create exam: %v", err)
+	}
+	if f.debtID, err = repo.CreateDebt(ctx, commands.CreateDebt{
+		ExamID:      f.examID,
+		StudentUUID: f.student,
+		TeacherUUID: f.teacher,
+	}); err != nil {
+		t.Fatalf("create debt: %v", err)
+	}
+
+	return f
+}
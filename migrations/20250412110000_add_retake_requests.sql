@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists retake_requests(
+    id serial primary key,
+    debt_id integer not null references debts(id) on delete cascade,
+    student_uuid text not null references students(uuid),
+    teacher_uuid text references teachers(uuid),
+    status text not null default 'pending',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    constraint retake_requests_status_check check (status in ('pending', 'acknowledged', 'scheduled', 'declined'))
+);
+
+-- only one open request per debt at a time
+create unique index if not exists retake_requests_open_debt_idx
+    on retake_requests(debt_id)
+    where status in ('pending', 'acknowledged');
+
+create index if not exists retake_requests_teacher_status_idx
+    on retake_requests(teacher_uuid, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table retake_requests;
+-- +goose StatementEnd
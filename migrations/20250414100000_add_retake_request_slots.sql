@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists retake_request_slots(
+    id serial primary key,
+    request_id integer not null references retake_requests(id) on delete cascade,
+    starts_at timestamptz not null,
+    ends_at timestamptz not null,
+    constraint retake_request_slots_range_check check (ends_at > starts_at)
+);
+
+create index if not exists retake_request_slots_request_idx
+    on retake_request_slots(request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table retake_request_slots;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists retake_sessions(
+    id serial primary key,
+    exam_id integer not null references exams(id),
+    teacher_uuid text not null references teachers(uuid),
+    date timestamp not null,
+    address text not null default '',
+    capacity integer not null,
+    signup_deadline timestamp not null,
+    cancel_deadline timestamp not null,
+    created_at timestamptz not null default now(),
+    constraint retake_sessions_capacity_check check (capacity > 0),
+    constraint retake_sessions_deadlines_check check (signup_deadline <= date and cancel_deadline <= date)
+);
+
+create index if not exists retake_sessions_exam_idx
+    on retake_sessions(exam_id);
+
+create table if not exists retake_bookings(
+    id serial primary key,
+    session_id integer not null references retake_sessions(id) on delete cascade,
+    debt_id integer not null references debts(id) on delete cascade,
+    student_uuid text not null references students(uuid),
+    status text not null,
+    created_at timestamptz not null default clock_timestamp(),
+    updated_at timestamptz not null default clock_timestamp(),
+    constraint retake_bookings_status_check check (status in ('booked', 'waitlisted', 'cancelled'))
+);
+
+-- a debt can hold a seat or a waitlist place in one session only
+create unique index if not exists retake_bookings_active_debt_idx
+    on retake_bookings(debt_id)
+    where status <> 'cancelled';
+
+create index if not exists retake_bookings_session_status_idx
+    on retake_bookings(session_id, status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table retake_bookings;
+drop table retake_sessions;
+-- +goose StatementEnd
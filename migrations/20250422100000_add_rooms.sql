@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists rooms(
+    id serial primary key,
+    building text not null,
+    number text not null,
+    capacity integer not null,
+    accessible boolean not null default false,
+    constraint rooms_capacity_check check (capacity > 0),
+    constraint rooms_building_number_key unique (building, number)
+);
+
+-- rooms that are still referenced by retakes can not be deleted
+alter table debts add column if not exists room_id integer references rooms(id) on delete restrict;
+alter table retake_sessions add column if not exists room_id integer references rooms(id) on delete restrict;
+
+create index if not exists debts_room_date_idx
+    on debts(room_id, date);
+create index if not exists debts_teacher_date_idx
+    on debts(teacher_uuid, date);
+create index if not exists retake_sessions_room_date_idx
+    on retake_sessions(room_id, date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists retake_sessions_room_date_idx;
+drop index if exists debts_teacher_date_idx;
+drop index if exists debts_room_date_idx;
+alter table retake_sessions drop column if exists room_id;
+alter table debts drop column if exists room_id;
+drop table rooms;
+-- +goose StatementEnd
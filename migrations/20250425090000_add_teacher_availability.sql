@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- weekly windows repeat every week, weekday follows extract(dow): 0 is Sunday,
+-- minutes are counted from midnight. Exceptions add one-off windows and
+-- blackouts forbid retakes in [starts_at, ends_at).
+create table if not exists teacher_availability(
+    id serial primary key,
+    teacher_uuid text not null references teachers(uuid) on delete cascade,
+    kind text not null,
+    weekday smallint,
+    start_minute integer,
+    end_minute integer,
+    starts_at timestamp,
+    ends_at timestamp,
+    note text not null default '',
+    created_at timestamptz not null default now(),
+    constraint teacher_availability_kind_check check (kind in ('weekly', 'exception', 'blackout')),
+    constraint teacher_availability_weekly_check check (
+        kind <> 'weekly' or (
+            weekday between 0 and 6
+            and start_minute >= 0
+            and end_minute <= 1440
+            and start_minute < end_minute
+        )
+    ),
+    constraint teacher_availability_interval_check check (
+        kind = 'weekly' or (starts_at is not null and ends_at is not null and starts_at < ends_at)
+    )
+);
+
+create index if not exists teacher_availability_teacher_idx
+    on teacher_availability(teacher_uuid, kind);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table teacher_availability;
+-- +goose StatementEnd
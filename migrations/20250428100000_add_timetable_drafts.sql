@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists timetable_drafts(
+    id serial primary key,
+    status text not null default 'draft',
+    period_start timestamp not null,
+    deadline timestamp not null,
+    created_by text not null default '',
+    created_at timestamptz not null default now(),
+    applied_at timestamptz,
+    constraint timetable_drafts_status_check check (status in ('draft', 'applied', 'discarded')),
+    constraint timetable_drafts_period_check check (period_start < deadline)
+);
+
+-- items without a date are debts the generator could not place, reason tells why
+create table if not exists timetable_draft_items(
+    id serial primary key,
+    draft_id integer not null references timetable_drafts(id) on delete cascade,
+    debt_id integer not null references debts(id) on delete cascade,
+    room_id integer references rooms(id) on delete cascade,
+    date timestamp,
+    reason text not null default ''
+);
+
+create index if not exists timetable_draft_items_draft_idx
+    on timetable_draft_items(draft_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table timetable_draft_items;
+drop table timetable_drafts;
+-- +goose StatementEnd
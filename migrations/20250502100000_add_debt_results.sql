@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- credit is pass/fail, the other assessment types are graded from 2 to 5.
+alter table exams add column if not exists assessment_type text not null default 'exam';
+alter table exams add constraint exams_assessment_type_check
+    check (assessment_type in ('exam', 'credit', 'graded_credit', 'coursework'));
+
+-- a debt is closed by its first passing result and stays for the history.
+alter table debts add column if not exists closed_at timestamptz;
+
+-- one result per retake of a debt, attempted_at is the retake date.
+create table if not exists debt_results(
+    id serial primary key,
+    debt_id integer not null references debts(id) on delete cascade,
+    session_id integer references retake_sessions(id) on delete set null,
+    teacher_uuid text not null references teachers(uuid),
+    passed boolean not null,
+    grade smallint,
+    attempted_at timestamp not null,
+    recorded_at timestamptz not null default now(),
+    constraint debt_results_grade_check check (grade is null or grade between 2 and 5),
+    constraint debt_results_attempt_unique unique (debt_id, attempted_at)
+);
+
+create index if not exists debt_results_session_idx on debt_results(session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table debt_results;
+alter table debts drop column closed_at;
+alter table exams drop constraint exams_assessment_type_check;
+alter table exams drop column assessment_type;
+-- +goose StatementEnd
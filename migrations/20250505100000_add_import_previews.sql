@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a dry-run import keeps the parsed records until an admin confirms them
+-- with the token or the preview expires.
+create table if not exists import_previews(
+    token text primary key,
+    created_by text not null,
+    records jsonb not null,
+    issues jsonb not null default '[]',
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    confirmed_at timestamptz
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table import_previews;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an import profile tells the parser which sheets to read, where the header
+-- row is and how cells map to students, teachers and exams.
+create table if not exists import_profiles(
+    id bigserial primary key,
+    name text not null unique,
+    layout text not null check (layout in ('matrix', 'list')),
+    sheets text[] not null default '{}',
+    header_row integer not null default 1 check (header_row >= 1),
+    separator text not null default ' ',
+    student_fields text[] not null default '{}',
+    teacher_fields text[] not null default '{}',
+    columns jsonb not null default '{}',
+    created_at timestamptz not null default now()
+);
+
+alter table import_previews
+    add column profile_id bigint references import_profiles(id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table import_previews drop column profile_id;
+drop table import_profiles;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- imports run in the background, a worker claims queued jobs and keeps
+-- updated_at fresh while it runs one, so a job of a stopped worker is
+-- claimed again.
+create table if not exists import_jobs(
+    id bigserial primary key,
+    source text not null check (source in ('file', 'preview')),
+    status text not null default 'queued' check (status in ('queued', 'running', 'completed', 'failed')),
+    actor text not null,
+    file_name text not null default '',
+    checksum text not null default '',
+    profile_id bigint references import_profiles(id) on delete set null,
+    preview_token text references import_previews(token) on delete set null,
+    records jsonb not null,
+    issues jsonb not null default '[]',
+    errors jsonb not null default '[]',
+    total integer not null default 0,
+    processed integer not null default 0,
+    created integer not null default 0,
+    existing integer not null default 0,
+    failed integer not null default 0,
+    attempts integer not null default 0,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    started_at timestamptz,
+    finished_at timestamptz
+);
+
+create index if not exists import_jobs_status_idx on import_jobs(status, id);
+create index if not exists import_jobs_checksum_idx on import_jobs(checksum);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table import_jobs;
+-- +goose StatementEnd
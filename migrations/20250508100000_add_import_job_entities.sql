@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every group, student, teacher, exam and debt an import job created. A
+-- rollback deletes the ones nothing else references and drops their rows,
+-- the rest stay for a later rollback.
+create table if not exists import_job_entities(
+    job_id bigint not null references import_jobs(id) on delete cascade,
+    entity text not null check (entity in ('group', 'student', 'teacher', 'exam', 'debt')),
+    entity_id text not null,
+    created_at timestamptz not null default now(),
+    primary key (entity, entity_id)
+);
+
+create index if not exists import_job_entities_job_idx on import_job_entities(job_id);
+
+alter table import_jobs add column if not exists rolled_back_at timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table import_jobs drop column rolled_back_at;
+drop table import_job_entities;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the search matches a query against these documents with pg_trgm for
+-- fuzzy and partial words and the russian text search for word forms. The
+-- expressions are the ones the search queries use, or the indexes are not
+-- picked.
+create extension if not exists pg_trgm;
+
+create index if not exists students_search_trgm_idx on students using gin (
+    (coalesce(last_name, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(middle_name, '') || ' ' || coalesce(email, '')) gin_trgm_ops
+);
+create index if not exists students_search_tsv_idx on students using gin (
+    to_tsvector('russian', coalesce(last_name, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(middle_name, '') || ' ' || coalesce(email, ''))
+);
+
+create index if not exists teachers_search_trgm_idx on teachers using gin (
+    (coalesce(last_name, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(middle_name, '') || ' ' || coalesce(email, '')) gin_trgm_ops
+);
+create index if not exists teachers_search_tsv_idx on teachers using gin (
+    to_tsvector('russian', coalesce(last_name, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(middle_name, '') || ' ' || coalesce(email, ''))
+);
+
+create index if not exists groups_search_trgm_idx on groups using gin ((coalesce(name, '')) gin_trgm_ops);
+create index if not exists groups_search_tsv_idx on groups using gin (to_tsvector('russian', coalesce(name, '')));
+
+create index if not exists exams_search_trgm_idx on exams using gin ((coalesce(name, '')) gin_trgm_ops);
+create index if not exists exams_search_tsv_idx on exams using gin (to_tsvector('russian', coalesce(name, '')));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists exams_search_tsv_idx;
+drop index if exists exams_search_trgm_idx;
+drop index if exists groups_search_tsv_idx;
+drop index if exists groups_search_trgm_idx;
+drop index if exists teachers_search_tsv_idx;
+drop index if exists teachers_search_trgm_idx;
+drop index if exists students_search_tsv_idx;
+drop index if exists students_search_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a deleted row keeps its place until it is restored or purged, debts,
+-- requests and sessions go on pointing at it. Its email stays taken.
+alter table students add column if not exists deleted_at timestamptz;
+alter table teachers add column if not exists deleted_at timestamptz;
+alter table groups add column if not exists deleted_at timestamptz;
+alter table exams add column if not exists deleted_at timestamptz;
+
+create index if not exists students_deleted_at_idx on students(deleted_at) where deleted_at is not null;
+create index if not exists teachers_deleted_at_idx on teachers(deleted_at) where deleted_at is not null;
+create index if not exists groups_deleted_at_idx on groups(deleted_at) where deleted_at is not null;
+create index if not exists exams_deleted_at_idx on exams(deleted_at) where deleted_at is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists exams_deleted_at_idx;
+drop index if exists groups_deleted_at_idx;
+drop index if exists teachers_deleted_at_idx;
+drop index if exists students_deleted_at_idx;
+
+alter table exams drop column deleted_at;
+alter table groups drop column deleted_at;
+alter table teachers drop column deleted_at;
+alter table students drop column deleted_at;
+-- +goose StatementEnd
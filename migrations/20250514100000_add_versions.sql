@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every update bumps the version, an update based on an older one is
+-- rejected, so two admins editing the same row can not overwrite each other.
+alter table students add column if not exists version bigint not null default 1;
+alter table teachers add column if not exists version bigint not null default 1;
+alter table groups add column if not exists version bigint not null default 1;
+alter table exams add column if not exists version bigint not null default 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table exams drop column version;
+alter table groups drop column version;
+alter table teachers drop column version;
+alter table students drop column version;
+-- +goose StatementEnd
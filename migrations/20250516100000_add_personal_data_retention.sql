@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a student who graduated or was expelled has left, their names, email and
+-- password are anonymized once the retention is over. The row stays, so
+-- their debts and results keep counting.
+alter table students add column if not exists status text not null default 'active';
+alter table students add column if not exists left_at timestamptz;
+alter table students add column if not exists anonymized_at timestamptz;
+alter table students add constraint students_status_check
+    check (status in ('active', 'graduated', 'expelled'));
+
+create index if not exists students_left_at_idx on students(left_at)
+    where left_at is not null and anonymized_at is null;
+
+-- the audit outlives the personal data, it keeps nothing but the uuids
+create table if not exists personal_data_audit(
+    id serial primary key,
+    student_uuid text not null,
+    action text not null,
+    actor_uuid text not null default '',
+    created_at timestamptz not null default now(),
+    constraint personal_data_audit_action_check check (action in ('export', 'erase', 'anonymize'))
+);
+
+create index if not exists personal_data_audit_student_idx on personal_data_audit(student_uuid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table personal_data_audit;
+
+drop index if exists students_left_at_idx;
+
+alter table students drop constraint students_status_check;
+alter table students drop column anonymized_at;
+alter table students drop column left_at;
+alter table students drop column status;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- debts are versioned like students, teachers, groups and exams, an update
+-- based on an older version is rejected.
+alter table debts add column if not exists version bigint not null default 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table debts drop column version;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+// Package migrations embeds the goose migrations, so the binary can apply
+// them itself, see postgres.Migrate.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
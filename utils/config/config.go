@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -19,6 +20,14 @@ type Config struct {
 	SMTP2OAuthCode    string `env:"SMTP2OAUTHCODE"`
 	Secret            string `env:"SECRET"`
 	AdminPass         string `env:"ADMINPASS"`
+	MigrateOnStart    bool   `env:"MIGRATEONSTART"`
+	// TrashRetention is how long deleted entities stay restorable, "720h".
+	// Zero keeps the default, a negative one never purges them.
+	TrashRetention time.Duration `env:"TRASHRETENTION"`
+	// PersonalDataRetention is how many years after a student left its
+	// personal data is kept. Zero keeps the default, a negative one never
+	// anonymizes it.
+	PersonalDataRetention int `env:"PERSONALDATARETENTION"`
 }
 
 func ReadConfig() (*Config, error) {
@@ -28,16 +37,19 @@ func ReadConfig() (*Config, error) {
 	errors.FatalOnError(err)
 
 	config := &Config{
-		SMTPHost:          v.GetString("SMTPHOST"),
-		SMTPPort:          v.GetString("SMTPPORT"),
-		AuthEmail:         v.GetString("AUTHEMAIL"),
-		AuthEmailPassword: v.GetString("AUTHEMAILPASSWORD"),
-		Port:              v.GetString("PORT"),
-		WithJWTAuth:       v.GetBool("WITHJWTAUTH"),
-		DbString:          v.GetString("DBSTRING"),
-		SMTP2OAuthCode:    v.GetString("SMTP2OAUTHCODE"),
-		Secret:            v.GetString("SECRET"),
-		AdminPass:         v.GetString("ADMINPASS"),
+		SMTPHost:              v.GetString("SMTPHOST"),
+		SMTPPort:              v.GetString("SMTPPORT"),
+		AuthEmail:             v.GetString("AUTHEMAIL"),
+		AuthEmailPassword:     v.GetString("AUTHEMAILPASSWORD"),
+		Port:                  v.GetString("PORT"),
+		WithJWTAuth:           v.GetBool("WITHJWTAUTH"),
+		DbString:              v.GetString("DBSTRING"),
+		SMTP2OAuthCode:        v.GetString("SMTP2OAUTHCODE"),
+		Secret:                v.GetString("SECRET"),
+		AdminPass:             v.GetString("ADMINPASS"),
+		MigrateOnStart:        v.GetBool("MIGRATEONSTART"),
+		TrashRetention:        v.GetDuration("TRASHRETENTION"),
+		PersonalDataRetention: v.GetInt("PERSONALDATARETENTION"),
 	}
 	fmt.Println(config)
 
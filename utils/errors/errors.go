@@ -18,5 +18,21 @@ var ErroNoItemsFound = errors.New("no items found")
 var ErroInvalidPassword = errors.New("wrong password")
 var ErrUserDoesNotHaveRights = errors.New("user doesn't have required rights")
 var ErrCannotDetermineUUID = errors.New("token is malformed: can't determine structure")
+var ErrRetakeRequestAlreadyExists = errors.New("retake request for this debt is already open")
+var ErrRetakeRequestCooldown = errors.New("retake for this debt was requested too recently")
+var ErrRetakeAlreadyBooked = errors.New("debt is already booked for a retake session")
+var ErrRetakeSignupClosed = errors.New("sign-up for the retake session is closed")
+var ErrRetakeCancellationClosed = errors.New("booking can not be cancelled after the cancellation deadline")
+var ErrRoomInUse = errors.New("room is referenced by scheduled retakes")
+var ErrScheduleConflict = errors.New("retake overlaps with another retake")
+var ErrTimetableDraftClosed = errors.New("timetable draft is already applied or discarded")
+var ErrTimetableDraftOutdated = errors.New("debts of the timetable draft were changed after it was generated")
+var ErrResultAlreadyRecorded = errors.New("result of this retake is already recorded")
+var ErrDebtClosed = errors.New("debt is already closed")
+var ErrImportPreviewClosed = errors.New("import preview is already confirmed or expired")
+var ErrImportJobActive = errors.New("import job is queued or running")
+var ErrVersionMismatch = errors.New("entity was changed since it was read")
+var ErrVersionRequired = errors.New("version of the entity is required")
+var ErrInTrash = errors.New("entity is in the trash")
 
 const MethodKey string = "in method"
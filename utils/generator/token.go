@@ -0,0 +1,18 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const TOKENLEN = 16
+
+// GenerateToken returns length random bytes encoded as hex.
+func GenerateToken(length int) (string, error) {
+	token := make([]byte, length)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
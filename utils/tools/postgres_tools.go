@@ -2,12 +2,29 @@ package tools
 
 import (
 	"context"
+	e "errors"
 
 	valueobjects "github.com/VanLavr/Diploma-fin/internal/domain/value_objects"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
 )
 
 func GetTransaction(ctx context.Context) (pgx.Tx, bool) {
 	tx, ok := ctx.Value(valueobjects.TransactionKey{}).(pgx.Tx)
 	return tx, ok
 }
+
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return e.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return e.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}